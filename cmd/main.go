@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	authHandler "rakit-tiket-be/internal/app/app_auth/handler"
 	authService "rakit-tiket-be/internal/app/app_auth/service"
@@ -39,11 +40,29 @@ import (
 	paymentHandler "rakit-tiket-be/internal/app/app_payment/handler"
 	paymentService "rakit-tiket-be/internal/app/app_payment/service"
 
+	jobHandler "rakit-tiket-be/internal/app/app_job/handler"
+	jobService "rakit-tiket-be/internal/app/app_job/service"
+
+	outboxHandler "rakit-tiket-be/internal/app/app_outbox/handler"
+	outboxService "rakit-tiket-be/internal/app/app_outbox/service"
+
+	addonHandler "rakit-tiket-be/internal/app/app_addon/handler"
+	addonService "rakit-tiket-be/internal/app/app_addon/service"
+	presaleHandler "rakit-tiket-be/internal/app/app_presale/handler"
+	presaleService "rakit-tiket-be/internal/app/app_presale/service"
+	promoHandler "rakit-tiket-be/internal/app/app_promo/handler"
+	promoService "rakit-tiket-be/internal/app/app_promo/service"
+	waitlistHandler "rakit-tiket-be/internal/app/app_waitlist/handler"
+	waitlistService "rakit-tiket-be/internal/app/app_waitlist/service"
+
 	"rakit-tiket-be/config"
 	"rakit-tiket-be/internal/pkg/client"
+	"rakit-tiket-be/internal/pkg/cron"
 	"rakit-tiket-be/internal/pkg/email"
+	"rakit-tiket-be/internal/pkg/lifecycle"
 	"rakit-tiket-be/internal/pkg/middleware"
 	"rakit-tiket-be/internal/pkg/payment"
+	"rakit-tiket-be/internal/pkg/qrsign"
 	"rakit-tiket-be/pkg/constant"
 	"rakit-tiket-be/pkg/util"
 
@@ -104,46 +123,94 @@ func main() {
 		os.Exit(1)
 	}
 	midtransIsProduction := midtransEnvironment == "true"
-	paymentFactory := payment.NewPaymentFactory(midtransServerKey, midtransIsProduction)
+	xenditConfig := payment.XenditConfig{
+		SecretKey:     envgo.GetString("XENDIT_SECRET_KEY", ""),
+		CallbackToken: envgo.GetString("XENDIT_CALLBACK_TOKEN", ""),
+		BaseURL:       envgo.GetString("XENDIT_BASE_URL", payment.XenditDefaultBaseURL),
+	}
+	dokuConfig := payment.DokuConfig{
+		ClientID:         envgo.GetString("DOKU_CLIENT_ID", ""),
+		SecretKey:        envgo.GetString("DOKU_SECRET_KEY", ""),
+		BaseURL:          envgo.GetString("DOKU_BASE_URL", payment.DokuDefaultBaseURL),
+		NotificationPath: envgo.GetString("DOKU_NOTIFICATION_PATH", payment.DokuDefaultNotificationPath),
+	}
+	paymentFactory := payment.NewPaymentFactory(midtransServerKey, midtransIsProduction, xenditConfig, dokuConfig)
 	emailSvc := email.MakeEmailService(log, smtpHost, smtpPort, smtpUser, smtpPass, senderName, senderEmail)
 
+	// Kunci penandatangan QR tiket; kunci lama tetap dicantumkan saat rotasi.
+	// Kode tanpa tanda tangan (nomor order / TKT...) mudah ditebak, hanya diterima bila QR_ALLOW_UNSIGNED=true.
+	qrSigner, err := qrsign.NewSigner(qrsign.Config{
+		Keys:          envgo.GetString("QR_SIGNING_KEYS", ""),
+		ActiveKeyID:   envgo.GetString("QR_SIGNING_ACTIVE_KEY", ""),
+		AllowUnsigned: envgo.GetString("QR_ALLOW_UNSIGNED", "false") == "true",
+	})
+	if err != nil {
+		log.Error(context.Background(), "Invalid QR signing configuration: "+err.Error())
+		os.Exit(1)
+	}
+
 	// Service
 	landingPageService := landingPageService.MakeLandingPageService(sqlDB)
 	fileService := fileService.MakeFileService(log, sqlDB)
 	authSvc := authService.MakeAuthService(log, sqlDB)
 
-	ticketSvc := ticketService.MakeTicketService(log, sqlDB)
+	presaleSvc := presaleService.MakePresaleService(log, sqlDB)
+	ticketSvc := ticketService.MakeTicketService(log, sqlDB, presaleSvc)
+	priceTierSvc := ticketService.MakePriceTierService(log, sqlDB)
 	eventSvc := eventService.MakeEventService(log, sqlDB)
 	artistSvc := artistService.MakeArtistService(log, sqlDB)
+	promoSvc := promoService.MakePromoService(log, sqlDB)
+	waitlistSvc := waitlistService.MakeWaitlistService(log, sqlDB)
+	addonSvc := addonService.MakeAddonService(log, sqlDB)
 
 	bankAccountSvc := paymentService.MakeBankAccountService(log, sqlDB)
-	manualTransferSvc := paymentService.MakeManualTransferService(log, sqlDB, emailSvc)
+	manualTransferSvc := paymentService.MakeManualTransferService(log, sqlDB)
 	paymentConfigSvc := paymentService.MakePaymentConfigService(log, sqlDB)
 	checkoutInitiator := paymentService.MakeCheckoutInitiator(log, sqlDB, paymentFactory, bankAccountSvc)
 	regService := regService.MakeRegistrantService(log, sqlDB, checkoutInitiator, paymentConfigSvc)
 	checkoutSvc := paymentService.MakeCheckoutService(log, sqlDB, paymentFactory, bankAccountSvc, paymentConfigSvc)
-	ordService := orderService.MakeOrderService(log, sqlDB, paymentFactory, emailSvc)
-
-	gateSvc := gateService.MakeGateService(log, sqlDB)
-	scanSvc := gateService.MakeScanService(log, sqlDB)
-
-	hypeSvc := hypeService.MakeHypeService(log, sqlDB)
+	ordService := orderService.MakeOrderService(log, sqlDB, paymentFactory)
+	refundSvc := paymentService.MakeRefundService(log, sqlDB, paymentFactory)
+
+	gateSvc := gateService.MakeGateService(log, sqlDB, qrSigner)
+	gateFeed := gateService.MakeGateFeed(log, sqlDB, gateSvc, gateService.GateFeedConfig{
+		StatsInterval:   time.Duration(envgo.GetInt("GATE_FEED_STATS_INTERVAL_SECONDS", 5)) * time.Second,
+		ScanRateAlert:   envgo.GetInt("GATE_FEED_SCAN_RATE_ALERT", 60),
+		RejectRateAlert: envgo.GetInt("GATE_FEED_REJECT_RATE_ALERT", 20),
+	})
+	scanSvc := gateService.MakeScanService(log, sqlDB, qrSigner, gateFeed)
+	syncSvc := gateService.MakeSyncService(log, sqlDB, qrSigner, gateFeed)
+	deviceSvc := gateService.MakeDeviceService(log, sqlDB)
+	physicalTicketSvc := gateService.MakePhysicalTicketService(log, sqlDB, qrSigner)
+	merchPickupSvc := gateService.MakeMerchPickupService(log, sqlDB, qrSigner)
+
+	hypeSvc := hypeService.MakeHypeService(log, sqlDB, presaleSvc)
+
+	jobSvc := jobService.MakeJobService(log, sqlDB)
+	outboxSvc := outboxService.MakeOutboxService(log, sqlDB, emailSvc, qrSigner, envgo.GetString("WAITLIST_CLAIM_URL", "https://rakittiket.com/waitlist/claim"))
 
 	// Adapter
 	landingPageAdapter := landingPageHandler.MakeHttpAdapter(landingPageService, fileService, authMiddleware)
 	fileAdapter := fileHandler.MakeFileAdapter(log, fileService)
 	authAdapter := authHandler.MakeHttpAdapter(log, authSvc, authMiddleware)
-	ticketAdapter := ticketHandler.MakeHttpAdapter(ticketSvc, authMiddleware)
+	ticketAdapter := ticketHandler.MakeHttpAdapter(ticketSvc, priceTierSvc, authMiddleware)
 	registrantHttpHandler := regHandler.MakeHttpAdapter(regService, authMiddleware)
 	orderHttpHandler := orderHandler.MakeHttpAdapter(log, ordService, authMiddleware)
 	eventAdapter := eventHandler.MakeHttpAdapter(eventSvc, authMiddleware)
 	artistAdapter := artistHandler.MakeHttpAdapter(artistSvc, fileService, authMiddleware)
-	paymentAdapter := paymentHandler.MakeHttpAdapter(log, bankAccountSvc, manualTransferSvc, checkoutSvc, paymentConfigSvc, fileService, authMiddleware)
+	promoAdapter := promoHandler.MakeHttpAdapter(promoSvc, authMiddleware)
+	presaleAdapter := presaleHandler.MakeHttpAdapter(presaleSvc, authMiddleware)
+	waitlistAdapter := waitlistHandler.MakeHttpAdapter(waitlistSvc, authMiddleware)
+	addonAdapter := addonHandler.MakeHttpAdapter(addonSvc, authMiddleware)
+	paymentAdapter := paymentHandler.MakeHttpAdapter(log, bankAccountSvc, manualTransferSvc, checkoutSvc, paymentConfigSvc, refundSvc, fileService, authMiddleware)
 
-	gateAdapter := gateHandler.MakeGateHandler(log, gateSvc, scanSvc, authMiddleware)
+	gateAdapter := gateHandler.MakeGateHandler(log, gateSvc, scanSvc, syncSvc, deviceSvc, physicalTicketSvc, merchPickupSvc, gateFeed, authMiddleware)
 
 	hypeAdapter := hypeHandler.MakeHttpAdapter(log, hypeSvc, authMiddleware)
 
+	jobAdapter := jobHandler.MakeHttpAdapter(log, jobSvc, authMiddleware)
+	outboxAdapter := outboxHandler.MakeHttpAdapter(log, outboxSvc, authMiddleware)
+
 	// Register Routes
 	apiGroup := e.Group("/api")
 
@@ -155,31 +222,58 @@ func main() {
 	orderHttpHandler.RegisterRoute(apiGroup)
 	eventAdapter.RegisterRoute(apiGroup)
 	artistAdapter.RegisterRoute(apiGroup)
+	promoAdapter.RegisterRoute(apiGroup)
+	presaleAdapter.RegisterRoute(apiGroup)
+	waitlistAdapter.RegisterRoute(apiGroup)
+	addonAdapter.RegisterRoute(apiGroup)
 	paymentAdapter.RegisterRoute(apiGroup)
 
 	gateAdapter.RegisterRouter(apiGroup)
 
 	hypeAdapter.RegisterRoute(apiGroup)
 
+	jobAdapter.RegisterRoute(apiGroup)
+	outboxAdapter.RegisterRoute(apiGroup)
+
 	// Start Cron Scheduler
-	// scheduler := cron.NewScheduler(ordService, log)
-	// if err := scheduler.Start(); err != nil {
-	// 	log.Error(context.Background(), "Failed to start cron scheduler")
-	// 	os.Exit(1)
-	// }
-
-	// // Graceful Shutdown
-	// go func() {
-	// 	quit := make(chan os.Signal, 1)
-	// 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	// 	<-quit
-	// 	log.Info(context.Background(), "Shutting down server...")
-	// 	scheduler.Stop()
-	// 	os.Exit(0)
-	// }()
+	scheduler := cron.NewScheduler(log, sqlDB, jobSvc)
+	jobSpecs := cron.JobSpecs{
+		ExpireOrders:    envgo.GetString("CRON_EXPIRE_ORDERS", "0 */5 * * * *"),
+		ReconcileOrders: envgo.GetString("CRON_RECONCILE_ORDERS", "0 */10 * * * *"),
+		PaymentReminder: envgo.GetString("CRON_PAYMENT_REMINDER", "0 * * * * *"),
+		FlashSaleStart:  envgo.GetString("CRON_FLASH_SALE_START", "0 * * * * *"),
+		FlashSaleEnd:    envgo.GetString("CRON_FLASH_SALE_END", "0 * * * * *"),
+		EmailOutbox:     envgo.GetString("CRON_EMAIL_OUTBOX", "*/15 * * * * *"),
+		WaitlistOffers:  envgo.GetString("CRON_WAITLIST_OFFERS", "0 * * * * *"),
+	}
+	for _, job := range cron.DefaultJobs(jobSpecs, ordService, hypeSvc, outboxSvc, waitlistSvc) {
+		if err := scheduler.Register(job); err != nil {
+			log.Error(context.Background(), "Failed to register cron job: "+err.Error())
+			os.Exit(1)
+		}
+	}
+	scheduler.Start()
+
+	// Graceful Shutdown: stop HTTP -> tunggu cron (termasuk worker outbox) -> tunggu email -> tutup DB
+	shutdownTimeout, err := strconv.Atoi(envgo.GetString("SHUTDOWN_TIMEOUT_SECONDS", "30"))
+	if err != nil || shutdownTimeout <= 0 {
+		shutdownTimeout = 30
+	}
+	lifecycleManager := lifecycle.NewManager(log, time.Duration(shutdownTimeout)*time.Second)
+	// Stream feed gate diputus dulu agar e.Shutdown tidak menunggu koneksi SSE yang tidak pernah selesai
+	lifecycleManager.OnShutdown("gate_feed", gateFeed.Close)
+	lifecycleManager.OnShutdown("http", e.Shutdown)
+	lifecycleManager.OnShutdown("cron", scheduler.Stop)
+	lifecycleManager.OnShutdown("database", func(ctx context.Context) error {
+		return sqlDB.Close()
+	})
 
 	// Start Server
 	port := envgo.GetString("PORT", "8001")
 	log.Info(context.Background(), "Starting HTTP server on port "+port)
-	e.Logger.Fatal(e.Start(":" + port))
+	if err := lifecycleManager.Run(func() error { return e.Start(":" + port) }); err != nil {
+		log.Error(context.Background(), "Server shutdown with error: "+err.Error())
+		os.Exit(1)
+	}
+	log.Info(context.Background(), "Server stopped gracefully")
 }
@@ -23,7 +23,7 @@ func CorsOptions(clientOriginUrl string) cors.Options {
 	return cors.Options{
 		AllowedOrigins:   []string{clientOriginUrl},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-Device-Token"},
 		MaxAge:           86400,
 		AllowCredentials: true,
 	}
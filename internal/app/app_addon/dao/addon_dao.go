@@ -0,0 +1,208 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	baseDao "rakit-tiket-be/internal/pkg/dao"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	entity "rakit-tiket-be/pkg/entity/app_addon"
+	"rakit-tiket-be/pkg/util"
+
+	"gitlab.com/threetopia/sqlgo/v2"
+	"go.uber.org/zap"
+)
+
+type AddonDAO interface {
+	// Search mengurutkan add-on per event berdasarkan order_priority; Variants tidak diisi
+	Search(ctx context.Context, query entity.AddonQuery) (entity.Addons, error)
+	Insert(ctx context.Context, addons entity.Addons) error
+	Update(ctx context.Context, addons entity.Addons) error
+	SoftDelete(ctx context.Context, id pubEntity.UUID) error
+}
+
+type addonDAO struct {
+	log   util.LogUtil
+	dbTrx baseDao.DBTransaction
+}
+
+func MakeAddonDAO(log util.LogUtil, dbTrx baseDao.DBTransaction) AddonDAO {
+	return addonDAO{
+		log:   log,
+		dbTrx: dbTrx,
+	}
+}
+
+func (d addonDAO) Search(ctx context.Context, query entity.AddonQuery) (entity.Addons, error) {
+	sqlSelect := sqlgo.NewSQLGoSelect().
+		SetSQLSelect("a.id", "id").
+		SetSQLSelect("a.event_id", "event_id").
+		SetSQLSelect("a.name", "name").
+		SetSQLSelect("a.description", "description").
+		SetSQLSelect("a.type", "type").
+		SetSQLSelect("a.max_per_order", "max_per_order").
+		SetSQLSelect("a.order_priority", "order_priority").
+		SetSQLSelect("a.is_active", "is_active").
+		SetSQLSelect("a.deleted", "deleted").
+		SetSQLSelect("a.data_hash", "data_hash").
+		SetSQLSelect("a.created_at", "created_at").
+		SetSQLSelect("a.updated_at", "updated_at")
+
+	sqlFrom := sqlgo.NewSQLGoFrom().
+		SetSQLFrom("addons", "a")
+
+	sqlWhere := sqlgo.NewSQLGoWhere()
+	sqlWhere.SetSQLWhere("AND", "a.deleted", "=", false)
+
+	if len(query.IDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "a.id", "IN", query.IDs)
+	}
+	if len(query.EventIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "a.event_id", "IN", query.EventIDs)
+	}
+	if query.IsActive != nil {
+		sqlWhere.SetSQLWhere("AND", "a.is_active", "=", *query.IsActive)
+	}
+
+	sqlOrder := sqlgo.NewSQLGoOrder().
+		SetSQLOrder("a.event_id", "ASC").
+		SetSQLOrder("a.order_priority", "ASC").
+		SetSQLOrder("a.name", "ASC")
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoSelect(sqlSelect).
+		SetSQLGoFrom(sqlFrom).
+		SetSQLGoWhere(sqlWhere).
+		SetSQLGoOrder(sqlOrder)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "addonDAO.Search",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	rows, err := d.dbTrx.GetSqlDB().QueryContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "addonDAO.Search", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var addons entity.Addons
+	for rows.Next() {
+		var a entity.Addon
+		if err := rows.Scan(
+			&a.ID, &a.EventID, &a.Name, &a.Description, &a.Type,
+			&a.MaxPerOrder, &a.OrderPriority, &a.IsActive,
+			&a.Deleted, &a.DataHash, &a.CreatedAt, &a.UpdatedAt,
+		); err != nil {
+			d.log.Error(ctx, "addonDAO.Search.Scan", zap.Error(err))
+			return nil, err
+		}
+		addons = append(addons, a)
+	}
+
+	return addons, nil
+}
+
+func (d addonDAO) Insert(ctx context.Context, addons entity.Addons) error {
+	if len(addons) < 1 {
+		return fmt.Errorf("empty addon data")
+	}
+
+	sqlInsert := sqlgo.NewSQLGoInsert().
+		SetSQLInsert("addons").
+		SetSQLInsertColumn(
+			"id", "event_id", "name", "description", "type",
+			"max_per_order", "order_priority", "is_active",
+			"deleted", "data_hash", "created_at",
+		)
+
+	for i, a := range addons {
+		a.CreatedAt = time.Now()
+		if a.ID == "" {
+			a.ID = pubEntity.MakeUUID("ADDON", string(a.EventID), a.Name, a.CreatedAt.String())
+		}
+
+		sqlInsert.SetSQLInsertValue(
+			a.ID, a.EventID, a.Name, a.Description, a.Type,
+			a.MaxPerOrder, a.OrderPriority, a.IsActive,
+			a.Deleted, a.DataHash, a.CreatedAt,
+		)
+		addons[i] = a
+	}
+
+	sql := sqlgo.NewSQLGo().SetSQLSchema("public").SetSQLGoInsert(sqlInsert)
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "addonDAO.Insert", zap.String("SQL", sqlStr), zap.Int("Count", len(addons)))
+
+	_, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "addonDAO.Insert", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Update tidak memindahkan add-on ke event lain; event_id tetap dari data awal
+func (d addonDAO) Update(ctx context.Context, addons entity.Addons) error {
+	if len(addons) < 1 {
+		return fmt.Errorf("empty addon data")
+	}
+
+	for i, a := range addons {
+		now := time.Now()
+		a.UpdatedAt = &now
+
+		sql := sqlgo.NewSQLGo().
+			SetSQLSchema("public").
+			SetSQLUpdate("addons").
+			SetSQLUpdateValue("name", a.Name).
+			SetSQLUpdateValue("description", a.Description).
+			SetSQLUpdateValue("type", a.Type).
+			SetSQLUpdateValue("max_per_order", a.MaxPerOrder).
+			SetSQLUpdateValue("order_priority", a.OrderPriority).
+			SetSQLUpdateValue("is_active", a.IsActive).
+			SetSQLUpdateValue("data_hash", a.DataHash).
+			SetSQLUpdateValue("updated_at", a.UpdatedAt).
+			SetSQLWhere("AND", "id", "=", a.ID)
+
+		sqlStr := sql.BuildSQL()
+		sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+		d.log.Debug(ctx, "addonDAO.Update", zap.String("ID", string(a.ID)))
+
+		if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...); err != nil {
+			d.log.Error(ctx, "addonDAO.Update", zap.Error(err))
+			return err
+		}
+		addons[i] = a
+	}
+	return nil
+}
+
+func (d addonDAO) SoftDelete(ctx context.Context, id pubEntity.UUID) error {
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLUpdate("addons").
+		SetSQLUpdateValue("deleted", true).
+		SetSQLUpdateValue("updated_at", time.Now()).
+		SetSQLWhere("AND", "id", "=", id)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "addonDAO.SoftDelete", zap.String("ID", string(id)))
+
+	if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...); err != nil {
+		d.log.Error(ctx, "addonDAO.SoftDelete", zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,326 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	baseDao "rakit-tiket-be/internal/pkg/dao"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	entity "rakit-tiket-be/pkg/entity/app_addon"
+	"rakit-tiket-be/pkg/util"
+
+	"gitlab.com/threetopia/sqlgo/v2"
+	"go.uber.org/zap"
+)
+
+type AddonVariantDAO interface {
+	// Search mengurutkan varian per add-on berdasarkan variant_order
+	Search(ctx context.Context, query entity.AddonVariantQuery) (entity.AddonVariants, error)
+	// SearchForUpdate mengunci varian berurutan berdasarkan id agar registrasi paralel tidak deadlock
+	SearchForUpdate(ctx context.Context, query entity.AddonVariantQuery) (entity.AddonVariants, error)
+	Insert(ctx context.Context, variants entity.AddonVariants) error
+	// Update menghitung ulang available_qty dari total; booked_qty dan sold_qty tidak disentuh
+	Update(ctx context.Context, variants entity.AddonVariants) error
+	Delete(ctx context.Context, ids []string) error
+
+	// Siklus stok sama dengan tiket: available -> booked -> sold, atau kembali ke available
+	BookStock(ctx context.Context, id pubEntity.UUID, qty int) error
+	ConfirmSold(ctx context.Context, id pubEntity.UUID, qty int) error
+	ReleaseBooked(ctx context.Context, id pubEntity.UUID, qty int) error
+	ReleaseSold(ctx context.Context, id pubEntity.UUID, qty int) error
+}
+
+type addonVariantDAO struct {
+	log   util.LogUtil
+	dbTrx baseDao.DBTransaction
+}
+
+func MakeAddonVariantDAO(log util.LogUtil, dbTrx baseDao.DBTransaction) AddonVariantDAO {
+	return addonVariantDAO{
+		log:   log,
+		dbTrx: dbTrx,
+	}
+}
+
+func (d addonVariantDAO) buildSearch(query entity.AddonVariantQuery) sqlgo.SQLGo {
+	sqlSelect := sqlgo.NewSQLGoSelect().
+		SetSQLSelect("v.id", "id").
+		SetSQLSelect("v.addon_id", "addon_id").
+		SetSQLSelect("v.name", "name").
+		SetSQLSelect("v.price", "price").
+		SetSQLSelect("v.variant_order", "variant_order").
+		SetSQLSelect("v.is_active", "is_active").
+		SetSQLSelect("v.total", "total").
+		SetSQLSelect("v.available_qty", "available_qty").
+		SetSQLSelect("v.booked_qty", "booked_qty").
+		SetSQLSelect("v.sold_qty", "sold_qty").
+		SetSQLSelect("v.created_at", "created_at").
+		SetSQLSelect("v.updated_at", "updated_at")
+
+	sqlFrom := sqlgo.NewSQLGoFrom().
+		SetSQLFrom("addon_variants", "v")
+
+	sqlWhere := sqlgo.NewSQLGoWhere()
+
+	if len(query.IDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "v.id", "IN", query.IDs)
+	}
+	if len(query.AddonIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "v.addon_id", "IN", query.AddonIDs)
+	}
+
+	return sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoSelect(sqlSelect).
+		SetSQLGoFrom(sqlFrom).
+		SetSQLGoWhere(sqlWhere)
+}
+
+func (d addonVariantDAO) Search(ctx context.Context, query entity.AddonVariantQuery) (entity.AddonVariants, error) {
+	sql := d.buildSearch(query).
+		SetSQLGoOrder(sqlgo.NewSQLGoOrder().
+			SetSQLOrder("v.addon_id", "ASC").
+			SetSQLOrder("v.variant_order", "ASC"))
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "addonVariantDAO.Search",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	rows, err := d.dbTrx.GetSqlDB().QueryContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "addonVariantDAO.Search", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	return d.scanRows(ctx, rows)
+}
+
+func (d addonVariantDAO) SearchForUpdate(ctx context.Context, query entity.AddonVariantQuery) (entity.AddonVariants, error) {
+	sql := d.buildSearch(query).
+		SetSQLGoOrder(sqlgo.NewSQLGoOrder().
+			SetSQLOrder("v.id", "ASC"))
+
+	sqlStr := sql.BuildSQL() + " FOR UPDATE"
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "addonVariantDAO.SearchForUpdate",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	rows, err := d.dbTrx.GetSqlTx().QueryContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "addonVariantDAO.SearchForUpdate", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	return d.scanRows(ctx, rows)
+}
+
+func (d addonVariantDAO) scanRows(ctx context.Context, rows *sql.Rows) (entity.AddonVariants, error) {
+	var variants entity.AddonVariants
+	for rows.Next() {
+		var v entity.AddonVariant
+		if err := rows.Scan(
+			&v.ID, &v.AddonID, &v.Name, &v.Price, &v.VariantOrder, &v.IsActive,
+			&v.Total, &v.AvailableQty, &v.BookedQty, &v.SoldQty,
+			&v.CreatedAt, &v.UpdatedAt,
+		); err != nil {
+			d.log.Error(ctx, "addonVariantDAO.scanRows", zap.Error(err))
+			return nil, err
+		}
+		variants = append(variants, v)
+	}
+
+	return variants, nil
+}
+
+func (d addonVariantDAO) Insert(ctx context.Context, variants entity.AddonVariants) error {
+	if len(variants) < 1 {
+		return fmt.Errorf("empty addon variant data")
+	}
+
+	sqlInsert := sqlgo.NewSQLGoInsert().
+		SetSQLInsert("addon_variants").
+		SetSQLInsertColumn(
+			"id", "addon_id", "name", "price", "variant_order", "is_active",
+			"total", "available_qty", "booked_qty", "sold_qty", "created_at",
+		)
+
+	for i, v := range variants {
+		v.CreatedAt = time.Now()
+		if v.ID == "" {
+			v.ID = pubEntity.MakeUUID("ADDON_VARIANT", string(v.AddonID), v.Name, v.CreatedAt.String())
+		}
+		v.AvailableQty = v.Total
+		v.BookedQty = 0
+		v.SoldQty = 0
+
+		sqlInsert.SetSQLInsertValue(
+			v.ID, v.AddonID, v.Name, v.Price, v.VariantOrder, v.IsActive,
+			v.Total, v.AvailableQty, v.BookedQty, v.SoldQty, v.CreatedAt,
+		)
+		variants[i] = v
+	}
+
+	sql := sqlgo.NewSQLGo().SetSQLSchema("public").SetSQLGoInsert(sqlInsert)
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "addonVariantDAO.Insert", zap.String("SQL", sqlStr), zap.Int("Count", len(variants)))
+
+	_, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "addonVariantDAO.Insert", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (d addonVariantDAO) Update(ctx context.Context, variants entity.AddonVariants) error {
+	if len(variants) < 1 {
+		return fmt.Errorf("empty addon variant data")
+	}
+
+	query := `
+        UPDATE addon_variants
+        SET
+            name          = $1,
+            price         = $2,
+            variant_order = $3,
+            is_active     = $4,
+            total         = $5,
+            available_qty = $5 - booked_qty - sold_qty,
+            updated_at    = $6
+        WHERE id = $7
+        AND $5 >= booked_qty + sold_qty
+    `
+
+	for i, v := range variants {
+		now := time.Now()
+		v.UpdatedAt = &now
+
+		d.log.Debug(ctx, "addonVariantDAO.Update", zap.String("ID", string(v.ID)))
+
+		result, err := d.dbTrx.GetSqlTx().ExecContext(ctx, query,
+			v.Name, v.Price, v.VariantOrder, v.IsActive, v.Total, v.UpdatedAt, v.ID,
+		)
+		if err != nil {
+			d.log.Error(ctx, "addonVariantDAO.Update", zap.Error(err))
+			return err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil || rows == 0 {
+			d.log.Warn(ctx, "addonVariantDAO.Update.NoRowsAffected", zap.String("ID", string(v.ID)))
+			return fmt.Errorf("total varian %s lebih kecil dari stok yang sudah dipesan", v.Name)
+		}
+		variants[i] = v
+	}
+	return nil
+}
+
+func (d addonVariantDAO) Delete(ctx context.Context, ids []string) error {
+	if len(ids) < 1 {
+		return nil
+	}
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLDelete("addon_variants").
+		SetSQLWhere("AND", "id", "IN", ids)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "addonVariantDAO.Delete", zap.Strings("IDs", ids))
+
+	if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...); err != nil {
+		d.log.Error(ctx, "addonVariantDAO.Delete", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (d addonVariantDAO) BookStock(ctx context.Context, id pubEntity.UUID, qty int) error {
+	query := `
+        UPDATE addon_variants
+        SET
+            available_qty = available_qty - $1,
+            booked_qty    = booked_qty + $1,
+            updated_at    = $2
+        WHERE id = $3
+        AND available_qty >= $1
+    `
+	return d.adjustStock(ctx, "BookStock", query, id, qty)
+}
+
+func (d addonVariantDAO) ConfirmSold(ctx context.Context, id pubEntity.UUID, qty int) error {
+	query := `
+        UPDATE addon_variants
+        SET
+            booked_qty = booked_qty - $1,
+            sold_qty   = sold_qty + $1,
+            updated_at = $2
+        WHERE id = $3
+        AND booked_qty >= $1
+    `
+	return d.adjustStock(ctx, "ConfirmSold", query, id, qty)
+}
+
+func (d addonVariantDAO) ReleaseBooked(ctx context.Context, id pubEntity.UUID, qty int) error {
+	query := `
+        UPDATE addon_variants
+        SET
+            booked_qty    = booked_qty - $1,
+            available_qty = available_qty + $1,
+            updated_at    = $2
+        WHERE id = $3
+        AND booked_qty >= $1
+    `
+	return d.adjustStock(ctx, "ReleaseBooked", query, id, qty)
+}
+
+func (d addonVariantDAO) ReleaseSold(ctx context.Context, id pubEntity.UUID, qty int) error {
+	query := `
+        UPDATE addon_variants
+        SET
+            sold_qty      = sold_qty - $1,
+            available_qty = available_qty + $1,
+            updated_at    = $2
+        WHERE id = $3
+        AND sold_qty >= $1
+    `
+	return d.adjustStock(ctx, "ReleaseSold", query, id, qty)
+}
+
+// adjustStock menjalankan satu perpindahan stok; gagal jika kolom sumber tidak mencukupi
+func (d addonVariantDAO) adjustStock(ctx context.Context, op, query string, id pubEntity.UUID, qty int) error {
+	if qty <= 0 {
+		return fmt.Errorf("invalid qty")
+	}
+
+	d.log.Debug(ctx, "addonVariantDAO."+op, zap.String("ID", string(id)), zap.Int("Qty", qty))
+
+	result, err := d.dbTrx.GetSqlTx().ExecContext(ctx, query, qty, time.Now(), id)
+	if err != nil {
+		d.log.Error(ctx, "addonVariantDAO."+op, zap.Error(err))
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil || rows == 0 {
+		d.log.Warn(ctx, "addonVariantDAO."+op+".NoRowsAffected", zap.String("ID", string(id)))
+		return fmt.Errorf("insufficient addon stock")
+	}
+
+	return nil
+}
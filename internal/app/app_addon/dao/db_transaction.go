@@ -0,0 +1,55 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+
+	baseDao "rakit-tiket-be/internal/pkg/dao"
+	"rakit-tiket-be/pkg/util"
+)
+
+// OrderAddonDAOs cukup untuk booking dan siklus stok add-on order, dipenuhi juga oleh
+// transaksi modul lain (registrant, payment, gate) agar add-on ikut transaksi stok tiket
+type OrderAddonDAOs interface {
+	baseDao.DBTransaction
+
+	GetAddonDAO() AddonDAO
+	GetAddonVariantDAO() AddonVariantDAO
+	GetOrderAddonDAO() OrderAddonDAO
+}
+
+type DBTransaction interface {
+	OrderAddonDAOs
+}
+
+type dbTransaction struct {
+	baseDao.DBTransaction
+
+	addonDAO        AddonDAO
+	addonVariantDAO AddonVariantDAO
+	orderAddonDAO   OrderAddonDAO
+}
+
+func NewTransactionAddon(ctx context.Context, log util.LogUtil, sqlDB *sql.DB) DBTransaction {
+	dbTrx := &dbTransaction{
+		DBTransaction: baseDao.NewTransaction(ctx, sqlDB),
+	}
+
+	dbTrx.addonDAO = MakeAddonDAO(log, dbTrx)
+	dbTrx.addonVariantDAO = MakeAddonVariantDAO(log, dbTrx)
+	dbTrx.orderAddonDAO = MakeOrderAddonDAO(log, dbTrx)
+
+	return dbTrx
+}
+
+func (dbTrx *dbTransaction) GetAddonDAO() AddonDAO {
+	return dbTrx.addonDAO
+}
+
+func (dbTrx *dbTransaction) GetAddonVariantDAO() AddonVariantDAO {
+	return dbTrx.addonVariantDAO
+}
+
+func (dbTrx *dbTransaction) GetOrderAddonDAO() OrderAddonDAO {
+	return dbTrx.orderAddonDAO
+}
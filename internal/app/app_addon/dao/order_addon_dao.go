@@ -0,0 +1,204 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	baseDao "rakit-tiket-be/internal/pkg/dao"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	entity "rakit-tiket-be/pkg/entity/app_addon"
+	"rakit-tiket-be/pkg/util"
+
+	"gitlab.com/threetopia/sqlgo/v2"
+	"go.uber.org/zap"
+)
+
+type OrderAddonDAO interface {
+	Search(ctx context.Context, query entity.OrderAddonQuery) (entity.OrderAddons, error)
+	// SearchForUpdate mengunci baris add-on order agar dua scan pickup tidak mengambil item yang sama
+	SearchForUpdate(ctx context.Context, query entity.OrderAddonQuery) (entity.OrderAddons, error)
+	Insert(ctx context.Context, addons entity.OrderAddons) error
+	// Redeem menyimpan redeemed_qty, redeemed_at dan redeemed_by hasil scan pickup
+	Redeem(ctx context.Context, addons entity.OrderAddons) error
+}
+
+type orderAddonDAO struct {
+	log   util.LogUtil
+	dbTrx baseDao.DBTransaction
+}
+
+func MakeOrderAddonDAO(log util.LogUtil, dbTrx baseDao.DBTransaction) OrderAddonDAO {
+	return orderAddonDAO{
+		log:   log,
+		dbTrx: dbTrx,
+	}
+}
+
+func (d orderAddonDAO) Search(ctx context.Context, query entity.OrderAddonQuery) (entity.OrderAddons, error) {
+	return d.search(ctx, query, false)
+}
+
+func (d orderAddonDAO) SearchForUpdate(ctx context.Context, query entity.OrderAddonQuery) (entity.OrderAddons, error) {
+	return d.search(ctx, query, true)
+}
+
+func (d orderAddonDAO) search(ctx context.Context, query entity.OrderAddonQuery, forUpdate bool) (entity.OrderAddons, error) {
+	sqlSelect := sqlgo.NewSQLGoSelect().
+		SetSQLSelect("oa.id", "id").
+		SetSQLSelect("oa.order_id", "order_id").
+		SetSQLSelect("oa.addon_id", "addon_id").
+		SetSQLSelect("oa.variant_id", "variant_id").
+		SetSQLSelect("oa.addon_name", "addon_name").
+		SetSQLSelect("oa.variant_name", "variant_name").
+		SetSQLSelect("oa.addon_type", "addon_type").
+		SetSQLSelect("oa.quantity", "quantity").
+		SetSQLSelect("oa.unit_price", "unit_price").
+		SetSQLSelect("oa.subtotal", "subtotal").
+		SetSQLSelect("oa.redeemed_qty", "redeemed_qty").
+		SetSQLSelect("oa.redeemed_at", "redeemed_at").
+		SetSQLSelect("oa.redeemed_by", "redeemed_by").
+		SetSQLSelect("oa.created_at", "created_at").
+		SetSQLSelect("oa.updated_at", "updated_at").
+		SetSQLSelect("o.event_id", "event_id").
+		SetSQLSelect("o.order_number", "order_number").
+		SetSQLSelect("o.payment_status", "payment_status")
+
+	sqlFrom := sqlgo.NewSQLGoFrom().
+		SetSQLFrom("order_addons", "oa")
+
+	sqlJoin := sqlgo.NewSQLGoJoin()
+	sqlJoin.SetSQLJoin("INNER", "orders", "o", sqlgo.SetSQLJoinWhere("AND", "o.id", "=", "oa.order_id"))
+
+	sqlWhere := sqlgo.NewSQLGoWhere()
+
+	if len(query.IDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "oa.id", "IN", query.IDs)
+	}
+	if len(query.OrderIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "oa.order_id", "IN", query.OrderIDs)
+	}
+	if len(query.VariantIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "oa.variant_id", "IN", query.VariantIDs)
+	}
+
+	sqlOrder := sqlgo.NewSQLGoOrder()
+	sqlOrder.SetSQLOrder("oa.order_id", "ASC")
+	sqlOrder.SetSQLOrder("oa.addon_name", "ASC")
+	sqlOrder.SetSQLOrder("oa.variant_name", "ASC")
+
+	sqlStmt := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoSelect(sqlSelect).
+		SetSQLGoFrom(sqlFrom).
+		SetSQLGoJoin(sqlJoin).
+		SetSQLGoWhere(sqlWhere).
+		SetSQLGoOrder(sqlOrder)
+
+	sqlStr := sqlStmt.BuildSQL()
+	if forUpdate {
+		sqlStr += " FOR UPDATE OF oa"
+	}
+	sqlParams := sqlStmt.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "orderAddonDAO.Search",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	// Dibaca di dalam transaksi agar siklus stok add-on konsisten dengan order yang sedang dikunci
+	rows, err := d.dbTrx.GetSqlTx().QueryContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "orderAddonDAO.Search", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var addons entity.OrderAddons
+	for rows.Next() {
+		var a entity.OrderAddon
+		if err := rows.Scan(
+			&a.ID, &a.OrderID, &a.AddonID, &a.VariantID,
+			&a.AddonName, &a.VariantName, &a.AddonType,
+			&a.Quantity, &a.UnitPrice, &a.Subtotal,
+			&a.RedeemedQty, &a.RedeemedAt, &a.RedeemedBy,
+			&a.CreatedAt, &a.UpdatedAt,
+			&a.EventID, &a.OrderNumber, &a.PaymentStatus,
+		); err != nil {
+			d.log.Error(ctx, "orderAddonDAO.Search.Scan", zap.Error(err))
+			return nil, err
+		}
+		addons = append(addons, a)
+	}
+
+	return addons, nil
+}
+
+func (d orderAddonDAO) Insert(ctx context.Context, addons entity.OrderAddons) error {
+	if len(addons) < 1 {
+		return nil
+	}
+
+	sqlInsert := sqlgo.NewSQLGoInsert().
+		SetSQLInsert("order_addons").
+		SetSQLInsertColumn(
+			"id", "order_id", "addon_id", "variant_id",
+			"addon_name", "variant_name", "addon_type",
+			"quantity", "unit_price", "subtotal", "created_at",
+		)
+
+	for i, a := range addons {
+		if a.CreatedAt.IsZero() {
+			a.CreatedAt = time.Now()
+		}
+		if a.ID == "" {
+			a.ID = pubEntity.MakeUUID("ORDER_ADDON", string(a.OrderID), string(a.VariantID))
+		}
+
+		sqlInsert.SetSQLInsertValue(
+			a.ID, a.OrderID, a.AddonID, a.VariantID,
+			a.AddonName, a.VariantName, a.AddonType,
+			a.Quantity, a.UnitPrice, a.Subtotal, a.CreatedAt,
+		)
+		addons[i] = a
+	}
+
+	sql := sqlgo.NewSQLGo().SetSQLSchema("public").SetSQLGoInsert(sqlInsert)
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "orderAddonDAO.Insert", zap.String("SQL", sqlStr), zap.Int("Count", len(addons)))
+
+	if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...); err != nil {
+		d.log.Error(ctx, "orderAddonDAO.Insert", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (d orderAddonDAO) Redeem(ctx context.Context, addons entity.OrderAddons) error {
+	for i, a := range addons {
+		now := time.Now()
+		a.UpdatedAt = &now
+
+		sql := sqlgo.NewSQLGo().
+			SetSQLSchema("public").
+			SetSQLUpdate("order_addons").
+			SetSQLUpdateValue("redeemed_qty", a.RedeemedQty).
+			SetSQLUpdateValue("redeemed_at", a.RedeemedAt).
+			SetSQLUpdateValue("redeemed_by", a.RedeemedBy).
+			SetSQLUpdateValue("updated_at", a.UpdatedAt).
+			SetSQLWhere("AND", "id", "=", a.ID)
+
+		sqlStr := sql.BuildSQL()
+		sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+		d.log.Debug(ctx, "orderAddonDAO.Redeem", zap.String("ID", string(a.ID)), zap.Int("RedeemedQty", a.RedeemedQty))
+
+		if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...); err != nil {
+			d.log.Error(ctx, "orderAddonDAO.Redeem", zap.Error(err))
+			return err
+		}
+		addons[i] = a
+	}
+	return nil
+}
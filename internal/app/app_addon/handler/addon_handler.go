@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"rakit-tiket-be/internal/app/app_addon/service"
+	"rakit-tiket-be/internal/pkg/middleware"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	entity "rakit-tiket-be/pkg/entity/app_addon"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AddonHandler interface {
+	RegisterRouter(g *echo.Group)
+}
+
+type addonHandler struct {
+	addonService service.AddonService
+	middleware   middleware.AuthMiddleware
+}
+
+func MakeAddonHandler(
+	addonService service.AddonService,
+	middleware middleware.AuthMiddleware,
+) addonHandler {
+	return addonHandler{
+		addonService: addonService,
+		middleware:   middleware,
+	}
+}
+
+func (h addonHandler) RegisterRouter(g *echo.Group) {
+	public := g.Group("/v1")
+	public.GET("/events/:event_id/addons", h.getEventCatalog)
+
+	restricted := g.Group("/v1/admin")
+
+	restricted.Use(h.middleware.VerifyToken)
+	restricted.Use(h.middleware.RequireAdmin)
+
+	restricted.GET("/addons", h.searchAddons)
+	restricted.POST("/addons", h.insertAddon)
+	restricted.PUT("/addon/:id", h.updateAddon)
+	restricted.DELETE("/addon/:id", h.softDeleteAddon)
+}
+
+func (h addonHandler) getEventCatalog(c echo.Context) error {
+	data, err := h.addonService.GetEventCatalog(c.Request().Context(), c.Param("event_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+		"count":   len(data),
+	})
+}
+
+func (h addonHandler) searchAddons(c echo.Context) error {
+	var query entity.AddonQuery
+
+	if err := c.Bind(&query); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.addonService.Search(c.Request().Context(), query)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+		"count":   len(data),
+	})
+}
+
+func (h addonHandler) insertAddon(c echo.Context) error {
+	var addon entity.Addon
+
+	if err := c.Bind(&addon); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := h.addonService.Insert(c.Request().Context(), &addon); err != nil {
+		return addonError(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    addon,
+	})
+}
+
+func (h addonHandler) updateAddon(c echo.Context) error {
+	var addon entity.Addon
+
+	if err := c.Bind(&addon); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Force ID dari URL
+	addon.ID = pubEntity.UUID(c.Param("id"))
+
+	if err := h.addonService.Update(c.Request().Context(), &addon); err != nil {
+		return addonError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    addon,
+	})
+}
+
+func (h addonHandler) softDeleteAddon(c echo.Context) error {
+	if err := h.addonService.SoftDelete(c.Request().Context(), pubEntity.UUID(c.Param("id"))); err != nil {
+		return addonError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Addon deleted successfully",
+	})
+}
+
+func addonError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrAddonNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidAddon):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrAddonInUse):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"rakit-tiket-be/internal/app/app_addon/service"
+	"rakit-tiket-be/internal/pkg/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+type HttpHandler interface {
+	RegisterRoute(g *echo.Group)
+}
+
+type httpHandler struct {
+	addonService service.AddonService
+
+	addonHandler AddonHandler
+}
+
+func MakeHttpAdapter(
+	addonService service.AddonService,
+	authMiddleware middleware.AuthMiddleware,
+) HttpHandler {
+	return httpHandler{
+		addonService: addonService,
+		addonHandler: MakeAddonHandler(addonService, authMiddleware),
+	}
+}
+
+func (h httpHandler) RegisterRoute(g *echo.Group) {
+	h.addonHandler.RegisterRouter(g)
+}
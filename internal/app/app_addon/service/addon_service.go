@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"rakit-tiket-be/internal/app/app_addon/dao"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	entity "rakit-tiket-be/pkg/entity/app_addon"
+	"rakit-tiket-be/pkg/util"
+)
+
+var (
+	ErrAddonNotFound = errors.New("add-on tidak ditemukan")
+	ErrInvalidAddon  = errors.New("data add-on tidak valid")
+	ErrAddonInUse    = errors.New("varian add-on sudah dipakai order")
+)
+
+type AddonService interface {
+	Search(ctx context.Context, query entity.AddonQuery) (entity.Addons, error)
+	// GetEventCatalog mengembalikan add-on dan varian aktif event untuk halaman registrasi
+	GetEventCatalog(ctx context.Context, eventID string) (entity.Addons, error)
+	Insert(ctx context.Context, addon *entity.Addon) error
+	// Update mengganti data add-on dan seluruh variannya; urutan array menjadi urutan varian
+	Update(ctx context.Context, addon *entity.Addon) error
+	SoftDelete(ctx context.Context, id pubEntity.UUID) error
+}
+
+type addonService struct {
+	log   util.LogUtil
+	sqlDB *sql.DB
+}
+
+func MakeAddonService(log util.LogUtil, sqlDB *sql.DB) AddonService {
+	return addonService{
+		log:   log,
+		sqlDB: sqlDB,
+	}
+}
+
+func (s addonService) Search(ctx context.Context, query entity.AddonQuery) (entity.Addons, error) {
+	dbTrx := dao.NewTransactionAddon(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	addons, err := dbTrx.GetAddonDAO().Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if err := loadVariants(ctx, dbTrx.GetAddonVariantDAO(), addons); err != nil {
+		return nil, err
+	}
+
+	if addons == nil {
+		addons = entity.Addons{}
+	}
+	return addons, nil
+}
+
+func (s addonService) GetEventCatalog(ctx context.Context, eventID string) (entity.Addons, error) {
+	if !util.IsValidUUID(eventID) {
+		return entity.Addons{}, nil
+	}
+
+	isActive := true
+	addons, err := s.Search(ctx, entity.AddonQuery{EventIDs: []string{eventID}, IsActive: &isActive})
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := make(entity.Addons, 0, len(addons))
+	for _, a := range addons {
+		variants := make(entity.AddonVariants, 0, len(a.Variants))
+		for _, v := range a.Variants {
+			if v.IsActive {
+				variants = append(variants, v)
+			}
+		}
+		if len(variants) == 0 {
+			continue
+		}
+		a.Variants = variants
+		catalog = append(catalog, a)
+	}
+
+	return catalog, nil
+}
+
+func (s addonService) Insert(ctx context.Context, addon *entity.Addon) error {
+	if !util.IsValidUUID(string(addon.EventID)) {
+		return fmt.Errorf("%w: event_id tidak valid", ErrInvalidAddon)
+	}
+	if err := normalizeAddon(addon); err != nil {
+		return err
+	}
+
+	dbTrx := dao.NewTransactionAddon(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	addon.ID = ""
+	addons := entity.Addons{*addon}
+	if err := dbTrx.GetAddonDAO().Insert(ctx, addons); err != nil {
+		return err
+	}
+	*addon = addons[0]
+
+	for i := range addon.Variants {
+		addon.Variants[i].ID = ""
+		addon.Variants[i].AddonID = addon.ID
+	}
+	if err := dbTrx.GetAddonVariantDAO().Insert(ctx, addon.Variants); err != nil {
+		return err
+	}
+
+	return dbTrx.GetSqlTx().Commit()
+}
+
+func (s addonService) Update(ctx context.Context, addon *entity.Addon) error {
+	if !util.IsValidUUID(string(addon.ID)) {
+		return ErrAddonNotFound
+	}
+	if err := normalizeAddon(addon); err != nil {
+		return err
+	}
+
+	dbTrx := dao.NewTransactionAddon(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	existing, err := dbTrx.GetAddonDAO().Search(ctx, entity.AddonQuery{IDs: []string{string(addon.ID)}})
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		return ErrAddonNotFound
+	}
+	addon.EventID = existing[0].EventID
+	addon.CreatedAt = existing[0].CreatedAt
+
+	// Varian dikunci agar total baru tidak balapan dengan registrasi yang sedang mem-booking stok
+	current, err := dbTrx.GetAddonVariantDAO().SearchForUpdate(ctx, entity.AddonVariantQuery{AddonIDs: []string{string(addon.ID)}})
+	if err != nil {
+		return err
+	}
+	currentMap := make(map[pubEntity.UUID]entity.AddonVariant)
+	for _, v := range current {
+		currentMap[v.ID] = v
+	}
+
+	var inserts, updates entity.AddonVariants
+	kept := make(map[pubEntity.UUID]bool)
+	for _, v := range addon.Variants {
+		v.AddonID = addon.ID
+		if v.ID == "" {
+			inserts = append(inserts, v)
+			continue
+		}
+
+		cur, ok := currentMap[v.ID]
+		if !ok {
+			return fmt.Errorf("%w: varian %s bukan milik add-on ini", ErrInvalidAddon, v.ID)
+		}
+		if v.Total < cur.BookedQty+cur.SoldQty {
+			return fmt.Errorf("%w: total varian %s tidak boleh kurang dari %d (dibooking + terjual)", ErrInvalidAddon, v.Name, cur.BookedQty+cur.SoldQty)
+		}
+		kept[v.ID] = true
+		updates = append(updates, v)
+	}
+
+	// Varian yang pernah dibeli tidak dihapus agar snapshot order tetap punya referensi stok; nonaktifkan saja
+	var deleteIDs []string
+	for _, cur := range current {
+		if !kept[cur.ID] {
+			deleteIDs = append(deleteIDs, string(cur.ID))
+		}
+	}
+	if len(deleteIDs) > 0 {
+		used, err := dbTrx.GetOrderAddonDAO().Search(ctx, entity.OrderAddonQuery{VariantIDs: deleteIDs})
+		if err != nil {
+			return err
+		}
+		if len(used) > 0 {
+			return fmt.Errorf("%w: %s, set is_active false untuk menyembunyikannya", ErrAddonInUse, used[0].DisplayName())
+		}
+		if err := dbTrx.GetAddonVariantDAO().Delete(ctx, deleteIDs); err != nil {
+			return err
+		}
+	}
+
+	if len(updates) > 0 {
+		if err := dbTrx.GetAddonVariantDAO().Update(ctx, updates); err != nil {
+			return err
+		}
+	}
+	if len(inserts) > 0 {
+		if err := dbTrx.GetAddonVariantDAO().Insert(ctx, inserts); err != nil {
+			return err
+		}
+	}
+
+	if err := dbTrx.GetAddonDAO().Update(ctx, entity.Addons{*addon}); err != nil {
+		return err
+	}
+
+	if err := dbTrx.GetSqlTx().Commit(); err != nil {
+		return err
+	}
+
+	addons, err := s.Search(ctx, entity.AddonQuery{IDs: []string{string(addon.ID)}})
+	if err != nil {
+		return err
+	}
+	if len(addons) > 0 {
+		*addon = addons[0]
+	}
+	return nil
+}
+
+// SoftDelete tidak melepas stok order pending; add-on yang dihapus hanya tidak bisa dipilih lagi
+func (s addonService) SoftDelete(ctx context.Context, id pubEntity.UUID) error {
+	if !util.IsValidUUID(string(id)) {
+		return ErrAddonNotFound
+	}
+
+	dbTrx := dao.NewTransactionAddon(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	existing, err := dbTrx.GetAddonDAO().Search(ctx, entity.AddonQuery{IDs: []string{string(id)}})
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		return ErrAddonNotFound
+	}
+
+	if err := dbTrx.GetAddonDAO().SoftDelete(ctx, id); err != nil {
+		return err
+	}
+
+	return dbTrx.GetSqlTx().Commit()
+}
+
+// loadVariants mengisi Variants setiap add-on
+func loadVariants(ctx context.Context, variantDAO dao.AddonVariantDAO, addons entity.Addons) error {
+	if len(addons) == 0 {
+		return nil
+	}
+
+	addonIDs := make([]string, 0, len(addons))
+	for _, a := range addons {
+		addonIDs = append(addonIDs, string(a.ID))
+	}
+
+	variants, err := variantDAO.Search(ctx, entity.AddonVariantQuery{AddonIDs: addonIDs})
+	if err != nil {
+		return err
+	}
+
+	variantMap := make(map[pubEntity.UUID]entity.AddonVariants)
+	for _, v := range variants {
+		variantMap[v.AddonID] = append(variantMap[v.AddonID], v)
+	}
+
+	for i := range addons {
+		addons[i].Variants = variantMap[addons[i].ID]
+		if addons[i].Variants == nil {
+			addons[i].Variants = entity.AddonVariants{}
+		}
+	}
+
+	return nil
+}
+
+// normalizeAddon memvalidasi add-on beserta variannya; urutan array varian menjadi variant_order
+func normalizeAddon(a *entity.Addon) error {
+	a.Name = strings.TrimSpace(a.Name)
+	if a.Name == "" || len(a.Name) > 150 {
+		return fmt.Errorf("%w: name wajib diisi (maksimal 150 karakter)", ErrInvalidAddon)
+	}
+
+	a.Type = entity.AddonType(strings.ToUpper(strings.TrimSpace(string(a.Type))))
+	switch a.Type {
+	case "":
+		a.Type = entity.AddonTypeMerch
+	case entity.AddonTypeMerch, entity.AddonTypeParking, entity.AddonTypeShuttle, entity.AddonTypeOther:
+	default:
+		return fmt.Errorf("%w: type harus MERCH, PARKING, SHUTTLE atau OTHER", ErrInvalidAddon)
+	}
+
+	if a.MaxPerOrder != nil && *a.MaxPerOrder <= 0 {
+		return fmt.Errorf("%w: max_per_order harus lebih dari 0", ErrInvalidAddon)
+	}
+
+	if len(a.Variants) == 0 {
+		return fmt.Errorf("%w: minimal satu varian", ErrInvalidAddon)
+	}
+
+	names := make(map[string]bool)
+	for i := range a.Variants {
+		v := &a.Variants[i]
+		v.Name = strings.TrimSpace(v.Name)
+		if v.Name == "" || len(v.Name) > 100 {
+			return fmt.Errorf("%w: nama varian ke-%d wajib diisi (maksimal 100 karakter)", ErrInvalidAddon, i+1)
+		}
+		if names[strings.ToLower(v.Name)] {
+			return fmt.Errorf("%w: nama varian %q duplikat", ErrInvalidAddon, v.Name)
+		}
+		names[strings.ToLower(v.Name)] = true
+
+		if v.Price < 0 {
+			return fmt.Errorf("%w: harga varian %s tidak boleh negatif", ErrInvalidAddon, v.Name)
+		}
+		if v.Total < 0 {
+			return fmt.Errorf("%w: total varian %s tidak boleh negatif", ErrInvalidAddon, v.Name)
+		}
+		v.VariantOrder = i + 1
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"rakit-tiket-be/internal/app/app_addon/dao"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	entity "rakit-tiket-be/pkg/entity/app_addon"
+	"rakit-tiket-be/pkg/util"
+)
+
+var (
+	// ErrAddonUnavailable dipakai saat pilihan add-on di registrasi tidak valid (varian tidak aktif, event lain, lewat batas per order)
+	ErrAddonUnavailable = errors.New("add-on tidak tersedia")
+	ErrAddonSoldOut     = errors.New("stok add-on tidak mencukupi (habis)")
+)
+
+// BookAddons memvalidasi pilihan add-on registrasi lalu mem-booking stok varian secara atomik.
+// Varian dikunci (FOR UPDATE) di transaksi yang sama dengan BookStock tiket; hasilnya snapshot
+// add-on order tanpa OrderID yang diisi setelah order dibuat.
+func BookAddons(ctx context.Context, daos dao.OrderAddonDAOs, eventID pubEntity.UUID, selections []entity.AddonSelection) (entity.OrderAddons, error) {
+	qtyMap := make(map[pubEntity.UUID]int)
+	var variantIDs []string
+	for _, sel := range selections {
+		if !util.IsValidUUID(string(sel.VariantID)) || sel.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: variant_id dan quantity add-on wajib diisi", ErrAddonUnavailable)
+		}
+		if qtyMap[sel.VariantID] == 0 {
+			variantIDs = append(variantIDs, string(sel.VariantID))
+		}
+		qtyMap[sel.VariantID] += sel.Quantity
+	}
+	if len(qtyMap) == 0 {
+		return nil, nil
+	}
+
+	variants, err := daos.GetAddonVariantDAO().SearchForUpdate(ctx, entity.AddonVariantQuery{IDs: variantIDs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock addon variants: %v", err)
+	}
+	if len(variants) != len(qtyMap) {
+		return nil, fmt.Errorf("%w: varian add-on tidak ditemukan", ErrAddonUnavailable)
+	}
+
+	var addonIDs []string
+	for _, v := range variants {
+		addonIDs = append(addonIDs, string(v.AddonID))
+	}
+	addons, err := daos.GetAddonDAO().Search(ctx, entity.AddonQuery{IDs: addonIDs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch addons: %v", err)
+	}
+	addonMap := make(map[pubEntity.UUID]entity.Addon)
+	for _, a := range addons {
+		addonMap[a.ID] = a
+	}
+
+	addonQty := make(map[pubEntity.UUID]int)
+	var orderAddons entity.OrderAddons
+	for _, v := range variants {
+		addon, ok := addonMap[v.AddonID]
+		if !ok || addon.EventID != eventID {
+			return nil, fmt.Errorf("%w: add-on bukan untuk event ini", ErrAddonUnavailable)
+		}
+		if !addon.IsActive || !v.IsActive {
+			return nil, fmt.Errorf("%w: %s - %s", ErrAddonUnavailable, addon.Name, v.Name)
+		}
+
+		qty := qtyMap[v.ID]
+		addonQty[addon.ID] += qty
+		if addon.MaxPerOrder != nil && addonQty[addon.ID] > *addon.MaxPerOrder {
+			return nil, fmt.Errorf("%w: maksimal %d %s per order", ErrAddonUnavailable, *addon.MaxPerOrder, addon.Name)
+		}
+
+		if err := daos.GetAddonVariantDAO().BookStock(ctx, v.ID, qty); err != nil {
+			return nil, fmt.Errorf("%w: %s - %s", ErrAddonSoldOut, addon.Name, v.Name)
+		}
+
+		orderAddons = append(orderAddons, entity.OrderAddon{
+			AddonID:     addon.ID,
+			VariantID:   v.ID,
+			AddonName:   addon.Name,
+			VariantName: v.Name,
+			AddonType:   addon.Type,
+			Quantity:    qty,
+			UnitPrice:   v.Price,
+			Subtotal:    v.Price * float64(qty),
+			EventID:     eventID,
+		})
+	}
+
+	return orderAddons, nil
+}
+
+// ConfirmOrderAddons memindahkan stok add-on order dari booked ke sold saat order dibayar
+func ConfirmOrderAddons(ctx context.Context, daos dao.OrderAddonDAOs, orderIDs []pubEntity.UUID) error {
+	return moveOrderAddonStock(ctx, daos, orderIDs, orderedQty, daos.GetAddonVariantDAO().ConfirmSold, "ConfirmSold")
+}
+
+// ReleaseOrderAddons mengembalikan stok booked add-on order yang expired / gagal / dibatalkan
+func ReleaseOrderAddons(ctx context.Context, daos dao.OrderAddonDAOs, orderIDs []pubEntity.UUID) error {
+	return moveOrderAddonStock(ctx, daos, orderIDs, orderedQty, daos.GetAddonVariantDAO().ReleaseBooked, "ReleaseBooked")
+}
+
+// RebookOrderAddons mem-booking ulang add-on order expired yang ternyata dibayar, sebelum dikonfirmasi terjual
+func RebookOrderAddons(ctx context.Context, daos dao.OrderAddonDAOs, orderIDs []pubEntity.UUID) error {
+	return moveOrderAddonStock(ctx, daos, orderIDs, orderedQty, daos.GetAddonVariantDAO().BookStock, "BookStock")
+}
+
+// RefundOrderAddons mengembalikan stok sold add-on order yang di-refund penuh.
+// Item yang sudah diambil di booth pickup tidak kembali ke stok.
+func RefundOrderAddons(ctx context.Context, daos dao.OrderAddonDAOs, orderIDs []pubEntity.UUID) error {
+	return moveOrderAddonStock(ctx, daos, orderIDs, entity.OrderAddon.RemainingQty, daos.GetAddonVariantDAO().ReleaseSold, "ReleaseSold")
+}
+
+func orderedQty(a entity.OrderAddon) int {
+	return a.Quantity
+}
+
+// moveOrderAddonStock menjumlahkan quantity add-on per varian untuk semua order lalu menjalankan
+// satu perpindahan stok per varian, berurutan berdasarkan id varian seperti SearchForUpdate
+func moveOrderAddonStock(
+	ctx context.Context,
+	daos dao.OrderAddonDAOs,
+	orderIDs []pubEntity.UUID,
+	qtyOf func(entity.OrderAddon) int,
+	move func(ctx context.Context, id pubEntity.UUID, qty int) error,
+	op string,
+) error {
+	if len(orderIDs) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(orderIDs))
+	for _, id := range orderIDs {
+		ids = append(ids, string(id))
+	}
+
+	orderAddons, err := daos.GetOrderAddonDAO().Search(ctx, entity.OrderAddonQuery{OrderIDs: ids})
+	if err != nil {
+		return fmt.Errorf("failed to fetch order addons: %v", err)
+	}
+
+	qtyMap := make(map[pubEntity.UUID]int)
+	for _, a := range orderAddons {
+		if qty := qtyOf(a); qty > 0 {
+			qtyMap[a.VariantID] += qty
+		}
+	}
+
+	variantIDs := make([]pubEntity.UUID, 0, len(qtyMap))
+	for id := range qtyMap {
+		variantIDs = append(variantIDs, id)
+	}
+	sort.Slice(variantIDs, func(i, j int) bool { return variantIDs[i] < variantIDs[j] })
+
+	for _, id := range variantIDs {
+		if err := move(ctx, id, qtyMap[id]); err != nil {
+			return fmt.Errorf("gagal %s add-on %s: %v", op, id, err)
+		}
+	}
+
+	return nil
+}
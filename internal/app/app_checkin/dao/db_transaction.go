@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 
+	addonDao "rakit-tiket-be/internal/app/app_addon/dao"
+	orderDao "rakit-tiket-be/internal/app/app_order/dao"
 	"rakit-tiket-be/internal/pkg/dao"
 	"rakit-tiket-be/pkg/util"
 )
@@ -12,16 +14,35 @@ type DBTransaction interface {
 	dao.DBTransaction
 
 	GetPhysicalTicketDAO() PhysicalTicketDAO
+	GetPhysicalTicketAuditDAO() PhysicalTicketAuditDAO
+	GetPhysicalTicketBatchDAO() PhysicalTicketBatchDAO
 	GetGateConfigDAO() GateConfigDAO
 	GetGateLogDAO() GateLogDAO
+	GetScannerDeviceDAO() ScannerDeviceDAO
+	GetRegistrantCheckInDAO() RegistrantCheckInDAO
+	GetGateZoneDAO() GateZoneDAO
+
+	GetETicketDAO() orderDao.ETicketDAO
+	GetAddonDAO() addonDao.AddonDAO
+	GetAddonVariantDAO() addonDao.AddonVariantDAO
+	GetOrderAddonDAO() addonDao.OrderAddonDAO
 }
 
 type dbTransaction struct {
 	dao.DBTransaction
 
 	physicalTicketDAO PhysicalTicketDAO
+	ticketAuditDAO    PhysicalTicketAuditDAO
+	ticketBatchDAO    PhysicalTicketBatchDAO
 	gateConfigDAO     GateConfigDAO
 	gateLogDAO        GateLogDAO
+	scannerDeviceDAO  ScannerDeviceDAO
+	registrantDAO     RegistrantCheckInDAO
+	gateZoneDAO       GateZoneDAO
+	eTicketDAO        orderDao.ETicketDAO
+	addonDAO          addonDao.AddonDAO
+	addonVariantDAO   addonDao.AddonVariantDAO
+	orderAddonDAO     addonDao.OrderAddonDAO
 }
 
 func NewTransactionGate(ctx context.Context, log util.LogUtil, sqlDB *sql.DB) DBTransaction {
@@ -30,8 +51,17 @@ func NewTransactionGate(ctx context.Context, log util.LogUtil, sqlDB *sql.DB) DB
 	}
 
 	dbTrx.physicalTicketDAO = MakePhysicalTicketDAO(log, dbTrx)
+	dbTrx.ticketAuditDAO = MakePhysicalTicketAuditDAO(log, dbTrx)
+	dbTrx.ticketBatchDAO = MakePhysicalTicketBatchDAO(log, dbTrx)
 	dbTrx.gateConfigDAO = MakeGateConfigDAO(log, dbTrx)
 	dbTrx.gateLogDAO = MakeGateLogDAO(log, dbTrx)
+	dbTrx.scannerDeviceDAO = MakeScannerDeviceDAO(log, dbTrx)
+	dbTrx.registrantDAO = MakeRegistrantCheckInDAO(log, dbTrx)
+	dbTrx.gateZoneDAO = MakeGateZoneDAO(log, dbTrx)
+	dbTrx.eTicketDAO = orderDao.MakeETicketDAO(log, dbTrx)
+	dbTrx.addonDAO = addonDao.MakeAddonDAO(log, dbTrx)
+	dbTrx.addonVariantDAO = addonDao.MakeAddonVariantDAO(log, dbTrx)
+	dbTrx.orderAddonDAO = addonDao.MakeOrderAddonDAO(log, dbTrx)
 
 	return dbTrx
 }
@@ -40,6 +70,14 @@ func (dbTrx *dbTransaction) GetPhysicalTicketDAO() PhysicalTicketDAO {
 	return dbTrx.physicalTicketDAO
 }
 
+func (dbTrx *dbTransaction) GetPhysicalTicketAuditDAO() PhysicalTicketAuditDAO {
+	return dbTrx.ticketAuditDAO
+}
+
+func (dbTrx *dbTransaction) GetPhysicalTicketBatchDAO() PhysicalTicketBatchDAO {
+	return dbTrx.ticketBatchDAO
+}
+
 func (dbTrx *dbTransaction) GetGateConfigDAO() GateConfigDAO {
 	return dbTrx.gateConfigDAO
 }
@@ -47,3 +85,31 @@ func (dbTrx *dbTransaction) GetGateConfigDAO() GateConfigDAO {
 func (dbTrx *dbTransaction) GetGateLogDAO() GateLogDAO {
 	return dbTrx.gateLogDAO
 }
+
+func (dbTrx *dbTransaction) GetScannerDeviceDAO() ScannerDeviceDAO {
+	return dbTrx.scannerDeviceDAO
+}
+
+func (dbTrx *dbTransaction) GetRegistrantCheckInDAO() RegistrantCheckInDAO {
+	return dbTrx.registrantDAO
+}
+
+func (dbTrx *dbTransaction) GetGateZoneDAO() GateZoneDAO {
+	return dbTrx.gateZoneDAO
+}
+
+func (dbTrx *dbTransaction) GetETicketDAO() orderDao.ETicketDAO {
+	return dbTrx.eTicketDAO
+}
+
+func (dbTrx *dbTransaction) GetAddonDAO() addonDao.AddonDAO {
+	return dbTrx.addonDAO
+}
+
+func (dbTrx *dbTransaction) GetAddonVariantDAO() addonDao.AddonVariantDAO {
+	return dbTrx.addonVariantDAO
+}
+
+func (dbTrx *dbTransaction) GetOrderAddonDAO() addonDao.OrderAddonDAO {
+	return dbTrx.orderAddonDAO
+}
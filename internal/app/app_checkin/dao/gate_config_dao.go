@@ -42,6 +42,10 @@ func (d gateConfigDAO) Search(ctx context.Context, query entity.GateConfigQuery)
 		SetSQLSelect("gc.mode", "mode").
 		SetSQLSelect("gc.max_scan_per_ticket", "max_scan_per_ticket").
 		SetSQLSelect("gc.max_scan_by_type", "max_scan_by_type").
+		SetSQLSelect("gc.timezone", "timezone").
+		SetSQLSelect("gc.entry_windows", "entry_windows").
+		SetSQLSelect("gc.daily_reset_time", "daily_reset_time").
+		SetSQLSelect("gc.reentry_cooldown_seconds", "reentry_cooldown_seconds").
 		SetSQLSelect("gc.is_active", "is_active").
 		SetSQLSelect("gc.created_at", "created_at").
 		SetSQLSelect("gc.updated_at", "updated_at")
@@ -90,7 +94,7 @@ func (d gateConfigDAO) Search(ctx context.Context, query entity.GateConfigQuery)
 	var configs entity.GateConfigs
 	for rows.Next() {
 		var cfg entity.GateConfig
-		var maxScanByTypeJSON []byte
+		var maxScanByTypeJSON, entryWindowsJSON []byte
 
 		if err := rows.Scan(
 			&cfg.ID,
@@ -98,6 +102,10 @@ func (d gateConfigDAO) Search(ctx context.Context, query entity.GateConfigQuery)
 			&cfg.Mode,
 			&cfg.MaxScanPerTicket,
 			&maxScanByTypeJSON,
+			&cfg.Timezone,
+			&entryWindowsJSON,
+			&cfg.DailyResetTime,
+			&cfg.ReentryCooldownSeconds,
 			&cfg.IsActive,
 			&cfg.CreatedAt,
 			&cfg.UpdatedAt,
@@ -109,6 +117,9 @@ func (d gateConfigDAO) Search(ctx context.Context, query entity.GateConfigQuery)
 		if len(maxScanByTypeJSON) > 0 {
 			_ = json.Unmarshal(maxScanByTypeJSON, &cfg.MaxScanByType)
 		}
+		if len(entryWindowsJSON) > 0 {
+			_ = json.Unmarshal(entryWindowsJSON, &cfg.EntryWindows)
+		}
 
 		configs = append(configs, cfg)
 	}
@@ -124,6 +135,10 @@ func (d gateConfigDAO) GetByEventID(ctx context.Context, eventID string) (*entit
 		SetSQLSelect("gc.mode", "mode").
 		SetSQLSelect("gc.max_scan_per_ticket", "max_scan_per_ticket").
 		SetSQLSelect("gc.max_scan_by_type", "max_scan_by_type").
+		SetSQLSelect("gc.timezone", "timezone").
+		SetSQLSelect("gc.entry_windows", "entry_windows").
+		SetSQLSelect("gc.daily_reset_time", "daily_reset_time").
+		SetSQLSelect("gc.reentry_cooldown_seconds", "reentry_cooldown_seconds").
 		SetSQLSelect("gc.is_active", "is_active").
 		SetSQLSelect("gc.created_at", "created_at").
 		SetSQLSelect("gc.updated_at", "updated_at")
@@ -150,7 +165,7 @@ func (d gateConfigDAO) GetByEventID(ctx context.Context, eventID string) (*entit
 	)
 
 	var cfg entity.GateConfig
-	var maxScanByTypeJSON []byte
+	var maxScanByTypeJSON, entryWindowsJSON []byte
 
 	err := d.dbTrx.GetSqlDB().QueryRowContext(ctx, sqlStr, sqlParams...).Scan(
 		&cfg.ID,
@@ -158,6 +173,10 @@ func (d gateConfigDAO) GetByEventID(ctx context.Context, eventID string) (*entit
 		&cfg.Mode,
 		&cfg.MaxScanPerTicket,
 		&maxScanByTypeJSON,
+		&cfg.Timezone,
+		&entryWindowsJSON,
+		&cfg.DailyResetTime,
+		&cfg.ReentryCooldownSeconds,
 		&cfg.IsActive,
 		&cfg.CreatedAt,
 		&cfg.UpdatedAt,
@@ -169,6 +188,9 @@ func (d gateConfigDAO) GetByEventID(ctx context.Context, eventID string) (*entit
 	if len(maxScanByTypeJSON) > 0 {
 		_ = json.Unmarshal(maxScanByTypeJSON, &cfg.MaxScanByType)
 	}
+	if len(entryWindowsJSON) > 0 {
+		_ = json.Unmarshal(entryWindowsJSON, &cfg.EntryWindows)
+	}
 
 	return &cfg, nil
 }
@@ -184,6 +206,11 @@ func (d gateConfigDAO) Insert(ctx context.Context, config entity.GateConfig) err
 		maxScanByTypeJSON, _ = json.Marshal(config.MaxScanByType)
 	}
 
+	var entryWindowsJSON []byte
+	if config.EntryWindows != nil {
+		entryWindowsJSON, _ = json.Marshal(config.EntryWindows)
+	}
+
 	sql := sqlgo.NewSQLGo().
 		SetSQLSchema("public").
 		SetSQLGoInsert(sqlgo.NewSQLGoInsert()).
@@ -194,6 +221,10 @@ func (d gateConfigDAO) Insert(ctx context.Context, config entity.GateConfig) err
 			"mode",
 			"max_scan_per_ticket",
 			"max_scan_by_type",
+			"timezone",
+			"entry_windows",
+			"daily_reset_time",
+			"reentry_cooldown_seconds",
 			"is_active",
 			"data_hash",
 			"created_at",
@@ -204,6 +235,10 @@ func (d gateConfigDAO) Insert(ctx context.Context, config entity.GateConfig) err
 			config.Mode,
 			config.MaxScanPerTicket,
 			maxScanByTypeJSON,
+			config.Timezone,
+			entryWindowsJSON,
+			config.DailyResetTime,
+			config.ReentryCooldownSeconds,
 			config.IsActive,
 			config.DataHash,
 			config.CreatedAt,
@@ -240,12 +275,21 @@ func (d gateConfigDAO) Update(ctx context.Context, config entity.GateConfig) err
 		maxScanByTypeJSON, _ = json.Marshal(config.MaxScanByType)
 	}
 
+	var entryWindowsJSON []byte
+	if config.EntryWindows != nil {
+		entryWindowsJSON, _ = json.Marshal(config.EntryWindows)
+	}
+
 	sql := sqlgo.NewSQLGo().
 		SetSQLSchema("public").
 		SetSQLUpdate("gate_configs").
 		SetSQLUpdateValue("mode", config.Mode).
 		SetSQLUpdateValue("max_scan_per_ticket", config.MaxScanPerTicket).
 		SetSQLUpdateValue("max_scan_by_type", maxScanByTypeJSON).
+		SetSQLUpdateValue("timezone", config.Timezone).
+		SetSQLUpdateValue("entry_windows", entryWindowsJSON).
+		SetSQLUpdateValue("daily_reset_time", config.DailyResetTime).
+		SetSQLUpdateValue("reentry_cooldown_seconds", config.ReentryCooldownSeconds).
 		SetSQLUpdateValue("is_active", config.IsActive).
 		SetSQLUpdateValue("updated_at", config.UpdatedAt).
 		SetSQLWhere("AND", "id", "=", config.ID)
@@ -14,7 +14,8 @@ import (
 
 type GateLogDAO interface {
 	Search(ctx context.Context, query entity.GateLogQuery) (entity.GateLogs, error)
-	Insert(ctx context.Context, logEntry entity.GateLog) error
+	Insert(ctx context.Context, logEntry *entity.GateLog) error
+	Update(ctx context.Context, logEntry entity.GateLog) error
 }
 
 type gateLogDAO struct {
@@ -33,16 +34,24 @@ func (d gateLogDAO) Search(ctx context.Context, query entity.GateLogQuery) (enti
 
 	sqlSelect := sqlgo.NewSQLGoSelect().
 		SetSQLSelect("gl.id", "id").
+		SetSQLSelect("gl.seq", "seq").
 		SetSQLSelect("gl.event_id", "event_id").
 		SetSQLSelect("gl.physical_ticket_id", "physical_ticket_id").
+		SetSQLSelect("gl.e_ticket_id", "e_ticket_id").
+		SetSQLSelect("gl.qr_code", "qr_code").
 		SetSQLSelect("gl.scanned_by", "scanned_by").
 		SetSQLSelect("gl.action", "action").
 		SetSQLSelect("gl.success", "success").
 		SetSQLSelect("gl.message", "message").
 		SetSQLSelect("gl.gate_name", "gate_name").
+		SetSQLSelect("gl.zone_id", "zone_id").
 		SetSQLSelect("gl.ticket_type", "ticket_type").
 		SetSQLSelect("gl.scan_sequence", "scan_sequence").
-		SetSQLSelect("gl.created_at", "created_at")
+		SetSQLSelect("gl.device_id", "device_id").
+		SetSQLSelect("gl.client_scan_id", "client_scan_id").
+		SetSQLSelect("gl.scanned_at", "scanned_at").
+		SetSQLSelect("gl.created_at", "created_at").
+		SetSQLSelect("gl.updated_at", "updated_at")
 
 	sqlFrom := sqlgo.NewSQLGoFrom().
 		SetSQLFrom("gate_logs", "gl")
@@ -58,18 +67,47 @@ func (d gateLogDAO) Search(ctx context.Context, query entity.GateLogQuery) (enti
 	if len(query.PhysicalTicketIDs) > 0 {
 		sqlWhere.SetSQLWhere("AND", "gl.physical_ticket_id", "IN", query.PhysicalTicketIDs)
 	}
+	if len(query.ETicketIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "gl.e_ticket_id", "IN", query.ETicketIDs)
+	}
 	if len(query.Actions) > 0 {
 		sqlWhere.SetSQLWhere("AND", "gl.action", "IN", query.Actions)
 	}
 	if len(query.GateNames) > 0 {
 		sqlWhere.SetSQLWhere("AND", "gl.gate_name", "IN", query.GateNames)
 	}
+	if len(query.DeviceIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "gl.device_id", "IN", query.DeviceIDs)
+	}
+	if len(query.ClientScanIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "gl.client_scan_id", "IN", query.ClientScanIDs)
+	}
+	if query.AfterSeq > 0 {
+		sqlWhere.SetSQLWhere("AND", "gl.seq", ">", query.AfterSeq)
+	}
+	if query.BeforeSeq > 0 {
+		sqlWhere.SetSQLWhere("AND", "gl.seq", "<", query.BeforeSeq)
+	}
+
+	sqlOrder := sqlgo.NewSQLGoOrder()
+	if query.Latest {
+		sqlOrder.SetSQLOrder("gl.seq", "DESC")
+	} else {
+		sqlOrder.SetSQLOrder("gl.seq", "ASC")
+	}
+
+	sqlOffsetLimit := sqlgo.NewSQLGoOffsetLimit()
+	if query.Limit > 0 {
+		sqlOffsetLimit.SetSQLLimit(query.Limit)
+	}
 
 	sql := sqlgo.NewSQLGo().
 		SetSQLSchema("public").
 		SetSQLGoSelect(sqlSelect).
 		SetSQLGoFrom(sqlFrom).
-		SetSQLGoWhere(sqlWhere)
+		SetSQLGoWhere(sqlWhere).
+		SetSQLGoOrder(sqlOrder).
+		SetSQLGoOffsetLimit(sqlOffsetLimit)
 
 	sqlStr := sql.BuildSQL()
 	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
@@ -96,16 +134,24 @@ func (d gateLogDAO) Search(ctx context.Context, query entity.GateLogQuery) (enti
 
 		if err := rows.Scan(
 			&gl.ID,
+			&gl.Seq,
 			&gl.EventID,
 			&gl.PhysicalTicketID,
+			&gl.ETicketID,
+			&gl.QRCode,
 			&gl.ScannedBy,
 			&gl.Action,
 			&gl.Success,
 			&gl.Message,
 			&gl.GateName,
+			&gl.ZoneID,
 			&gl.TicketType,
 			&gl.ScanSequence,
+			&gl.DeviceID,
+			&gl.ClientScanID,
+			&gl.ScannedAt,
 			&gl.CreatedAt,
+			&gl.UpdatedAt,
 		); err != nil {
 			d.log.Error(ctx, "gateLogDAO.Search.Scan", zap.Error(err))
 			return nil, err
@@ -117,9 +163,13 @@ func (d gateLogDAO) Search(ctx context.Context, query entity.GateLogQuery) (enti
 	return logs, nil
 }
 
-func (d gateLogDAO) Insert(ctx context.Context, logEntry entity.GateLog) error {
+// Insert mengisi CreatedAt dan Seq (cursor dari database) ke logEntry
+func (d gateLogDAO) Insert(ctx context.Context, logEntry *entity.GateLog) error {
 
 	logEntry.CreatedAt = time.Now()
+	if logEntry.ScannedAt == nil {
+		logEntry.ScannedAt = &logEntry.CreatedAt
+	}
 
 	sql := sqlgo.NewSQLGo().
 		SetSQLSchema("public").
@@ -129,30 +179,42 @@ func (d gateLogDAO) Insert(ctx context.Context, logEntry entity.GateLog) error {
 			"id",
 			"event_id",
 			"physical_ticket_id",
+			"e_ticket_id",
+			"qr_code",
 			"scanned_by",
 			"action",
 			"success",
 			"message",
 			"gate_name",
+			"zone_id",
 			"ticket_type",
 			"scan_sequence",
+			"device_id",
+			"client_scan_id",
+			"scanned_at",
 			"created_at",
 		).
 		SetSQLInsertValue(
 			logEntry.ID,
 			logEntry.EventID,
 			logEntry.PhysicalTicketID,
+			logEntry.ETicketID,
+			logEntry.QRCode,
 			logEntry.ScannedBy,
 			logEntry.Action,
 			logEntry.Success,
 			logEntry.Message,
 			logEntry.GateName,
+			logEntry.ZoneID,
 			logEntry.TicketType,
 			logEntry.ScanSequence,
+			logEntry.DeviceID,
+			logEntry.ClientScanID,
+			logEntry.ScannedAt,
 			logEntry.CreatedAt,
 		)
 
-	sqlStr := sql.BuildSQL()
+	sqlStr := sql.BuildSQL() + " RETURNING seq"
 	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
 
 	d.log.Debug(ctx, "gateLogDAO.Insert",
@@ -160,7 +222,7 @@ func (d gateLogDAO) Insert(ctx context.Context, logEntry entity.GateLog) error {
 		zap.Any("Params", sqlParams),
 	)
 
-	_, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...)
+	err := d.dbTrx.GetSqlTx().QueryRowContext(ctx, sqlStr, sqlParams...).Scan(&logEntry.Seq)
 	if err != nil {
 		d.log.Error(ctx, "gateLogDAO.Insert",
 			zap.String("SQL", sqlStr),
@@ -172,3 +234,38 @@ func (d gateLogDAO) Insert(ctx context.Context, logEntry entity.GateLog) error {
 
 	return nil
 }
+
+// Update hanya mengubah hasil scan; dipakai saat sync offline mengklasifikasi ulang scan lama
+func (d gateLogDAO) Update(ctx context.Context, logEntry entity.GateLog) error {
+	now := time.Now()
+	logEntry.UpdatedAt = &now
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLUpdate("gate_logs").
+		SetSQLUpdateValue("action", logEntry.Action).
+		SetSQLUpdateValue("success", logEntry.Success).
+		SetSQLUpdateValue("message", logEntry.Message).
+		SetSQLUpdateValue("scan_sequence", logEntry.ScanSequence).
+		SetSQLUpdateValue("updated_at", logEntry.UpdatedAt).
+		SetSQLWhere("AND", "id", "=", logEntry.ID)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "gateLogDAO.Update",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...); err != nil {
+		d.log.Error(ctx, "gateLogDAO.Update",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,366 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	baseDao "rakit-tiket-be/internal/pkg/dao"
+	entity "rakit-tiket-be/pkg/entity/app_gate_zone"
+	"rakit-tiket-be/pkg/util"
+
+	"gitlab.com/threetopia/sqlgo/v2"
+	"go.uber.org/zap"
+)
+
+// GateZoneDAO mengelola layout zona event: gate_zones, gate_zone_gates dan gate_zone_access
+type GateZoneDAO interface {
+	Search(ctx context.Context, query entity.GateZoneQuery) (entity.GateZones, error)
+	SearchGates(ctx context.Context, query entity.GateZoneQuery) (entity.ZoneGates, error)
+	SearchAccess(ctx context.Context, query entity.GateZoneQuery) (entity.ZoneAccesses, error)
+	Insert(ctx context.Context, zone entity.GateZone) error
+	InsertGate(ctx context.Context, gate entity.ZoneGate) error
+	InsertAccess(ctx context.Context, access entity.ZoneAccess) error
+	DeleteByEventID(ctx context.Context, eventID string) error
+}
+
+type gateZoneDAO struct {
+	log   util.LogUtil
+	dbTrx baseDao.DBTransaction
+}
+
+func MakeGateZoneDAO(log util.LogUtil, dbTrx baseDao.DBTransaction) GateZoneDAO {
+	return gateZoneDAO{
+		log:   log,
+		dbTrx: dbTrx,
+	}
+}
+
+func (d gateZoneDAO) Search(ctx context.Context, query entity.GateZoneQuery) (entity.GateZones, error) {
+
+	sqlSelect := sqlgo.NewSQLGoSelect().
+		SetSQLSelect("gz.id", "id").
+		SetSQLSelect("gz.event_id", "event_id").
+		SetSQLSelect("gz.code", "code").
+		SetSQLSelect("gz.name", "name").
+		SetSQLSelect("gz.capacity", "capacity").
+		SetSQLSelect("gz.deleted", "deleted").
+		SetSQLSelect("gz.data_hash", "data_hash").
+		SetSQLSelect("gz.created_at", "created_at").
+		SetSQLSelect("gz.updated_at", "updated_at")
+
+	sqlFrom := sqlgo.NewSQLGoFrom().
+		SetSQLFrom("gate_zones", "gz")
+
+	sqlWhere := sqlgo.NewSQLGoWhere().
+		SetSQLWhere("AND", "gz.deleted", "=", false)
+
+	if len(query.IDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "gz.id", "IN", query.IDs)
+	}
+	if len(query.EventIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "gz.event_id", "IN", query.EventIDs)
+	}
+
+	sqlOrder := sqlgo.NewSQLGoOrder()
+	sqlOrder.SetSQLOrder("gz.code", "ASC")
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoSelect(sqlSelect).
+		SetSQLGoFrom(sqlFrom).
+		SetSQLGoWhere(sqlWhere).
+		SetSQLGoOrder(sqlOrder)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "gateZoneDAO.Search",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	rows, err := d.dbTrx.GetSqlDB().QueryContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "gateZoneDAO.Search",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var zones entity.GateZones
+	for rows.Next() {
+		var zone entity.GateZone
+
+		if err := rows.Scan(
+			&zone.ID,
+			&zone.EventID,
+			&zone.Code,
+			&zone.Name,
+			&zone.Capacity,
+			&zone.Deleted,
+			&zone.DataHash,
+			&zone.CreatedAt,
+			&zone.UpdatedAt,
+		); err != nil {
+			d.log.Error(ctx, "gateZoneDAO.Search.Scan", zap.Error(err))
+			return nil, err
+		}
+
+		zones = append(zones, zone)
+	}
+
+	return zones, nil
+}
+
+func (d gateZoneDAO) SearchGates(ctx context.Context, query entity.GateZoneQuery) (entity.ZoneGates, error) {
+
+	sqlSelect := sqlgo.NewSQLGoSelect().
+		SetSQLSelect("zg.id", "id").
+		SetSQLSelect("zg.event_id", "event_id").
+		SetSQLSelect("zg.zone_id", "zone_id").
+		SetSQLSelect("zg.gate_name", "gate_name").
+		SetSQLSelect("zg.created_at", "created_at")
+
+	sqlFrom := sqlgo.NewSQLGoFrom().
+		SetSQLFrom("gate_zone_gates", "zg")
+
+	sqlWhere := sqlgo.NewSQLGoWhere()
+
+	if len(query.EventIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "zg.event_id", "IN", query.EventIDs)
+	}
+
+	sqlOrder := sqlgo.NewSQLGoOrder()
+	sqlOrder.SetSQLOrder("zg.gate_name", "ASC")
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoSelect(sqlSelect).
+		SetSQLGoFrom(sqlFrom).
+		SetSQLGoWhere(sqlWhere).
+		SetSQLGoOrder(sqlOrder)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "gateZoneDAO.SearchGates",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	rows, err := d.dbTrx.GetSqlDB().QueryContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "gateZoneDAO.SearchGates",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var gates entity.ZoneGates
+	for rows.Next() {
+		var gate entity.ZoneGate
+
+		if err := rows.Scan(
+			&gate.ID,
+			&gate.EventID,
+			&gate.ZoneID,
+			&gate.GateName,
+			&gate.CreatedAt,
+		); err != nil {
+			d.log.Error(ctx, "gateZoneDAO.SearchGates.Scan", zap.Error(err))
+			return nil, err
+		}
+
+		gates = append(gates, gate)
+	}
+
+	return gates, nil
+}
+
+func (d gateZoneDAO) SearchAccess(ctx context.Context, query entity.GateZoneQuery) (entity.ZoneAccesses, error) {
+
+	sqlSelect := sqlgo.NewSQLGoSelect().
+		SetSQLSelect("za.id", "id").
+		SetSQLSelect("za.event_id", "event_id").
+		SetSQLSelect("za.zone_id", "zone_id").
+		SetSQLSelect("za.ticket_type", "ticket_type").
+		SetSQLSelect("za.created_at", "created_at")
+
+	sqlFrom := sqlgo.NewSQLGoFrom().
+		SetSQLFrom("gate_zone_access", "za")
+
+	sqlWhere := sqlgo.NewSQLGoWhere()
+
+	if len(query.EventIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "za.event_id", "IN", query.EventIDs)
+	}
+
+	sqlOrder := sqlgo.NewSQLGoOrder()
+	sqlOrder.SetSQLOrder("za.ticket_type", "ASC")
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoSelect(sqlSelect).
+		SetSQLGoFrom(sqlFrom).
+		SetSQLGoWhere(sqlWhere).
+		SetSQLGoOrder(sqlOrder)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "gateZoneDAO.SearchAccess",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	rows, err := d.dbTrx.GetSqlDB().QueryContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "gateZoneDAO.SearchAccess",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accesses entity.ZoneAccesses
+	for rows.Next() {
+		var access entity.ZoneAccess
+
+		if err := rows.Scan(
+			&access.ID,
+			&access.EventID,
+			&access.ZoneID,
+			&access.TicketType,
+			&access.CreatedAt,
+		); err != nil {
+			d.log.Error(ctx, "gateZoneDAO.SearchAccess.Scan", zap.Error(err))
+			return nil, err
+		}
+
+		accesses = append(accesses, access)
+	}
+
+	return accesses, nil
+}
+
+func (d gateZoneDAO) Insert(ctx context.Context, zone entity.GateZone) error {
+
+	zone.CreatedAt = time.Now()
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoInsert(sqlgo.NewSQLGoInsert()).
+		SetSQLInsert("gate_zones").
+		SetSQLInsertColumn(
+			"id",
+			"event_id",
+			"code",
+			"name",
+			"capacity",
+			"data_hash",
+			"created_at",
+		).
+		SetSQLInsertValue(
+			zone.ID,
+			zone.EventID,
+			zone.Code,
+			zone.Name,
+			zone.Capacity,
+			"-",
+			zone.CreatedAt,
+		)
+
+	return d.exec(ctx, "gateZoneDAO.Insert", sql)
+}
+
+func (d gateZoneDAO) InsertGate(ctx context.Context, gate entity.ZoneGate) error {
+
+	gate.CreatedAt = time.Now()
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoInsert(sqlgo.NewSQLGoInsert()).
+		SetSQLInsert("gate_zone_gates").
+		SetSQLInsertColumn(
+			"id",
+			"event_id",
+			"zone_id",
+			"gate_name",
+			"created_at",
+		).
+		SetSQLInsertValue(
+			gate.ID,
+			gate.EventID,
+			gate.ZoneID,
+			gate.GateName,
+			gate.CreatedAt,
+		)
+
+	return d.exec(ctx, "gateZoneDAO.InsertGate", sql)
+}
+
+func (d gateZoneDAO) InsertAccess(ctx context.Context, access entity.ZoneAccess) error {
+
+	access.CreatedAt = time.Now()
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoInsert(sqlgo.NewSQLGoInsert()).
+		SetSQLInsert("gate_zone_access").
+		SetSQLInsertColumn(
+			"id",
+			"event_id",
+			"zone_id",
+			"ticket_type",
+			"created_at",
+		).
+		SetSQLInsertValue(
+			access.ID,
+			access.EventID,
+			access.ZoneID,
+			access.TicketType,
+			access.CreatedAt,
+		)
+
+	return d.exec(ctx, "gateZoneDAO.InsertAccess", sql)
+}
+
+// DeleteByEventID menghapus seluruh layout zona event; gate dan matriks akses ikut terhapus (ON DELETE CASCADE)
+func (d gateZoneDAO) DeleteByEventID(ctx context.Context, eventID string) error {
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLDelete("gate_zones").
+		SetSQLWhere("AND", "event_id", "=", eventID)
+
+	return d.exec(ctx, "gateZoneDAO.DeleteByEventID", sql)
+}
+
+func (d gateZoneDAO) exec(ctx context.Context, name string, sql sqlgo.SQLGo) error {
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, name,
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...); err != nil {
+		d.log.Error(ctx, name,
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return nil
+}
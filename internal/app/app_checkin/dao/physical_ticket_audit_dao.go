@@ -0,0 +1,197 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	baseDao "rakit-tiket-be/internal/pkg/dao"
+	entity "rakit-tiket-be/pkg/entity/app_physical_ticket"
+	"rakit-tiket-be/pkg/util"
+
+	"gitlab.com/threetopia/sqlgo/v2"
+	"go.uber.org/zap"
+)
+
+type PhysicalTicketAuditDAO interface {
+	Search(ctx context.Context, query entity.PhysicalTicketAuditQuery) (entity.PhysicalTicketAudits, error)
+	Insert(ctx context.Context, audits entity.PhysicalTicketAudits) error
+}
+
+type physicalTicketAuditDAO struct {
+	log   util.LogUtil
+	dbTrx baseDao.DBTransaction
+}
+
+func MakePhysicalTicketAuditDAO(log util.LogUtil, dbTrx baseDao.DBTransaction) PhysicalTicketAuditDAO {
+	return physicalTicketAuditDAO{
+		log:   log,
+		dbTrx: dbTrx,
+	}
+}
+
+func (d physicalTicketAuditDAO) Search(ctx context.Context, query entity.PhysicalTicketAuditQuery) (entity.PhysicalTicketAudits, error) {
+
+	sqlSelect := sqlgo.NewSQLGoSelect().
+		SetSQLSelect("pta.id", "id").
+		SetSQLSelect("pta.event_id", "event_id").
+		SetSQLSelect("pta.physical_ticket_id", "physical_ticket_id").
+		SetSQLSelect("pta.action", "action").
+		SetSQLSelect("pta.from_status", "from_status").
+		SetSQLSelect("pta.to_status", "to_status").
+		SetSQLSelect("pta.from_ticket_type", "from_ticket_type").
+		SetSQLSelect("pta.to_ticket_type", "to_ticket_type").
+		SetSQLSelect("pta.from_scan_count", "from_scan_count").
+		SetSQLSelect("pta.to_scan_count", "to_scan_count").
+		SetSQLSelect("pta.related_ticket_id", "related_ticket_id").
+		SetSQLSelect("COALESCE(pta.reason, '')", "reason").
+		SetSQLSelect("pta.performed_by", "performed_by").
+		SetSQLSelect("pta.created_at", "created_at")
+
+	sqlFrom := sqlgo.NewSQLGoFrom().
+		SetSQLFrom("physical_ticket_audits", "pta")
+
+	sqlWhere := sqlgo.NewSQLGoWhere()
+
+	if len(query.EventIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "pta.event_id", "IN", query.EventIDs)
+	}
+	if len(query.PhysicalTicketIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "pta.physical_ticket_id", "IN", query.PhysicalTicketIDs)
+	}
+	if len(query.Actions) > 0 {
+		sqlWhere.SetSQLWhere("AND", "pta.action", "IN", query.Actions)
+	}
+
+	sqlOrder := sqlgo.NewSQLGoOrder()
+	sqlOrder.SetSQLOrder("pta.created_at", "ASC")
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoSelect(sqlSelect).
+		SetSQLGoFrom(sqlFrom).
+		SetSQLGoWhere(sqlWhere).
+		SetSQLGoOrder(sqlOrder)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "physicalTicketAuditDAO.Search",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	rows, err := d.dbTrx.GetSqlDB().QueryContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "physicalTicketAuditDAO.Search",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var audits entity.PhysicalTicketAudits
+	for rows.Next() {
+		var audit entity.PhysicalTicketAudit
+
+		if err := rows.Scan(
+			&audit.ID,
+			&audit.EventID,
+			&audit.PhysicalTicketID,
+			&audit.Action,
+			&audit.FromStatus,
+			&audit.ToStatus,
+			&audit.FromTicketType,
+			&audit.ToTicketType,
+			&audit.FromScanCount,
+			&audit.ToScanCount,
+			&audit.RelatedTicketID,
+			&audit.Reason,
+			&audit.PerformedBy,
+			&audit.CreatedAt,
+		); err != nil {
+			d.log.Error(ctx, "physicalTicketAuditDAO.Search.Scan", zap.Error(err))
+			return nil, err
+		}
+
+		audits = append(audits, audit)
+	}
+
+	return audits, nil
+}
+
+func (d physicalTicketAuditDAO) Insert(ctx context.Context, audits entity.PhysicalTicketAudits) error {
+
+	if len(audits) < 1 {
+		return nil
+	}
+
+	sqlInsert := sqlgo.NewSQLGoInsert().
+		SetSQLInsert("physical_ticket_audits").
+		SetSQLInsertColumn(
+			"id",
+			"event_id",
+			"physical_ticket_id",
+			"action",
+			"from_status",
+			"to_status",
+			"from_ticket_type",
+			"to_ticket_type",
+			"from_scan_count",
+			"to_scan_count",
+			"related_ticket_id",
+			"reason",
+			"performed_by",
+			"created_at",
+		)
+
+	for i, audit := range audits {
+		if audit.CreatedAt.IsZero() {
+			audit.CreatedAt = time.Now()
+		}
+
+		sqlInsert.SetSQLInsertValue(
+			audit.ID,
+			audit.EventID,
+			audit.PhysicalTicketID,
+			audit.Action,
+			audit.FromStatus,
+			audit.ToStatus,
+			audit.FromTicketType,
+			audit.ToTicketType,
+			audit.FromScanCount,
+			audit.ToScanCount,
+			audit.RelatedTicketID,
+			audit.Reason,
+			audit.PerformedBy,
+			audit.CreatedAt,
+		)
+
+		audits[i] = audit
+	}
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoInsert(sqlInsert)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "physicalTicketAuditDAO.Insert",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	_, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "physicalTicketAuditDAO.Insert",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return nil
+}
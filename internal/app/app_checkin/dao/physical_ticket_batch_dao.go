@@ -0,0 +1,165 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	baseDao "rakit-tiket-be/internal/pkg/dao"
+	entity "rakit-tiket-be/pkg/entity/app_physical_ticket"
+	"rakit-tiket-be/pkg/util"
+
+	"gitlab.com/threetopia/sqlgo/v2"
+	"go.uber.org/zap"
+)
+
+type PhysicalTicketBatchDAO interface {
+	Search(ctx context.Context, query entity.PhysicalTicketBatchQuery) (entity.PhysicalTicketBatches, error)
+	Insert(ctx context.Context, batch entity.PhysicalTicketBatch) error
+}
+
+type physicalTicketBatchDAO struct {
+	log   util.LogUtil
+	dbTrx baseDao.DBTransaction
+}
+
+func MakePhysicalTicketBatchDAO(log util.LogUtil, dbTrx baseDao.DBTransaction) PhysicalTicketBatchDAO {
+	return physicalTicketBatchDAO{
+		log:   log,
+		dbTrx: dbTrx,
+	}
+}
+
+func (d physicalTicketBatchDAO) Search(ctx context.Context, query entity.PhysicalTicketBatchQuery) (entity.PhysicalTicketBatches, error) {
+
+	sqlSelect := sqlgo.NewSQLGoSelect().
+		SetSQLSelect("ptb.id", "id").
+		SetSQLSelect("ptb.event_id", "event_id").
+		SetSQLSelect("ptb.vendor", "vendor").
+		SetSQLSelect("ptb.print_date", "print_date").
+		SetSQLSelect("ptb.ticket_counts", "ticket_counts").
+		SetSQLSelect("ptb.created_by", "created_by").
+		SetSQLSelect("ptb.created_at", "created_at").
+		SetSQLSelect("ptb.updated_at", "updated_at")
+
+	sqlFrom := sqlgo.NewSQLGoFrom().
+		SetSQLFrom("physical_ticket_batches", "ptb")
+
+	sqlWhere := sqlgo.NewSQLGoWhere().
+		SetSQLWhere("AND", "ptb.deleted", "=", false)
+
+	if len(query.IDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "ptb.id", "IN", query.IDs)
+	}
+	if len(query.EventIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "ptb.event_id", "IN", query.EventIDs)
+	}
+
+	sqlOrder := sqlgo.NewSQLGoOrder()
+	sqlOrder.SetSQLOrder("ptb.created_at", "DESC")
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoSelect(sqlSelect).
+		SetSQLGoFrom(sqlFrom).
+		SetSQLGoWhere(sqlWhere).
+		SetSQLGoOrder(sqlOrder)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "physicalTicketBatchDAO.Search",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	rows, err := d.dbTrx.GetSqlDB().QueryContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "physicalTicketBatchDAO.Search",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batches entity.PhysicalTicketBatches
+	for rows.Next() {
+		var batch entity.PhysicalTicketBatch
+		var ticketCountsJSON []byte
+
+		if err := rows.Scan(
+			&batch.ID,
+			&batch.EventID,
+			&batch.Vendor,
+			&batch.PrintDate,
+			&ticketCountsJSON,
+			&batch.CreatedBy,
+			&batch.CreatedAt,
+			&batch.UpdatedAt,
+		); err != nil {
+			d.log.Error(ctx, "physicalTicketBatchDAO.Search.Scan", zap.Error(err))
+			return nil, err
+		}
+
+		if len(ticketCountsJSON) > 0 {
+			_ = json.Unmarshal(ticketCountsJSON, &batch.TicketCounts)
+		}
+
+		batches = append(batches, batch)
+	}
+
+	return batches, nil
+}
+
+func (d physicalTicketBatchDAO) Insert(ctx context.Context, batch entity.PhysicalTicketBatch) error {
+	batch.CreatedAt = time.Now()
+
+	ticketCountsJSON, _ := json.Marshal(batch.TicketCounts)
+
+	sqlInsert := sqlgo.NewSQLGoInsert().
+		SetSQLInsert("physical_ticket_batches").
+		SetSQLInsertColumn(
+			"id",
+			"event_id",
+			"vendor",
+			"print_date",
+			"ticket_counts",
+			"created_by",
+			"created_at",
+		).
+		SetSQLInsertValue(
+			batch.ID,
+			batch.EventID,
+			batch.Vendor,
+			batch.PrintDate,
+			ticketCountsJSON,
+			batch.CreatedBy,
+			batch.CreatedAt,
+		)
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoInsert(sqlInsert)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "physicalTicketBatchDAO.Insert",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	_, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "physicalTicketBatchDAO.Insert",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package dao
 
 import (
 	"context"
+	gosql "database/sql"
 	"time"
 
 	baseDao "rakit-tiket-be/internal/pkg/dao"
@@ -15,7 +16,9 @@ import (
 
 type PhysicalTicketDAO interface {
 	Search(ctx context.Context, query entity.PhysicalTicketQuery) (entity.PhysicalTickets, error)
+	SearchForUpdate(ctx context.Context, query entity.PhysicalTicketQuery) (entity.PhysicalTickets, error)
 	SearchByQRCode(ctx context.Context, qrCode string) (*entity.PhysicalTicket, error)
+	Count(ctx context.Context, query entity.PhysicalTicketQuery) (int, error)
 	Insert(ctx context.Context, tickets entity.PhysicalTickets) error
 	Update(ctx context.Context, tickets entity.PhysicalTickets) error
 	SoftDelete(ctx context.Context, id pubEntity.UUID) error
@@ -34,24 +37,15 @@ func MakePhysicalTicketDAO(log util.LogUtil, dbTrx baseDao.DBTransaction) Physic
 }
 
 func (d physicalTicketDAO) Search(ctx context.Context, query entity.PhysicalTicketQuery) (entity.PhysicalTickets, error) {
+	return d.search(ctx, query, false)
+}
 
-	sqlSelect := sqlgo.NewSQLGoSelect().
-		SetSQLSelect("pt.id", "id").
-		SetSQLSelect("pt.event_id", "event_id").
-		SetSQLSelect("pt.ticket_type", "ticket_type").
-		SetSQLSelect("pt.ticket_id", "ticket_id").
-		SetSQLSelect("pt.qr_code", "qr_code").
-		SetSQLSelect("pt.qr_code_hash", "qr_code_hash").
-		SetSQLSelect("pt.status", "status").
-		SetSQLSelect("pt.scan_count", "scan_count").
-		SetSQLSelect("pt.checked_in_at", "checked_in_at").
-		SetSQLSelect("pt.checked_out_at", "checked_out_at").
-		SetSQLSelect("pt.created_at", "created_at").
-		SetSQLSelect("pt.updated_at", "updated_at")
-
-	sqlFrom := sqlgo.NewSQLGoFrom().
-		SetSQLFrom("physical_tickets", "pt")
+// SearchForUpdate mengunci baris tiket (urutan tetap agar tidak deadlock) sampai transaksi selesai
+func (d physicalTicketDAO) SearchForUpdate(ctx context.Context, query entity.PhysicalTicketQuery) (entity.PhysicalTickets, error) {
+	return d.search(ctx, query, true)
+}
 
+func (d physicalTicketDAO) buildWhere(query entity.PhysicalTicketQuery) sqlgo.SQLGoWhere {
 	sqlWhere := sqlgo.NewSQLGoWhere().
 		SetSQLWhere("AND", "pt.deleted", "=", false)
 
@@ -73,12 +67,63 @@ func (d physicalTicketDAO) Search(ctx context.Context, query entity.PhysicalTick
 	if len(query.Statuses) > 0 {
 		sqlWhere.SetSQLWhere("AND", "pt.status", "IN", query.Statuses)
 	}
+	if len(query.ReplacesIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "pt.replaces_id", "IN", query.ReplacesIDs)
+	}
+	if len(query.BatchIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "pt.batch_id", "IN", query.BatchIDs)
+	}
+	if len(query.Serials) > 0 {
+		sqlWhere.SetSQLWhere("AND", "pt.serial", "IN", query.Serials)
+	}
+
+	return sqlWhere
+}
+
+func (d physicalTicketDAO) search(ctx context.Context, query entity.PhysicalTicketQuery, forUpdate bool) (entity.PhysicalTickets, error) {
+
+	sqlSelect := sqlgo.NewSQLGoSelect().
+		SetSQLSelect("pt.id", "id").
+		SetSQLSelect("pt.event_id", "event_id").
+		SetSQLSelect("pt.ticket_type", "ticket_type").
+		SetSQLSelect("pt.ticket_id", "ticket_id").
+		SetSQLSelect("pt.qr_code", "qr_code").
+		SetSQLSelect("pt.qr_code_hash", "qr_code_hash").
+		SetSQLSelect("pt.status", "status").
+		SetSQLSelect("pt.scan_count", "scan_count").
+		SetSQLSelect("pt.checked_in_at", "checked_in_at").
+		SetSQLSelect("pt.checked_out_at", "checked_out_at").
+		SetSQLSelect("pt.scans_reset_at", "scans_reset_at").
+		SetSQLSelect("pt.replaces_id", "replaces_id").
+		SetSQLSelect("pt.batch_id", "batch_id").
+		SetSQLSelect("pt.batch_seq", "batch_seq").
+		SetSQLSelect("pt.serial", "serial").
+		SetSQLSelect("pt.created_at", "created_at").
+		SetSQLSelect("pt.updated_at", "updated_at")
+
+	sqlFrom := sqlgo.NewSQLGoFrom().
+		SetSQLFrom("physical_tickets", "pt")
+
+	sqlOrder := sqlgo.NewSQLGoOrder()
+	sqlOrder.SetSQLOrder("pt.created_at", "ASC")
+	sqlOrder.SetSQLOrder("pt.id", "ASC")
+
+	sqlOffsetLimit := sqlgo.NewSQLGoOffsetLimit()
+	if !query.PagingQuery.NoLimit && query.PagingQuery.Limit > 0 {
+		if query.PagingQuery.Page > 0 {
+			sqlOffsetLimit.SQLPageLimit(query.PagingQuery.Page.Int(), query.PagingQuery.Limit.Int())
+		} else {
+			sqlOffsetLimit.SetSQLLimit(query.PagingQuery.Limit.Int())
+		}
+	}
 
 	sql := sqlgo.NewSQLGo().
 		SetSQLSchema("public").
 		SetSQLGoSelect(sqlSelect).
 		SetSQLGoFrom(sqlFrom).
-		SetSQLGoWhere(sqlWhere)
+		SetSQLGoWhere(d.buildWhere(query)).
+		SetSQLGoOrder(sqlOrder).
+		SetSQLGoOffsetLimit(sqlOffsetLimit)
 
 	sqlStr := sql.BuildSQL()
 	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
@@ -88,7 +133,15 @@ func (d physicalTicketDAO) Search(ctx context.Context, query entity.PhysicalTick
 		zap.Any("Params", sqlParams),
 	)
 
-	rows, err := d.dbTrx.GetSqlDB().QueryContext(ctx, sqlStr, sqlParams...)
+	var (
+		rows *gosql.Rows
+		err  error
+	)
+	if forUpdate {
+		rows, err = d.dbTrx.GetSqlTx().QueryContext(ctx, sqlStr+" FOR UPDATE", sqlParams...)
+	} else {
+		rows, err = d.dbTrx.GetSqlDB().QueryContext(ctx, sqlStr, sqlParams...)
+	}
 	if err != nil {
 		d.log.Error(ctx, "physicalTicketDAO.Search",
 			zap.String("SQL", sqlStr),
@@ -114,6 +167,11 @@ func (d physicalTicketDAO) Search(ctx context.Context, query entity.PhysicalTick
 			&tkt.ScanCount,
 			&tkt.CheckedInAt,
 			&tkt.CheckedOutAt,
+			&tkt.ScansResetAt,
+			&tkt.ReplacesID,
+			&tkt.BatchID,
+			&tkt.BatchSeq,
+			&tkt.Serial,
 			&tkt.CreatedAt,
 			&tkt.UpdatedAt,
 		); err != nil {
@@ -127,6 +185,41 @@ func (d physicalTicketDAO) Search(ctx context.Context, query entity.PhysicalTick
 	return tickets, nil
 }
 
+func (d physicalTicketDAO) Count(ctx context.Context, query entity.PhysicalTicketQuery) (int, error) {
+	sqlSelect := sqlgo.NewSQLGoSelect()
+	sqlSelect.SetSQLSelect("COUNT(pt.id)", "count")
+
+	sqlFrom := sqlgo.NewSQLGoFrom()
+	sqlFrom.SetSQLFrom("physical_tickets", "pt")
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoSelect(sqlSelect).
+		SetSQLGoFrom(sqlFrom).
+		SetSQLGoWhere(d.buildWhere(query))
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "physicalTicketDAO.Count",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	var totalCount int
+	err := d.dbTrx.GetSqlDB().QueryRowContext(ctx, sqlStr, sqlParams...).Scan(&totalCount)
+	if err != nil {
+		d.log.Error(ctx, "physicalTicketDAO.Count",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+			zap.Error(err),
+		)
+		return 0, err
+	}
+
+	return totalCount, nil
+}
+
 func (d physicalTicketDAO) SearchByQRCode(ctx context.Context, qrCode string) (*entity.PhysicalTicket, error) {
 
 	sqlSelect := sqlgo.NewSQLGoSelect().
@@ -140,6 +233,11 @@ func (d physicalTicketDAO) SearchByQRCode(ctx context.Context, qrCode string) (*
 		SetSQLSelect("pt.scan_count", "scan_count").
 		SetSQLSelect("pt.checked_in_at", "checked_in_at").
 		SetSQLSelect("pt.checked_out_at", "checked_out_at").
+		SetSQLSelect("pt.scans_reset_at", "scans_reset_at").
+		SetSQLSelect("pt.replaces_id", "replaces_id").
+		SetSQLSelect("pt.batch_id", "batch_id").
+		SetSQLSelect("pt.batch_seq", "batch_seq").
+		SetSQLSelect("pt.serial", "serial").
 		SetSQLSelect("pt.created_at", "created_at").
 		SetSQLSelect("pt.updated_at", "updated_at")
 
@@ -176,6 +274,11 @@ func (d physicalTicketDAO) SearchByQRCode(ctx context.Context, qrCode string) (*
 		&tkt.ScanCount,
 		&tkt.CheckedInAt,
 		&tkt.CheckedOutAt,
+		&tkt.ScansResetAt,
+		&tkt.ReplacesID,
+		&tkt.BatchID,
+		&tkt.BatchSeq,
+		&tkt.Serial,
 		&tkt.CreatedAt,
 		&tkt.UpdatedAt,
 	)
@@ -203,6 +306,10 @@ func (d physicalTicketDAO) Insert(ctx context.Context, tickets entity.PhysicalTi
 			"qr_code_hash",
 			"status",
 			"scan_count",
+			"replaces_id",
+			"batch_id",
+			"batch_seq",
+			"serial",
 			"data_hash",
 			"created_at",
 		)
@@ -221,6 +328,10 @@ func (d physicalTicketDAO) Insert(ctx context.Context, tickets entity.PhysicalTi
 			tkt.QRCodeHash,
 			tkt.Status,
 			tkt.ScanCount,
+			tkt.ReplacesID,
+			tkt.BatchID,
+			tkt.BatchSeq,
+			tkt.Serial,
 			tkt.DaoEntity.DataHash,
 			tkt.CreatedAt,
 		)
@@ -271,6 +382,7 @@ func (d physicalTicketDAO) Update(ctx context.Context, tickets entity.PhysicalTi
 			SetSQLUpdateValue("scan_count", tkt.ScanCount).
 			SetSQLUpdateValue("checked_in_at", tkt.CheckedInAt).
 			SetSQLUpdateValue("checked_out_at", tkt.CheckedOutAt).
+			SetSQLUpdateValue("scans_reset_at", tkt.ScansResetAt).
 			SetSQLUpdateValue("updated_at", tkt.UpdatedAt).
 			SetSQLWhere("AND", "id", "=", tkt.ID)
 
@@ -0,0 +1,50 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	baseDao "rakit-tiket-be/internal/pkg/dao"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	"rakit-tiket-be/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+// RegistrantCheckInDAO menjaga registrants.checked_in tetap sama dengan status kursi milik registrant
+type RegistrantCheckInDAO interface {
+	MarkCheckedIn(ctx context.Context, registrantID pubEntity.UUID, checkedInAt time.Time) error
+}
+
+type registrantCheckInDAO struct {
+	log   util.LogUtil
+	dbTrx baseDao.DBTransaction
+}
+
+func MakeRegistrantCheckInDAO(log util.LogUtil, dbTrx baseDao.DBTransaction) RegistrantCheckInDAO {
+	return registrantCheckInDAO{
+		log:   log,
+		dbTrx: dbTrx,
+	}
+}
+
+func (d registrantCheckInDAO) MarkCheckedIn(ctx context.Context, registrantID pubEntity.UUID, checkedInAt time.Time) error {
+	query := `
+        UPDATE registrants
+        SET
+            checked_in    = true,
+            checked_in_at = $1,
+            updated_at    = $1
+        WHERE id = $2
+        AND COALESCE(checked_in, false) = false
+    `
+
+	d.log.Debug(ctx, "registrantCheckInDAO.MarkCheckedIn", zap.String("RegistrantID", string(registrantID)))
+
+	if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, query, checkedInAt, registrantID); err != nil {
+		d.log.Error(ctx, "registrantCheckInDAO.MarkCheckedIn", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,231 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	baseDao "rakit-tiket-be/internal/pkg/dao"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	entity "rakit-tiket-be/pkg/entity/app_scanner_device"
+	"rakit-tiket-be/pkg/util"
+
+	"gitlab.com/threetopia/sqlgo/v2"
+	"go.uber.org/zap"
+)
+
+type ScannerDeviceDAO interface {
+	Search(ctx context.Context, query entity.ScannerDeviceQuery) (entity.ScannerDevices, error)
+	Insert(ctx context.Context, device entity.ScannerDevice) error
+	Update(ctx context.Context, device entity.ScannerDevice) error
+	TouchLastSeen(ctx context.Context, id pubEntity.UUID, seenAt time.Time) error
+}
+
+type scannerDeviceDAO struct {
+	log   util.LogUtil
+	dbTrx baseDao.DBTransaction
+}
+
+func MakeScannerDeviceDAO(log util.LogUtil, dbTrx baseDao.DBTransaction) ScannerDeviceDAO {
+	return scannerDeviceDAO{
+		log:   log,
+		dbTrx: dbTrx,
+	}
+}
+
+func (d scannerDeviceDAO) Search(ctx context.Context, query entity.ScannerDeviceQuery) (entity.ScannerDevices, error) {
+
+	sqlSelect := sqlgo.NewSQLGoSelect().
+		SetSQLSelect("sd.id", "id").
+		SetSQLSelect("sd.device_id", "device_id").
+		SetSQLSelect("sd.name", "name").
+		SetSQLSelect("sd.event_id", "event_id").
+		SetSQLSelect("sd.gate_name", "gate_name").
+		SetSQLSelect("sd.token_hash", "token_hash").
+		SetSQLSelect("sd.revoked_at", "revoked_at").
+		SetSQLSelect("sd.last_seen_at", "last_seen_at").
+		SetSQLSelect("sd.created_by", "created_by").
+		SetSQLSelect("sd.deleted", "deleted").
+		SetSQLSelect("sd.data_hash", "data_hash").
+		SetSQLSelect("sd.created_at", "created_at").
+		SetSQLSelect("sd.updated_at", "updated_at")
+
+	sqlFrom := sqlgo.NewSQLGoFrom().
+		SetSQLFrom("scanner_devices", "sd")
+
+	sqlWhere := sqlgo.NewSQLGoWhere().
+		SetSQLWhere("AND", "sd.deleted", "=", false)
+
+	if !query.IncludeRevoked {
+		sqlWhere.SQLWhere(sqlgo.SetSQLWhereNotParam("AND", "sd.revoked_at", " IS ", "NULL"))
+	}
+	if len(query.IDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "sd.id", "IN", query.IDs)
+	}
+	if len(query.DeviceIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "sd.device_id", "IN", query.DeviceIDs)
+	}
+	if len(query.EventIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "sd.event_id", "IN", query.EventIDs)
+	}
+	if len(query.TokenHashes) > 0 {
+		sqlWhere.SetSQLWhere("AND", "sd.token_hash", "IN", query.TokenHashes)
+	}
+
+	sqlOrder := sqlgo.NewSQLGoOrder()
+	sqlOrder.SetSQLOrder("sd.created_at", "ASC")
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoSelect(sqlSelect).
+		SetSQLGoFrom(sqlFrom).
+		SetSQLGoWhere(sqlWhere).
+		SetSQLGoOrder(sqlOrder)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "scannerDeviceDAO.Search",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	rows, err := d.dbTrx.GetSqlDB().QueryContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "scannerDeviceDAO.Search",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var devices entity.ScannerDevices
+	for rows.Next() {
+		var device entity.ScannerDevice
+
+		if err := rows.Scan(
+			&device.ID,
+			&device.DeviceID,
+			&device.Name,
+			&device.EventID,
+			&device.GateName,
+			&device.TokenHash,
+			&device.RevokedAt,
+			&device.LastSeenAt,
+			&device.CreatedBy,
+			&device.Deleted,
+			&device.DataHash,
+			&device.CreatedAt,
+			&device.UpdatedAt,
+		); err != nil {
+			d.log.Error(ctx, "scannerDeviceDAO.Search.Scan", zap.Error(err))
+			return nil, err
+		}
+
+		devices = append(devices, device)
+	}
+
+	return devices, nil
+}
+
+func (d scannerDeviceDAO) Insert(ctx context.Context, device entity.ScannerDevice) error {
+
+	device.CreatedAt = time.Now()
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoInsert(sqlgo.NewSQLGoInsert()).
+		SetSQLInsert("scanner_devices").
+		SetSQLInsertColumn(
+			"id",
+			"device_id",
+			"name",
+			"event_id",
+			"gate_name",
+			"token_hash",
+			"created_by",
+			"data_hash",
+			"created_at",
+		).
+		SetSQLInsertValue(
+			device.ID,
+			device.DeviceID,
+			device.Name,
+			device.EventID,
+			device.GateName,
+			device.TokenHash,
+			device.CreatedBy,
+			"-",
+			device.CreatedAt,
+		)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "scannerDeviceDAO.Insert",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...); err != nil {
+		d.log.Error(ctx, "scannerDeviceDAO.Insert",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return nil
+}
+
+func (d scannerDeviceDAO) Update(ctx context.Context, device entity.ScannerDevice) error {
+
+	now := time.Now()
+	device.UpdatedAt = &now
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLUpdate("scanner_devices").
+		SetSQLUpdateValue("name", device.Name).
+		SetSQLUpdateValue("event_id", device.EventID).
+		SetSQLUpdateValue("gate_name", device.GateName).
+		SetSQLUpdateValue("token_hash", device.TokenHash).
+		SetSQLUpdateValue("revoked_at", device.RevokedAt).
+		SetSQLUpdateValue("updated_at", device.UpdatedAt).
+		SetSQLWhere("AND", "id", "=", device.ID)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "scannerDeviceDAO.Update",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...); err != nil {
+		d.log.Error(ctx, "scannerDeviceDAO.Update",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return nil
+}
+
+// TouchLastSeen dipanggil di luar transaksi setiap kali perangkat terautentikasi
+func (d scannerDeviceDAO) TouchLastSeen(ctx context.Context, id pubEntity.UUID, seenAt time.Time) error {
+	query := `UPDATE scanner_devices SET last_seen_at = $1 WHERE id = $2`
+
+	d.log.Debug(ctx, "scannerDeviceDAO.TouchLastSeen", zap.String("ID", string(id)))
+
+	if _, err := d.dbTrx.GetSqlDB().ExecContext(ctx, query, seenAt, id); err != nil {
+		d.log.Error(ctx, "scannerDeviceDAO.TouchLastSeen", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
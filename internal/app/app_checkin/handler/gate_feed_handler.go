@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"rakit-tiket-be/internal/app/app_checkin/service"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+const feedHeartbeatInterval = 15 * time.Second
+
+// streamGateFeed mengirim feed gate live sebagai Server-Sent Events. Klien yang reconnect mengirim
+// header Last-Event-ID (atau ?cursor=) dan menerima scan yang terlewat dari gate_logs lebih dulu.
+func (h *gateHandler) streamGateFeed(c echo.Context) error {
+	eventID := c.Param("event_id")
+
+	if eventID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "event_id is required")
+	}
+
+	cursorParam := c.Request().Header.Get("Last-Event-ID")
+	if cursorParam == "" {
+		cursorParam = c.QueryParam("cursor")
+	}
+
+	var cursor int64
+	if cursorParam != "" {
+		parsed, err := strconv.ParseInt(cursorParam, 10, 64)
+		if err != nil || parsed < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "cursor must be a gate log seq")
+		}
+		cursor = parsed
+	}
+
+	ctx := c.Request().Context()
+
+	// Subscribe sebelum membaca catch-up / snapshot agar scan di antaranya tidak terlewat
+	sub := h.gateFeed.Subscribe(eventID)
+	defer sub.Close()
+
+	var backlog []service.GateFeedEvent
+	if cursor > 0 {
+		events, truncated, err := h.gateFeed.CatchUp(ctx, eventID, cursor)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		backlog = append(backlog, events...)
+		backlog = append(backlog, service.GateFeedEvent{
+			Type:    service.GateFeedEventCatchUp,
+			EventID: eventID,
+			At:      time.Now(),
+			CatchUp: &service.FeedCatchUp{Replayed: len(events), Truncated: truncated},
+		})
+	}
+
+	snapshot, err := h.gateFeed.Snapshot(ctx, eventID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	// Scan live yang sudah ikut catch-up dibuang berdasarkan ID; seq tidak dipakai karena scan
+	// yang commit terlambat bisa memiliki seq lebih kecil dari scan yang sudah dikirim
+	replayed := make(map[string]struct{})
+	for _, event := range backlog {
+		if err := writeFeedEvent(res, event); err != nil {
+			return nil
+		}
+		if event.Scan != nil {
+			replayed[event.Scan.ID] = struct{}{}
+		}
+	}
+
+	// Klien baru mulai dari scan terakhir saat ini; snapshot membawa cursor tersebut
+	if cursor > 0 {
+		snapshot.Cursor = 0
+	}
+	if err := writeFeedEvent(res, *snapshot); err != nil {
+		return nil
+	}
+
+	heartbeat := time.NewTicker(feedHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": ping\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		case event, ok := <-sub.Events:
+			if !ok {
+				// Feed ditutup (shutdown) atau klien terlalu lambat; klien reconnect dengan cursor terakhir
+				return nil
+			}
+			if event.Scan != nil {
+				if _, ok := replayed[event.Scan.ID]; ok {
+					delete(replayed, event.Scan.ID)
+					continue
+				}
+			}
+			if err := writeFeedEvent(res, event); err != nil {
+				h.log.Debug(ctx, "gateHandler.streamGateFeed write failed", zap.Error(err))
+				return nil
+			}
+		}
+	}
+}
+
+// writeFeedEvent menulis satu event SSE; hanya event yang membawa cursor yang diberi id
+func writeFeedEvent(res *echo.Response, event service.GateFeedEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if event.Cursor > 0 {
+		if _, err := fmt.Fprintf(res, "id: %d\n", event.Cursor); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+		return err
+	}
+
+	res.Flush()
+	return nil
+}
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"rakit-tiket-be/internal/app/app_checkin/service"
 	"rakit-tiket-be/internal/pkg/middleware"
@@ -10,35 +12,57 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+const maxGateLogsLimit = 500
+
 type GateHandler interface {
 	RegisterRouter(g *echo.Group)
 }
 
 type gateHandler struct {
-	log            util.LogUtil
-	gateService    service.GateService
-	scanService    service.ScanService
-	authMiddleware middleware.AuthMiddleware
+	log                   util.LogUtil
+	gateService           service.GateService
+	scanService           service.ScanService
+	syncService           service.SyncService
+	deviceService         service.DeviceService
+	physicalTicketService service.PhysicalTicketService
+	merchPickupService    service.MerchPickupService
+	gateFeed              service.GateFeed
+	authMiddleware        middleware.AuthMiddleware
 }
 
 func MakeGateHandler(
 	log util.LogUtil,
 	gateService service.GateService,
 	scanService service.ScanService,
+	syncService service.SyncService,
+	deviceService service.DeviceService,
+	physicalTicketService service.PhysicalTicketService,
+	merchPickupService service.MerchPickupService,
+	gateFeed service.GateFeed,
 	authMiddleware middleware.AuthMiddleware,
 ) GateHandler {
 	return &gateHandler{
-		log:            log,
-		gateService:    gateService,
-		scanService:    scanService,
-		authMiddleware: authMiddleware,
+		log:                   log,
+		gateService:           gateService,
+		scanService:           scanService,
+		syncService:           syncService,
+		deviceService:         deviceService,
+		physicalTicketService: physicalTicketService,
+		merchPickupService:    merchPickupService,
+		gateFeed:              gateFeed,
+		authMiddleware:        authMiddleware,
 	}
 }
 
 func (h *gateHandler) RegisterRouter(g *echo.Group) {
-	public := g.Group("/v1")
+	// Scan dan sinkronisasi gate: perangkat scanner terdaftar atau user ADMIN / GROUND STAFF
+	staff := g.Group("/v1/gate")
+	staff.Use(h.requireGateIdentity)
 
-	public.POST("/gate/scan", h.scanTicket)
+	staff.POST("/scan", h.scanTicket)
+	staff.GET("/manifest/:event_id", h.getManifest)
+	staff.POST("/sync", h.syncScans)
+	staff.POST("/merch/scan", h.scanMerchPickup)
 
 	admin := g.Group("/v1/admin")
 	admin.Use(h.authMiddleware.VerifyToken)
@@ -47,11 +71,32 @@ func (h *gateHandler) RegisterRouter(g *echo.Group) {
 	admin.POST("/gate/config", h.createGateConfig)
 	admin.GET("/gate/config/:event_id", h.getGateConfig)
 
+	admin.PUT("/gate/zones/:event_id", h.setZoneLayout)
+	admin.GET("/gate/zones/:event_id", h.getZoneLayout)
+
 	admin.POST("/gate/generate-qr", h.generatePhysicalTickets)
 	admin.GET("/gate/qr/:event_id", h.getPhysicalTickets)
+	admin.GET("/gate/batches", h.getPhysicalTicketBatches)
+	admin.GET("/gate/batches/:id/export", h.exportPhysicalTicketBatch)
+
+	admin.GET("/gate/tickets", h.searchPhysicalTickets)
+	admin.GET("/gate/tickets/:id", h.getPhysicalTicket)
+	admin.POST("/gate/tickets/void", h.voidPhysicalTickets)
+	admin.POST("/gate/tickets/:id/replace", h.replacePhysicalTicket)
+	admin.POST("/gate/tickets/:id/reassign", h.reassignPhysicalTicket)
+	admin.POST("/gate/tickets/:id/reset", h.resetPhysicalTicketScans)
 
 	admin.GET("/gate/stats/:event_id", h.getGateStats)
 	admin.GET("/gate/logs/:event_id", h.getGateLogs)
+	admin.GET("/gate/feed/:event_id", h.streamGateFeed)
+
+	// Endpoint scan lama dari dashboard admin, sekarang lewat pipeline scan yang sama
+	admin.POST("/tickets/scan", h.scanTicket, h.userGateIdentity)
+
+	admin.POST("/gate/devices", h.registerDevice)
+	admin.GET("/gate/devices", h.getDevices)
+	admin.POST("/gate/devices/:id/revoke", h.revokeDevice)
+	admin.POST("/gate/devices/:id/rotate-token", h.rotateDeviceToken)
 }
 
 func (h *gateHandler) createGateConfig(c echo.Context) error {
@@ -75,6 +120,9 @@ func (h *gateHandler) createGateConfig(c echo.Context) error {
 
 	data, err := h.gateService.CreateGateConfig(c.Request().Context(), req)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidGateConfig) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
@@ -102,6 +150,51 @@ func (h *gateHandler) getGateConfig(c echo.Context) error {
 	})
 }
 
+func (h *gateHandler) setZoneLayout(c echo.Context) error {
+	eventID := c.Param("event_id")
+
+	if eventID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "event_id is required")
+	}
+
+	var req service.SetZoneLayoutRequest
+
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.gateService.SetZoneLayout(c.Request().Context(), eventID, req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidZoneLayout) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+	})
+}
+
+func (h *gateHandler) getZoneLayout(c echo.Context) error {
+	eventID := c.Param("event_id")
+
+	if eventID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "event_id is required")
+	}
+
+	data, err := h.gateService.GetZoneLayout(c.Request().Context(), eventID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+	})
+}
+
 func (h *gateHandler) generatePhysicalTickets(c echo.Context) error {
 	var req service.GenerateTicketsRequest
 
@@ -113,8 +206,11 @@ func (h *gateHandler) generatePhysicalTickets(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "event_id is required")
 	}
 
-	data, err := h.gateService.GeneratePhysicalTickets(c.Request().Context(), req)
+	data, err := h.gateService.GeneratePhysicalTickets(c.Request().Context(), req, performedBy(c))
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidBatch) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
@@ -169,19 +265,56 @@ func (h *gateHandler) getGateStats(c echo.Context) error {
 
 func (h *gateHandler) scanTicket(c echo.Context) error {
 	var req struct {
-		QRCode   string `json:"qr_code"`
-		GateName string `json:"gate_name"`
+		QRCode      string `json:"qr_code"`
+		Code        string `json:"code"`         // klien lama /admin/tickets/scan
+		OrderNumber string `json:"order_number"` // QR lama berisi nomor order
 	}
 
 	if err := c.Bind(&req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
+	if req.QRCode == "" {
+		req.QRCode = req.Code
+	}
+	if req.QRCode == "" {
+		req.QRCode = req.OrderNumber
+	}
+
 	if req.QRCode == "" {
 		return echo.NewHTTPError(http.StatusBadRequest, "qr_code is required")
 	}
 
-	data, err := h.scanService.ScanTicket(c.Request().Context(), req.QRCode, req.GateName, "")
+	data, err := h.scanService.ScanTicket(c.Request().Context(), req.QRCode, gateIdentityFrom(c))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if !data.Success {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"data":    data,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+	})
+}
+
+func (h *gateHandler) scanMerchPickup(c echo.Context) error {
+	var req service.MerchPickupRequest
+
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if req.QRCode == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "qr_code is required")
+	}
+
+	data, err := h.merchPickupService.ScanPickup(c.Request().Context(), req, gateIdentityFrom(c))
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
@@ -207,7 +340,115 @@ func (h *gateHandler) getGateLogs(c echo.Context) error {
 	}
 
 	limit := 100
-	data, err := h.scanService.GetGateLogs(c.Request().Context(), eventID, limit)
+	if v, err := strconv.Atoi(c.QueryParam("limit")); err == nil && v > 0 {
+		limit = v
+	}
+	if limit > maxGateLogsLimit {
+		limit = maxGateLogsLimit
+	}
+
+	var before int64
+	if v := c.QueryParam("before"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || parsed < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "before must be a gate log seq")
+		}
+		before = parsed
+	}
+
+	data, err := h.scanService.GetGateLogs(c.Request().Context(), eventID, limit, before)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	// next_before kosong berarti sudah halaman terakhir
+	var nextBefore *int64
+	if len(data) == limit {
+		nextBefore = &data[len(data)-1].Seq
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success":     true,
+		"data":        data,
+		"count":       len(data),
+		"next_before": nextBefore,
+	})
+}
+
+func (h *gateHandler) getManifest(c echo.Context) error {
+	eventID := c.Param("event_id")
+
+	if eventID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "event_id is required")
+	}
+
+	data, err := h.syncService.GetManifest(c.Request().Context(), eventID, gateIdentityFrom(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrEventNotAssigned):
+			return echo.NewHTTPError(http.StatusForbidden, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+	})
+}
+
+func (h *gateHandler) syncScans(c echo.Context) error {
+	var req service.SyncScansRequest
+
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.syncService.SyncScans(c.Request().Context(), req, gateIdentityFrom(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrEventNotAssigned):
+			return echo.NewHTTPError(http.StatusForbidden, err.Error())
+		case errors.Is(err, service.ErrInvalidSyncRequest), errors.Is(err, service.ErrSyncBatchTooLarge):
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+	})
+}
+
+func (h *gateHandler) registerDevice(c echo.Context) error {
+	var req service.RegisterDeviceRequest
+
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	createdBy, _ := c.Get("user_id").(string)
+
+	data, err := h.deviceService.RegisterDevice(c.Request().Context(), req, createdBy)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidDevice), errors.Is(err, service.ErrGateNotRegistered):
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrDeviceAlreadyExists):
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+	})
+}
+
+func (h *gateHandler) getDevices(c echo.Context) error {
+	data, err := h.deviceService.GetDevices(c.Request().Context(), c.QueryParam("event_id"))
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
@@ -218,3 +459,33 @@ func (h *gateHandler) getGateLogs(c echo.Context) error {
 		"count":   len(data),
 	})
 }
+
+func (h *gateHandler) revokeDevice(c echo.Context) error {
+	data, err := h.deviceService.RevokeDevice(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrDeviceNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+	})
+}
+
+func (h *gateHandler) rotateDeviceToken(c echo.Context) error {
+	data, err := h.deviceService.RotateToken(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrDeviceNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+	})
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"rakit-tiket-be/internal/app/app_checkin/service"
+	authEntity "rakit-tiket-be/pkg/entity/app_auth"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	deviceTokenHeader  = "X-Device-Token"
+	gateIdentityCtxKey = "gate_identity"
+)
+
+// requireGateIdentity mengizinkan perangkat scanner terdaftar (header X-Device-Token)
+// atau user ADMIN / GROUND STAFF (Bearer token). Identitas yang lolos disimpan di context
+// sehingga scanned_by dan gate_name tidak pernah diambil dari input client.
+func (h *gateHandler) requireGateIdentity(next echo.HandlerFunc) echo.HandlerFunc {
+	userChain := h.authMiddleware.VerifyToken(
+		h.authMiddleware.RequireRoles(string(authEntity.RoleAdmin), string(authEntity.RoleGroundStaff))(
+			h.userGateIdentity(next),
+		),
+	)
+
+	return func(c echo.Context) error {
+		token := c.Request().Header.Get(deviceTokenHeader)
+		if token == "" {
+			return userChain(c)
+		}
+
+		device, err := h.deviceService.AuthenticateDevice(c.Request().Context(), token)
+		if err != nil {
+			if errors.Is(err, service.ErrDeviceUnauthorized) {
+				return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+
+		c.Set(gateIdentityCtxKey, service.MakeDeviceGateIdentity(*device))
+		return next(c)
+	}
+}
+
+// userGateIdentity menyimpan identitas user yang sudah lolos VerifyToken sebagai identitas gate
+func (h *gateHandler) userGateIdentity(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, _ := c.Get("user_id").(string)
+		c.Set(gateIdentityCtxKey, service.MakeUserGateIdentity(userID))
+		return next(c)
+	}
+}
+
+func gateIdentityFrom(c echo.Context) service.GateIdentity {
+	identity, _ := c.Get(gateIdentityCtxKey).(service.GateIdentity)
+	return identity
+}
@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"rakit-tiket-be/internal/app/app_checkin/service"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+func (h *gateHandler) searchPhysicalTickets(c echo.Context) error {
+	var req service.SearchPhysicalTicketsRequest
+
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if req.EventID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "event_id is required")
+	}
+
+	data, err := h.physicalTicketService.SearchTickets(c.Request().Context(), req)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data.Tickets,
+		"total":   data.Total,
+		"page":    data.Page,
+		"limit":   data.Limit,
+	})
+}
+
+func (h *gateHandler) getPhysicalTicket(c echo.Context) error {
+	data, err := h.physicalTicketService.GetTicket(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return physicalTicketError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+	})
+}
+
+func (h *gateHandler) voidPhysicalTickets(c echo.Context) error {
+	var req service.VoidTicketsRequest
+
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.physicalTicketService.VoidTickets(c.Request().Context(), req, performedBy(c))
+	if err != nil {
+		return physicalTicketError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+	})
+}
+
+func (h *gateHandler) replacePhysicalTicket(c echo.Context) error {
+	var req service.TicketChangeRequest
+
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.physicalTicketService.ReplaceTicket(c.Request().Context(), c.Param("id"), req, performedBy(c))
+	if err != nil {
+		return physicalTicketError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+	})
+}
+
+func (h *gateHandler) reassignPhysicalTicket(c echo.Context) error {
+	var req service.TicketChangeRequest
+
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if req.TicketType == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "ticket_type is required")
+	}
+
+	data, err := h.physicalTicketService.ReassignTicket(c.Request().Context(), c.Param("id"), req, performedBy(c))
+	if err != nil {
+		return physicalTicketError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+	})
+}
+
+func (h *gateHandler) resetPhysicalTicketScans(c echo.Context) error {
+	var req service.TicketChangeRequest
+
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.physicalTicketService.ResetScans(c.Request().Context(), c.Param("id"), req, performedBy(c))
+	if err != nil {
+		return physicalTicketError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// performedBy memakai format yang sama dengan gate_logs.scanned_by
+func performedBy(c echo.Context) string {
+	userID, _ := c.Get("user_id").(string)
+	return service.MakeUserGateIdentity(userID).ScannedBy
+}
+
+func physicalTicketError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrPhysicalTicketNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidVoidRequest), errors.Is(err, service.ErrInvalidTicketType):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrPhysicalTicketVoided), errors.Is(err, service.ErrPhysicalTicketReplaced):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
+
+func (h *gateHandler) getPhysicalTicketBatches(c echo.Context) error {
+	eventID := c.QueryParam("event_id")
+
+	if eventID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "event_id is required")
+	}
+
+	data, err := h.physicalTicketService.GetBatches(c.Request().Context(), eventID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+		"count":   len(data),
+	})
+}
+
+// exportPhysicalTicketBatch mengirim file cetak batch: PDF lembar multi-up, CSV serial, atau ZIP PNG QR.
+// CSV dan ZIP ditulis langsung ke response.
+func (h *gateHandler) exportPhysicalTicketBatch(c echo.Context) error {
+	format := service.ExportFormat(strings.ToLower(c.QueryParam("format")))
+	if format == "" {
+		format = service.ExportFormatPDF
+	}
+	if !format.Valid() {
+		return echo.NewHTTPError(http.StatusBadRequest, service.ErrInvalidExportFormat.Error())
+	}
+
+	ctx := c.Request().Context()
+
+	export, err := h.physicalTicketService.PrepareBatchExport(ctx, c.Param("id"), c.QueryParam("ticket_type"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrBatchNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case errors.Is(err, service.ErrInvalidBatch):
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", export.FileName(format)))
+
+	if format == service.ExportFormatPDF {
+		pdf, err := export.RenderPDF()
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		return c.Blob(http.StatusOK, format.ContentType(), pdf)
+	}
+
+	res.Header().Set(echo.HeaderContentType, format.ContentType())
+	res.WriteHeader(http.StatusOK)
+
+	write := export.WriteCSV
+	if format == service.ExportFormatZIP {
+		write = export.WriteZIP
+	}
+	if err := write(res); err != nil {
+		// Header sudah terkirim; klien menerima file terpotong
+		h.log.Error(ctx, "gateHandler.exportPhysicalTicketBatch", zap.Error(err))
+	}
+
+	return nil
+}
@@ -0,0 +1,417 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"rakit-tiket-be/internal/app/app_checkin/dao"
+	"rakit-tiket-be/internal/pkg/qrsign"
+	"rakit-tiket-be/internal/pkg/ticketcode"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	app_gate_config "rakit-tiket-be/pkg/entity/app_gate_config"
+	app_gate_log "rakit-tiket-be/pkg/entity/app_gate_log"
+	orderEntity "rakit-tiket-be/pkg/entity/app_order"
+	app_physical_ticket "rakit-tiket-be/pkg/entity/app_physical_ticket"
+	"rakit-tiket-be/pkg/util"
+)
+
+type CredentialType string
+
+const (
+	CredentialPhysicalTicket CredentialType = "PHYSICAL_TICKET"
+	CredentialETicket        CredentialType = "E_TICKET"
+)
+
+// Prefix kode e-ticket yang diketik manual; kode ini acak 120 bit sehingga tetap diterima
+// walaupun kode tanpa tanda tangan lainnya (QR lama, nomor order) sudah tidak diizinkan.
+const eTicketCodePrefix = "ET"
+
+// gateCredential adalah sesuatu yang bisa di-scan di gate: tiket fisik atau e-ticket order.
+// Aturan gate_configs dijalankan terhadap field umum di sini, lalu disimpan ke tabel asalnya.
+type gateCredential struct {
+	Type       CredentialType
+	ID         pubEntity.UUID
+	EventID    pubEntity.UUID
+	TicketType string
+	Status     app_physical_ticket.PhysicalTicketStatus
+	ScanCount  int
+
+	initialScanCount int
+	physical         *app_physical_ticket.PhysicalTicket
+	eTicket          *orderEntity.ETicket
+}
+
+func newPhysicalCredential(ticket *app_physical_ticket.PhysicalTicket) *gateCredential {
+	return &gateCredential{
+		Type:             CredentialPhysicalTicket,
+		ID:               ticket.ID,
+		EventID:          ticket.EventID,
+		TicketType:       ticket.TicketType,
+		Status:           ticket.Status,
+		ScanCount:        ticket.ScanCount,
+		initialScanCount: ticket.ScanCount,
+		physical:         ticket,
+	}
+}
+
+func newETicketCredential(eTicket *orderEntity.ETicket) *gateCredential {
+	return &gateCredential{
+		Type:             CredentialETicket,
+		ID:               eTicket.ID,
+		EventID:          eTicket.EventID,
+		TicketType:       eTicket.TicketType,
+		Status:           app_physical_ticket.PhysicalTicketStatus(eTicket.Status),
+		ScanCount:        eTicket.ScanCount,
+		initialScanCount: eTicket.ScanCount,
+		eTicket:          eTicket,
+	}
+}
+
+// rejectReason berisi alasan kredensial ditolak terlepas dari batas scan
+func (c *gateCredential) rejectReason() string {
+	if c.Status == app_physical_ticket.PhysicalTicketStatusVoid {
+		return "Tiket sudah dibatalkan"
+	}
+	if c.eTicket != nil && c.eTicket.PaymentStatus != "paid" {
+		return "Pembayaran belum lunas"
+	}
+	return ""
+}
+
+// recordAdmission mencatat satu scan yang lolos; ScanCount diatur oleh pemanggil
+func (c *gateCredential) recordAdmission(action app_gate_log.GateLogAction, status app_physical_ticket.PhysicalTicketStatus, at time.Time) {
+	c.Status = status
+
+	switch {
+	case c.physical != nil && action == app_gate_log.GateLogActionCheckOut:
+		c.physical.CheckedOutAt = &at
+	case c.physical != nil:
+		c.physical.CheckedInAt = &at
+	case action == app_gate_log.GateLogActionCheckOut:
+		c.eTicket.CheckedOutAt = &at
+	default:
+		c.eTicket.CheckedInAt = &at
+	}
+}
+
+// lastAdmission adalah waktu scan terakhir yang lolos (check-in atau check-out)
+func (c *gateCredential) lastAdmission() time.Time {
+	var checkedIn, checkedOut *time.Time
+	if c.physical != nil {
+		checkedIn, checkedOut = c.physical.CheckedInAt, c.physical.CheckedOutAt
+	} else {
+		checkedIn, checkedOut = c.eTicket.CheckedInAt, c.eTicket.CheckedOutAt
+	}
+
+	var last time.Time
+	if checkedIn != nil {
+		last = *checkedIn
+	}
+	if checkedOut != nil && checkedOut.After(last) {
+		last = *checkedOut
+	}
+	return last
+}
+
+// countsLog menentukan apakah gate log ikut dihitung; log sebelum reset scan tiket fisik diabaikan
+func (c *gateCredential) countsLog(l app_gate_log.GateLog) bool {
+	if c.physical == nil || c.physical.ScansResetAt == nil {
+		return true
+	}
+	return !scannedAt(l).Before(*c.physical.ScansResetAt)
+}
+
+// attach mengisi referensi tiket di gate_logs
+func (c *gateCredential) attach(logEntry *app_gate_log.GateLog) {
+	eventID := c.EventID
+	id := c.ID
+
+	logEntry.EventID = &eventID
+	logEntry.TicketType = c.TicketType
+	if c.physical != nil {
+		logEntry.PhysicalTicketID = &id
+	} else {
+		logEntry.ETicketID = &id
+	}
+}
+
+func (c *gateCredential) save(ctx context.Context, dbTrx dao.DBTransaction) error {
+	if c.physical != nil {
+		c.physical.Status = c.Status
+		c.physical.ScanCount = c.ScanCount
+		return dbTrx.GetPhysicalTicketDAO().Update(ctx, app_physical_ticket.PhysicalTickets{*c.physical})
+	}
+
+	c.eTicket.Status = orderEntity.ETicketStatus(c.Status)
+	c.eTicket.ScanCount = c.ScanCount
+	c.eTicket.CheckedIn = c.ScanCount > 0
+	if err := dbTrx.GetETicketDAO().UpdateScanState(ctx, *c.eTicket); err != nil {
+		return err
+	}
+
+	// Status check-in registrant mengikuti kursi milik registrant sendiri
+	if c.eTicket.AttendeeID == nil && c.initialScanCount == 0 && c.ScanCount > 0 && c.eTicket.CheckedInAt != nil {
+		return dbTrx.GetRegistrantCheckInDAO().MarkCheckedIn(ctx, c.eTicket.RegistrantID, *c.eTicket.CheckedInAt)
+	}
+	return nil
+}
+
+func (c *gateCredential) result() ScanResult {
+	result := ScanResult{
+		CredentialType: string(c.Type),
+		TicketType:     c.TicketType,
+		ScanCount:      c.ScanCount,
+	}
+	if c.eTicket != nil {
+		result.TicketCode = c.eTicket.Code
+		result.HolderName = c.eTicket.HolderName
+		result.SeatNumber = c.eTicket.SeatNumber
+		result.OrderNumber = c.eTicket.OrderNumber
+	}
+	return result
+}
+
+// credentialRef adalah hasil verifikasi QR sebelum lookup ke DB
+type credentialRef struct {
+	// QR bertanda tangan
+	Signed  bool
+	Kind    qrsign.Kind
+	ID      pubEntity.UUID
+	EventID pubEntity.UUID
+	Seat    int
+
+	// Kode tanpa tanda tangan: kode e-ticket, QR tiket fisik lama atau nomor order
+	Code string
+
+	// Serial tiket fisik yang diketik staf (bentuk kanonik)
+	Serial string
+}
+
+// verifyCredential memverifikasi tanda tangan QR tanpa menyentuh DB. Jika kode ditolak,
+// yang dikembalikan adalah event yang diklaim (bila ada) dan alasan penolakan untuk gate_logs.
+func verifyCredential(signer qrsign.Signer, code string) (*credentialRef, *pubEntity.UUID, string) {
+	payload, err := signer.Verify(code)
+	switch {
+	case err == nil && payload.Kind == qrsign.KindMerchPickup:
+		return nil, &payload.EventID, "QR pengambilan add-on tidak berlaku sebagai tiket masuk"
+	case err == nil:
+		return &credentialRef{
+			Signed:  true,
+			Kind:    payload.Kind,
+			ID:      payload.TicketID,
+			EventID: payload.EventID,
+			Seat:    payload.Seat,
+		}, nil, ""
+	case errors.Is(err, qrsign.ErrUnsignedCode):
+		// Serial acak 50 bit dengan check digit tetap diterima walaupun kode tanpa tanda tangan tidak diizinkan
+		serial, serialErr := ticketcode.Parse(code)
+		switch {
+		case serialErr == nil:
+			return &credentialRef{Serial: serial}, nil, ""
+		case errors.Is(serialErr, ticketcode.ErrCheckDigit):
+			return nil, nil, "Kode tiket salah ketik, periksa kembali"
+		case signer.AllowUnsigned() || isETicketCode(code):
+			return &credentialRef{Code: code}, nil, ""
+		}
+	}
+
+	// Event yang diklaim kode palsu tetap dicatat agar percobaan pemalsuan terlihat per event
+	var eventID *pubEntity.UUID
+	if claimed := qrsign.ClaimedPayload(code); claimed != nil {
+		eventID = &claimed.EventID
+	}
+	return nil, eventID, "QR code tidak valid"
+}
+
+func isETicketCode(code string) bool {
+	return strings.HasPrefix(code, eTicketCodePrefix) && len(code) > len(eTicketCodePrefix)
+}
+
+// credentialLookup adalah hasil lookup satu ref; reason terisi jika cred nil
+type credentialLookup struct {
+	cred   *gateCredential
+	reason string
+}
+
+// lockCredentials mencari dan mengunci (FOR UPDATE) kredensial untuk setiap ref, dengan urutan
+// hasil sama dengan refs. Ref yang menunjuk tiket yang sama mendapat pointer yang sama.
+func lockCredentials(ctx context.Context, dbTrx dao.DBTransaction, refs []credentialRef, allowUnsigned bool) ([]credentialLookup, error) {
+	var physicalIDs, eTicketIDs, serials, codes, legacyCodes []string
+	for _, ref := range refs {
+		switch {
+		case ref.Signed && ref.Kind == qrsign.KindPhysicalTicket:
+			physicalIDs = append(physicalIDs, string(ref.ID))
+		case ref.Signed:
+			eTicketIDs = append(eTicketIDs, string(ref.ID))
+		case ref.Serial != "":
+			serials = append(serials, ref.Serial)
+		default:
+			codes = append(codes, ref.Code)
+			if allowUnsigned {
+				legacyCodes = append(legacyCodes, ref.Code)
+			}
+		}
+	}
+
+	credByID := make(map[pubEntity.UUID]*gateCredential)
+	addPhysical := func(tickets app_physical_ticket.PhysicalTickets) map[string]*gateCredential {
+		byCode := make(map[string]*gateCredential)
+		for i := range tickets {
+			cred, ok := credByID[tickets[i].ID]
+			if !ok {
+				cred = newPhysicalCredential(&tickets[i])
+				credByID[cred.ID] = cred
+			}
+			byCode[tickets[i].QRCode] = cred
+		}
+		return byCode
+	}
+	addETickets := func(eTickets orderEntity.ETickets) []*gateCredential {
+		creds := make([]*gateCredential, 0, len(eTickets))
+		for i := range eTickets {
+			cred, ok := credByID[eTickets[i].ID]
+			if !ok {
+				cred = newETicketCredential(&eTickets[i])
+				credByID[cred.ID] = cred
+			}
+			creds = append(creds, cred)
+		}
+		return creds
+	}
+
+	if len(physicalIDs) > 0 {
+		tickets, err := dbTrx.GetPhysicalTicketDAO().SearchForUpdate(ctx, app_physical_ticket.PhysicalTicketQuery{IDs: physicalIDs})
+		if err != nil {
+			return nil, err
+		}
+		addPhysical(tickets)
+	}
+
+	if len(eTicketIDs) > 0 {
+		eTickets, err := dbTrx.GetETicketDAO().SearchForUpdate(ctx, orderEntity.ETicketQuery{IDs: eTicketIDs})
+		if err != nil {
+			return nil, err
+		}
+		addETickets(eTickets)
+	}
+
+	// Serial unik per event; serial yang sama di event lain diperlakukan seperti nomor order multi-kursi
+	physicalBySerial := make(map[string][]*gateCredential)
+	if len(serials) > 0 {
+		tickets, err := dbTrx.GetPhysicalTicketDAO().SearchForUpdate(ctx, app_physical_ticket.PhysicalTicketQuery{Serials: serials})
+		if err != nil {
+			return nil, err
+		}
+		addPhysical(tickets)
+		for _, t := range tickets {
+			serial := derefString(t.Serial)
+			physicalBySerial[serial] = append(physicalBySerial[serial], credByID[t.ID])
+		}
+	}
+
+	eTicketByCode := make(map[string]*gateCredential)
+	physicalByCode := make(map[string]*gateCredential)
+	seatsByOrderNumber := make(map[string][]*gateCredential)
+
+	if len(codes) > 0 {
+		eTickets, err := dbTrx.GetETicketDAO().SearchForUpdate(ctx, orderEntity.ETicketQuery{Codes: codes})
+		if err != nil {
+			return nil, err
+		}
+		for _, cred := range addETickets(eTickets) {
+			eTicketByCode[cred.eTicket.Code] = cred
+		}
+	}
+
+	if len(legacyCodes) > 0 {
+		tickets, err := dbTrx.GetPhysicalTicketDAO().SearchForUpdate(ctx, app_physical_ticket.PhysicalTicketQuery{QRCodes: legacyCodes})
+		if err != nil {
+			return nil, err
+		}
+		physicalByCode = addPhysical(tickets)
+
+		// QR lama e-ticket berisi nomor order
+		eTickets, err := dbTrx.GetETicketDAO().SearchForUpdate(ctx, orderEntity.ETicketQuery{OrderNumbers: legacyCodes})
+		if err != nil {
+			return nil, err
+		}
+		for _, cred := range addETickets(eTickets) {
+			seatsByOrderNumber[cred.eTicket.OrderNumber] = append(seatsByOrderNumber[cred.eTicket.OrderNumber], cred)
+		}
+	}
+
+	lookups := make([]credentialLookup, len(refs))
+	for i, ref := range refs {
+		if ref.Signed {
+			cred, ok := credByID[ref.ID]
+			switch {
+			case !ok || cred.EventID != ref.EventID:
+				lookups[i].reason = "Tiket tidak ditemukan"
+			case cred.eTicket != nil && cred.eTicket.SeatNumber != ref.Seat:
+				lookups[i].reason = "QR code tidak valid"
+			default:
+				lookups[i].cred = cred
+			}
+			continue
+		}
+
+		if ref.Serial != "" {
+			switch matches := physicalBySerial[ref.Serial]; {
+			case len(matches) == 1:
+				lookups[i].cred = matches[0]
+			case len(matches) > 1:
+				lookups[i].reason = "Kode tiket terdaftar di lebih dari satu event, scan QR tiket"
+			default:
+				lookups[i].reason = "Tiket tidak ditemukan"
+			}
+			continue
+		}
+
+		if cred, ok := eTicketByCode[ref.Code]; ok {
+			lookups[i].cred = cred
+			continue
+		}
+		if cred, ok := physicalByCode[ref.Code]; ok {
+			lookups[i].cred = cred
+			continue
+		}
+
+		switch seats := seatsByOrderNumber[ref.Code]; {
+		case len(seats) == 1:
+			lookups[i].cred = seats[0]
+		case len(seats) > 1:
+			lookups[i].reason = "Order berisi lebih dari satu tiket, scan QR pada masing-masing tiket"
+		default:
+			lookups[i].reason = "Tiket tidak ditemukan"
+		}
+	}
+
+	return lookups, nil
+}
+
+// loadGateConfig mengembalikan gate_configs event; event tanpa konfigurasi memakai
+// CHECK_IN satu kali scan, sama dengan perilaku check-in e-ticket sebelum ada gate config.
+func loadGateConfig(ctx context.Context, dbTrx dao.DBTransaction, eventID string) (*app_gate_config.GateConfig, error) {
+	config, err := dbTrx.GetGateConfigDAO().GetByEventID(ctx, eventID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &app_gate_config.GateConfig{
+			EventID:          pubEntity.UUID(eventID),
+			Mode:             app_gate_config.GateModeCheckIn,
+			MaxScanPerTicket: 1,
+			Timezone:         util.GetTimeZone().String(),
+			IsActive:         true,
+		}, nil
+	}
+	return config, err
+}
+
+// truncateCode memotong kode sepanjang kolom gate_logs.qr_code; kode palsu bisa berukuran sembarang
+func truncateCode(code string) *string {
+	if len(code) > 255 {
+		code = code[:255]
+	}
+	return &code
+}
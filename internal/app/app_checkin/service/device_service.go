@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"rakit-tiket-be/internal/app/app_checkin/dao"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	app_scanner_device "rakit-tiket-be/pkg/entity/app_scanner_device"
+	"rakit-tiket-be/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+const (
+	deviceTokenPrefix = "sd_"
+	// deviceTokenBytes menghasilkan 256 bit entropi untuk token perangkat
+	deviceTokenBytes = 32
+)
+
+var (
+	ErrDeviceNotFound      = errors.New("perangkat scanner tidak ditemukan")
+	ErrDeviceAlreadyExists = errors.New("device_id sudah terdaftar")
+	ErrDeviceUnauthorized  = errors.New("token perangkat tidak valid atau sudah dicabut")
+	ErrInvalidDevice       = errors.New("device_id, name, event_id dan gate_name wajib diisi")
+	ErrEventNotAssigned    = errors.New("perangkat tidak ditugaskan untuk event ini")
+)
+
+// GateIdentity adalah identitas terautentikasi yang melakukan scan di gate,
+// baik user GROUND STAFF/ADMIN maupun perangkat scanner terdaftar.
+type GateIdentity struct {
+	// ScannedBy disimpan di gate_logs.scanned_by: "user:<user id>" atau "device:<device id>"
+	ScannedBy string
+	GateName  string
+	// EventID terisi untuk perangkat; perangkat hanya boleh scan event yang ditugaskan
+	EventID  *pubEntity.UUID
+	DeviceID string
+}
+
+func MakeUserGateIdentity(userID string) GateIdentity {
+	return GateIdentity{ScannedBy: "user:" + userID}
+}
+
+func MakeDeviceGateIdentity(device app_scanner_device.ScannerDevice) GateIdentity {
+	eventID := device.EventID
+	return GateIdentity{
+		ScannedBy: "device:" + device.DeviceID,
+		GateName:  device.GateName,
+		EventID:   &eventID,
+		DeviceID:  device.DeviceID,
+	}
+}
+
+// CanAccessEvent bernilai true jika identitas boleh melakukan scan untuk event tersebut
+func (i GateIdentity) CanAccessEvent(eventID pubEntity.UUID) bool {
+	return i.EventID == nil || *i.EventID == eventID
+}
+
+func (i GateIdentity) deviceIDPtr() *string {
+	if i.DeviceID == "" {
+		return nil
+	}
+	deviceID := i.DeviceID
+	return &deviceID
+}
+
+type DeviceService interface {
+	RegisterDevice(ctx context.Context, req RegisterDeviceRequest, createdBy string) (*RegisteredDevice, error)
+	GetDevices(ctx context.Context, eventID string) (app_scanner_device.ScannerDevices, error)
+	RevokeDevice(ctx context.Context, id string) (*app_scanner_device.ScannerDevice, error)
+	RotateToken(ctx context.Context, id string) (*RegisteredDevice, error)
+	AuthenticateDevice(ctx context.Context, token string) (*app_scanner_device.ScannerDevice, error)
+}
+
+type RegisterDeviceRequest struct {
+	DeviceID string `json:"device_id"`
+	Name     string `json:"name"`
+	EventID  string `json:"event_id"`
+	GateName string `json:"gate_name"`
+}
+
+// RegisteredDevice berisi token plaintext yang hanya ditampilkan sekali saat registrasi / rotasi
+type RegisteredDevice struct {
+	Device app_scanner_device.ScannerDevice `json:"device"`
+	Token  string                           `json:"token"`
+}
+
+type deviceService struct {
+	log   util.LogUtil
+	sqlDB *sql.DB
+}
+
+func MakeDeviceService(log util.LogUtil, sqlDB *sql.DB) DeviceService {
+	return &deviceService{
+		log:   log,
+		sqlDB: sqlDB,
+	}
+}
+
+func (s *deviceService) RegisterDevice(ctx context.Context, req RegisterDeviceRequest, createdBy string) (*RegisteredDevice, error) {
+	req.DeviceID = strings.TrimSpace(req.DeviceID)
+	req.GateName = strings.TrimSpace(req.GateName)
+	if req.DeviceID == "" || req.Name == "" || req.EventID == "" || req.GateName == "" {
+		return nil, ErrInvalidDevice
+	}
+
+	dbTrx := dao.NewTransactionGate(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	existing, err := dbTrx.GetScannerDeviceDAO().Search(ctx, app_scanner_device.ScannerDeviceQuery{
+		DeviceIDs:      []string{req.DeviceID},
+		IncludeRevoked: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, ErrDeviceAlreadyExists
+	}
+
+	// Event dengan layout zona hanya menerima gate yang terdaftar
+	zones, err := loadZoneAccess(ctx, dbTrx, req.EventID)
+	if err != nil {
+		return nil, err
+	}
+	if !zones.hasGate(req.GateName) {
+		return nil, ErrGateNotRegistered
+	}
+
+	token, tokenHash, err := makeDeviceToken()
+	if err != nil {
+		return nil, err
+	}
+
+	device := app_scanner_device.ScannerDevice{
+		ID:        pubEntity.MakeUUID("SCANNER_DEVICE", req.DeviceID),
+		DeviceID:  req.DeviceID,
+		Name:      req.Name,
+		EventID:   pubEntity.UUID(req.EventID),
+		GateName:  req.GateName,
+		TokenHash: tokenHash,
+	}
+	if createdBy != "" {
+		creator := pubEntity.UUID(createdBy)
+		device.CreatedBy = &creator
+	}
+
+	if err := dbTrx.GetScannerDeviceDAO().Insert(ctx, device); err != nil {
+		return nil, fmt.Errorf("gagal mendaftarkan perangkat: %v", err)
+	}
+
+	if err := dbTrx.GetSqlTx().Commit(); err != nil {
+		return nil, err
+	}
+
+	s.log.Info(ctx, "scanner device registered",
+		zap.String("device_id", device.DeviceID),
+		zap.String("event_id", string(device.EventID)),
+		zap.String("gate_name", device.GateName),
+	)
+
+	device.CreatedAt = time.Now()
+
+	return &RegisteredDevice{Device: device, Token: token}, nil
+}
+
+func (s *deviceService) GetDevices(ctx context.Context, eventID string) (app_scanner_device.ScannerDevices, error) {
+	dbTrx := dao.NewTransactionGate(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	query := app_scanner_device.ScannerDeviceQuery{IncludeRevoked: true}
+	if eventID != "" {
+		query.EventIDs = []string{eventID}
+	}
+
+	return dbTrx.GetScannerDeviceDAO().Search(ctx, query)
+}
+
+func (s *deviceService) RevokeDevice(ctx context.Context, id string) (*app_scanner_device.ScannerDevice, error) {
+	dbTrx := dao.NewTransactionGate(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	device, err := s.findDevice(ctx, dbTrx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if device.RevokedAt == nil {
+		now := time.Now()
+		device.RevokedAt = &now
+
+		if err := dbTrx.GetScannerDeviceDAO().Update(ctx, *device); err != nil {
+			return nil, fmt.Errorf("gagal mencabut perangkat: %v", err)
+		}
+
+		if err := dbTrx.GetSqlTx().Commit(); err != nil {
+			return nil, err
+		}
+
+		s.log.Info(ctx, "scanner device revoked", zap.String("device_id", device.DeviceID))
+	}
+
+	return device, nil
+}
+
+// RotateToken menerbitkan token baru dan membatalkan token lama. Perangkat yang sudah
+// dicabut diaktifkan kembali dengan token baru ini.
+func (s *deviceService) RotateToken(ctx context.Context, id string) (*RegisteredDevice, error) {
+	dbTrx := dao.NewTransactionGate(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	device, err := s.findDevice(ctx, dbTrx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	token, tokenHash, err := makeDeviceToken()
+	if err != nil {
+		return nil, err
+	}
+
+	device.TokenHash = tokenHash
+	device.RevokedAt = nil
+
+	if err := dbTrx.GetScannerDeviceDAO().Update(ctx, *device); err != nil {
+		return nil, fmt.Errorf("gagal memperbarui token perangkat: %v", err)
+	}
+
+	if err := dbTrx.GetSqlTx().Commit(); err != nil {
+		return nil, err
+	}
+
+	s.log.Info(ctx, "scanner device token rotated", zap.String("device_id", device.DeviceID))
+
+	return &RegisteredDevice{Device: *device, Token: token}, nil
+}
+
+func (s *deviceService) AuthenticateDevice(ctx context.Context, token string) (*app_scanner_device.ScannerDevice, error) {
+	if !strings.HasPrefix(token, deviceTokenPrefix) {
+		return nil, ErrDeviceUnauthorized
+	}
+
+	dbTrx := dao.NewTransactionGate(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	devices, err := dbTrx.GetScannerDeviceDAO().Search(ctx, app_scanner_device.ScannerDeviceQuery{
+		TokenHashes: []string{util.MakeSHA256(token)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(devices) == 0 {
+		return nil, ErrDeviceUnauthorized
+	}
+
+	device := devices[0]
+
+	// last_seen_at hanya informasi monitoring, kegagalan update tidak menggagalkan autentikasi
+	if err := dbTrx.GetScannerDeviceDAO().TouchLastSeen(ctx, device.ID, time.Now()); err != nil {
+		s.log.Warn(ctx, "deviceService.AuthenticateDevice touch last seen failed", zap.Error(err))
+	}
+
+	return &device, nil
+}
+
+func (s *deviceService) findDevice(ctx context.Context, dbTrx dao.DBTransaction, id string) (*app_scanner_device.ScannerDevice, error) {
+	devices, err := dbTrx.GetScannerDeviceDAO().Search(ctx, app_scanner_device.ScannerDeviceQuery{
+		IDs:            []string{id},
+		IncludeRevoked: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(devices) == 0 {
+		return nil, ErrDeviceNotFound
+	}
+	return &devices[0], nil
+}
+
+// makeDeviceToken mengembalikan token plaintext beserta hash sha256 yang disimpan di DB
+func makeDeviceToken() (string, string, error) {
+	code, err := util.MakeRandomCode(deviceTokenBytes)
+	if err != nil {
+		return "", "", fmt.Errorf("gagal membuat token perangkat: %v", err)
+	}
+	token := deviceTokenPrefix + code
+	return token, util.MakeSHA256(token), nil
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"rakit-tiket-be/internal/app/app_checkin/dao"
+	app_gate_config "rakit-tiket-be/pkg/entity/app_gate_config"
+	app_gate_log "rakit-tiket-be/pkg/entity/app_gate_log"
+	"rakit-tiket-be/pkg/util"
+)
+
+const (
+	entryDateLayout = "2006-01-02"
+	entryTimeLayout = "15:04"
+)
+
+var ErrInvalidGateConfig = errors.New("konfigurasi gate tidak valid")
+
+// EntryRuleConfig adalah aturan waktu masuk di gate config dan manifest perangkat offline
+type EntryRuleConfig struct {
+	Timezone               string                                   `json:"timezone"`
+	EntryWindows           map[string][]app_gate_config.EntryWindow `json:"entry_windows"`
+	DailyResetTime         *string                                  `json:"daily_reset_time"`
+	ReentryCooldownSeconds int                                      `json:"reentry_cooldown_seconds"`
+}
+
+func makeEntryRuleConfig(config *app_gate_config.GateConfig) EntryRuleConfig {
+	return EntryRuleConfig{
+		Timezone:               config.Timezone,
+		EntryWindows:           config.EntryWindows,
+		DailyResetTime:         config.DailyResetTime,
+		ReentryCooldownSeconds: config.ReentryCooldownSeconds,
+	}
+}
+
+// entryRules adalah aturan waktu masuk gate_configs yang sudah di-parse dalam zona waktu event
+type entryRules struct {
+	config   *app_gate_config.GateConfig
+	location *time.Location
+	daily    bool
+	resetAt  time.Time // hanya jam dan menit yang dipakai
+	cooldown time.Duration
+}
+
+// newEntryRules tidak pernah gagal; nilai yang rusak di DB diperlakukan seperti tidak diatur
+func newEntryRules(config *app_gate_config.GateConfig) *entryRules {
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil || config.Timezone == "" {
+		location = util.GetTimeZone()
+	}
+
+	rules := &entryRules{
+		config:   config,
+		location: location,
+		cooldown: time.Duration(config.ReentryCooldownSeconds) * time.Second,
+	}
+
+	if config.DailyResetTime != nil {
+		if resetAt, err := time.Parse(entryTimeLayout, *config.DailyResetTime); err == nil {
+			rules.daily = true
+			rules.resetAt = resetAt
+		}
+	}
+
+	return rules
+}
+
+// validateEntryRules dipanggil saat gate_configs disimpan
+func validateEntryRules(config *app_gate_config.GateConfig) error {
+	if _, err := time.LoadLocation(config.Timezone); err != nil {
+		return fmt.Errorf("%w: timezone %s tidak dikenal", ErrInvalidGateConfig, config.Timezone)
+	}
+	if config.ReentryCooldownSeconds < 0 {
+		return fmt.Errorf("%w: reentry_cooldown_seconds tidak boleh negatif", ErrInvalidGateConfig)
+	}
+	if config.DailyResetTime != nil {
+		if _, err := time.Parse(entryTimeLayout, *config.DailyResetTime); err != nil {
+			return fmt.Errorf("%w: daily_reset_time harus berformat HH:MM", ErrInvalidGateConfig)
+		}
+	}
+
+	rules := newEntryRules(config)
+	for ticketType, windows := range config.EntryWindows {
+		for _, window := range windows {
+			if _, _, ok := rules.windowRange(window); !ok {
+				return fmt.Errorf("%w: entry window %s harus berisi date YYYY-MM-DD, start dan end HH:MM", ErrInvalidGateConfig, ticketType)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *entryRules) windowRange(window app_gate_config.EntryWindow) (time.Time, time.Time, bool) {
+	start, err := time.ParseInLocation(entryDateLayout+" "+entryTimeLayout, window.Date+" "+window.Start, r.location)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	end, err := time.ParseInLocation(entryDateLayout+" "+entryTimeLayout, window.Date+" "+window.End, r.location)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return start, end, true
+}
+
+// windowReason berisi alasan penolakan jika ticket type punya jadwal masuk dan at di luar semua jadwalnya
+func (r *entryRules) windowReason(ticketType string, at time.Time) string {
+	windows := r.config.EntryWindows[ticketType]
+	if len(windows) == 0 {
+		return ""
+	}
+
+	var next *time.Time
+	for _, window := range windows {
+		start, end, ok := r.windowRange(window)
+		if !ok {
+			continue
+		}
+		if !at.Before(start) && at.Before(end) {
+			return ""
+		}
+		if at.Before(start) && (next == nil || start.Before(*next)) {
+			next = &start
+		}
+	}
+
+	if next == nil {
+		return fmt.Sprintf("Jadwal masuk tiket %s sudah berakhir", ticketType)
+	}
+	return fmt.Sprintf("Di luar jadwal masuk tiket %s, jadwal berikutnya %s", ticketType, next.Format(entryDateLayout+" "+entryTimeLayout))
+}
+
+// dayStart adalah awal hari event (jam reset terakhir) yang memuat at
+func (r *entryRules) dayStart(at time.Time) time.Time {
+	local := at.In(r.location)
+	year, month, day := local.Date()
+
+	start := time.Date(year, month, day, r.resetAt.Hour(), r.resetAt.Minute(), 0, 0, r.location)
+	if local.Before(start) {
+		start = start.AddDate(0, 0, -1)
+	}
+	return start
+}
+
+// ticketAdmissions menghitung scan yang lolos untuk satu tiket. total adalah scan_count kumulatif
+// tiket; jika batas scan harian aktif, batas dibandingkan dengan scan pada hari event berjalan.
+type ticketAdmissions struct {
+	rules    *entryRules
+	total    int
+	last     time.Time
+	dayStart time.Time
+	today    int
+}
+
+// record harus dipanggil berurutan menurut waktu scan
+func (a *ticketAdmissions) record(at time.Time) {
+	a.total++
+	if at.After(a.last) {
+		a.last = at
+	}
+
+	if !a.rules.daily {
+		return
+	}
+	if start := a.rules.dayStart(at); !start.Equal(a.dayStart) {
+		a.dayStart = start
+		a.today = 0
+	}
+	a.today++
+}
+
+// count adalah jumlah scan yang dibandingkan dengan batas scan untuk scan pada waktu at
+func (a *ticketAdmissions) count(at time.Time) int {
+	if !a.rules.daily {
+		return a.total
+	}
+	if !a.rules.dayStart(at).Equal(a.dayStart) {
+		return 0
+	}
+	return a.today
+}
+
+// exceededMessage dipakai saat count sudah mencapai batas scan
+func (a *ticketAdmissions) exceededMessage(maxScan int) string {
+	if a.rules.daily {
+		return fmt.Sprintf("Melebihi batas scan hari ini (%d/%d)", a.today, maxScan)
+	}
+	return fmt.Sprintf("Melebihi batas scan (%d/%d)", a.total, maxScan)
+}
+
+// cooldownReason berisi alasan penolakan jika tiket masuk kembali sebelum jeda re-entry berakhir
+func (a *ticketAdmissions) cooldownReason(at time.Time) string {
+	if a.rules.cooldown <= 0 || a.last.IsZero() {
+		return ""
+	}
+
+	wait := a.last.Add(a.rules.cooldown).Sub(at)
+	if wait <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("Masuk kembali bisa dilakukan %d detik lagi", int((wait+time.Second-1)/time.Second))
+}
+
+// loadAdmissions menyiapkan hitungan scan tiket untuk scan online. Scan terakhir diambil dari
+// waktu check-in / check-out tiket; riwayat gate_logs hanya dibaca jika batas scan harian aktif.
+func loadAdmissions(ctx context.Context, dbTrx dao.DBTransaction, rules *entryRules, cred *gateCredential, at time.Time) (*ticketAdmissions, error) {
+	admissions := &ticketAdmissions{
+		rules: rules,
+		total: cred.ScanCount,
+		last:  cred.lastAdmission(),
+	}
+	if !rules.daily {
+		return admissions, nil
+	}
+
+	query := app_gate_log.GateLogQuery{
+		Actions: []string{
+			string(app_gate_log.GateLogActionCheckIn),
+			string(app_gate_log.GateLogActionCheckOut),
+		},
+	}
+	if cred.Type == CredentialPhysicalTicket {
+		query.PhysicalTicketIDs = []string{string(cred.ID)}
+	} else {
+		query.ETicketIDs = []string{string(cred.ID)}
+	}
+
+	logs, err := dbTrx.GetGateLogDAO().Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	admissions.dayStart = rules.dayStart(at)
+	for _, l := range logs {
+		if l.Success && cred.countsLog(l) && !scannedAt(l).Before(admissions.dayStart) {
+			admissions.today++
+		}
+	}
+
+	return admissions, nil
+}
@@ -0,0 +1,480 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"rakit-tiket-be/internal/app/app_checkin/dao"
+	app_gate_log "rakit-tiket-be/pkg/entity/app_gate_log"
+	"rakit-tiket-be/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// feedBufferSize: subscriber yang tertinggal lebih dari ini diputus dan harus reconnect dengan cursor
+	feedBufferSize = 256
+	// feedCatchUpLimit membatasi jumlah scan yang diputar ulang saat reconnect
+	feedCatchUpLimit = 1000
+	// feedCatchUpOverlap: seq dialokasikan saat insert, bukan saat commit, sehingga scan dengan seq lebih kecil
+	// bisa tersimpan setelah cursor klien. Catch-up mundur sejauh ini dan klien membuang duplikat lewat scan.id
+	feedCatchUpOverlap = 200
+	feedRateWindow     = time.Minute
+)
+
+type GateFeedEventType string
+
+const (
+	GateFeedEventScan    GateFeedEventType = "scan"
+	GateFeedEventStats   GateFeedEventType = "stats"
+	GateFeedEventRate    GateFeedEventType = "rate"
+	GateFeedEventAlert   GateFeedEventType = "alert"
+	GateFeedEventCatchUp GateFeedEventType = "catchup"
+)
+
+type ScanRateAlertKind string
+
+const (
+	ScanRateAlertHighScanRate   ScanRateAlertKind = "HIGH_SCAN_RATE"
+	ScanRateAlertHighRejectRate ScanRateAlertKind = "HIGH_REJECT_RATE"
+)
+
+// GateFeedEvent adalah satu pesan di feed gate live. Cursor terisi untuk event scan (gate_logs.seq)
+// dan snapshot stats awal; klien mengirim cursor terakhir saat reconnect agar tidak ada scan yang terlewat.
+// Catch-up dapat mengirim ulang scan yang sudah diterima, sehingga klien perlu de-duplikasi berdasarkan scan.id.
+type GateFeedEvent struct {
+	Type    GateFeedEventType `json:"type"`
+	EventID string            `json:"event_id"`
+	Cursor  int64             `json:"cursor,omitempty"`
+	At      time.Time         `json:"at"`
+
+	Scan    *GateLogInfo   `json:"scan,omitempty"`
+	Stats   *GateStats     `json:"stats,omitempty"`
+	Rate    *ScanRate      `json:"rate,omitempty"`
+	Alert   *ScanRateAlert `json:"alert,omitempty"`
+	CatchUp *FeedCatchUp   `json:"catchup,omitempty"`
+}
+
+// ScanRate adalah jumlah scan dalam satu menit terakhir
+type ScanRate struct {
+	PerMinute int            `json:"per_minute"`
+	Rejected  int            `json:"rejected"`
+	ByGate    map[string]int `json:"by_gate"`
+}
+
+type ScanRateAlert struct {
+	Kind      ScanRateAlertKind `json:"kind"`
+	GateName  string            `json:"gate_name,omitempty"`
+	PerMinute int               `json:"per_minute"`
+	Threshold int               `json:"threshold"`
+	Message   string            `json:"message"`
+}
+
+type FeedCatchUp struct {
+	Replayed int `json:"replayed"`
+	// Truncated berarti masih ada scan setelah cursor; klien perlu mengambil sisanya lewat /gate/logs
+	Truncated bool `json:"truncated"`
+}
+
+type GateFeedConfig struct {
+	StatsInterval time.Duration
+	// Ambang alert per menit; 0 mematikan alert tersebut
+	ScanRateAlert   int
+	RejectRateAlert int
+}
+
+// GateFeed menyebarkan hasil scan yang dipublikasikan scanService / syncService ke banyak klien admin.
+// Feed hanya melihat scan dari instance ini; catch-up tetap membaca gate_logs sehingga tidak ada scan hilang.
+type GateFeed interface {
+	Publish(logs ...app_gate_log.GateLog)
+	Subscribe(eventID string) *GateFeedSubscription
+	CatchUp(ctx context.Context, eventID string, cursor int64) ([]GateFeedEvent, bool, error)
+	Snapshot(ctx context.Context, eventID string) (*GateFeedEvent, error)
+	Close(ctx context.Context) error
+}
+
+type GateFeedSubscription struct {
+	Events <-chan GateFeedEvent
+	close  func()
+}
+
+func (s *GateFeedSubscription) Close() {
+	s.close()
+}
+
+type recentScan struct {
+	at       time.Time
+	gateName string
+	success  bool
+}
+
+// feedState adalah state feed satu event; loop stats hanya berjalan selama ada subscriber
+type feedState struct {
+	subscribers map[int]chan GateFeedEvent
+	recent      []recentScan
+	dirty       bool
+	alerting    map[string]bool
+	stop        chan struct{}
+}
+
+type gateFeed struct {
+	log         util.LogUtil
+	sqlDB       *sql.DB
+	gateService GateService
+	cfg         GateFeedConfig
+
+	mu     sync.Mutex
+	events map[string]*feedState
+	nextID int
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func MakeGateFeed(log util.LogUtil, sqlDB *sql.DB, gateService GateService, cfg GateFeedConfig) GateFeed {
+	if cfg.StatsInterval <= 0 {
+		cfg.StatsInterval = 5 * time.Second
+	}
+
+	return &gateFeed{
+		log:         log,
+		sqlDB:       sqlDB,
+		gateService: gateService,
+		cfg:         cfg,
+		events:      make(map[string]*feedState),
+	}
+}
+
+// Publish dipanggil setelah transaksi scan commit; log tanpa seq (gagal disimpan) diabaikan
+func (f *gateFeed) Publish(logs ...app_gate_log.GateLog) {
+	now := time.Now()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return
+	}
+
+	for _, l := range logs {
+		if l.Seq == 0 || l.EventID == nil {
+			continue
+		}
+
+		eventID := string(*l.EventID)
+		state := f.stateFor(eventID)
+
+		state.recent = append(state.recent, recentScan{at: now, gateName: l.GateName, success: l.Success})
+		state.recent = pruneRecent(state.recent, now)
+		state.dirty = true
+
+		info := makeGateLogInfo(l)
+		f.broadcast(state, GateFeedEvent{
+			Type:    GateFeedEventScan,
+			EventID: eventID,
+			Cursor:  l.Seq,
+			At:      now,
+			Scan:    &info,
+		})
+	}
+}
+
+func (f *gateFeed) Subscribe(eventID string) *GateFeedSubscription {
+	ch := make(chan GateFeedEvent, feedBufferSize)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		close(ch)
+		return &GateFeedSubscription{Events: ch, close: func() {}}
+	}
+
+	state := f.stateFor(eventID)
+	f.nextID++
+	id := f.nextID
+	state.subscribers[id] = ch
+
+	if state.stop == nil {
+		state.stop = make(chan struct{})
+		f.wg.Add(1)
+		go f.run(eventID, state, state.stop)
+	}
+
+	return &GateFeedSubscription{
+		Events: ch,
+		close: func() {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			f.unsubscribe(state, id)
+		},
+	}
+}
+
+// CatchUp membaca scan setelah cursor dari gate_logs, maksimal feedCatchUpLimit. Pembacaan dimulai
+// feedCatchUpOverlap seq sebelum cursor agar scan yang commit terlambat ikut terkirim; sebagian scan
+// yang diputar ulang mungkin sudah diterima klien.
+func (f *gateFeed) CatchUp(ctx context.Context, eventID string, cursor int64) ([]GateFeedEvent, bool, error) {
+	dbTrx := dao.NewTransactionGate(ctx, f.log, f.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	after := cursor - feedCatchUpOverlap
+	if after < 0 {
+		after = 0
+	}
+
+	logs, err := dbTrx.GetGateLogDAO().Search(ctx, app_gate_log.GateLogQuery{
+		EventIDs: []string{eventID},
+		AfterSeq: after,
+		Limit:    feedCatchUpLimit + 1,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	truncated := len(logs) > feedCatchUpLimit
+	if truncated {
+		logs = logs[:feedCatchUpLimit]
+	}
+
+	events := make([]GateFeedEvent, 0, len(logs))
+	for _, l := range logs {
+		info := makeGateLogInfo(l)
+		events = append(events, GateFeedEvent{
+			Type:    GateFeedEventScan,
+			EventID: eventID,
+			Cursor:  l.Seq,
+			At:      l.CreatedAt,
+			Scan:    &info,
+		})
+	}
+
+	return events, truncated, nil
+}
+
+// Snapshot berisi statistik saat ini dan cursor scan terakhir event
+func (f *gateFeed) Snapshot(ctx context.Context, eventID string) (*GateFeedEvent, error) {
+	dbTrx := dao.NewTransactionGate(ctx, f.log, f.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	// Cursor dibaca sebelum stats sehingga stats sudah mencakup semua scan sampai cursor
+	latest, err := dbTrx.GetGateLogDAO().Search(ctx, app_gate_log.GateLogQuery{
+		EventIDs: []string{eventID},
+		Limit:    1,
+		Latest:   true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := f.gateService.GetGateStats(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &GateFeedEvent{
+		Type:    GateFeedEventStats,
+		EventID: eventID,
+		At:      time.Now(),
+		Stats:   stats,
+	}
+	if len(latest) > 0 {
+		event.Cursor = latest[0].Seq
+	}
+
+	return event, nil
+}
+
+// Close memutus semua subscriber agar koneksi stream selesai sebelum HTTP server dimatikan
+func (f *gateFeed) Close(ctx context.Context) error {
+	f.mu.Lock()
+	f.closed = true
+	for _, state := range f.events {
+		for id := range state.subscribers {
+			f.unsubscribe(state, id)
+		}
+	}
+	f.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		f.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("gate feed belum berhenti: %w", ctx.Err())
+	}
+}
+
+// run mengirim stats (jika ada scan baru), scan rate dan alert secara berkala untuk satu event
+func (f *gateFeed) run(eventID string, state *feedState, stop chan struct{}) {
+	defer f.wg.Done()
+
+	ticker := time.NewTicker(f.cfg.StatsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			f.tick(eventID, state)
+		}
+	}
+}
+
+func (f *gateFeed) tick(eventID string, state *feedState) {
+	now := time.Now()
+
+	f.mu.Lock()
+	dirty := state.dirty
+	state.dirty = false
+	state.recent = pruneRecent(state.recent, now)
+	rate := makeScanRate(state.recent)
+	alerts := f.checkAlerts(state, rate)
+
+	f.broadcast(state, GateFeedEvent{Type: GateFeedEventRate, EventID: eventID, At: now, Rate: &rate})
+	for i := range alerts {
+		f.broadcast(state, GateFeedEvent{Type: GateFeedEventAlert, EventID: eventID, At: now, Alert: &alerts[i]})
+	}
+	f.mu.Unlock()
+
+	if !dirty {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), f.cfg.StatsInterval)
+	defer cancel()
+
+	stats, err := f.gateService.GetGateStats(ctx, eventID)
+	if err != nil {
+		f.log.Warn(ctx, "gateFeed.tick stats failed", zap.String("event_id", eventID), zap.Error(err))
+		f.mu.Lock()
+		state.dirty = true
+		f.mu.Unlock()
+		return
+	}
+
+	f.mu.Lock()
+	f.broadcast(state, GateFeedEvent{Type: GateFeedEventStats, EventID: eventID, At: time.Now(), Stats: stats})
+	f.mu.Unlock()
+}
+
+// checkAlerts hanya mengirim alert saat rate melewati ambang; alert aktif lagi setelah rate turun di bawah ambang
+func (f *gateFeed) checkAlerts(state *feedState, rate ScanRate) []ScanRateAlert {
+	var alerts []ScanRateAlert
+
+	if f.cfg.ScanRateAlert > 0 {
+		for gateName, perMinute := range rate.ByGate {
+			key := "gate:" + gateName
+			if perMinute < f.cfg.ScanRateAlert {
+				delete(state.alerting, key)
+				continue
+			}
+			if state.alerting[key] {
+				continue
+			}
+			state.alerting[key] = true
+			alerts = append(alerts, ScanRateAlert{
+				Kind:      ScanRateAlertHighScanRate,
+				GateName:  gateName,
+				PerMinute: perMinute,
+				Threshold: f.cfg.ScanRateAlert,
+				Message:   fmt.Sprintf("Gate %s: %d scan/menit melewati ambang %d", gateName, perMinute, f.cfg.ScanRateAlert),
+			})
+		}
+		for key := range state.alerting {
+			if gateName, ok := strings.CutPrefix(key, "gate:"); ok && rate.ByGate[gateName] == 0 {
+				delete(state.alerting, key)
+			}
+		}
+	}
+
+	if f.cfg.RejectRateAlert > 0 {
+		key := "reject"
+		if rate.Rejected < f.cfg.RejectRateAlert {
+			delete(state.alerting, key)
+		} else if !state.alerting[key] {
+			state.alerting[key] = true
+			alerts = append(alerts, ScanRateAlert{
+				Kind:      ScanRateAlertHighRejectRate,
+				PerMinute: rate.Rejected,
+				Threshold: f.cfg.RejectRateAlert,
+				Message:   fmt.Sprintf("%d scan ditolak dalam 1 menit terakhir, melewati ambang %d", rate.Rejected, f.cfg.RejectRateAlert),
+			})
+		}
+	}
+
+	return alerts
+}
+
+// stateFor dipanggil dengan f.mu terkunci
+func (f *gateFeed) stateFor(eventID string) *feedState {
+	state, ok := f.events[eventID]
+	if !ok {
+		state = &feedState{
+			subscribers: make(map[int]chan GateFeedEvent),
+			alerting:    make(map[string]bool),
+		}
+		f.events[eventID] = state
+	}
+	return state
+}
+
+// broadcast dipanggil dengan f.mu terkunci. Subscriber yang buffer-nya penuh diputus
+// supaya satu klien lambat tidak menahan scan di gate.
+func (f *gateFeed) broadcast(state *feedState, event GateFeedEvent) {
+	for id, ch := range state.subscribers {
+		select {
+		case ch <- event:
+		default:
+			f.log.Warn(context.Background(), "gateFeed slow subscriber dropped", zap.String("event_id", event.EventID))
+			f.unsubscribe(state, id)
+		}
+	}
+}
+
+// unsubscribe dipanggil dengan f.mu terkunci
+func (f *gateFeed) unsubscribe(state *feedState, id int) {
+	ch, ok := state.subscribers[id]
+	if !ok {
+		return
+	}
+	delete(state.subscribers, id)
+	close(ch)
+
+	if len(state.subscribers) == 0 && state.stop != nil {
+		close(state.stop)
+		state.stop = nil
+	}
+}
+
+func pruneRecent(recent []recentScan, now time.Time) []recentScan {
+	cutoff := now.Add(-feedRateWindow)
+	i := 0
+	for i < len(recent) && recent[i].at.Before(cutoff) {
+		i++
+	}
+	return recent[i:]
+}
+
+func makeScanRate(recent []recentScan) ScanRate {
+	rate := ScanRate{ByGate: make(map[string]int)}
+	for _, r := range recent {
+		rate.PerMinute++
+		if !r.success {
+			rate.Rejected++
+		}
+		if r.gateName != "" {
+			rate.ByGate[r.gateName]++
+		}
+	}
+	return rate
+}
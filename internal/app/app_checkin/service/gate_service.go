@@ -4,13 +4,17 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"rakit-tiket-be/internal/app/app_checkin/dao"
 	ticketDao "rakit-tiket-be/internal/app/app_ticket/dao"
+	"rakit-tiket-be/internal/pkg/qrsign"
 	pubEntity "rakit-tiket-be/pkg/entity"
 	app_gate_config "rakit-tiket-be/pkg/entity/app_gate_config"
+	app_gate_log "rakit-tiket-be/pkg/entity/app_gate_log"
+	orderEntity "rakit-tiket-be/pkg/entity/app_order"
 	app_physical_ticket "rakit-tiket-be/pkg/entity/app_physical_ticket"
 	ticketEntity "rakit-tiket-be/pkg/entity/app_ticket"
 	"rakit-tiket-be/pkg/util"
@@ -19,14 +23,18 @@ import (
 )
 
 type GateService interface {
-	GeneratePhysicalTickets(ctx context.Context, req GenerateTicketsRequest) (map[string]GenerateResult, error)
+	GeneratePhysicalTickets(ctx context.Context, req GenerateTicketsRequest, createdBy string) (map[string]GenerateResult, error)
 	GetPhysicalTickets(ctx context.Context, eventID string, ticketTypes []string) ([]PhysicalTicketInfo, error)
 	GetGateConfig(ctx context.Context, eventID string) (*GateConfigInfo, error)
 	CreateGateConfig(ctx context.Context, req CreateGateConfigRequest) (*GateConfigInfo, error)
 	GetGateStats(ctx context.Context, eventID string) (*GateStats, error)
+	SetZoneLayout(ctx context.Context, eventID string, req SetZoneLayoutRequest) (*ZoneLayout, error)
+	GetZoneLayout(ctx context.Context, eventID string) (*ZoneLayout, error)
 }
 
+// GenerateResult: start_code dan end_code adalah serial tiket pertama dan terakhir (batch_seq) ticket type di batch
 type GenerateResult struct {
+	BatchID   string `json:"batch_id"`
 	Generated int    `json:"generated"`
 	StartCode string `json:"start_code"`
 	EndCode   string `json:"end_code"`
@@ -36,12 +44,15 @@ type GenerateTicketsRequest struct {
 	EventID     string         `json:"event_id"`
 	TicketTypes []string       `json:"ticket_types"`
 	Qty         map[string]int `json:"qty"`
+	Vendor      string         `json:"vendor"`
+	PrintDate   string         `json:"print_date"` // YYYY-MM-DD, opsional
 }
 
 type PhysicalTicketInfo struct {
 	ID          string `json:"id"`
 	QRCode      string `json:"qr_code"`
 	QRCodeImage string `json:"qr_code_image"`
+	Serial      string `json:"serial,omitempty"`
 	TicketType  string `json:"ticket_type"`
 	TicketID    string `json:"ticket_id"`
 	Status      string `json:"status"`
@@ -55,6 +66,7 @@ type GateConfigInfo struct {
 	MaxScanPerTicket int            `json:"max_scan_per_ticket"`
 	MaxScanByType    map[string]int `json:"max_scan_by_type"`
 	IsActive         bool           `json:"is_active"`
+	EntryRuleConfig
 }
 
 type CreateGateConfigRequest struct {
@@ -63,14 +75,31 @@ type CreateGateConfigRequest struct {
 	MaxScanPerTicket int            `json:"max_scan_per_ticket"`
 	MaxScanByType    map[string]int `json:"max_scan_by_type"`
 	IsActive         bool           `json:"is_active"`
+	EntryRuleConfig
 }
 
+// GateStats menggabungkan tiket fisik dan e-ticket order (yang sudah lunas) untuk satu event
 type GateStats struct {
+	TotalTickets         int                  `json:"total_tickets"`
 	TotalPhysicalTickets int                  `json:"total_physical_tickets"`
+	TotalETickets        int                  `json:"total_e_tickets"`
 	CheckedIn            int                  `json:"checked_in"`
 	CheckedOut           int                  `json:"checked_out"`
 	ActiveNow            int                  `json:"active_now"`
 	ByType               map[string]TypeStats `json:"by_type"`
+	BySource             map[string]TypeStats `json:"by_source"`     // PHYSICAL_TICKET / E_TICKET
+	ScanAttempts         map[string]int       `json:"scan_attempts"` // jumlah gate_logs per action
+	ByGate               map[string]TypeStats `json:"by_gate"`       // total = percobaan scan di gate, active_now = tiket yang masuk terakhir lewat gate ini
+	ByZone               map[string]ZoneStats `json:"by_zone"`       // per kode zona, kosong jika event tanpa zona
+}
+
+// ZoneStats: occupancy adalah jumlah tiket yang scan sukses terakhirnya CHECK_IN di gate zona tersebut
+type ZoneStats struct {
+	Name      string `json:"name"`
+	Capacity  *int   `json:"capacity"`
+	Occupancy int    `json:"occupancy"`
+	Entries   int    `json:"entries"`
+	Denied    int    `json:"denied"`
 }
 
 type TypeStats struct {
@@ -81,51 +110,90 @@ type TypeStats struct {
 }
 
 type gateService struct {
-	log   util.LogUtil
-	sqlDB *sql.DB
+	log      util.LogUtil
+	sqlDB    *sql.DB
+	qrSigner qrsign.Signer
 }
 
-func MakeGateService(log util.LogUtil, sqlDB *sql.DB) GateService {
+func MakeGateService(log util.LogUtil, sqlDB *sql.DB, qrSigner qrsign.Signer) GateService {
 	return &gateService{
-		log:   log,
-		sqlDB: sqlDB,
+		log:      log,
+		sqlDB:    sqlDB,
+		qrSigner: qrSigner,
 	}
 }
 
-func (s *gateService) GeneratePhysicalTickets(ctx context.Context, req GenerateTicketsRequest) (map[string]GenerateResult, error) {
+// GeneratePhysicalTickets membuat satu batch cetak untuk semua ticket type di request
+func (s *gateService) GeneratePhysicalTickets(ctx context.Context, req GenerateTicketsRequest, createdBy string) (map[string]GenerateResult, error) {
 	dbTrx := dao.NewTransactionGate(ctx, s.log, s.sqlDB)
 	defer dbTrx.GetSqlTx().Rollback()
 
 	eventID := req.EventID
 
+	var printDate *time.Time
+	if req.PrintDate != "" {
+		parsed, err := time.ParseInLocation(entryDateLayout, req.PrintDate, util.GetTimeZone())
+		if err != nil {
+			return nil, fmt.Errorf("%w: print_date harus berformat YYYY-MM-DD", ErrInvalidBatch)
+		}
+		printDate = &parsed
+	}
+
 	ticketMasterMap, err := s.getTicketMasters(ctx, eventID, req.TicketTypes)
 	if err != nil {
 		return nil, err
 	}
 
+	ticketCounts := make(map[string]int)
+	for ticketType := range ticketMasterMap {
+		if qty := req.Qty[ticketType]; qty > 0 {
+			ticketCounts[ticketType] = qty
+		}
+	}
+
+	if len(ticketCounts) == 0 {
+		return nil, fmt.Errorf("tidak ada ticket untuk digenerate")
+	}
+
+	batch := app_physical_ticket.PhysicalTicketBatch{
+		ID:           pubEntity.MakeUUID("PHYSICAL_TICKET_BATCH", eventID, time.Now().String()),
+		EventID:      pubEntity.UUID(eventID),
+		Vendor:       strings.TrimSpace(req.Vendor),
+		PrintDate:    printDate,
+		TicketCounts: ticketCounts,
+		CreatedBy:    createdBy,
+	}
+	if err := dbTrx.GetPhysicalTicketBatchDAO().Insert(ctx, batch); err != nil {
+		return nil, fmt.Errorf("gagal menyimpan batch: %v", err)
+	}
+
 	result := make(map[string]GenerateResult)
 
-	for ticketType, ticketMaster := range ticketMasterMap {
-		requestedQty := 0
-		if req.Qty != nil {
-			if qty, ok := req.Qty[ticketType]; ok {
-				requestedQty = qty
-			}
-		}
+	for ticketType, requestedQty := range ticketCounts {
+		ticketMaster := ticketMasterMap[ticketType]
 
-		if requestedQty <= 0 {
-			continue
+		serials, err := allocateSerials(ctx, dbTrx, eventID, requestedQty)
+		if err != nil {
+			return nil, err
 		}
 
-		seq := 0
+		for seq := 1; seq <= requestedQty; seq++ {
+			serial := serials[seq-1]
+			batchSeq := seq
 
-		for i := 0; i < requestedQty; i++ {
-			qrCode := s.generateQRCode(ticketType, seq)
-			seq++
+			pt := pubEntity.MakeUUID(serial, time.Now().String())
 
-			pt := pubEntity.MakeUUID(qrCode, time.Now().String())
+			// Isi QR ditandatangani agar gate bisa menolak kode palsu tanpa query ke DB
+			qrCode, err := s.qrSigner.Sign(qrsign.Payload{
+				Kind:     qrsign.KindPhysicalTicket,
+				EventID:  pubEntity.UUID(eventID),
+				TicketID: pt,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("gagal menandatangani qr code: %v", err)
+			}
 
-			err := dbTrx.GetPhysicalTicketDAO().Insert(ctx, app_physical_ticket.PhysicalTickets{
+			err = dbTrx.GetPhysicalTicketDAO().Insert(ctx, app_physical_ticket.PhysicalTickets{
 				{
 					ID:         pt,
 					EventID:    pubEntity.UUID(eventID),
@@ -133,6 +201,9 @@ func (s *gateService) GeneratePhysicalTickets(ctx context.Context, req GenerateT
 					TicketID:   ticketMaster.ID,
 					QRCode:     qrCode,
 					QRCodeHash: util.MakeUUID(qrCode),
+					BatchID:    &batch.ID,
+					BatchSeq:   &batchSeq,
+					Serial:     &serial,
 				},
 			})
 			if err != nil {
@@ -142,27 +213,24 @@ func (s *gateService) GeneratePhysicalTickets(ctx context.Context, req GenerateT
 		}
 
 		result[ticketType] = GenerateResult{
+			BatchID:   string(batch.ID),
 			Generated: requestedQty,
-			StartCode: s.generateQRCode(ticketType, 0),
-			EndCode:   s.generateQRCode(ticketType, requestedQty-1),
+			StartCode: serials[0],
+			EndCode:   serials[requestedQty-1],
 		}
 	}
 
-	if len(result) == 0 {
-		return nil, fmt.Errorf("tidak ada ticket untuk digenerate")
-	}
-
 	if err := dbTrx.GetSqlTx().Commit(); err != nil {
 		return nil, err
 	}
 
-	return result, nil
-}
+	s.log.Info(ctx, "physical ticket batch generated",
+		zap.String("event_id", eventID),
+		zap.String("batch_id", string(batch.ID)),
+		zap.String("vendor", batch.Vendor),
+	)
 
-func (s *gateService) generateQRCode(ticketType string, sequence int) string {
-	year := time.Now().Year()
-	prefix := fmt.Sprintf("TKT%d-%s", year, strings.ToUpper(ticketType))
-	return fmt.Sprintf("%s-%d%03d", prefix, time.Now().UnixNano(), sequence+1)
+	return result, nil
 }
 
 func (s *gateService) getTicketMasters(ctx context.Context, eventID string, ticketTypes []string) (map[string]ticketEntity.Ticket, error) {
@@ -221,6 +289,7 @@ func (s *gateService) GetPhysicalTickets(ctx context.Context, eventID string, ti
 			ID:          string(t.ID),
 			QRCode:      t.QRCode,
 			QRCodeImage: qrCodeImage,
+			Serial:      derefString(t.Serial),
 			TicketType:  t.TicketType,
 			TicketID:    string(t.TicketID),
 			Status:      string(t.Status),
@@ -239,14 +308,7 @@ func (s *gateService) GetGateConfig(ctx context.Context, eventID string) (*GateC
 		return nil, err
 	}
 
-	return &GateConfigInfo{
-		ID:               string(config.ID),
-		EventID:          string(config.EventID),
-		Mode:             string(config.Mode),
-		MaxScanPerTicket: config.MaxScanPerTicket,
-		MaxScanByType:    config.MaxScanByType,
-		IsActive:         config.IsActive,
-	}, nil
+	return makeGateConfigInfo(config), nil
 }
 
 func (s *gateService) CreateGateConfig(ctx context.Context, req CreateGateConfigRequest) (*GateConfigInfo, error) {
@@ -254,12 +316,23 @@ func (s *gateService) CreateGateConfig(ctx context.Context, req CreateGateConfig
 	defer dbTrx.GetSqlTx().Rollback()
 
 	config := app_gate_config.GateConfig{
-		ID:               pubEntity.MakeUUID(req.EventID, time.Now().String()),
-		EventID:          pubEntity.UUID(req.EventID),
-		Mode:             app_gate_config.GateMode(req.Mode),
-		MaxScanPerTicket: req.MaxScanPerTicket,
-		MaxScanByType:    req.MaxScanByType,
-		IsActive:         req.IsActive,
+		ID:                     pubEntity.MakeUUID(req.EventID, time.Now().String()),
+		EventID:                pubEntity.UUID(req.EventID),
+		Mode:                   app_gate_config.GateMode(req.Mode),
+		MaxScanPerTicket:       req.MaxScanPerTicket,
+		MaxScanByType:          req.MaxScanByType,
+		Timezone:               req.Timezone,
+		EntryWindows:           req.EntryWindows,
+		DailyResetTime:         req.DailyResetTime,
+		ReentryCooldownSeconds: req.ReentryCooldownSeconds,
+		IsActive:               req.IsActive,
+	}
+	if config.Timezone == "" {
+		config.Timezone = util.GetTimeZone().String()
+	}
+
+	if err := validateEntryRules(&config); err != nil {
+		return nil, err
 	}
 
 	existing, _ := dbTrx.GetGateConfigDAO().GetByEventID(ctx, req.EventID)
@@ -268,6 +341,10 @@ func (s *gateService) CreateGateConfig(ctx context.Context, req CreateGateConfig
 		existing.Mode = app_gate_config.GateMode(req.Mode)
 		existing.MaxScanPerTicket = req.MaxScanPerTicket
 		existing.MaxScanByType = req.MaxScanByType
+		existing.Timezone = config.Timezone
+		existing.EntryWindows = config.EntryWindows
+		existing.DailyResetTime = config.DailyResetTime
+		existing.ReentryCooldownSeconds = config.ReentryCooldownSeconds
 		existing.IsActive = req.IsActive
 
 		if err := dbTrx.GetGateConfigDAO().Update(ctx, *existing); err != nil {
@@ -283,6 +360,10 @@ func (s *gateService) CreateGateConfig(ctx context.Context, req CreateGateConfig
 		return nil, err
 	}
 
+	return makeGateConfigInfo(&config), nil
+}
+
+func makeGateConfigInfo(config *app_gate_config.GateConfig) *GateConfigInfo {
 	return &GateConfigInfo{
 		ID:               string(config.ID),
 		EventID:          string(config.EventID),
@@ -290,11 +371,13 @@ func (s *gateService) CreateGateConfig(ctx context.Context, req CreateGateConfig
 		MaxScanPerTicket: config.MaxScanPerTicket,
 		MaxScanByType:    config.MaxScanByType,
 		IsActive:         config.IsActive,
-	}, nil
+		EntryRuleConfig:  makeEntryRuleConfig(config),
+	}
 }
 
 func (s *gateService) GetGateStats(ctx context.Context, eventID string) (*GateStats, error) {
 	dbTrx := dao.NewTransactionGate(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
 
 	tickets, err := dbTrx.GetPhysicalTicketDAO().Search(ctx, app_physical_ticket.PhysicalTicketQuery{
 		EventIDs: []string{eventID},
@@ -303,27 +386,78 @@ func (s *gateService) GetGateStats(ctx context.Context, eventID string) (*GateSt
 		return nil, err
 	}
 
+	eTickets, err := dbTrx.GetETicketDAO().Search(ctx, orderEntity.ETicketQuery{
+		EventIDs: []string{eventID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logs, err := dbTrx.GetGateLogDAO().Search(ctx, app_gate_log.GateLogQuery{
+		EventIDs: []string{eventID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	stats := &GateStats{
-		ByType: make(map[string]TypeStats),
+		ByType:       make(map[string]TypeStats),
+		BySource:     make(map[string]TypeStats),
+		ScanAttempts: make(map[string]int),
+		ByGate:       make(map[string]TypeStats),
+		ByZone:       make(map[string]ZoneStats),
 	}
 
-	for _, t := range tickets {
-		stats.TotalPhysicalTickets++
+	count := func(source CredentialType, ticketType string, status app_physical_ticket.PhysicalTicketStatus) {
+		stats.TotalTickets++
 
-		typeStats := stats.ByType[t.TicketType]
+		typeStats := stats.ByType[ticketType]
+		sourceStats := stats.BySource[string(source)]
 		typeStats.Total++
+		sourceStats.Total++
 
-		if t.Status == app_physical_ticket.PhysicalTicketStatusCheckedIn {
+		if status == app_physical_ticket.PhysicalTicketStatusCheckedIn {
 			stats.CheckedIn++
 			typeStats.CheckedIn++
-		} else if t.Status == app_physical_ticket.PhysicalTicketStatusCheckedOut {
+			sourceStats.CheckedIn++
+		} else if status == app_physical_ticket.PhysicalTicketStatusCheckedOut {
 			stats.CheckedOut++
 			typeStats.CheckedOut++
+			sourceStats.CheckedOut++
+		}
+
+		stats.ByType[ticketType] = typeStats
+		stats.BySource[string(source)] = sourceStats
+	}
+
+	for _, t := range tickets {
+		// Tiket VOID (termasuk yang sudah diganti) tidak dihitung agar penggantian tidak menggandakan total
+		if t.Status == app_physical_ticket.PhysicalTicketStatusVoid {
+			continue
 		}
+		stats.TotalPhysicalTickets++
+		count(CredentialPhysicalTicket, t.TicketType, t.Status)
+	}
 
-		stats.ByType[t.TicketType] = typeStats
+	for _, t := range eTickets {
+		if t.PaymentStatus != "paid" {
+			continue
+		}
+		stats.TotalETickets++
+		count(CredentialETicket, t.TicketType, app_physical_ticket.PhysicalTicketStatus(t.Status))
 	}
 
+	zones, err := loadZoneAccess(ctx, dbTrx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, l := range logs {
+		stats.ScanAttempts[string(l.Action)]++
+	}
+
+	s.countLocations(stats, zones, logs)
+
 	stats.ActiveNow = stats.CheckedIn
 
 	for ticketType, ts := range stats.ByType {
@@ -331,5 +465,91 @@ func (s *gateService) GetGateStats(ctx context.Context, eventID string) (*GateSt
 		stats.ByType[ticketType] = ts
 	}
 
+	for source, ts := range stats.BySource {
+		ts.ActiveNow = ts.CheckedIn
+		stats.BySource[source] = ts
+	}
+
 	return stats, nil
 }
+
+// countLocations memutar gate_logs per tiket berdasarkan scanned_at. Tiket dihitung aktif di gate
+// (dan zona gate tersebut) tempat scan sukses terakhirnya bila scan itu CHECK_IN; tiket yang terakhir
+// CHECK_OUT tidak dihitung di gate / zona mana pun.
+func (s *gateService) countLocations(stats *GateStats, zones *zoneAccess, logs app_gate_log.GateLogs) {
+	if zones != nil {
+		for _, zone := range zones.zones {
+			stats.ByZone[zone.Code] = ZoneStats{Name: zone.Name, Capacity: zone.Capacity}
+		}
+	}
+
+	sorted := make(app_gate_log.GateLogs, len(logs))
+	copy(sorted, logs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return scannedAt(sorted[i]).Before(scannedAt(sorted[j]))
+	})
+
+	lastByTicket := make(map[pubEntity.UUID]app_gate_log.GateLog)
+	for _, l := range sorted {
+		if l.GateName != "" {
+			gateStats := stats.ByGate[l.GateName]
+			gateStats.Total++
+			if l.Success && l.Action == app_gate_log.GateLogActionCheckIn {
+				gateStats.CheckedIn++
+			} else if l.Success && l.Action == app_gate_log.GateLogActionCheckOut {
+				gateStats.CheckedOut++
+			}
+			stats.ByGate[l.GateName] = gateStats
+		}
+
+		if zone, ok := zones.zoneOf(l.ZoneID); ok {
+			zoneStats := stats.ByZone[zone.Code]
+			switch {
+			case l.Action == app_gate_log.GateLogActionAccessDenied:
+				zoneStats.Denied++
+			case l.Success && l.Action == app_gate_log.GateLogActionCheckIn:
+				zoneStats.Entries++
+			}
+			stats.ByZone[zone.Code] = zoneStats
+		}
+
+		if !l.Success {
+			continue
+		}
+		if ticketID := gateLogTicketID(l); ticketID != nil {
+			lastByTicket[*ticketID] = l
+		}
+	}
+
+	for _, l := range lastByTicket {
+		if l.Action != app_gate_log.GateLogActionCheckIn {
+			continue
+		}
+
+		if l.GateName != "" {
+			gateStats := stats.ByGate[l.GateName]
+			gateStats.ActiveNow++
+			stats.ByGate[l.GateName] = gateStats
+		}
+
+		if zone, ok := zones.zoneOf(l.ZoneID); ok {
+			zoneStats := stats.ByZone[zone.Code]
+			zoneStats.Occupancy++
+			stats.ByZone[zone.Code] = zoneStats
+		}
+	}
+}
+
+func scannedAt(l app_gate_log.GateLog) time.Time {
+	if l.ScannedAt != nil {
+		return *l.ScannedAt
+	}
+	return l.CreatedAt
+}
+
+func gateLogTicketID(l app_gate_log.GateLog) *pubEntity.UUID {
+	if l.PhysicalTicketID != nil {
+		return l.PhysicalTicketID
+	}
+	return l.ETicketID
+}
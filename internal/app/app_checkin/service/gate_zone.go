@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"rakit-tiket-be/internal/app/app_checkin/dao"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	app_gate_zone "rakit-tiket-be/pkg/entity/app_gate_zone"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidZoneLayout = errors.New("layout zona tidak valid")
+	ErrGateNotRegistered = errors.New("gate_name tidak terdaftar di zona mana pun untuk event ini")
+)
+
+// ZoneLayout adalah registry zona dan gate satu event beserta matriks akses per ticket type.
+// Event tanpa zona tidak memakai pengecekan akses zona.
+type ZoneLayout struct {
+	EventID string        `json:"event_id"`
+	Zones   []ZoneSummary `json:"zones"`
+	// AccessMatrix: ticket type -> kode zona yang boleh dimasuki
+	AccessMatrix map[string][]string `json:"access_matrix"`
+}
+
+type ZoneSummary struct {
+	ID       string   `json:"id,omitempty"`
+	Code     string   `json:"code"`
+	Name     string   `json:"name"`
+	Capacity *int     `json:"capacity"`
+	Gates    []string `json:"gates"`
+}
+
+type SetZoneLayoutRequest struct {
+	Zones        []ZoneSummary       `json:"zones"`
+	AccessMatrix map[string][]string `json:"access_matrix"`
+}
+
+// SetZoneLayout mengganti seluruh layout zona event. Zone ID dibentuk dari event dan kode zona
+// sehingga tetap sama setelah layout diganti dan gate_logs.zone_id lama tetap bisa dirujuk.
+func (s *gateService) SetZoneLayout(ctx context.Context, eventID string, req SetZoneLayoutRequest) (*ZoneLayout, error) {
+	zoneIDs := make(map[string]pubEntity.UUID)
+	gateNames := make(map[string]bool)
+
+	for i, zone := range req.Zones {
+		zone.Code = strings.ToUpper(strings.TrimSpace(zone.Code))
+		zone.Name = strings.TrimSpace(zone.Name)
+		if zone.Code == "" || zone.Name == "" {
+			return nil, fmt.Errorf("%w: code dan name zona wajib diisi", ErrInvalidZoneLayout)
+		}
+		if _, ok := zoneIDs[zone.Code]; ok {
+			return nil, fmt.Errorf("%w: kode zona %s duplikat", ErrInvalidZoneLayout, zone.Code)
+		}
+		if zone.Capacity != nil && *zone.Capacity < 0 {
+			return nil, fmt.Errorf("%w: kapasitas zona %s tidak boleh negatif", ErrInvalidZoneLayout, zone.Code)
+		}
+
+		for j, gateName := range zone.Gates {
+			gateName = strings.TrimSpace(gateName)
+			if gateName == "" {
+				return nil, fmt.Errorf("%w: nama gate di zona %s kosong", ErrInvalidZoneLayout, zone.Code)
+			}
+			if gateNames[gateName] {
+				return nil, fmt.Errorf("%w: gate %s terdaftar di lebih dari satu zona", ErrInvalidZoneLayout, gateName)
+			}
+			gateNames[gateName] = true
+			zone.Gates[j] = gateName
+		}
+
+		zoneIDs[zone.Code] = pubEntity.MakeUUID("GATE_ZONE", eventID, zone.Code)
+		req.Zones[i] = zone
+	}
+
+	for ticketType, zoneCodes := range req.AccessMatrix {
+		for _, code := range zoneCodes {
+			if _, ok := zoneIDs[strings.ToUpper(strings.TrimSpace(code))]; !ok {
+				return nil, fmt.Errorf("%w: zona %s untuk ticket type %s tidak ada", ErrInvalidZoneLayout, code, ticketType)
+			}
+		}
+	}
+
+	dbTrx := dao.NewTransactionGate(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	zoneDAO := dbTrx.GetGateZoneDAO()
+
+	if err := zoneDAO.DeleteByEventID(ctx, eventID); err != nil {
+		return nil, fmt.Errorf("gagal menghapus layout zona lama: %v", err)
+	}
+
+	for _, zone := range req.Zones {
+		zoneID := zoneIDs[zone.Code]
+
+		if err := zoneDAO.Insert(ctx, app_gate_zone.GateZone{
+			ID:       zoneID,
+			EventID:  pubEntity.UUID(eventID),
+			Code:     zone.Code,
+			Name:     zone.Name,
+			Capacity: zone.Capacity,
+		}); err != nil {
+			return nil, fmt.Errorf("gagal menyimpan zona %s: %v", zone.Code, err)
+		}
+
+		for _, gateName := range zone.Gates {
+			if err := zoneDAO.InsertGate(ctx, app_gate_zone.ZoneGate{
+				ID:       pubEntity.MakeUUID("GATE_ZONE_GATE", eventID, gateName),
+				EventID:  pubEntity.UUID(eventID),
+				ZoneID:   zoneID,
+				GateName: gateName,
+			}); err != nil {
+				return nil, fmt.Errorf("gagal menyimpan gate %s: %v", gateName, err)
+			}
+		}
+	}
+
+	for ticketType, zoneCodes := range req.AccessMatrix {
+		ticketType = strings.TrimSpace(ticketType)
+		seen := make(map[pubEntity.UUID]bool)
+
+		for _, code := range zoneCodes {
+			zoneID := zoneIDs[strings.ToUpper(strings.TrimSpace(code))]
+			if seen[zoneID] {
+				continue
+			}
+			seen[zoneID] = true
+
+			if err := zoneDAO.InsertAccess(ctx, app_gate_zone.ZoneAccess{
+				ID:         pubEntity.MakeUUID("GATE_ZONE_ACCESS", eventID, ticketType, string(zoneID)),
+				EventID:    pubEntity.UUID(eventID),
+				ZoneID:     zoneID,
+				TicketType: ticketType,
+			}); err != nil {
+				return nil, fmt.Errorf("gagal menyimpan akses %s: %v", ticketType, err)
+			}
+		}
+	}
+
+	if err := dbTrx.GetSqlTx().Commit(); err != nil {
+		return nil, err
+	}
+
+	s.log.Info(ctx, "gate zone layout updated",
+		zap.String("event_id", eventID),
+		zap.Int("zones", len(req.Zones)),
+		zap.Int("gates", len(gateNames)),
+	)
+
+	return s.GetZoneLayout(ctx, eventID)
+}
+
+func (s *gateService) GetZoneLayout(ctx context.Context, eventID string) (*ZoneLayout, error) {
+	dbTrx := dao.NewTransactionGate(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	zones, err := loadZoneAccess(ctx, dbTrx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	return zones.layout(eventID), nil
+}
+
+// zoneAccess adalah layout zona event yang sudah diindeks untuk pengecekan scan
+type zoneAccess struct {
+	zones     app_gate_zone.GateZones
+	zoneByID  map[pubEntity.UUID]app_gate_zone.GateZone
+	gates     app_gate_zone.ZoneGates
+	gateZone  map[string]pubEntity.UUID
+	accesses  app_gate_zone.ZoneAccesses
+	allowedIn map[string]map[pubEntity.UUID]bool
+}
+
+// loadZoneAccess mengembalikan nil jika event belum punya zona
+func loadZoneAccess(ctx context.Context, dbTrx dao.DBTransaction, eventID string) (*zoneAccess, error) {
+	query := app_gate_zone.GateZoneQuery{EventIDs: []string{eventID}}
+
+	zones, err := dbTrx.GetGateZoneDAO().Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(zones) == 0 {
+		return nil, nil
+	}
+
+	gates, err := dbTrx.GetGateZoneDAO().SearchGates(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	accesses, err := dbTrx.GetGateZoneDAO().SearchAccess(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	z := &zoneAccess{
+		zones:     zones,
+		zoneByID:  make(map[pubEntity.UUID]app_gate_zone.GateZone),
+		gates:     gates,
+		gateZone:  make(map[string]pubEntity.UUID),
+		accesses:  accesses,
+		allowedIn: make(map[string]map[pubEntity.UUID]bool),
+	}
+
+	for _, zone := range zones {
+		z.zoneByID[zone.ID] = zone
+	}
+	for _, gate := range gates {
+		z.gateZone[gate.GateName] = gate.ZoneID
+	}
+	for _, access := range accesses {
+		if z.allowedIn[access.TicketType] == nil {
+			z.allowedIn[access.TicketType] = make(map[pubEntity.UUID]bool)
+		}
+		z.allowedIn[access.TicketType][access.ZoneID] = true
+	}
+
+	return z, nil
+}
+
+// check mengembalikan zona gate dan alasan penolakan bila ticket type tidak boleh lewat gate tersebut.
+// Scan tanpa gate (user ADMIN / GROUND STAFF) dan event tanpa zona tidak dicek.
+func (z *zoneAccess) check(gateName, ticketType string) (*pubEntity.UUID, string) {
+	if z == nil || gateName == "" {
+		return nil, ""
+	}
+
+	zoneID, ok := z.gateZone[gateName]
+	if !ok {
+		return nil, fmt.Sprintf("Akses ditolak: gate %s tidak terdaftar di zona mana pun", gateName)
+	}
+
+	if !z.allowedIn[ticketType][zoneID] {
+		return &zoneID, fmt.Sprintf("Akses ditolak untuk zona %s", z.zoneByID[zoneID].Name)
+	}
+
+	return &zoneID, ""
+}
+
+func (z *zoneAccess) hasGate(gateName string) bool {
+	if z == nil {
+		return true
+	}
+	_, ok := z.gateZone[gateName]
+	return ok
+}
+
+func (z *zoneAccess) zoneOf(zoneID *pubEntity.UUID) (app_gate_zone.GateZone, bool) {
+	if z == nil || zoneID == nil {
+		return app_gate_zone.GateZone{}, false
+	}
+	zone, ok := z.zoneByID[*zoneID]
+	return zone, ok
+}
+
+func (z *zoneAccess) zoneName(zoneID *pubEntity.UUID) string {
+	if z == nil || zoneID == nil {
+		return ""
+	}
+	return z.zoneByID[*zoneID].Name
+}
+
+func (z *zoneAccess) layout(eventID string) *ZoneLayout {
+	layout := &ZoneLayout{
+		EventID:      eventID,
+		Zones:        []ZoneSummary{},
+		AccessMatrix: make(map[string][]string),
+	}
+	if z == nil {
+		return layout
+	}
+
+	gatesByZone := make(map[pubEntity.UUID][]string)
+	for _, gate := range z.gates {
+		gatesByZone[gate.ZoneID] = append(gatesByZone[gate.ZoneID], gate.GateName)
+	}
+
+	for _, zone := range z.zones {
+		gates := gatesByZone[zone.ID]
+		if gates == nil {
+			gates = []string{}
+		}
+		layout.Zones = append(layout.Zones, ZoneSummary{
+			ID:       string(zone.ID),
+			Code:     zone.Code,
+			Name:     zone.Name,
+			Capacity: zone.Capacity,
+			Gates:    gates,
+		})
+	}
+
+	for _, access := range z.accesses {
+		layout.AccessMatrix[access.TicketType] = append(layout.AccessMatrix[access.TicketType], z.zoneByID[access.ZoneID].Code)
+	}
+
+	return layout
+}
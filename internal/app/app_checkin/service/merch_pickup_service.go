@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"rakit-tiket-be/internal/app/app_checkin/dao"
+	"rakit-tiket-be/internal/pkg/qrsign"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	addonEntity "rakit-tiket-be/pkg/entity/app_addon"
+	orderEntity "rakit-tiket-be/pkg/entity/app_order"
+	"rakit-tiket-be/pkg/util"
+)
+
+type MerchPickupService interface {
+	// ScanPickup menandai add-on order sebagai sudah diambil. Tanpa items, semua sisa add-on diambil sekaligus.
+	ScanPickup(ctx context.Context, req MerchPickupRequest, identity GateIdentity) (*MerchPickupResult, error)
+}
+
+type MerchPickupRequest struct {
+	QRCode string            `json:"qr_code"`
+	Items  []MerchPickupItem `json:"items"`
+}
+
+type MerchPickupItem struct {
+	OrderAddonID pubEntity.UUID `json:"order_addon_id"`
+	Quantity     int            `json:"quantity"`
+}
+
+type MerchPickupResult struct {
+	Success     bool   `json:"success"`
+	Message     string `json:"message"`
+	OrderNumber string `json:"order_number,omitempty"`
+
+	// Redeemed berisi quantity yang diserahkan pada scan ini per add-on
+	Redeemed []MerchPickupItem `json:"redeemed,omitempty"`
+	// Addons adalah kondisi add-on order setelah scan, termasuk sisa yang belum diambil
+	Addons addonEntity.OrderAddons `json:"addons,omitempty"`
+}
+
+type merchPickupService struct {
+	log      util.LogUtil
+	sqlDB    *sql.DB
+	qrSigner qrsign.Signer
+}
+
+func MakeMerchPickupService(log util.LogUtil, sqlDB *sql.DB, qrSigner qrsign.Signer) MerchPickupService {
+	return &merchPickupService{
+		log:      log,
+		sqlDB:    sqlDB,
+		qrSigner: qrSigner,
+	}
+}
+
+func (s *merchPickupService) ScanPickup(ctx context.Context, req MerchPickupRequest, identity GateIdentity) (*MerchPickupResult, error) {
+	payload, err := s.qrSigner.Verify(req.QRCode)
+	if err != nil || payload.Kind != qrsign.KindMerchPickup {
+		return &MerchPickupResult{Message: "QR pengambilan add-on tidak valid"}, nil
+	}
+	if !identity.CanAccessEvent(payload.EventID) {
+		return &MerchPickupResult{Message: "Perangkat tidak ditugaskan untuk event ini"}, nil
+	}
+
+	dbTrx := dao.NewTransactionGate(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	// Dikunci agar dua booth yang scan QR yang sama tidak menyerahkan item dua kali
+	addons, err := dbTrx.GetOrderAddonDAO().SearchForUpdate(ctx, addonEntity.OrderAddonQuery{
+		OrderIDs: []string{string(payload.TicketID)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock order addons: %w", err)
+	}
+	if len(addons) == 0 {
+		return &MerchPickupResult{Message: "Order tidak memiliki add-on"}, nil
+	}
+
+	result := &MerchPickupResult{
+		OrderNumber: addons[0].OrderNumber,
+		Addons:      addons,
+	}
+	if addons[0].EventID != payload.EventID {
+		result.Message = "QR pengambilan add-on tidak valid"
+		return result, nil
+	}
+	if addons[0].PaymentStatus != orderEntity.OrderStatusPaid {
+		result.Message = "Order belum lunas atau sudah dibatalkan"
+		return result, nil
+	}
+
+	requested := make(map[pubEntity.UUID]int)
+	if len(req.Items) == 0 {
+		for _, a := range addons {
+			if a.RemainingQty() > 0 {
+				requested[a.ID] = a.RemainingQty()
+			}
+		}
+	}
+	for _, item := range req.Items {
+		if item.Quantity <= 0 {
+			result.Message = "quantity pengambilan harus lebih dari 0"
+			return result, nil
+		}
+		requested[item.OrderAddonID] += item.Quantity
+	}
+	if len(requested) == 0 {
+		result.Message = "Semua add-on order ini sudah diambil"
+		return result, nil
+	}
+
+	matched := 0
+	for _, a := range addons {
+		qty, ok := requested[a.ID]
+		if !ok {
+			continue
+		}
+		matched++
+		if qty > a.RemainingQty() {
+			result.Message = fmt.Sprintf("Sisa %s yang belum diambil hanya %d", a.DisplayName(), a.RemainingQty())
+			return result, nil
+		}
+	}
+	if matched != len(requested) {
+		result.Message = "Add-on tidak ditemukan pada order ini"
+		return result, nil
+	}
+
+	now := time.Now()
+	var redeemed addonEntity.OrderAddons
+	for i := range addons {
+		qty, ok := requested[addons[i].ID]
+		if !ok {
+			continue
+		}
+
+		addons[i].RedeemedQty += qty
+		addons[i].RedeemedAt = &now
+		addons[i].RedeemedBy = &identity.ScannedBy
+		addons[i].UpdatedAt = &now
+		redeemed = append(redeemed, addons[i])
+		result.Redeemed = append(result.Redeemed, MerchPickupItem{OrderAddonID: addons[i].ID, Quantity: qty})
+	}
+
+	if err := dbTrx.GetOrderAddonDAO().Redeem(ctx, redeemed); err != nil {
+		return nil, fmt.Errorf("failed to redeem order addons: %w", err)
+	}
+
+	if err := dbTrx.GetSqlTx().Commit(); err != nil {
+		return nil, err
+	}
+
+	result.Success = true
+	result.Message = "Add-on berhasil diserahkan"
+	return result, nil
+}
@@ -0,0 +1,360 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"rakit-tiket-be/internal/app/app_checkin/dao"
+	app_physical_ticket "rakit-tiket-be/pkg/entity/app_physical_ticket"
+	"rakit-tiket-be/pkg/util"
+
+	"github.com/SebastiaanKlippert/go-wkhtmltopdf"
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	batchSheetColumns = 3
+	batchSheetRows    = 4
+	batchQRSize       = 300
+)
+
+var (
+	ErrInvalidBatch        = errors.New("batch tiket fisik tidak valid")
+	ErrBatchNotFound       = errors.New("batch tiket fisik tidak ditemukan")
+	ErrInvalidExportFormat = errors.New("format export harus pdf, csv atau zip")
+)
+
+type ExportFormat string
+
+const (
+	ExportFormatPDF ExportFormat = "pdf"
+	ExportFormatCSV ExportFormat = "csv"
+	ExportFormatZIP ExportFormat = "zip"
+)
+
+func (f ExportFormat) Valid() bool {
+	return f == ExportFormatPDF || f == ExportFormatCSV || f == ExportFormatZIP
+}
+
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportFormatPDF:
+		return "application/pdf"
+	case ExportFormatCSV:
+		return "text/csv; charset=utf-8"
+	}
+	return "application/zip"
+}
+
+type PhysicalTicketBatchInfo struct {
+	ID           string         `json:"id"`
+	EventID      string         `json:"event_id"`
+	Vendor       string         `json:"vendor"`
+	PrintDate    string         `json:"print_date,omitempty"`
+	TicketCounts map[string]int `json:"ticket_counts"`
+	CreatedBy    string         `json:"created_by"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+// BatchExport berisi tiket satu batch (tanpa tiket VOID) yang diurutkan per ticket type dan urutan cetak
+type BatchExport struct {
+	Batch      PhysicalTicketBatchInfo
+	TicketType string
+	Tickets    app_physical_ticket.PhysicalTickets
+}
+
+func (s *physicalTicketService) GetBatches(ctx context.Context, eventID string) ([]PhysicalTicketBatchInfo, error) {
+	dbTrx := dao.NewTransactionGate(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	batches, err := dbTrx.GetPhysicalTicketBatchDAO().Search(ctx, app_physical_ticket.PhysicalTicketBatchQuery{
+		EventIDs: []string{eventID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]PhysicalTicketBatchInfo, 0, len(batches))
+	for _, b := range batches {
+		result = append(result, makeBatchInfo(b))
+	}
+
+	return result, nil
+}
+
+func (s *physicalTicketService) PrepareBatchExport(ctx context.Context, batchID, ticketType string) (*BatchExport, error) {
+	if !util.IsValidUUID(batchID) {
+		return nil, ErrBatchNotFound
+	}
+
+	dbTrx := dao.NewTransactionGate(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	batches, err := dbTrx.GetPhysicalTicketBatchDAO().Search(ctx, app_physical_ticket.PhysicalTicketBatchQuery{
+		IDs: []string{batchID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(batches) == 0 {
+		return nil, ErrBatchNotFound
+	}
+
+	query := app_physical_ticket.PhysicalTicketQuery{
+		BatchIDs: []string{batchID},
+		Statuses: []app_physical_ticket.PhysicalTicketStatus{
+			app_physical_ticket.PhysicalTicketStatusActive,
+			app_physical_ticket.PhysicalTicketStatusCheckedIn,
+			app_physical_ticket.PhysicalTicketStatusCheckedOut,
+			app_physical_ticket.PhysicalTicketStatusExceeded,
+		},
+	}
+	if ticketType != "" {
+		query.TicketTypes = []string{ticketType}
+	}
+
+	tickets, err := dbTrx.GetPhysicalTicketDAO().Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tickets) == 0 {
+		return nil, fmt.Errorf("%w: tidak ada tiket untuk diexport", ErrInvalidBatch)
+	}
+
+	sort.SliceStable(tickets, func(i, j int) bool {
+		if tickets[i].TicketType != tickets[j].TicketType {
+			return tickets[i].TicketType < tickets[j].TicketType
+		}
+		return derefInt(tickets[i].BatchSeq) < derefInt(tickets[j].BatchSeq)
+	})
+
+	return &BatchExport{
+		Batch:      makeBatchInfo(batches[0]),
+		TicketType: ticketType,
+		Tickets:    tickets,
+	}, nil
+}
+
+func (e *BatchExport) FileName(format ExportFormat) string {
+	name := "physical-tickets-" + e.Batch.ID[:8]
+	if e.TicketType != "" {
+		name += "-" + fileSafe(e.TicketType)
+	}
+	return name + "." + string(format)
+}
+
+// WriteCSV menulis satu baris per tiket; png_file menunjuk file QR di dalam export ZIP
+func (e *BatchExport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write([]string{"batch_id", "ticket_type", "batch_seq", "serial", "physical_ticket_id", "qr_code", "png_file"}); err != nil {
+		return err
+	}
+
+	for _, t := range e.Tickets {
+		if err := writer.Write([]string{
+			e.Batch.ID,
+			t.TicketType,
+			strconv.Itoa(derefInt(t.BatchSeq)),
+			ticketSerial(t),
+			string(t.ID),
+			t.QRCode,
+			pngFileName(t),
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteZIP menulis PNG QR per tiket (folder per ticket type) beserta tickets.csv
+func (e *BatchExport) WriteZIP(w io.Writer) error {
+	archive := zip.NewWriter(w)
+
+	for _, t := range e.Tickets {
+		png, err := qrcode.Encode(t.QRCode, qrcode.Medium, batchQRSize)
+		if err != nil {
+			return fmt.Errorf("gagal membuat QR %s: %v", ticketSerial(t), err)
+		}
+
+		file, err := archive.Create(pngFileName(t))
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write(png); err != nil {
+			return err
+		}
+	}
+
+	manifest, err := archive.Create("tickets.csv")
+	if err != nil {
+		return err
+	}
+	if err := e.WriteCSV(manifest); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+type batchSheetPage struct {
+	TicketType string
+	Page       int
+	Pages      int
+	Rows       [][]*batchSheetCell
+}
+
+type batchSheetCell struct {
+	TicketType string
+	Serial     string
+	Seq        int
+	QRImage    template.URL
+}
+
+type batchSheetData struct {
+	Batch PhysicalTicketBatchInfo
+	Pages []batchSheetPage
+}
+
+// RenderPDF membuat lembar A4 berisi 3 x 4 tiket dengan tanda potong; setiap ticket type mulai di halaman baru
+func (e *BatchExport) RenderPDF() ([]byte, error) {
+	tmpl, err := template.New("batch_sheet_pdf").Parse(batchSheetPDFTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %v", err)
+	}
+
+	perPage := batchSheetColumns * batchSheetRows
+	data := batchSheetData{Batch: e.Batch}
+
+	var cells []*batchSheetCell
+	flush := func() {
+		for start := 0; start < len(cells); start += perPage {
+			end := start + perPage
+			if end > len(cells) {
+				end = len(cells)
+			}
+
+			page := batchSheetPage{TicketType: cells[start].TicketType}
+			for row := 0; row < batchSheetRows; row++ {
+				rowCells := make([]*batchSheetCell, batchSheetColumns)
+				for col := 0; col < batchSheetColumns; col++ {
+					if i := start + row*batchSheetColumns + col; i < end {
+						rowCells[col] = cells[i]
+					}
+				}
+				page.Rows = append(page.Rows, rowCells)
+			}
+			data.Pages = append(data.Pages, page)
+		}
+		cells = nil
+	}
+
+	for i, t := range e.Tickets {
+		if i > 0 && t.TicketType != e.Tickets[i-1].TicketType {
+			flush()
+		}
+
+		qrImage, err := util.GenerateQRCodeBase64(t.QRCode, batchQRSize)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membuat QR %s: %v", ticketSerial(t), err)
+		}
+
+		cells = append(cells, &batchSheetCell{
+			TicketType: t.TicketType,
+			Serial:     ticketSerial(t),
+			Seq:        derefInt(t.BatchSeq),
+			QRImage:    template.URL(qrImage),
+		})
+	}
+	flush()
+
+	for i := range data.Pages {
+		data.Pages[i].Page = i + 1
+		data.Pages[i].Pages = len(data.Pages)
+	}
+
+	var renderedHTML bytes.Buffer
+	if err := tmpl.Execute(&renderedHTML, data); err != nil {
+		return nil, fmt.Errorf("failed to render HTML: %v", err)
+	}
+
+	pdfg, err := wkhtmltopdf.NewPDFGenerator()
+	if err != nil {
+		return nil, fmt.Errorf("failed to init pdf generator: %v (pastikan wkhtmltopdf terinstal di OS)", err)
+	}
+
+	page := wkhtmltopdf.NewPageReader(bytes.NewReader(renderedHTML.Bytes()))
+	// Ukuran sel dalam mm harus tetap agar tanda potong sesuai saat dicetak
+	page.DisableSmartShrinking.Set(true)
+	pdfg.AddPage(page)
+
+	pdfg.MarginLeft.Set(0)
+	pdfg.MarginRight.Set(0)
+	pdfg.MarginTop.Set(0)
+	pdfg.MarginBottom.Set(0)
+	pdfg.PageSize.Set(wkhtmltopdf.PageSizeA4)
+
+	if err := pdfg.Create(); err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %v", err)
+	}
+
+	return pdfg.Bytes(), nil
+}
+
+func makeBatchInfo(b app_physical_ticket.PhysicalTicketBatch) PhysicalTicketBatchInfo {
+	info := PhysicalTicketBatchInfo{
+		ID:           string(b.ID),
+		EventID:      string(b.EventID),
+		Vendor:       b.Vendor,
+		TicketCounts: b.TicketCounts,
+		CreatedBy:    b.CreatedBy,
+		CreatedAt:    b.CreatedAt,
+	}
+	if b.PrintDate != nil {
+		info.PrintDate = b.PrintDate.Format(entryDateLayout)
+	}
+	if info.TicketCounts == nil {
+		info.TicketCounts = map[string]int{}
+	}
+	return info
+}
+
+// ticketSerial memakai ID tiket untuk tiket yang dibuat sebelum serial disimpan
+func ticketSerial(t app_physical_ticket.PhysicalTicket) string {
+	if t.Serial != nil && *t.Serial != "" {
+		return *t.Serial
+	}
+	return string(t.ID)
+}
+
+func pngFileName(t app_physical_ticket.PhysicalTicket) string {
+	return fmt.Sprintf("%s/%04d-%s.png", fileSafe(t.TicketType), derefInt(t.BatchSeq), fileSafe(ticketSerial(t)))
+}
+
+func fileSafe(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func derefInt(i *int) int {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
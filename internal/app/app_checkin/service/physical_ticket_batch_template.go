@@ -0,0 +1,131 @@
+package service
+
+// batchSheetPDFTemplate: A4 210 x 297 mm, grid 3 x 4 sel 60 x 66 mm dengan tanda potong di setiap sudut sel
+const batchSheetPDFTemplate = `
+<!DOCTYPE html>
+<html lang="id">
+<head>
+  <meta charset="UTF-8">
+  <title>Batch {{ .Batch.ID }}</title>
+  <style>
+    @page { margin: 0; }
+
+    body {
+      margin: 0;
+      padding: 0;
+      color: #0f172a;
+      font-family: "DejaVu Sans", "Helvetica Neue", Helvetica, Arial, sans-serif;
+    }
+
+    .sheet {
+      width: 210mm;
+      height: 297mm;
+      overflow: hidden;
+      page-break-after: always;
+    }
+
+    .sheet:last-child {
+      page-break-after: auto;
+    }
+
+    .header {
+      height: 12mm;
+      padding: 5mm 15mm 0 15mm;
+      font-size: 8pt;
+      color: #475569;
+    }
+
+    .header strong {
+      color: #0f172a;
+    }
+
+    table.grid {
+      margin-left: 15mm;
+      border-collapse: collapse;
+      border-spacing: 0;
+    }
+
+    table.grid td {
+      padding: 0;
+      vertical-align: top;
+    }
+
+    .cell {
+      position: relative;
+      width: 60mm;
+      height: 66mm;
+      text-align: center;
+    }
+
+    .mark {
+      position: absolute;
+      width: 4mm;
+      height: 4mm;
+      border: 0 solid #000000;
+    }
+
+    .tl { top: 0; left: 0; border-top-width: 0.2mm; border-left-width: 0.2mm; }
+    .tr { top: 0; right: 0; border-top-width: 0.2mm; border-right-width: 0.2mm; }
+    .bl { bottom: 0; left: 0; border-bottom-width: 0.2mm; border-left-width: 0.2mm; }
+    .br { bottom: 0; right: 0; border-bottom-width: 0.2mm; border-right-width: 0.2mm; }
+
+    .type {
+      padding-top: 5mm;
+      font-size: 9pt;
+      font-weight: bold;
+      letter-spacing: 0.5mm;
+    }
+
+    .qr {
+      width: 44mm;
+      height: 44mm;
+      margin-top: 2mm;
+    }
+
+    .serial {
+      font-family: "DejaVu Sans Mono", "Courier New", monospace;
+      font-size: 8pt;
+      margin-top: 1mm;
+    }
+
+    .seq {
+      font-size: 6pt;
+      color: #64748b;
+    }
+  </style>
+</head>
+<body>
+{{ range .Pages }}
+  <div class="sheet">
+    <div class="header">
+      <strong>{{ .TicketType }}</strong>
+      &nbsp;|&nbsp; Batch {{ $.Batch.ID }}
+      {{ if $.Batch.Vendor }}&nbsp;|&nbsp; Vendor {{ $.Batch.Vendor }}{{ end }}
+      {{ if $.Batch.PrintDate }}&nbsp;|&nbsp; Cetak {{ $.Batch.PrintDate }}{{ end }}
+      &nbsp;|&nbsp; Hal {{ .Page }}/{{ .Pages }}
+    </div>
+    <table class="grid">
+      {{ range .Rows }}
+      <tr>
+        {{ range . }}
+        <td>
+          <div class="cell">
+            {{ if . }}
+            <span class="mark tl"></span><span class="mark tr"></span>
+            <span class="mark bl"></span><span class="mark br"></span>
+            <div class="type">{{ .TicketType }}</div>
+            <img class="qr" src="{{ .QRImage }}" alt="{{ .Serial }}">
+            <div class="serial">{{ .Serial }}</div>
+            <div class="seq">#{{ .Seq }}</div>
+            {{ end }}
+          </div>
+        </td>
+        {{ end }}
+      </tr>
+      {{ end }}
+    </table>
+  </div>
+{{ end }}
+</body>
+</html>
+`
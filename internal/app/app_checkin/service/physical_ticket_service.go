@@ -0,0 +1,526 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"rakit-tiket-be/internal/app/app_checkin/dao"
+	ticketDao "rakit-tiket-be/internal/app/app_ticket/dao"
+	"rakit-tiket-be/internal/pkg/qrsign"
+	"rakit-tiket-be/internal/pkg/ticketcode"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	app_physical_ticket "rakit-tiket-be/pkg/entity/app_physical_ticket"
+	ticketEntity "rakit-tiket-be/pkg/entity/app_ticket"
+	"rakit-tiket-be/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultPhysicalTicketLimit = 50
+	maxPhysicalTicketLimit     = 500
+	maxVoidBatchSize           = 1000
+)
+
+var (
+	ErrPhysicalTicketNotFound = errors.New("tiket fisik tidak ditemukan")
+	ErrPhysicalTicketVoided   = errors.New("tiket fisik sudah VOID")
+	ErrPhysicalTicketReplaced = errors.New("tiket fisik sudah pernah diganti")
+	ErrInvalidTicketType      = errors.New("ticket type tidak ditemukan untuk event ini")
+	ErrInvalidVoidRequest     = fmt.Errorf("event_id dan ticket_ids (maksimal %d) wajib diisi", maxVoidBatchSize)
+)
+
+// PhysicalTicketService mengelola siklus hidup tiket fisik oleh admin. Setiap perubahan dicatat
+// di physical_ticket_audits dalam transaksi yang sama dengan perubahan tiketnya.
+type PhysicalTicketService interface {
+	SearchTickets(ctx context.Context, req SearchPhysicalTicketsRequest) (*PhysicalTicketPage, error)
+	GetTicket(ctx context.Context, id string) (*PhysicalTicketDetail, error)
+	VoidTickets(ctx context.Context, req VoidTicketsRequest, performedBy string) (*VoidTicketsResult, error)
+	ReplaceTicket(ctx context.Context, id string, req TicketChangeRequest, performedBy string) (*ReplaceTicketResult, error)
+	ReassignTicket(ctx context.Context, id string, req TicketChangeRequest, performedBy string) (*PhysicalTicketDetail, error)
+	ResetScans(ctx context.Context, id string, req TicketChangeRequest, performedBy string) (*PhysicalTicketDetail, error)
+
+	GetBatches(ctx context.Context, eventID string) ([]PhysicalTicketBatchInfo, error)
+	PrepareBatchExport(ctx context.Context, batchID, ticketType string) (*BatchExport, error)
+}
+
+type SearchPhysicalTicketsRequest struct {
+	EventID     string `query:"event_id"`
+	Code        string `query:"code"` // QR bertanda tangan, QR lama atau ID tiket
+	Status      string `query:"status"`
+	TicketType  string `query:"ticket_type"`
+	Page        int    `query:"page"`
+	Limit       int    `query:"limit"`
+	WithQRImage bool   `query:"with_qr_image"`
+}
+
+type PhysicalTicketPage struct {
+	Tickets []PhysicalTicketRecord `json:"tickets"`
+	Total   int                    `json:"total"`
+	Page    int                    `json:"page"`
+	Limit   int                    `json:"limit"`
+}
+
+type PhysicalTicketRecord struct {
+	PhysicalTicketInfo
+	EventID      string     `json:"event_id"`
+	ReplacesID   string     `json:"replaces_id,omitempty"`
+	BatchID      string     `json:"batch_id,omitempty"`
+	CheckedInAt  *time.Time `json:"checked_in_at"`
+	CheckedOutAt *time.Time `json:"checked_out_at"`
+	ScansResetAt *time.Time `json:"scans_reset_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type PhysicalTicketDetail struct {
+	Ticket PhysicalTicketRecord                     `json:"ticket"`
+	Audits app_physical_ticket.PhysicalTicketAudits `json:"audits"`
+}
+
+type VoidTicketsRequest struct {
+	EventID   string   `json:"event_id"`
+	TicketIDs []string `json:"ticket_ids"`
+	Reason    string   `json:"reason"`
+}
+
+type VoidTicketsResult struct {
+	Voided      []string `json:"voided"`
+	AlreadyVoid []string `json:"already_void"`
+	NotFound    []string `json:"not_found"`
+}
+
+// TicketChangeRequest dipakai replace, reassign dan reset; TicketType hanya untuk reassign
+type TicketChangeRequest struct {
+	TicketType string `json:"ticket_type"`
+	Reason     string `json:"reason"`
+}
+
+type ReplaceTicketResult struct {
+	Old PhysicalTicketRecord `json:"old"`
+	New PhysicalTicketRecord `json:"new"`
+}
+
+type physicalTicketService struct {
+	log      util.LogUtil
+	sqlDB    *sql.DB
+	qrSigner qrsign.Signer
+}
+
+func MakePhysicalTicketService(log util.LogUtil, sqlDB *sql.DB, qrSigner qrsign.Signer) PhysicalTicketService {
+	return &physicalTicketService{
+		log:      log,
+		sqlDB:    sqlDB,
+		qrSigner: qrSigner,
+	}
+}
+
+func (s *physicalTicketService) SearchTickets(ctx context.Context, req SearchPhysicalTicketsRequest) (*PhysicalTicketPage, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = defaultPhysicalTicketLimit
+	}
+	if req.Limit > maxPhysicalTicketLimit {
+		req.Limit = maxPhysicalTicketLimit
+	}
+
+	query := app_physical_ticket.PhysicalTicketQuery{
+		EventIDs: []string{req.EventID},
+		PagingQuery: pubEntity.PagingQuery{
+			Page:  pubEntity.Page(req.Page),
+			Limit: pubEntity.Limit(req.Limit),
+		},
+	}
+	if req.Status != "" {
+		query.Statuses = []app_physical_ticket.PhysicalTicketStatus{app_physical_ticket.PhysicalTicketStatus(strings.ToUpper(req.Status))}
+	}
+	if req.TicketType != "" {
+		query.TicketTypes = []string{req.TicketType}
+	}
+
+	// QR bertanda tangan dicari lewat ID di payload, serial lewat bentuk kanoniknya,
+	// kode lain dicocokkan langsung ke qr_code atau id
+	if code := strings.TrimSpace(req.Code); code != "" {
+		if payload, err := s.qrSigner.Verify(code); err == nil && payload.Kind == qrsign.KindPhysicalTicket {
+			query.IDs = []string{string(payload.TicketID)}
+		} else if serial, err := ticketcode.Parse(code); err == nil {
+			query.Serials = []string{serial}
+		} else if util.IsValidUUID(code) {
+			query.IDs = []string{code}
+		} else {
+			query.QRCodes = []string{code}
+		}
+	}
+
+	dbTrx := dao.NewTransactionGate(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	tickets, err := dbTrx.GetPhysicalTicketDAO().Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := dbTrx.GetPhysicalTicketDAO().Count(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &PhysicalTicketPage{
+		Tickets: make([]PhysicalTicketRecord, 0, len(tickets)),
+		Total:   total,
+		Page:    req.Page,
+		Limit:   req.Limit,
+	}
+	for _, t := range tickets {
+		page.Tickets = append(page.Tickets, makePhysicalTicketRecord(t, req.WithQRImage))
+	}
+
+	return page, nil
+}
+
+func (s *physicalTicketService) GetTicket(ctx context.Context, id string) (*PhysicalTicketDetail, error) {
+	dbTrx := dao.NewTransactionGate(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	tickets, err := dbTrx.GetPhysicalTicketDAO().Search(ctx, app_physical_ticket.PhysicalTicketQuery{IDs: []string{id}})
+	if err != nil {
+		return nil, err
+	}
+	if len(tickets) == 0 {
+		return nil, ErrPhysicalTicketNotFound
+	}
+
+	return s.detail(ctx, dbTrx, tickets[0])
+}
+
+// VoidTickets membatalkan tiket secara massal; tiket yang sudah VOID atau bukan milik event dilewati
+func (s *physicalTicketService) VoidTickets(ctx context.Context, req VoidTicketsRequest, performedBy string) (*VoidTicketsResult, error) {
+	if req.EventID == "" || len(req.TicketIDs) == 0 || len(req.TicketIDs) > maxVoidBatchSize {
+		return nil, ErrInvalidVoidRequest
+	}
+
+	dbTrx := dao.NewTransactionGate(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	tickets, err := dbTrx.GetPhysicalTicketDAO().SearchForUpdate(ctx, app_physical_ticket.PhysicalTicketQuery{
+		IDs:      req.TicketIDs,
+		EventIDs: []string{req.EventID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]app_physical_ticket.PhysicalTicket)
+	for _, t := range tickets {
+		byID[string(t.ID)] = t
+	}
+
+	result := &VoidTicketsResult{
+		Voided:      []string{},
+		AlreadyVoid: []string{},
+		NotFound:    []string{},
+	}
+
+	now := time.Now()
+	var voided app_physical_ticket.PhysicalTickets
+	var audits app_physical_ticket.PhysicalTicketAudits
+	seen := make(map[string]bool)
+	for _, id := range req.TicketIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		ticket, ok := byID[id]
+		switch {
+		case !ok:
+			result.NotFound = append(result.NotFound, id)
+			continue
+		case ticket.Status == app_physical_ticket.PhysicalTicketStatusVoid:
+			result.AlreadyVoid = append(result.AlreadyVoid, id)
+			continue
+		}
+
+		before := ticket
+		ticket.Status = app_physical_ticket.PhysicalTicketStatusVoid
+		voided = append(voided, ticket)
+		audits = append(audits, makeTicketAudit(before, ticket, app_physical_ticket.PhysicalTicketAuditVoid, nil, req.Reason, performedBy, now))
+		result.Voided = append(result.Voided, id)
+	}
+
+	if err := dbTrx.GetPhysicalTicketDAO().Update(ctx, voided); err != nil {
+		return nil, fmt.Errorf("gagal void tiket: %v", err)
+	}
+	if err := dbTrx.GetPhysicalTicketAuditDAO().Insert(ctx, audits); err != nil {
+		return nil, fmt.Errorf("gagal menyimpan audit tiket: %v", err)
+	}
+
+	if err := dbTrx.GetSqlTx().Commit(); err != nil {
+		return nil, err
+	}
+
+	s.log.Info(ctx, "physical tickets voided",
+		zap.String("event_id", req.EventID),
+		zap.Int("voided", len(result.Voided)),
+		zap.String("performed_by", performedBy),
+	)
+
+	return result, nil
+}
+
+// ReplaceTicket menerbitkan QR baru untuk tiket hilang / rusak. Tiket lama menjadi VOID, sedangkan
+// status dan scan_count dibawa ke tiket baru sehingga penggantian tidak menambah jatah masuk.
+func (s *physicalTicketService) ReplaceTicket(ctx context.Context, id string, req TicketChangeRequest, performedBy string) (*ReplaceTicketResult, error) {
+	dbTrx := dao.NewTransactionGate(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	old, err := s.lockTicket(ctx, dbTrx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	replacements, err := dbTrx.GetPhysicalTicketDAO().Search(ctx, app_physical_ticket.PhysicalTicketQuery{ReplacesIDs: []string{id}})
+	if err != nil {
+		return nil, err
+	}
+	if len(replacements) > 0 {
+		return nil, ErrPhysicalTicketReplaced
+	}
+
+	now := time.Now()
+	newID := pubEntity.MakeUUID("PHYSICAL_TICKET_REPLACEMENT", id, now.String())
+	oldID := old.ID
+
+	qrCode, err := s.qrSigner.Sign(qrsign.Payload{
+		Kind:     qrsign.KindPhysicalTicket,
+		EventID:  old.EventID,
+		TicketID: newID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gagal menandatangani qr code: %v", err)
+	}
+
+	serials, err := allocateSerials(ctx, dbTrx, string(old.EventID), 1)
+	if err != nil {
+		return nil, err
+	}
+
+	replacement := app_physical_ticket.PhysicalTicket{
+		ID:         newID,
+		EventID:    old.EventID,
+		TicketType: old.TicketType,
+		TicketID:   old.TicketID,
+		QRCode:     qrCode,
+		QRCodeHash: util.MakeUUID(qrCode),
+		ReplacesID: &oldID,
+		Serial:     &serials[0],
+	}
+	inserted := app_physical_ticket.PhysicalTickets{replacement}
+	if err := dbTrx.GetPhysicalTicketDAO().Insert(ctx, inserted); err != nil {
+		return nil, fmt.Errorf("gagal menyimpan tiket pengganti: %v", err)
+	}
+	issued := inserted[0]
+
+	replacement = issued
+	replacement.Status = old.Status
+	replacement.ScanCount = old.ScanCount
+	replacement.CheckedInAt = old.CheckedInAt
+	replacement.CheckedOutAt = old.CheckedOutAt
+	if replacement.Status == app_physical_ticket.PhysicalTicketStatusVoid {
+		replacement.Status = app_physical_ticket.PhysicalTicketStatusActive
+		if replacement.ScanCount > 0 {
+			replacement.Status = app_physical_ticket.PhysicalTicketStatusCheckedIn
+		}
+	}
+
+	voided := old
+	voided.Status = app_physical_ticket.PhysicalTicketStatusVoid
+
+	if err := dbTrx.GetPhysicalTicketDAO().Update(ctx, app_physical_ticket.PhysicalTickets{voided, replacement}); err != nil {
+		return nil, fmt.Errorf("gagal update tiket: %v", err)
+	}
+
+	audits := app_physical_ticket.PhysicalTicketAudits{
+		makeTicketAudit(old, voided, app_physical_ticket.PhysicalTicketAuditReplace, &newID, req.Reason, performedBy, now),
+		makeTicketAudit(issued, replacement, app_physical_ticket.PhysicalTicketAuditReissue, &oldID, req.Reason, performedBy, now),
+	}
+	if err := dbTrx.GetPhysicalTicketAuditDAO().Insert(ctx, audits); err != nil {
+		return nil, fmt.Errorf("gagal menyimpan audit tiket: %v", err)
+	}
+
+	if err := dbTrx.GetSqlTx().Commit(); err != nil {
+		return nil, err
+	}
+
+	s.log.Info(ctx, "physical ticket replaced",
+		zap.String("old_id", id),
+		zap.String("new_id", string(newID)),
+		zap.String("performed_by", performedBy),
+	)
+
+	return &ReplaceTicketResult{
+		Old: makePhysicalTicketRecord(voided, false),
+		New: makePhysicalTicketRecord(replacement, true),
+	}, nil
+}
+
+// ReassignTicket memindahkan tiket ke ticket type lain di event yang sama. QR tidak berubah
+// karena payload QR hanya berisi event dan ID tiket.
+func (s *physicalTicketService) ReassignTicket(ctx context.Context, id string, req TicketChangeRequest, performedBy string) (*PhysicalTicketDetail, error) {
+	dbTrx := dao.NewTransactionGate(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	ticket, err := s.lockTicket(ctx, dbTrx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.Status == app_physical_ticket.PhysicalTicketStatusVoid {
+		return nil, ErrPhysicalTicketVoided
+	}
+
+	masters, err := ticketDao.NewTransactionTicket(ctx, s.log, s.sqlDB).GetTicketDAO().Search(ctx, ticketEntity.TicketQuery{
+		EventIDs: []string{string(ticket.EventID)},
+		Types:    []string{req.TicketType},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if req.TicketType == "" || len(masters) == 0 {
+		return nil, ErrInvalidTicketType
+	}
+
+	updated := ticket
+	updated.TicketType = masters[0].Type
+	updated.TicketID = masters[0].ID
+
+	return s.applyChange(ctx, dbTrx, ticket, updated, app_physical_ticket.PhysicalTicketAuditReassign, req.Reason, performedBy)
+}
+
+// ResetScans mengosongkan scan_count dan waktu check-in / check-out; riwayat gate_logs tetap utuh,
+// tetapi log sebelum scans_reset_at tidak lagi dihitung saat sync offline maupun batas scan harian
+func (s *physicalTicketService) ResetScans(ctx context.Context, id string, req TicketChangeRequest, performedBy string) (*PhysicalTicketDetail, error) {
+	dbTrx := dao.NewTransactionGate(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	ticket, err := s.lockTicket(ctx, dbTrx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.Status == app_physical_ticket.PhysicalTicketStatusVoid {
+		return nil, ErrPhysicalTicketVoided
+	}
+
+	updated := ticket
+	updated.Status = app_physical_ticket.PhysicalTicketStatusActive
+	updated.ScanCount = 0
+	updated.CheckedInAt = nil
+	updated.CheckedOutAt = nil
+	now := time.Now()
+	updated.ScansResetAt = &now
+
+	return s.applyChange(ctx, dbTrx, ticket, updated, app_physical_ticket.PhysicalTicketAuditResetScan, req.Reason, performedBy)
+}
+
+func (s *physicalTicketService) applyChange(ctx context.Context, dbTrx dao.DBTransaction, before, after app_physical_ticket.PhysicalTicket, action app_physical_ticket.PhysicalTicketAuditAction, reason, performedBy string) (*PhysicalTicketDetail, error) {
+	if err := dbTrx.GetPhysicalTicketDAO().Update(ctx, app_physical_ticket.PhysicalTickets{after}); err != nil {
+		return nil, fmt.Errorf("gagal update tiket: %v", err)
+	}
+
+	audit := makeTicketAudit(before, after, action, nil, reason, performedBy, time.Now())
+	if err := dbTrx.GetPhysicalTicketAuditDAO().Insert(ctx, app_physical_ticket.PhysicalTicketAudits{audit}); err != nil {
+		return nil, fmt.Errorf("gagal menyimpan audit tiket: %v", err)
+	}
+
+	if err := dbTrx.GetSqlTx().Commit(); err != nil {
+		return nil, err
+	}
+
+	s.log.Info(ctx, "physical ticket changed",
+		zap.String("id", string(after.ID)),
+		zap.String("action", string(action)),
+		zap.String("performed_by", performedBy),
+	)
+
+	return s.GetTicket(ctx, string(after.ID))
+}
+
+func (s *physicalTicketService) lockTicket(ctx context.Context, dbTrx dao.DBTransaction, id string) (app_physical_ticket.PhysicalTicket, error) {
+	if !util.IsValidUUID(id) {
+		return app_physical_ticket.PhysicalTicket{}, ErrPhysicalTicketNotFound
+	}
+
+	tickets, err := dbTrx.GetPhysicalTicketDAO().SearchForUpdate(ctx, app_physical_ticket.PhysicalTicketQuery{IDs: []string{id}})
+	if err != nil {
+		return app_physical_ticket.PhysicalTicket{}, err
+	}
+	if len(tickets) == 0 {
+		return app_physical_ticket.PhysicalTicket{}, ErrPhysicalTicketNotFound
+	}
+	return tickets[0], nil
+}
+
+func (s *physicalTicketService) detail(ctx context.Context, dbTrx dao.DBTransaction, ticket app_physical_ticket.PhysicalTicket) (*PhysicalTicketDetail, error) {
+	audits, err := dbTrx.GetPhysicalTicketAuditDAO().Search(ctx, app_physical_ticket.PhysicalTicketAuditQuery{
+		PhysicalTicketIDs: []string{string(ticket.ID)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if audits == nil {
+		audits = app_physical_ticket.PhysicalTicketAudits{}
+	}
+
+	return &PhysicalTicketDetail{
+		Ticket: makePhysicalTicketRecord(ticket, true),
+		Audits: audits,
+	}, nil
+}
+
+func makeTicketAudit(before, after app_physical_ticket.PhysicalTicket, action app_physical_ticket.PhysicalTicketAuditAction, relatedID *pubEntity.UUID, reason, performedBy string, at time.Time) app_physical_ticket.PhysicalTicketAudit {
+	return app_physical_ticket.PhysicalTicketAudit{
+		ID:               pubEntity.MakeUUID("PHYSICAL_TICKET_AUDIT", string(after.ID), string(action), at.String()),
+		EventID:          after.EventID,
+		PhysicalTicketID: after.ID,
+		Action:           action,
+		FromStatus:       before.Status,
+		ToStatus:         after.Status,
+		FromTicketType:   before.TicketType,
+		ToTicketType:     after.TicketType,
+		FromScanCount:    before.ScanCount,
+		ToScanCount:      after.ScanCount,
+		RelatedTicketID:  relatedID,
+		Reason:           strings.TrimSpace(reason),
+		PerformedBy:      performedBy,
+		CreatedAt:        at,
+	}
+}
+
+func makePhysicalTicketRecord(t app_physical_ticket.PhysicalTicket, withQRImage bool) PhysicalTicketRecord {
+	record := PhysicalTicketRecord{
+		PhysicalTicketInfo: PhysicalTicketInfo{
+			ID:         string(t.ID),
+			QRCode:     t.QRCode,
+			Serial:     derefString(t.Serial),
+			TicketType: t.TicketType,
+			TicketID:   string(t.TicketID),
+			Status:     string(t.Status),
+			ScanCount:  t.ScanCount,
+		},
+		EventID:      string(t.EventID),
+		ReplacesID:   derefUUID(t.ReplacesID),
+		BatchID:      derefUUID(t.BatchID),
+		CheckedInAt:  t.CheckedInAt,
+		CheckedOutAt: t.CheckedOutAt,
+		ScansResetAt: t.ScansResetAt,
+		CreatedAt:    t.CreatedAt,
+	}
+	if withQRImage {
+		record.QRCodeImage, _ = util.GenerateQRCodeBase64(t.QRCode, 256)
+	}
+	return record
+}
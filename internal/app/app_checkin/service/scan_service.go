@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"rakit-tiket-be/internal/app/app_checkin/dao"
+	"rakit-tiket-be/internal/pkg/qrsign"
 	pubEntity "rakit-tiket-be/pkg/entity"
 	app_gate_config "rakit-tiket-be/pkg/entity/app_gate_config"
 	app_gate_log "rakit-tiket-be/pkg/entity/app_gate_log"
@@ -17,68 +18,139 @@ import (
 )
 
 type ScanService interface {
-	ScanTicket(ctx context.Context, qrCode, gateName, scannedBy string) (*ScanResult, error)
-	GetGateLogs(ctx context.Context, eventID string, limit int) ([]GateLogInfo, error)
+	ScanTicket(ctx context.Context, qrCode string, identity GateIdentity) (*ScanResult, error)
+	GetGateLogs(ctx context.Context, eventID string, limit int, before int64) ([]GateLogInfo, error)
 }
 
 type ScanResult struct {
-	Success    bool   `json:"success"`
-	Action     string `json:"action"`
-	QRCode     string `json:"qr_code"`
-	TicketType string `json:"ticket_type"`
-	ScanCount  int    `json:"scan_count"`
-	Message    string `json:"message"`
+	Success        bool   `json:"success"`
+	Action         string `json:"action"`
+	QRCode         string `json:"qr_code"`
+	CredentialType string `json:"credential_type,omitempty"`
+	TicketType     string `json:"ticket_type"`
+	ScanCount      int    `json:"scan_count"`
+	Message        string `json:"message"`
+	Zone           string `json:"zone,omitempty"` // nama zona gate tempat scan
+
+	// Terisi untuk e-ticket order
+	TicketCode  string `json:"ticket_code,omitempty"`
+	HolderName  string `json:"holder_name,omitempty"`
+	SeatNumber  int    `json:"seat_number,omitempty"`
+	OrderNumber string `json:"order_number,omitempty"`
 }
 
 type GateLogInfo struct {
 	ID               string `json:"id"`
+	Seq              int64  `json:"seq"`
 	EventID          string `json:"event_id"`
 	PhysicalTicketID string `json:"physical_ticket_id"`
+	ETicketID        string `json:"e_ticket_id"`
+	QRCode           string `json:"qr_code"`
 	Action           string `json:"action"`
 	Success          bool   `json:"success"`
 	Message          string `json:"message"`
 	GateName         string `json:"gate_name"`
+	ZoneID           string `json:"zone_id"`
 	TicketType       string `json:"ticket_type"`
 	ScanSequence     int    `json:"scan_sequence"`
 	CreatedAt        string `json:"created_at"`
 }
 
 type scanService struct {
-	log   util.LogUtil
-	sqlDB *sql.DB
+	log      util.LogUtil
+	sqlDB    *sql.DB
+	qrSigner qrsign.Signer
+	feed     GateFeed
 }
 
-func MakeScanService(log util.LogUtil, sqlDB *sql.DB) ScanService {
+func MakeScanService(log util.LogUtil, sqlDB *sql.DB, qrSigner qrsign.Signer, feed GateFeed) ScanService {
 	return &scanService{
-		log:   log,
-		sqlDB: sqlDB,
+		log:      log,
+		sqlDB:    sqlDB,
+		qrSigner: qrSigner,
+		feed:     feed,
 	}
 }
 
-func (s *scanService) ScanTicket(ctx context.Context, qrCode, gateName, scannedBy string) (*ScanResult, error) {
+// ScanTicket memproses satu scan tiket fisik maupun e-ticket order dengan aturan gate_configs
+// dan matriks akses zona event tersebut. Setiap percobaan, termasuk yang ditolak, dicatat di gate_logs.
+func (s *scanService) ScanTicket(ctx context.Context, qrCode string, identity GateIdentity) (*ScanResult, error) {
 	dbTrx := dao.NewTransactionGate(ctx, s.log, s.sqlDB)
 	defer dbTrx.GetSqlTx().Rollback()
 
-	ticket, err := dbTrx.GetPhysicalTicketDAO().SearchByQRCode(ctx, qrCode)
-	if err != nil {
-		return &ScanResult{
-			Success: false,
-			Action:  "INVALID",
-			QRCode:  qrCode,
-			Message: "Tiket tidak ditemukan",
-		}, nil
+	// Tanda tangan diverifikasi sebelum query apa pun ke DB
+	ref, eventID, reason := verifyCredential(s.qrSigner, qrCode)
+	if ref == nil {
+		s.log.Warn(ctx, "scanService.ScanTicket rejected qr code", zap.String("reason", reason))
+	}
+
+	var cred *gateCredential
+	if ref != nil {
+		lookups, err := lockCredentials(ctx, dbTrx, []credentialRef{*ref}, s.qrSigner.AllowUnsigned())
+		if err != nil {
+			return nil, fmt.Errorf("gagal mencari tiket: %v", err)
+		}
+		cred, reason = lookups[0].cred, lookups[0].reason
+		if ref.Signed {
+			eventID = &ref.EventID
+		}
+	}
+
+	if cred != nil && !identity.CanAccessEvent(cred.EventID) {
+		eventID = &cred.EventID
+		reason = "Tiket bukan untuk event perangkat ini"
+		cred = nil
 	}
 
-	if ticket.Status == app_physical_ticket.PhysicalTicketStatusVoid {
+	if cred == nil {
+		logEntry := s.logInvalid(ctx, dbTrx, qrCode, eventID, reason, identity)
+
+		if err := dbTrx.GetSqlTx().Commit(); err != nil {
+			return nil, err
+		}
+		if logEntry != nil {
+			s.feed.Publish(*logEntry)
+		}
+
 		return &ScanResult{
 			Success: false,
-			Action:  "INVALID",
+			Action:  string(app_gate_log.GateLogActionInvalid),
 			QRCode:  qrCode,
-			Message: "Tiket sudah dibatalkan",
+			Message: reason,
 		}, nil
 	}
 
-	config, err := dbTrx.GetGateConfigDAO().GetByEventID(ctx, string(ticket.EventID))
+	result := cred.result()
+	result.QRCode = qrCode
+
+	zones, err := loadZoneAccess(ctx, dbTrx, string(cred.EventID))
+	if err != nil {
+		return nil, fmt.Errorf("gagal memuat zona gate: %v", err)
+	}
+	zoneID, zoneReason := zones.check(identity.GateName, cred.TicketType)
+	result.Zone = zones.zoneName(zoneID)
+
+	if reason := cred.rejectReason(); reason != "" {
+		if err := s.logAttempt(ctx, dbTrx, cred, qrCode, app_gate_log.GateLogActionInvalid, false, reason, identity, zoneID); err != nil {
+			return nil, err
+		}
+
+		result.Action = string(app_gate_log.GateLogActionInvalid)
+		result.Message = reason
+		return &result, nil
+	}
+
+	if zoneReason != "" {
+		if err := s.logAttempt(ctx, dbTrx, cred, qrCode, app_gate_log.GateLogActionAccessDenied, false, zoneReason, identity, zoneID); err != nil {
+			return nil, err
+		}
+
+		result.Action = string(app_gate_log.GateLogActionAccessDenied)
+		result.Message = zoneReason
+		return &result, nil
+	}
+
+	config, err := loadGateConfig(ctx, dbTrx, string(cred.EventID))
 	if err != nil {
 		return nil, fmt.Errorf("konfigurasi gate tidak ditemukan: %v", err)
 	}
@@ -87,122 +159,205 @@ func (s *scanService) ScanTicket(ctx context.Context, qrCode, gateName, scannedB
 		return nil, fmt.Errorf("gate check-in tidak aktif")
 	}
 
-	maxScan := config.MaxScanPerTicket
-	if config.MaxScanByType != nil {
-		if override, ok := config.MaxScanByType[ticket.TicketType]; ok {
-			maxScan = override
+	now := time.Now()
+	rules := newEntryRules(config)
+
+	if reason := rules.windowReason(cred.TicketType, now); reason != "" {
+		if err := s.logAttempt(ctx, dbTrx, cred, qrCode, app_gate_log.GateLogActionOutsideWindow, false, reason, identity, zoneID); err != nil {
+			return nil, err
 		}
+
+		result.Action = string(app_gate_log.GateLogActionOutsideWindow)
+		result.Message = reason
+		return &result, nil
 	}
 
-	if ticket.ScanCount >= maxScan {
-		s.log.Info(ctx, "scan exceeded max", zap.Int("scan_count", ticket.ScanCount), zap.Int("max_scan", maxScan))
+	admissions, err := loadAdmissions(ctx, dbTrx, rules, cred, now)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memuat riwayat scan: %v", err)
+	}
 
-		s.logAudit(ctx, dbTrx, ticket, "EXCEEDED", false, "Melebihi max scan", gateName, scannedBy)
+	maxScan := maxScanFor(config, cred.TicketType)
+	scanCount := admissions.count(now)
 
-		return &ScanResult{
-			Success:    false,
-			Action:     "EXCEEDED",
-			QRCode:     qrCode,
-			TicketType: ticket.TicketType,
-			ScanCount:  ticket.ScanCount,
-			Message:    fmt.Sprintf("Melebihi batas scan (%d/%d)", ticket.ScanCount, maxScan),
-		}, nil
+	if scanCount >= maxScan {
+		s.log.Info(ctx, "scan exceeded max", zap.Int("scan_count", scanCount), zap.Int("max_scan", maxScan))
+
+		message := admissions.exceededMessage(maxScan)
+		if err := s.logAttempt(ctx, dbTrx, cred, qrCode, app_gate_log.GateLogActionExceeded, false, message, identity, zoneID); err != nil {
+			return nil, err
+		}
+
+		result.Action = string(app_gate_log.GateLogActionExceeded)
+		result.Message = message
+		return &result, nil
 	}
 
-	var action string
-	var newStatus app_physical_ticket.PhysicalTicketStatus
-
-	if config.Mode == app_gate_config.GateModeCheckInOut {
-		if ticket.ScanCount == 0 {
-			action = "CHECK_IN"
-			newStatus = app_physical_ticket.PhysicalTicketStatusCheckedIn
-			now := time.Now()
-			ticket.CheckedInAt = &now
-		} else {
-			action = "CHECK_OUT"
-			newStatus = app_physical_ticket.PhysicalTicketStatusCheckedOut
-			now := time.Now()
-			ticket.CheckedOutAt = &now
+	action, newStatus := nextScanAction(config, scanCount)
+
+	if action == app_gate_log.GateLogActionCheckIn {
+		if reason := admissions.cooldownReason(now); reason != "" {
+			if err := s.logAttempt(ctx, dbTrx, cred, qrCode, app_gate_log.GateLogActionCooldown, false, reason, identity, zoneID); err != nil {
+				return nil, err
+			}
+
+			result.Action = string(app_gate_log.GateLogActionCooldown)
+			result.Message = reason
+			return &result, nil
 		}
-	} else {
-		action = "CHECK_IN"
-		newStatus = app_physical_ticket.PhysicalTicketStatusCheckedIn
-		now := time.Now()
-		ticket.CheckedInAt = &now
 	}
 
-	ticket.ScanCount++
-	ticket.Status = newStatus
+	cred.ScanCount++
+	cred.recordAdmission(action, newStatus, now)
 
-	if err := dbTrx.GetPhysicalTicketDAO().Update(ctx, app_physical_ticket.PhysicalTickets{*ticket}); err != nil {
+	if err := cred.save(ctx, dbTrx); err != nil {
 		return nil, fmt.Errorf("gagal update ticket: %v", err)
 	}
 
-	s.logAudit(ctx, dbTrx, ticket, action, true, fmt.Sprintf("%s berhasil", action), gateName, scannedBy)
-
-	if err := dbTrx.GetSqlTx().Commit(); err != nil {
+	message := fmt.Sprintf("%s berhasil", action)
+	if err := s.logAttempt(ctx, dbTrx, cred, qrCode, action, true, message, identity, zoneID); err != nil {
 		return nil, err
 	}
 
-	return &ScanResult{
-		Success:    true,
-		Action:     action,
-		QRCode:     qrCode,
-		TicketType: ticket.TicketType,
-		ScanCount:  ticket.ScanCount,
-		Message:    fmt.Sprintf("%s berhasil", action),
-	}, nil
+	s.log.Info(ctx, "Ticket scanned",
+		zap.String("credential_type", string(cred.Type)),
+		zap.String("ticket_id", string(cred.ID)),
+		zap.String("action", string(action)),
+	)
+
+	result = cred.result()
+	result.Success = true
+	result.QRCode = qrCode
+	result.Action = string(action)
+	result.Message = message
+	result.Zone = zones.zoneName(zoneID)
+	return &result, nil
+}
+
+// logInvalid mengembalikan nil jika log gagal disimpan; kegagalan ini tidak menggagalkan respon scan
+func (s *scanService) logInvalid(ctx context.Context, dbTrx dao.DBTransaction, qrCode string, eventID *pubEntity.UUID, message string, identity GateIdentity) *app_gate_log.GateLog {
+	now := time.Now()
+
+	logEntry := app_gate_log.GateLog{
+		ID:        pubEntity.MakeUUID(qrCode, string(app_gate_log.GateLogActionInvalid), now.String()),
+		EventID:   eventID,
+		QRCode:    truncateCode(qrCode),
+		ScannedBy: identity.ScannedBy,
+		Action:    app_gate_log.GateLogActionInvalid,
+		Success:   false,
+		Message:   message,
+		GateName:  identity.GateName,
+		DeviceID:  identity.deviceIDPtr(),
+		CreatedAt: now,
+	}
+
+	if err := dbTrx.GetGateLogDAO().Insert(ctx, &logEntry); err != nil {
+		return nil
+	}
+	return &logEntry
 }
 
-func (s *scanService) logAudit(ctx context.Context, dbTrx dao.DBTransaction, ticket *app_physical_ticket.PhysicalTicket, action string, success bool, message, gateName, scannedBy string) {
+// logAttempt mencatat scan yang merujuk tiket, commit transaksi scan lalu mengirim hasilnya ke feed gate
+func (s *scanService) logAttempt(ctx context.Context, dbTrx dao.DBTransaction, cred *gateCredential, qrCode string, action app_gate_log.GateLogAction, success bool, message string, identity GateIdentity, zoneID *pubEntity.UUID) error {
 	now := time.Now()
 
 	logEntry := app_gate_log.GateLog{
-		ID:               pubEntity.MakeUUID(string(ticket.ID), action, now.String()),
-		EventID:          ticket.EventID,
-		PhysicalTicketID: ticket.ID,
-		ScannedBy:        scannedBy,
-		Action:           app_gate_log.GateLogAction(action),
-		Success:          success,
-		Message:          message,
-		GateName:         gateName,
-		TicketType:       ticket.TicketType,
-		ScanSequence:     ticket.ScanCount,
-		CreatedAt:        now,
-	}
-
-	_ = dbTrx.GetGateLogDAO().Insert(ctx, logEntry)
+		ID:           pubEntity.MakeUUID(string(cred.ID), string(action), now.String()),
+		QRCode:       truncateCode(qrCode),
+		ScannedBy:    identity.ScannedBy,
+		Action:       action,
+		Success:      success,
+		Message:      message,
+		GateName:     identity.GateName,
+		ZoneID:       zoneID,
+		DeviceID:     identity.deviceIDPtr(),
+		ScanSequence: cred.ScanCount,
+		CreatedAt:    now,
+	}
+	cred.attach(&logEntry)
+
+	if err := dbTrx.GetGateLogDAO().Insert(ctx, &logEntry); err != nil {
+		return fmt.Errorf("gagal menyimpan gate log: %v", err)
+	}
+
+	if err := dbTrx.GetSqlTx().Commit(); err != nil {
+		return err
+	}
+
+	s.feed.Publish(logEntry)
+	return nil
 }
 
-func (s *scanService) GetGateLogs(ctx context.Context, eventID string, limit int) ([]GateLogInfo, error) {
+// GetGateLogs mengambil log terbaru lebih dulu; before adalah seq log terakhir halaman sebelumnya
+func (s *scanService) GetGateLogs(ctx context.Context, eventID string, limit int, before int64) ([]GateLogInfo, error) {
 	dbTrx := dao.NewTransactionGate(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
 
 	logs, err := dbTrx.GetGateLogDAO().Search(ctx, app_gate_log.GateLogQuery{
-		EventIDs: []string{eventID},
+		EventIDs:  []string{eventID},
+		BeforeSeq: before,
+		Limit:     limit,
+		Latest:    true,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	if limit > 0 && len(logs) > limit {
-		logs = logs[:limit]
-	}
-
-	var result []GateLogInfo
+	result := make([]GateLogInfo, 0, len(logs))
 	for _, l := range logs {
-		result = append(result, GateLogInfo{
-			ID:               string(l.ID),
-			EventID:          string(l.EventID),
-			PhysicalTicketID: string(l.PhysicalTicketID),
-			Action:           string(l.Action),
-			Success:          l.Success,
-			Message:          l.Message,
-			GateName:         l.GateName,
-			TicketType:       l.TicketType,
-			ScanSequence:     l.ScanSequence,
-			CreatedAt:        l.CreatedAt.Format("2006-01-02 15:04:05"),
-		})
+		result = append(result, makeGateLogInfo(l))
 	}
 
 	return result, nil
 }
+
+func makeGateLogInfo(l app_gate_log.GateLog) GateLogInfo {
+	return GateLogInfo{
+		ID:               string(l.ID),
+		Seq:              l.Seq,
+		EventID:          derefUUID(l.EventID),
+		PhysicalTicketID: derefUUID(l.PhysicalTicketID),
+		ETicketID:        derefUUID(l.ETicketID),
+		QRCode:           derefString(l.QRCode),
+		Action:           string(l.Action),
+		Success:          l.Success,
+		Message:          l.Message,
+		GateName:         l.GateName,
+		ZoneID:           derefUUID(l.ZoneID),
+		TicketType:       l.TicketType,
+		ScanSequence:     l.ScanSequence,
+		CreatedAt:        l.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func derefUUID(id *pubEntity.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return string(*id)
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func maxScanFor(config *app_gate_config.GateConfig, ticketType string) int {
+	maxScan := config.MaxScanPerTicket
+	if config.MaxScanByType != nil {
+		if override, ok := config.MaxScanByType[ticketType]; ok {
+			maxScan = override
+		}
+	}
+	return maxScan
+}
+
+// nextScanAction menentukan aksi scan berikutnya; dipakai scan online dan replay scan offline
+func nextScanAction(config *app_gate_config.GateConfig, scanCount int) (app_gate_log.GateLogAction, app_physical_ticket.PhysicalTicketStatus) {
+	if config.Mode == app_gate_config.GateModeCheckInOut && scanCount > 0 {
+		return app_gate_log.GateLogActionCheckOut, app_physical_ticket.PhysicalTicketStatusCheckedOut
+	}
+	return app_gate_log.GateLogActionCheckIn, app_physical_ticket.PhysicalTicketStatusCheckedIn
+}
@@ -0,0 +1,622 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"rakit-tiket-be/internal/app/app_checkin/dao"
+	"rakit-tiket-be/internal/pkg/qrsign"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	app_gate_log "rakit-tiket-be/pkg/entity/app_gate_log"
+	orderEntity "rakit-tiket-be/pkg/entity/app_order"
+	app_physical_ticket "rakit-tiket-be/pkg/entity/app_physical_ticket"
+	"rakit-tiket-be/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+const (
+	maxSyncBatchSize = 1000
+
+	// Waktu perangkat yang lebih maju dari ini dianggap jam perangkat salah dan diganti waktu server
+	maxClientClockSkew = 5 * time.Minute
+)
+
+var (
+	ErrSyncBatchTooLarge  = fmt.Errorf("maksimal %d scan per sync", maxSyncBatchSize)
+	ErrInvalidSyncRequest = errors.New("event_id, device_id dan client_scan_id wajib diisi")
+)
+
+type SyncService interface {
+	GetManifest(ctx context.Context, eventID string, identity GateIdentity) (*GateManifest, error)
+	SyncScans(ctx context.Context, req SyncScansRequest, identity GateIdentity) (*SyncScansResult, error)
+}
+
+// GateManifest adalah data yang dibutuhkan perangkat gate untuk memvalidasi scan tanpa koneksi
+type GateManifest struct {
+	EventID          string             `json:"event_id"`
+	GeneratedAt      time.Time          `json:"generated_at"`
+	Mode             string             `json:"mode"`
+	MaxScanPerTicket int                `json:"max_scan_per_ticket"`
+	MaxScanByType    map[string]int     `json:"max_scan_by_type"`
+	IsActive         bool               `json:"is_active"`
+	AllowUnsigned    bool               `json:"allow_unsigned"`
+	PublicKeys       []qrsign.PublicKey `json:"public_keys"`
+	ZoneLayout       *ZoneLayout        `json:"zone_layout"` // perangkat menolak ticket type yang tidak boleh lewat gate-nya
+	Tickets          []ManifestTicket   `json:"tickets"`
+
+	// Jadwal masuk, batas scan harian dan jeda re-entry untuk validasi offline
+	EntryRuleConfig
+}
+
+// ManifestTicket berisi tiket fisik dan e-ticket order yang sudah lunas. Untuk e-ticket, qr_code
+// adalah kode e-ticket yang bisa diketik manual; QR bertanda tangan dicocokkan lewat id dan seat_number.
+// Untuk tiket fisik, serial adalah kode yang bisa diketik manual.
+type ManifestTicket struct {
+	ID             string `json:"id"`
+	CredentialType string `json:"credential_type"`
+	QRCode         string `json:"qr_code"`
+	Serial         string `json:"serial,omitempty"`
+	TicketType     string `json:"ticket_type"`
+	Status         string `json:"status"`
+	ScanCount      int    `json:"scan_count"`
+	SeatNumber     int    `json:"seat_number,omitempty"`
+}
+
+type OfflineScan struct {
+	ClientScanID string    `json:"client_scan_id"`
+	QRCode       string    `json:"qr_code"`
+	ScannedAt    time.Time `json:"scanned_at"`
+	// Accepted berarti perangkat meloloskan pemegang tiket; scan yang ditolak perangkat hanya dicatat
+	Accepted bool `json:"accepted"`
+}
+
+// SyncScansRequest tidak membawa gate_name / scanned_by; keduanya diambil dari identitas terautentikasi.
+// Untuk perangkat scanner terdaftar, device_id juga diambil dari perangkat.
+type SyncScansRequest struct {
+	EventID  string        `json:"event_id"`
+	DeviceID string        `json:"device_id"`
+	Scans    []OfflineScan `json:"scans"`
+}
+
+type SyncScanResult struct {
+	ClientScanID  string `json:"client_scan_id"`
+	Action        string `json:"action"`
+	Success       bool   `json:"success"`
+	Message       string `json:"message"`
+	ScanSequence  int    `json:"scan_sequence"`
+	AlreadySynced bool   `json:"already_synced"`
+}
+
+type SyncScansResult struct {
+	Received     int              `json:"received"`
+	Accepted     int              `json:"accepted"`
+	Duplicates   int              `json:"duplicates"`
+	Invalid      int              `json:"invalid"`
+	Denied       int              `json:"denied"`       // ACCESS_DENIED: ticket type tidak boleh lewat gate perangkat
+	Restricted   int              `json:"restricted"`   // OUTSIDE_WINDOW / COOLDOWN: di luar jadwal masuk atau jeda re-entry
+	Reclassified []string         `json:"reclassified"` // ID gate_logs lama yang hasilnya berubah setelah digabung
+	Results      []SyncScanResult `json:"results"`
+}
+
+type syncService struct {
+	log      util.LogUtil
+	sqlDB    *sql.DB
+	qrSigner qrsign.Signer
+	feed     GateFeed
+}
+
+func MakeSyncService(log util.LogUtil, sqlDB *sql.DB, qrSigner qrsign.Signer, feed GateFeed) SyncService {
+	return &syncService{
+		log:      log,
+		sqlDB:    sqlDB,
+		qrSigner: qrSigner,
+		feed:     feed,
+	}
+}
+
+func (s *syncService) GetManifest(ctx context.Context, eventID string, identity GateIdentity) (*GateManifest, error) {
+	if !identity.CanAccessEvent(pubEntity.UUID(eventID)) {
+		return nil, ErrEventNotAssigned
+	}
+
+	dbTrx := dao.NewTransactionGate(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	config, err := loadGateConfig(ctx, dbTrx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	tickets, err := dbTrx.GetPhysicalTicketDAO().Search(ctx, app_physical_ticket.PhysicalTicketQuery{
+		EventIDs: []string{eventID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	eTickets, err := dbTrx.GetETicketDAO().Search(ctx, orderEntity.ETicketQuery{
+		EventIDs: []string{eventID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	zones, err := loadZoneAccess(ctx, dbTrx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &GateManifest{
+		EventID:          eventID,
+		GeneratedAt:      time.Now(),
+		Mode:             string(config.Mode),
+		MaxScanPerTicket: config.MaxScanPerTicket,
+		MaxScanByType:    config.MaxScanByType,
+		IsActive:         config.IsActive,
+		EntryRuleConfig:  makeEntryRuleConfig(config),
+		AllowUnsigned:    s.qrSigner.AllowUnsigned(),
+		PublicKeys:       s.qrSigner.PublicKeys(),
+		ZoneLayout:       zones.layout(eventID),
+		Tickets:          make([]ManifestTicket, 0, len(tickets)+len(eTickets)),
+	}
+
+	for _, t := range tickets {
+		manifest.Tickets = append(manifest.Tickets, ManifestTicket{
+			ID:             string(t.ID),
+			CredentialType: string(CredentialPhysicalTicket),
+			QRCode:         t.QRCode,
+			Serial:         derefString(t.Serial),
+			TicketType:     t.TicketType,
+			Status:         string(t.Status),
+			ScanCount:      t.ScanCount,
+		})
+	}
+
+	for _, t := range eTickets {
+		if t.PaymentStatus != "paid" {
+			continue
+		}
+		manifest.Tickets = append(manifest.Tickets, ManifestTicket{
+			ID:             string(t.ID),
+			CredentialType: string(CredentialETicket),
+			QRCode:         t.Code,
+			TicketType:     t.TicketType,
+			Status:         string(t.Status),
+			ScanCount:      t.ScanCount,
+			SeatNumber:     t.SeatNumber,
+		})
+	}
+
+	return manifest, nil
+}
+
+// syncEntry adalah satu scan yang diputar ulang untuk sebuah tiket, baik dari gate_logs maupun batch baru
+type syncEntry struct {
+	logEntry  app_gate_log.GateLog
+	isNew     bool
+	scannedAt time.Time
+	deviceID  string
+	clientID  string
+}
+
+// SyncScans menggabungkan scan offline ke physical_tickets dan gate_logs.
+// Semua scan yang meloloskan pemegang tiket diputar ulang per tiket berdasarkan urutan
+// (scanned_at, device_id, client_scan_id), sehingga hasil akhirnya sama apa pun urutan
+// perangkat melakukan sync. Scan yang melewati batas scan ditandai DUPLICATE, scan yang masuk
+// kembali sebelum jeda re-entry ditandai COOLDOWN, dan scan di luar jadwal masuk OUTSIDE_WINDOW.
+func (s *syncService) SyncScans(ctx context.Context, req SyncScansRequest, identity GateIdentity) (*SyncScansResult, error) {
+	// Perangkat terdaftar tidak boleh mengaku sebagai perangkat lain: device_id dari body diabaikan
+	if identity.DeviceID != "" {
+		req.DeviceID = identity.DeviceID
+	}
+	if req.EventID == "" || req.DeviceID == "" {
+		return nil, ErrInvalidSyncRequest
+	}
+	if !identity.CanAccessEvent(pubEntity.UUID(req.EventID)) {
+		return nil, ErrEventNotAssigned
+	}
+	if len(req.Scans) > maxSyncBatchSize {
+		return nil, ErrSyncBatchTooLarge
+	}
+
+	now := time.Now()
+	scans := make([]OfflineScan, 0, len(req.Scans))
+	seen := make(map[string]bool)
+	for _, scan := range req.Scans {
+		if scan.ClientScanID == "" {
+			return nil, ErrInvalidSyncRequest
+		}
+		if seen[scan.ClientScanID] {
+			continue
+		}
+		seen[scan.ClientScanID] = true
+
+		if scan.ScannedAt.IsZero() || scan.ScannedAt.After(now.Add(maxClientClockSkew)) {
+			scan.ScannedAt = now
+		}
+		scans = append(scans, scan)
+	}
+
+	result := &SyncScansResult{
+		Received:     len(scans),
+		Reclassified: []string{},
+		Results:      make([]SyncScanResult, 0, len(scans)),
+	}
+	if len(scans) == 0 {
+		return result, nil
+	}
+
+	dbTrx := dao.NewTransactionGate(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	config, err := loadGateConfig(ctx, dbTrx, req.EventID)
+	if err != nil {
+		return nil, err
+	}
+	rules := newEntryRules(config)
+
+	zones, err := loadZoneAccess(ctx, dbTrx, req.EventID)
+	if err != nil {
+		return nil, err
+	}
+
+	clientIDs := make([]string, 0, len(scans))
+	for _, scan := range scans {
+		clientIDs = append(clientIDs, scan.ClientScanID)
+	}
+
+	// Unggahan ulang batch yang sama tidak diproses dua kali
+	syncedLogs, err := dbTrx.GetGateLogDAO().Search(ctx, app_gate_log.GateLogQuery{
+		DeviceIDs:     []string{req.DeviceID},
+		ClientScanIDs: clientIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	syncedMap := make(map[string]app_gate_log.GateLog)
+	for _, l := range syncedLogs {
+		if l.ClientScanID != nil {
+			syncedMap[*l.ClientScanID] = l
+		}
+	}
+
+	// Tanda tangan QR diverifikasi sebelum lookup ke DB
+	credByScan := make(map[string]*gateCredential)
+	invalidByScan := make(map[string]string)
+	claimedEventByScan := make(map[string]*pubEntity.UUID)
+	var refs []credentialRef
+	var refScanIDs []string
+	for _, scan := range scans {
+		if _, ok := syncedMap[scan.ClientScanID]; ok {
+			continue
+		}
+
+		ref, claimedEventID, reason := verifyCredential(s.qrSigner, scan.QRCode)
+		switch {
+		case ref == nil:
+			invalidByScan[scan.ClientScanID] = reason
+			claimedEventByScan[scan.ClientScanID] = claimedEventID
+		case ref.Signed && string(ref.EventID) != req.EventID:
+			invalidByScan[scan.ClientScanID] = "QR bukan tiket event ini"
+			claimedEventByScan[scan.ClientScanID] = &ref.EventID
+		default:
+			refs = append(refs, *ref)
+			refScanIDs = append(refScanIDs, scan.ClientScanID)
+		}
+	}
+
+	lookups, err := lockCredentials(ctx, dbTrx, refs, s.qrSigner.AllowUnsigned())
+	if err != nil {
+		return nil, err
+	}
+
+	creds := make(map[pubEntity.UUID]*gateCredential)
+	var physicalIDs, eTicketIDs []string
+	for i, lookup := range lookups {
+		clientScanID := refScanIDs[i]
+		switch {
+		case lookup.cred == nil:
+			invalidByScan[clientScanID] = lookup.reason
+		case string(lookup.cred.EventID) != req.EventID:
+			invalidByScan[clientScanID] = "Tiket bukan untuk event ini"
+		default:
+			credByScan[clientScanID] = lookup.cred
+			if _, ok := creds[lookup.cred.ID]; ok {
+				continue
+			}
+			creds[lookup.cred.ID] = lookup.cred
+			if lookup.cred.Type == CredentialPhysicalTicket {
+				physicalIDs = append(physicalIDs, string(lookup.cred.ID))
+			} else {
+				eTicketIDs = append(eTicketIDs, string(lookup.cred.ID))
+			}
+		}
+	}
+
+	existingByTicket, err := s.admissionLogs(ctx, dbTrx, creds, physicalIDs, eTicketIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	newLogs := make(map[string]app_gate_log.GateLog)
+	entriesByTicket := make(map[pubEntity.UUID][]syncEntry)
+
+	for _, scan := range scans {
+		if _, ok := syncedMap[scan.ClientScanID]; ok {
+			continue
+		}
+
+		logEntry := s.newSyncLog(req, scan, identity)
+
+		cred := credByScan[scan.ClientScanID]
+		zoneReason := ""
+		if cred != nil {
+			cred.attach(&logEntry)
+			logEntry.ZoneID, zoneReason = zones.check(identity.GateName, cred.TicketType)
+		}
+
+		if reason, invalid := invalidByScan[scan.ClientScanID]; invalid {
+			logEntry.Action = app_gate_log.GateLogActionInvalid
+			logEntry.Message = reason
+			if claimed := claimedEventByScan[scan.ClientScanID]; claimed != nil {
+				logEntry.EventID = claimed
+			}
+		} else if reason := cred.rejectReason(); reason != "" {
+			logEntry.Action = app_gate_log.GateLogActionInvalid
+			logEntry.Message = reason
+		} else if zoneReason != "" {
+			logEntry.Action = app_gate_log.GateLogActionAccessDenied
+			logEntry.Message = zoneReason
+		} else if reason := rules.windowReason(cred.TicketType, scan.ScannedAt); reason != "" {
+			logEntry.Action = app_gate_log.GateLogActionOutsideWindow
+			logEntry.Message = reason
+		}
+
+		if logEntry.Action == "" && !scan.Accepted {
+			logEntry.Action = app_gate_log.GateLogActionExceeded
+			logEntry.Message = "Ditolak di perangkat saat offline"
+		}
+
+		if logEntry.Action != "" {
+			newLogs[scan.ClientScanID] = logEntry
+			continue
+		}
+
+		entriesByTicket[cred.ID] = append(entriesByTicket[cred.ID], syncEntry{
+			logEntry:  logEntry,
+			isNew:     true,
+			scannedAt: scan.ScannedAt,
+			deviceID:  req.DeviceID,
+			clientID:  scan.ClientScanID,
+		})
+	}
+
+	for ticketID, newEntries := range entriesByTicket {
+		cred := creds[ticketID]
+
+		entries := newEntries
+		admittedBefore := 0
+		for _, l := range existingByTicket[ticketID] {
+			if l.Action == app_gate_log.GateLogActionCheckIn || l.Action == app_gate_log.GateLogActionCheckOut {
+				admittedBefore++
+			}
+
+			entry := syncEntry{logEntry: l, scannedAt: l.CreatedAt}
+			if l.ScannedAt != nil {
+				entry.scannedAt = *l.ScannedAt
+			}
+			if l.DeviceID != nil {
+				entry.deviceID = *l.DeviceID
+			}
+			if l.ClientScanID != nil {
+				entry.clientID = *l.ClientScanID
+			}
+			entries = append(entries, entry)
+		}
+
+		sort.SliceStable(entries, func(i, j int) bool {
+			a, b := entries[i], entries[j]
+			if !a.scannedAt.Equal(b.scannedAt) {
+				return a.scannedAt.Before(b.scannedAt)
+			}
+			if a.deviceID != b.deviceID {
+				return a.deviceID < b.deviceID
+			}
+			if a.clientID != b.clientID {
+				return a.clientID < b.clientID
+			}
+			return a.logEntry.ID < b.logEntry.ID
+		})
+
+		// Scan yang tidak punya log (mis. sebelum audit trail ada) tetap dihitung sebagai dasar
+		baseCount := cred.ScanCount - admittedBefore
+		if baseCount < 0 {
+			baseCount = 0
+		}
+		admissions := &ticketAdmissions{rules: rules, total: baseCount}
+		maxScan := maxScanFor(config, cred.TicketType)
+
+		for _, entry := range entries {
+			previous := entry.logEntry
+
+			l := entry.logEntry
+			scanCount := admissions.count(entry.scannedAt)
+			action, status := nextScanAction(config, scanCount)
+
+			cooldownReason := ""
+			if action == app_gate_log.GateLogActionCheckIn {
+				cooldownReason = admissions.cooldownReason(entry.scannedAt)
+			}
+
+			switch {
+			case scanCount >= maxScan:
+				l.Action = app_gate_log.GateLogActionDuplicate
+				l.Success = false
+				l.Message = "Tiket sudah di-scan di gate lain"
+				if rules.daily {
+					l.Message = admissions.exceededMessage(maxScan)
+				}
+			case cooldownReason != "":
+				l.Action = app_gate_log.GateLogActionCooldown
+				l.Success = false
+				l.Message = cooldownReason
+			default:
+				admissions.record(entry.scannedAt)
+				cred.recordAdmission(action, status, entry.scannedAt)
+
+				l.Action = action
+				l.Success = true
+				if previous.Action != action || entry.isNew {
+					l.Message = fmt.Sprintf("%s berhasil", action)
+				}
+			}
+			l.ScanSequence = admissions.total
+
+			if entry.isNew {
+				newLogs[entry.clientID] = l
+				continue
+			}
+
+			if l.Action != previous.Action || l.Success != previous.Success || l.ScanSequence != previous.ScanSequence {
+				if err := dbTrx.GetGateLogDAO().Update(ctx, l); err != nil {
+					return nil, fmt.Errorf("gagal update gate log: %v", err)
+				}
+				if l.Action != previous.Action {
+					result.Reclassified = append(result.Reclassified, string(l.ID))
+				}
+			}
+		}
+
+		cred.ScanCount = admissions.total
+		if err := cred.save(ctx, dbTrx); err != nil {
+			return nil, fmt.Errorf("gagal update ticket: %v", err)
+		}
+	}
+
+	inserted := make(app_gate_log.GateLogs, 0, len(newLogs))
+	for _, scan := range scans {
+		if synced, ok := syncedMap[scan.ClientScanID]; ok {
+			result.Results = append(result.Results, SyncScanResult{
+				ClientScanID:  scan.ClientScanID,
+				Action:        string(synced.Action),
+				Success:       synced.Success,
+				Message:       synced.Message,
+				ScanSequence:  synced.ScanSequence,
+				AlreadySynced: true,
+			})
+			continue
+		}
+
+		logEntry := newLogs[scan.ClientScanID]
+		if err := dbTrx.GetGateLogDAO().Insert(ctx, &logEntry); err != nil {
+			return nil, fmt.Errorf("gagal menyimpan gate log: %v", err)
+		}
+		inserted = append(inserted, logEntry)
+
+		switch {
+		case logEntry.Success:
+			result.Accepted++
+		case logEntry.Action == app_gate_log.GateLogActionDuplicate:
+			result.Duplicates++
+		case logEntry.Action == app_gate_log.GateLogActionInvalid:
+			result.Invalid++
+		case logEntry.Action == app_gate_log.GateLogActionAccessDenied:
+			result.Denied++
+		case logEntry.Action == app_gate_log.GateLogActionOutsideWindow, logEntry.Action == app_gate_log.GateLogActionCooldown:
+			result.Restricted++
+		}
+
+		result.Results = append(result.Results, SyncScanResult{
+			ClientScanID: scan.ClientScanID,
+			Action:       string(logEntry.Action),
+			Success:      logEntry.Success,
+			Message:      logEntry.Message,
+			ScanSequence: logEntry.ScanSequence,
+		})
+	}
+
+	if err := dbTrx.GetSqlTx().Commit(); err != nil {
+		return nil, err
+	}
+
+	s.feed.Publish(inserted...)
+
+	s.log.Info(ctx, "Gate scans synced",
+		zap.String("event_id", req.EventID),
+		zap.String("device_id", req.DeviceID),
+		zap.Int("received", result.Received),
+		zap.Int("accepted", result.Accepted),
+		zap.Int("duplicates", result.Duplicates),
+		zap.Int("reclassified", len(result.Reclassified)),
+	)
+
+	return result, nil
+}
+
+// admissionLogs mengambil log scan yang ikut diputar ulang, dikelompokkan per tiket fisik / e-ticket.
+// Log sebelum reset scan tiket diabaikan agar hitungan lama tidak kembali setelah sync.
+func (s *syncService) admissionLogs(ctx context.Context, dbTrx dao.DBTransaction, creds map[pubEntity.UUID]*gateCredential, physicalIDs, eTicketIDs []string) (map[pubEntity.UUID][]app_gate_log.GateLog, error) {
+	actions := []string{
+		string(app_gate_log.GateLogActionCheckIn),
+		string(app_gate_log.GateLogActionCheckOut),
+		string(app_gate_log.GateLogActionDuplicate),
+		string(app_gate_log.GateLogActionCooldown),
+	}
+
+	logsByTicket := make(map[pubEntity.UUID][]app_gate_log.GateLog)
+
+	if len(physicalIDs) > 0 {
+		logs, err := dbTrx.GetGateLogDAO().Search(ctx, app_gate_log.GateLogQuery{
+			PhysicalTicketIDs: physicalIDs,
+			Actions:           actions,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range logs {
+			if l.PhysicalTicketID != nil && creds[*l.PhysicalTicketID].countsLog(l) {
+				logsByTicket[*l.PhysicalTicketID] = append(logsByTicket[*l.PhysicalTicketID], l)
+			}
+		}
+	}
+
+	if len(eTicketIDs) > 0 {
+		logs, err := dbTrx.GetGateLogDAO().Search(ctx, app_gate_log.GateLogQuery{
+			ETicketIDs: eTicketIDs,
+			Actions:    actions,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range logs {
+			if l.ETicketID != nil && creds[*l.ETicketID].countsLog(l) {
+				logsByTicket[*l.ETicketID] = append(logsByTicket[*l.ETicketID], l)
+			}
+		}
+	}
+
+	return logsByTicket, nil
+}
+
+func (s *syncService) newSyncLog(req SyncScansRequest, scan OfflineScan, identity GateIdentity) app_gate_log.GateLog {
+	eventID := pubEntity.UUID(req.EventID)
+	deviceID := req.DeviceID
+	clientScanID := scan.ClientScanID
+	scannedAt := scan.ScannedAt
+
+	return app_gate_log.GateLog{
+		ID:           pubEntity.MakeUUID("GATE_SYNC", deviceID, clientScanID),
+		EventID:      &eventID,
+		QRCode:       truncateCode(scan.QRCode),
+		ScannedBy:    identity.ScannedBy,
+		GateName:     identity.GateName,
+		DeviceID:     &deviceID,
+		ClientScanID: &clientScanID,
+		ScannedAt:    &scannedAt,
+	}
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"rakit-tiket-be/internal/app/app_checkin/dao"
+	"rakit-tiket-be/internal/pkg/ticketcode"
+	app_physical_ticket "rakit-tiket-be/pkg/entity/app_physical_ticket"
+)
+
+const serialLookupChunk = 1000
+
+// allocateSerials membuat n kode tiket acak yang belum dipakai di event. Tabrakan (sangat jarang
+// dengan 50 bit acak) dibuat ulang; unique index (event_id, serial) menjaga generate yang berjalan bersamaan.
+func allocateSerials(ctx context.Context, dbTrx dao.DBTransaction, eventID string, n int) ([]string, error) {
+	serials := make([]string, 0, n)
+	used := make(map[string]bool)
+
+	for attempt := 0; len(serials) < n; attempt++ {
+		if attempt >= 5 {
+			return nil, fmt.Errorf("gagal membuat kode tiket unik untuk event %s", eventID)
+		}
+
+		var candidates []string
+		for len(serials)+len(candidates) < n {
+			code, err := ticketcode.Generate()
+			if err != nil {
+				return nil, fmt.Errorf("gagal membuat kode tiket: %v", err)
+			}
+			if used[code] {
+				continue
+			}
+			used[code] = true
+			candidates = append(candidates, code)
+		}
+
+		taken := make(map[string]bool)
+		for start := 0; start < len(candidates); start += serialLookupChunk {
+			end := start + serialLookupChunk
+			if end > len(candidates) {
+				end = len(candidates)
+			}
+
+			existing, err := dbTrx.GetPhysicalTicketDAO().Search(ctx, app_physical_ticket.PhysicalTicketQuery{
+				EventIDs: []string{eventID},
+				Serials:  candidates[start:end],
+			})
+			if err != nil {
+				return nil, err
+			}
+			for _, t := range existing {
+				taken[derefString(t.Serial)] = true
+			}
+		}
+
+		for _, code := range candidates {
+			if !taken[code] {
+				serials = append(serials, code)
+			}
+		}
+	}
+
+	return serials, nil
+}
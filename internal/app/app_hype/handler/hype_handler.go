@@ -5,6 +5,7 @@ import (
 
 	"rakit-tiket-be/internal/app/app_hype/service"
 	"rakit-tiket-be/internal/pkg/middleware"
+	presaleEntity "rakit-tiket-be/pkg/entity/app_presale"
 	"rakit-tiket-be/pkg/util"
 
 	"github.com/labstack/echo/v4"
@@ -49,7 +50,7 @@ func (h *hypeHandler) getActiveTickets(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "event_id is required")
 	}
 
-	tickets, err := h.hypeService.GetActiveTickets(c.Request().Context(), eventID)
+	tickets, err := h.hypeService.GetActiveTickets(c.Request().Context(), eventID, presaleCredential(c))
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
@@ -67,7 +68,7 @@ func (h *hypeHandler) checkAvailability(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "ticket_id is required")
 	}
 
-	check, err := h.hypeService.CheckAvailability(c.Request().Context(), ticketID)
+	check, err := h.hypeService.CheckAvailability(c.Request().Context(), ticketID, presaleCredential(c))
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
@@ -78,6 +79,14 @@ func (h *hypeHandler) checkAvailability(c echo.Context) error {
 	})
 }
 
+// presaleCredential membaca ?access_code= dan ?email= untuk membuka tiket presale
+func presaleCredential(c echo.Context) presaleEntity.Credential {
+	return presaleEntity.Credential{
+		AccessCode: c.QueryParam("access_code"),
+		Email:      c.QueryParam("email"),
+	}
+}
+
 func (h *hypeHandler) setFlashSale(c echo.Context) error {
 	var req service.SetFlashSaleRequest
 	if err := c.Bind(&req); err != nil {
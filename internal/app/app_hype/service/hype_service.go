@@ -7,19 +7,24 @@ import (
 	"time"
 
 	ticketDao "rakit-tiket-be/internal/app/app_ticket/dao"
+	"rakit-tiket-be/internal/pkg/pricing"
+	presaleEntity "rakit-tiket-be/pkg/entity/app_presale"
 	ticketEntity "rakit-tiket-be/pkg/entity/app_ticket"
 	"rakit-tiket-be/pkg/util"
 )
 
 type HypeService interface {
-	GetActiveTickets(ctx context.Context, eventID string) ([]ticketEntity.TicketDisplay, error)
+	GetActiveTickets(ctx context.Context, eventID string, cred presaleEntity.Credential) ([]ticketEntity.TicketDisplay, error)
 	GetTicketDisplay(ctx context.Context, ticketID string) (*ticketEntity.TicketDisplay, error)
-	CheckAvailability(ctx context.Context, ticketID string) (*AvailabilityCheck, error)
+	CheckAvailability(ctx context.Context, ticketID string, cred presaleEntity.Credential) (*AvailabilityCheck, error)
 
 	SetFlashSale(ctx context.Context, req SetFlashSaleRequest) error
 	DisableFlashSale(ctx context.Context, ticketID string) error
 	SetCountdown(ctx context.Context, req SetCountdownRequest) error
 	SetStockAlert(ctx context.Context, req SetStockAlertRequest) error
+
+	StartScheduledFlashSales(ctx context.Context) (int64, error)
+	EndExpiredFlashSales(ctx context.Context) (int64, error)
 }
 
 type AvailabilityCheck struct {
@@ -28,6 +33,8 @@ type AvailabilityCheck struct {
 	CurrentPrice   float64  `json:"current_price"`
 	IsFlashSale    bool     `json:"is_flash_sale"`
 	FlashSalePrice *float64 `json:"flash_sale_price"`
+	ActiveTier     *string  `json:"active_tier"`
+	NextPrice      *float64 `json:"next_price"`
 }
 
 type SetFlashSaleRequest struct {
@@ -48,19 +55,26 @@ type SetStockAlertRequest struct {
 	ShowStockAlert    bool   `json:"show_stock_alert"`
 }
 
+type PresaleAccessResolver interface {
+	ResolveAccess(ctx context.Context, eventIDs []string, cred presaleEntity.Credential) (presaleEntity.Access, error)
+}
+
 type hypeService struct {
-	log   util.LogUtil
-	sqlDB *sql.DB
+	log             util.LogUtil
+	sqlDB           *sql.DB
+	presaleResolver PresaleAccessResolver
 }
 
-func MakeHypeService(log util.LogUtil, sqlDB *sql.DB) HypeService {
+func MakeHypeService(log util.LogUtil, sqlDB *sql.DB, presaleResolver PresaleAccessResolver) HypeService {
 	return &hypeService{
-		log:   log,
-		sqlDB: sqlDB,
+		log:             log,
+		sqlDB:           sqlDB,
+		presaleResolver: presaleResolver,
 	}
 }
 
-func (s *hypeService) GetActiveTickets(ctx context.Context, eventID string) ([]ticketEntity.TicketDisplay, error) {
+// GetActiveTickets menyembunyikan tiket presale yang tidak dibuka oleh credential pembeli
+func (s *hypeService) GetActiveTickets(ctx context.Context, eventID string, cred presaleEntity.Credential) ([]ticketEntity.TicketDisplay, error) {
 	dbTrx := ticketDao.NewTransactionTicket(ctx, s.log, s.sqlDB)
 
 	tickets, err := dbTrx.GetTicketDAO().Search(ctx, ticketEntity.TicketQuery{
@@ -69,6 +83,15 @@ func (s *hypeService) GetActiveTickets(ctx context.Context, eventID string) ([]t
 	if err != nil {
 		return nil, err
 	}
+	if err := ticketDao.LoadPriceTiers(ctx, dbTrx.GetPriceTierDAO(), tickets); err != nil {
+		return nil, err
+	}
+
+	access, err := s.presaleResolver.ResolveAccess(ctx, presaleEntity.PresaleEventIDs(tickets), cred)
+	if err != nil {
+		return nil, err
+	}
+	tickets = access.Filter(tickets)
 
 	var result []ticketEntity.TicketDisplay
 	for _, t := range tickets {
@@ -88,6 +111,9 @@ func (s *hypeService) GetTicketDisplay(ctx context.Context, ticketID string) (*t
 	if err != nil {
 		return nil, err
 	}
+	if err := ticketDao.LoadPriceTiers(ctx, dbTrx.GetPriceTierDAO(), tickets); err != nil {
+		return nil, err
+	}
 
 	if len(tickets) == 0 {
 		return nil, fmt.Errorf("ticket tidak ditemukan")
@@ -97,7 +123,8 @@ func (s *hypeService) GetTicketDisplay(ctx context.Context, ticketID string) (*t
 	return &display, nil
 }
 
-func (s *hypeService) CheckAvailability(ctx context.Context, ticketID string) (*AvailabilityCheck, error) {
+// CheckAvailability memperlakukan tiket presale yang belum dibuka sama dengan tiket yang tidak ada
+func (s *hypeService) CheckAvailability(ctx context.Context, ticketID string, cred presaleEntity.Credential) (*AvailabilityCheck, error) {
 	dbTrx := ticketDao.NewTransactionTicket(ctx, s.log, s.sqlDB)
 
 	tickets, err := dbTrx.GetTicketDAO().Search(ctx, ticketEntity.TicketQuery{
@@ -106,49 +133,41 @@ func (s *hypeService) CheckAvailability(ctx context.Context, ticketID string) (*
 	if err != nil {
 		return nil, err
 	}
-
-	if len(tickets) == 0 {
-		return nil, fmt.Errorf("ticket tidak ditemukan")
+	if err := ticketDao.LoadPriceTiers(ctx, dbTrx.GetPriceTierDAO(), tickets); err != nil {
+		return nil, err
 	}
 
-	ticket := tickets[0]
-	result := &AvailabilityCheck{
-		Available:    true,
-		CurrentPrice: ticket.Price,
-		IsFlashSale:  ticket.IsFlashSale,
+	access, err := s.presaleResolver.ResolveAccess(ctx, presaleEntity.PresaleEventIDs(tickets), cred)
+	if err != nil {
+		return nil, err
 	}
+	tickets = access.Filter(tickets)
 
-	if ticket.FlashSalePrice != nil {
-		result.FlashSalePrice = ticket.FlashSalePrice
+	if len(tickets) == 0 {
+		return nil, fmt.Errorf("ticket tidak ditemukan")
 	}
 
+	ticket := tickets[0]
 	now := time.Now()
+	quote := pricing.ResolvePrice(ticket, now)
 
-	if ticket.AvailableQty <= 0 {
-		result.Available = false
-		result.Reason = "SOLD_OUT"
-		return result, nil
+	result := &AvailabilityCheck{
+		Available:      true,
+		CurrentPrice:   quote.UnitPrice,
+		IsFlashSale:    quote.IsFlashSale,
+		FlashSalePrice: ticket.FlashSalePrice,
 	}
 
-	if ticket.SaleStartTime != nil && now.Before(*ticket.SaleStartTime) {
-		result.Available = false
-		result.Reason = "SALE_NOT_STARTED"
-		return result, nil
+	if active, next := pricing.ActiveTier(ticket, now); active != nil {
+		result.ActiveTier = &active.Name
+		if next != nil {
+			result.NextPrice = &next.Price
+		}
 	}
 
-	if ticket.SaleEndTime != nil && now.After(*ticket.SaleEndTime) {
+	if reason := pricing.UnavailableReason(ticket, now); reason != "" {
 		result.Available = false
-		result.Reason = "SALE_ENDED"
-		return result, nil
-	}
-
-	if ticket.IsFlashSale && ticket.FlashStartTime != nil && ticket.FlashEndTime != nil {
-		if now.Before(*ticket.FlashStartTime) || now.After(*ticket.FlashEndTime) {
-			result.CurrentPrice = ticket.Price
-			result.IsFlashSale = false
-		} else if ticket.FlashSalePrice != nil {
-			result.CurrentPrice = *ticket.FlashSalePrice
-		}
+		result.Reason = reason
 	}
 
 	return result, nil
@@ -181,12 +200,24 @@ func (s *hypeService) computeDisplay(t ticketEntity.Ticket) ticketEntity.TicketD
 		display.FlashEndTime = &formatted
 	}
 
-	isOnFlashSale := t.IsFlashSale && t.FlashStartTime != nil && t.FlashEndTime != nil &&
-		now.After(*t.FlashStartTime) && now.Before(*t.FlashEndTime)
+	quote := pricing.ResolvePrice(t, now)
+	isOnFlashSale := quote.IsFlashSale
 	display.IsOnFlashSale = isOnFlashSale
-
-	if isOnFlashSale && t.FlashSalePrice != nil {
-		display.CurrentPrice = *t.FlashSalePrice
+	display.CurrentPrice = quote.UnitPrice
+	display.OriginalPrice = quote.OriginalPrice
+
+	// Tier group: tampilkan tier aktif, kapan tier berakhir, dan harga tier berikutnya
+	if active, next := pricing.ActiveTier(t, now); active != nil {
+		display.ActiveTierName = &active.Name
+		display.TierRemaining = pricing.TierRemaining(t, *active)
+		if active.EndAt != nil {
+			formatted := active.EndAt.Format("2006-01-02T15:04:05Z07:00")
+			display.TierEndsAt = &formatted
+		}
+		if next != nil {
+			display.NextTierName = &next.Name
+			display.NextPrice = &next.Price
+		}
 	}
 
 	threshold := 20
@@ -218,15 +249,9 @@ func (s *hypeService) computeDisplay(t ticketEntity.Ticket) ticketEntity.TicketD
 	}
 
 	display.IsAvailable = true
-	if t.AvailableQty <= 0 {
-		display.IsAvailable = false
-		display.UnAvailableReason = ptrString("SOLD_OUT")
-	} else if t.SaleStartTime != nil && now.Before(*t.SaleStartTime) {
-		display.IsAvailable = false
-		display.UnAvailableReason = ptrString("SALE_NOT_STARTED")
-	} else if t.SaleEndTime != nil && now.After(*t.SaleEndTime) {
+	if reason := pricing.UnavailableReason(t, now); reason != "" {
 		display.IsAvailable = false
-		display.UnAvailableReason = ptrString("SALE_ENDED")
+		display.UnAvailableReason = ptrString(reason)
 	}
 
 	if display.IsAvailable && t.ShowCountdown {
@@ -250,6 +275,9 @@ func (s *hypeService) computeDisplay(t ticketEntity.Ticket) ticketEntity.TicketD
 	} else if isOnFlashSale {
 		msg := "Flash sale! Harga spesial!"
 		display.UrgentMessage = &msg
+	} else if display.NextPrice != nil && display.TierRemaining != nil && *display.TierRemaining > 0 && *display.TierRemaining <= 10 {
+		msg := fmt.Sprintf("Sisa %d tiket %s sebelum harga naik!", *display.TierRemaining, *display.ActiveTierName)
+		display.UrgentMessage = &msg
 	} else if display.CountdownSeconds != nil && *display.CountdownSeconds < 3600 {
 		msg := "Segera berakhir!"
 		display.UrgentMessage = &msg
@@ -369,3 +397,97 @@ func (s *hypeService) SetStockAlert(ctx context.Context, req SetStockAlertReques
 
 	return dbTrx.GetSqlTx().Commit()
 }
+
+// StartScheduledFlashSales menyalakan countdown untuk flash sale yang sudah masuk jadwal
+func (s *hypeService) StartScheduledFlashSales(ctx context.Context) (int64, error) {
+	dbTrx := ticketDao.NewTransactionTicket(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	isFlashSale := true
+	tickets, err := dbTrx.GetTicketDAO().SearchForUpdate(ctx, ticketEntity.TicketQuery{
+		IsFlashSale: &isFlashSale,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var started ticketEntity.Tickets
+	for _, ticket := range tickets {
+		if ticket.FlashEndTime == nil || !ticket.FlashEndTime.After(now) {
+			continue
+		}
+		if ticket.FlashStartTime != nil && ticket.FlashStartTime.After(now) {
+			continue
+		}
+		if ticket.ShowCountdown && ticket.CountdownEnd != nil && ticket.CountdownEnd.Equal(*ticket.FlashEndTime) {
+			continue
+		}
+
+		ticket.ShowCountdown = true
+		ticket.CountdownEnd = ticket.FlashEndTime
+		started = append(started, ticket)
+	}
+
+	if len(started) == 0 {
+		return 0, nil
+	}
+
+	if err := dbTrx.GetTicketDAO().Update(ctx, started); err != nil {
+		return 0, err
+	}
+
+	if err := dbTrx.GetSqlTx().Commit(); err != nil {
+		return 0, err
+	}
+
+	return int64(len(started)), nil
+}
+
+// EndExpiredFlashSales mematikan flash sale yang sudah lewat flash_end_time
+func (s *hypeService) EndExpiredFlashSales(ctx context.Context) (int64, error) {
+	dbTrx := ticketDao.NewTransactionTicket(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	isFlashSale := true
+	tickets, err := dbTrx.GetTicketDAO().SearchForUpdate(ctx, ticketEntity.TicketQuery{
+		IsFlashSale: &isFlashSale,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var ended ticketEntity.Tickets
+	for _, ticket := range tickets {
+		if ticket.FlashEndTime == nil || ticket.FlashEndTime.After(now) {
+			continue
+		}
+
+		// Countdown hanya dimatikan jika memang milik flash sale ini
+		if ticket.CountdownEnd != nil && ticket.CountdownEnd.Equal(*ticket.FlashEndTime) {
+			ticket.ShowCountdown = false
+			ticket.CountdownEnd = nil
+		}
+
+		ticket.IsFlashSale = false
+		ticket.FlashSalePrice = nil
+		ticket.FlashStartTime = nil
+		ticket.FlashEndTime = nil
+		ended = append(ended, ticket)
+	}
+
+	if len(ended) == 0 {
+		return 0, nil
+	}
+
+	if err := dbTrx.GetTicketDAO().Update(ctx, ended); err != nil {
+		return 0, err
+	}
+
+	if err := dbTrx.GetSqlTx().Commit(); err != nil {
+		return 0, err
+	}
+
+	return int64(len(ended)), nil
+}
@@ -0,0 +1,34 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+
+	baseDao "rakit-tiket-be/internal/pkg/dao"
+	"rakit-tiket-be/pkg/util"
+)
+
+type DBTransaction interface {
+	baseDao.DBTransaction
+
+	GetJobRunDAO() JobRunDAO
+}
+
+type dbTransaction struct {
+	baseDao.DBTransaction
+
+	jobRunDAO JobRunDAO
+}
+
+func NewTransactionJob(ctx context.Context, log util.LogUtil, sqlDB *sql.DB) DBTransaction {
+	dbTrx := &dbTransaction{
+		DBTransaction: baseDao.NewTransaction(ctx, sqlDB),
+	}
+
+	dbTrx.jobRunDAO = MakeJobRunDAO(log, dbTrx)
+	return dbTrx
+}
+
+func (dbTrx *dbTransaction) GetJobRunDAO() JobRunDAO {
+	return dbTrx.jobRunDAO
+}
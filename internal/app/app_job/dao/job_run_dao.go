@@ -0,0 +1,255 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+
+	baseDao "rakit-tiket-be/internal/pkg/dao"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	entity "rakit-tiket-be/pkg/entity/app_job"
+	"rakit-tiket-be/pkg/util"
+
+	"gitlab.com/threetopia/sqlgo/v2"
+	"go.uber.org/zap"
+)
+
+type JobRunDAO interface {
+	Search(ctx context.Context, query entity.JobRunQuery) (entity.JobRuns, int, error)
+	Insert(ctx context.Context, jobRuns entity.JobRuns) error
+	Update(ctx context.Context, jobRuns entity.JobRuns) error
+}
+
+type jobRunDAO struct {
+	log   util.LogUtil
+	dbTrx baseDao.DBTransaction
+}
+
+func MakeJobRunDAO(log util.LogUtil, dbTrx baseDao.DBTransaction) JobRunDAO {
+	return jobRunDAO{
+		log:   log,
+		dbTrx: dbTrx,
+	}
+}
+
+func (d jobRunDAO) buildWhere(query entity.JobRunQuery) sqlgo.SQLGoWhere {
+	sqlWhere := sqlgo.NewSQLGoWhere()
+
+	if len(query.IDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "jr.id", "IN", query.IDs)
+	}
+
+	if len(query.JobNames) > 0 {
+		sqlWhere.SetSQLWhere("AND", "jr.job_name", "IN", query.JobNames)
+	}
+
+	if len(query.Statuses) > 0 {
+		sqlWhere.SetSQLWhere("AND", "jr.status", "IN", query.Statuses)
+	}
+
+	return sqlWhere
+}
+
+func (d jobRunDAO) Search(ctx context.Context, query entity.JobRunQuery) (entity.JobRuns, int, error) {
+	sqlSelect := sqlgo.NewSQLGoSelect().
+		SetSQLSelect("jr.id", "id").
+		SetSQLSelect("jr.job_name", "job_name").
+		SetSQLSelect("jr.instance", "instance").
+		SetSQLSelect("jr.status", "status").
+		SetSQLSelect("jr.started_at", "started_at").
+		SetSQLSelect("jr.finished_at", "finished_at").
+		SetSQLSelect("jr.affected_rows", "affected_rows").
+		SetSQLSelect("jr.error", "error")
+
+	sqlFrom := sqlgo.NewSQLGoFrom().
+		SetSQLFrom("job_runs", "jr")
+
+	sqlOrder := sqlgo.NewSQLGoOrder()
+	sqlOrder.SetSQLOrder("jr.started_at", "DESC")
+
+	sqlOffsetLimit := sqlgo.NewSQLGoOffsetLimit()
+	if !query.PagingQuery.NoLimit {
+		if query.PagingQuery.Page > 0 {
+			sqlOffsetLimit.SQLPageLimit(query.PagingQuery.Page.Int(), query.PagingQuery.Limit.Int())
+		} else {
+			sqlOffsetLimit.SetSQLLimit(query.PagingQuery.Limit.Int())
+		}
+	}
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoSelect(sqlSelect).
+		SetSQLGoFrom(sqlFrom).
+		SetSQLGoWhere(d.buildWhere(query)).
+		SetSQLGoOrder(sqlOrder).
+		SetSQLGoOffsetLimit(sqlOffsetLimit)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "jobRunDAO.Search",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	rows, err := d.dbTrx.GetSqlDB().QueryContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "jobRunDAO.Search",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+			zap.Error(err),
+		)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var jobRuns entity.JobRuns
+	for rows.Next() {
+		var jobRun entity.JobRun
+
+		if err := rows.Scan(
+			&jobRun.ID,
+			&jobRun.JobName,
+			&jobRun.Instance,
+			&jobRun.Status,
+			&jobRun.StartedAt,
+			&jobRun.FinishedAt,
+			&jobRun.AffectedRows,
+			&jobRun.Error,
+		); err != nil {
+			d.log.Error(ctx, "jobRunDAO.Search.Scan", zap.Error(err))
+			return nil, 0, err
+		}
+
+		jobRuns = append(jobRuns, jobRun)
+	}
+
+	totalCount, err := d.Count(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return jobRuns, totalCount, nil
+}
+
+func (d jobRunDAO) Count(ctx context.Context, query entity.JobRunQuery) (int, error) {
+	sqlSelect := sqlgo.NewSQLGoSelect()
+	sqlSelect.SetSQLSelect("COUNT(jr.id)", "count")
+
+	sqlFrom := sqlgo.NewSQLGoFrom()
+	sqlFrom.SetSQLFrom("job_runs", "jr")
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoSelect(sqlSelect).
+		SetSQLGoFrom(sqlFrom).
+		SetSQLGoWhere(d.buildWhere(query))
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "jobRunDAO.Count",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	var totalCount int
+	err := d.dbTrx.GetSqlDB().QueryRowContext(ctx, sqlStr, sqlParams...).Scan(&totalCount)
+	if err != nil {
+		d.log.Error(ctx, "jobRunDAO.Count",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+			zap.Error(err),
+		)
+		return 0, err
+	}
+
+	return totalCount, nil
+}
+
+func (d jobRunDAO) Insert(ctx context.Context, jobRuns entity.JobRuns) error {
+	if len(jobRuns) < 1 {
+		return fmt.Errorf("empty job run data")
+	}
+
+	sqlInsert := sqlgo.NewSQLGoInsert().
+		SetSQLInsert("job_runs").
+		SetSQLInsertColumn("id", "job_name", "instance", "status", "started_at", "finished_at", "affected_rows", "error")
+
+	for i, jobRun := range jobRuns {
+		if jobRun.ID == "" {
+			jobRun.ID = pubEntity.MakeUUID("JOB_RUN", jobRun.JobName, jobRun.StartedAt.String())
+		}
+
+		sqlInsert.SetSQLInsertValue(
+			jobRun.ID,
+			jobRun.JobName,
+			jobRun.Instance,
+			jobRun.Status,
+			jobRun.StartedAt,
+			jobRun.FinishedAt,
+			jobRun.AffectedRows,
+			jobRun.Error,
+		)
+
+		jobRuns[i] = jobRun
+	}
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoInsert(sqlInsert)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "jobRunDAO.Insert",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...); err != nil {
+		d.log.Error(ctx, "jobRunDAO.Insert",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return nil
+}
+
+func (d jobRunDAO) Update(ctx context.Context, jobRuns entity.JobRuns) error {
+	if len(jobRuns) < 1 {
+		return fmt.Errorf("empty job run data")
+	}
+
+	for _, jobRun := range jobRuns {
+		sql := sqlgo.NewSQLGo().
+			SetSQLSchema("public").
+			SetSQLUpdate("job_runs").
+			SetSQLUpdateValue("status", jobRun.Status).
+			SetSQLUpdateValue("finished_at", jobRun.FinishedAt).
+			SetSQLUpdateValue("affected_rows", jobRun.AffectedRows).
+			SetSQLUpdateValue("error", jobRun.Error).
+			SetSQLWhere("AND", "id", "=", jobRun.ID)
+
+		sqlStr := sql.BuildSQL()
+		sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+		d.log.Debug(ctx, "jobRunDAO.Update",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+		)
+
+		if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...); err != nil {
+			d.log.Error(ctx, "jobRunDAO.Update",
+				zap.String("SQL", sqlStr),
+				zap.Any("Params", sqlParams),
+				zap.Error(err),
+			)
+			return err
+		}
+	}
+
+	return nil
+}
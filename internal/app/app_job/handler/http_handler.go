@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"rakit-tiket-be/internal/app/app_job/service"
+	"rakit-tiket-be/internal/pkg/middleware"
+	"rakit-tiket-be/pkg/util"
+
+	"github.com/labstack/echo/v4"
+)
+
+type HttpHandler interface {
+	RegisterRoute(g *echo.Group)
+}
+
+type httpHandler struct {
+	jobHandler JobHandler
+}
+
+func MakeHttpAdapter(log util.LogUtil, jobService service.JobService, authMiddleware middleware.AuthMiddleware) HttpHandler {
+	return httpHandler{
+		jobHandler: MakeJobHandler(log, jobService, authMiddleware),
+	}
+}
+
+func (h httpHandler) RegisterRoute(g *echo.Group) {
+	h.jobHandler.RegisterRouter(g)
+}
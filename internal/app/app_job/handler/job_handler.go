@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+
+	"rakit-tiket-be/internal/app/app_job/service"
+	"rakit-tiket-be/internal/pkg/middleware"
+	entity "rakit-tiket-be/pkg/entity/app_job"
+	"rakit-tiket-be/pkg/util"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type JobHandler interface {
+	RegisterRouter(g *echo.Group)
+}
+
+type jobHandler struct {
+	log            util.LogUtil
+	jobService     service.JobService
+	authMiddleware middleware.AuthMiddleware
+}
+
+func MakeJobHandler(log util.LogUtil, jobService service.JobService, authMiddleware middleware.AuthMiddleware) JobHandler {
+	return &jobHandler{
+		log:            log,
+		jobService:     jobService,
+		authMiddleware: authMiddleware,
+	}
+}
+
+func (h *jobHandler) RegisterRouter(g *echo.Group) {
+	admin := g.Group("/v1/admin")
+	admin.Use(h.authMiddleware.VerifyToken)
+	admin.Use(h.authMiddleware.RequireAdmin)
+
+	admin.GET("/jobs/runs", h.getJobRuns)
+}
+
+func (h *jobHandler) getJobRuns(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var query entity.JobRunQuery
+	if err := c.Bind(&query); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid query parameters")
+	}
+
+	jobRuns, total, err := h.jobService.GetJobRuns(ctx, query)
+	if err != nil {
+		h.log.Error(ctx, "getJobRuns error", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to get job runs")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    jobRuns,
+		"total":   total,
+		"page":    query.PagingQuery.Page,
+		"limit":   query.PagingQuery.Limit,
+	})
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"rakit-tiket-be/internal/app/app_job/dao"
+	entity "rakit-tiket-be/pkg/entity/app_job"
+	"rakit-tiket-be/pkg/util"
+)
+
+const defaultJobRunLimit = 50
+
+type JobService interface {
+	GetJobRuns(ctx context.Context, query entity.JobRunQuery) (entity.JobRuns, int, error)
+
+	StartRun(ctx context.Context, jobName string, instance string) (*entity.JobRun, error)
+	FinishRun(ctx context.Context, jobRun entity.JobRun, affectedRows int64, runErr error) error
+}
+
+type jobService struct {
+	log   util.LogUtil
+	sqlDB *sql.DB
+}
+
+func MakeJobService(log util.LogUtil, sqlDB *sql.DB) JobService {
+	return &jobService{
+		log:   log,
+		sqlDB: sqlDB,
+	}
+}
+
+func (s *jobService) GetJobRuns(ctx context.Context, query entity.JobRunQuery) (entity.JobRuns, int, error) {
+	dbTrx := dao.NewTransactionJob(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	if query.PagingQuery.Limit <= 0 {
+		query.PagingQuery.Limit = defaultJobRunLimit
+	}
+
+	return dbTrx.GetJobRunDAO().Search(ctx, query)
+}
+
+// StartRun mencatat job mulai berjalan (status RUNNING)
+func (s *jobService) StartRun(ctx context.Context, jobName string, instance string) (*entity.JobRun, error) {
+	dbTrx := dao.NewTransactionJob(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	jobRun := entity.JobRun{
+		JobName:   jobName,
+		Status:    entity.JobRunStatusRunning,
+		StartedAt: time.Now(),
+	}
+	if instance != "" {
+		jobRun.Instance = &instance
+	}
+
+	jobRuns := entity.JobRuns{jobRun}
+	if err := dbTrx.GetJobRunDAO().Insert(ctx, jobRuns); err != nil {
+		return nil, fmt.Errorf("failed to insert job run: %w", err)
+	}
+
+	if err := dbTrx.GetSqlTx().Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit: %w", err)
+	}
+
+	return &jobRuns[0], nil
+}
+
+// FinishRun mencatat hasil akhir job (SUCCESS / FAILED)
+func (s *jobService) FinishRun(ctx context.Context, jobRun entity.JobRun, affectedRows int64, runErr error) error {
+	dbTrx := dao.NewTransactionJob(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	now := time.Now()
+	jobRun.FinishedAt = &now
+	jobRun.AffectedRows = affectedRows
+	jobRun.Status = entity.JobRunStatusSuccess
+	if runErr != nil {
+		errMsg := runErr.Error()
+		jobRun.Status = entity.JobRunStatusFailed
+		jobRun.Error = &errMsg
+	}
+
+	if err := dbTrx.GetJobRunDAO().Update(ctx, entity.JobRuns{jobRun}); err != nil {
+		return fmt.Errorf("failed to update job run: %w", err)
+	}
+
+	if err := dbTrx.GetSqlTx().Commit(); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+
+	return nil
+}
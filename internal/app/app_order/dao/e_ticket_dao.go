@@ -0,0 +1,278 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	baseDao "rakit-tiket-be/internal/pkg/dao"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	entity "rakit-tiket-be/pkg/entity/app_order"
+	"rakit-tiket-be/pkg/util"
+
+	"gitlab.com/threetopia/sqlgo/v2"
+	"go.uber.org/zap"
+)
+
+type ETicketDAO interface {
+	Search(ctx context.Context, query entity.ETicketQuery) (entity.ETickets, error)
+	// SearchForUpdate mengunci baris e-ticket (bukan tabel yang di-join) selama transaksi scan
+	SearchForUpdate(ctx context.Context, query entity.ETicketQuery) (entity.ETickets, error)
+	Insert(ctx context.Context, eTickets entity.ETickets) error
+
+	// UpdateScanState hanya mengubah status scan (status, scan_count, checked_in/out)
+	UpdateScanState(ctx context.Context, eTicket entity.ETicket) error
+}
+
+type eTicketDAO struct {
+	log   util.LogUtil
+	dbTrx baseDao.DBTransaction
+}
+
+func MakeETicketDAO(log util.LogUtil, dbTrx baseDao.DBTransaction) ETicketDAO {
+	return eTicketDAO{
+		log:   log,
+		dbTrx: dbTrx,
+	}
+}
+
+func (d eTicketDAO) Search(ctx context.Context, query entity.ETicketQuery) (entity.ETickets, error) {
+	return d.search(ctx, query, false)
+}
+
+func (d eTicketDAO) SearchForUpdate(ctx context.Context, query entity.ETicketQuery) (entity.ETickets, error) {
+	return d.search(ctx, query, true)
+}
+
+func (d eTicketDAO) search(ctx context.Context, query entity.ETicketQuery, forUpdate bool) (entity.ETickets, error) {
+	sqlSelect := sqlgo.NewSQLGoSelect().
+		SetSQLSelect("et.id", "id").
+		SetSQLSelect("et.event_id", "event_id").
+		SetSQLSelect("et.order_id", "order_id").
+		SetSQLSelect("et.registrant_id", "registrant_id").
+		SetSQLSelect("et.attendee_id", "attendee_id").
+		SetSQLSelect("et.ticket_id", "ticket_id").
+		SetSQLSelect("et.holder_name", "holder_name").
+		SetSQLSelect("et.seat_number", "seat_number").
+		SetSQLSelect("et.code", "code").
+		SetSQLSelect("et.status", "status").
+		SetSQLSelect("et.scan_count", "scan_count").
+		SetSQLSelect("et.checked_in", "checked_in").
+		SetSQLSelect("et.checked_in_at", "checked_in_at").
+		SetSQLSelect("et.checked_out_at", "checked_out_at").
+		SetSQLSelect("COALESCE(t.type, '')", "ticket_type").
+		SetSQLSelect("o.order_number", "order_number").
+		SetSQLSelect("o.payment_status", "payment_status").
+		SetSQLSelect("et.deleted", "deleted").
+		SetSQLSelect("et.data_hash", "data_hash").
+		SetSQLSelect("et.created_at", "created_at").
+		SetSQLSelect("et.updated_at", "updated_at")
+
+	sqlFrom := sqlgo.NewSQLGoFrom().
+		SetSQLFrom("e_tickets", "et")
+
+	sqlJoin := sqlgo.NewSQLGoJoin()
+	sqlJoin.SetSQLJoin("INNER", "orders", "o", sqlgo.SetSQLJoinWhere("AND", "o.id", "=", "et.order_id"))
+	sqlJoin.SetSQLJoin("LEFT", "tickets", "t", sqlgo.SetSQLJoinWhere("AND", "t.id", "=", "et.ticket_id"))
+
+	sqlWhere := sqlgo.NewSQLGoWhere()
+	sqlWhere.SetSQLWhere("AND", "et.deleted", "=", false)
+
+	if len(query.IDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "et.id", "IN", query.IDs)
+	}
+
+	if len(query.EventIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "et.event_id", "IN", query.EventIDs)
+	}
+
+	if len(query.OrderIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "et.order_id", "IN", query.OrderIDs)
+	}
+
+	if len(query.RegistrantIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "et.registrant_id", "IN", query.RegistrantIDs)
+	}
+
+	if len(query.Codes) > 0 {
+		sqlWhere.SetSQLWhere("AND", "et.code", "IN", query.Codes)
+	}
+
+	if len(query.OrderNumbers) > 0 {
+		sqlWhere.SetSQLWhere("AND", "o.order_number", "IN", query.OrderNumbers)
+	}
+
+	sqlOrder := sqlgo.NewSQLGoOrder()
+	sqlOrder.SetSQLOrder("et.order_id", "ASC")
+	sqlOrder.SetSQLOrder("et.seat_number", "ASC")
+
+	sqlStmt := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoSelect(sqlSelect).
+		SetSQLGoFrom(sqlFrom).
+		SetSQLGoJoin(sqlJoin).
+		SetSQLGoWhere(sqlWhere).
+		SetSQLGoOrder(sqlOrder)
+
+	sqlStr := sqlStmt.BuildSQL()
+	if forUpdate {
+		sqlStr += " FOR UPDATE OF et"
+	}
+	sqlParams := sqlStmt.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "eTicketDAO.Search",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	// Dibaca di dalam transaksi agar penerbitan e-ticket idempoten di bawah row lock order
+	rows, err := d.dbTrx.GetSqlTx().QueryContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "eTicketDAO.Search", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var eTickets entity.ETickets
+	for rows.Next() {
+		var eTicket entity.ETicket
+		if err := rows.Scan(
+			&eTicket.ID,
+			&eTicket.EventID,
+			&eTicket.OrderID,
+			&eTicket.RegistrantID,
+			&eTicket.AttendeeID,
+			&eTicket.TicketID,
+			&eTicket.HolderName,
+			&eTicket.SeatNumber,
+			&eTicket.Code,
+			&eTicket.Status,
+			&eTicket.ScanCount,
+			&eTicket.CheckedIn,
+			&eTicket.CheckedInAt,
+			&eTicket.CheckedOutAt,
+			&eTicket.TicketType,
+			&eTicket.OrderNumber,
+			&eTicket.PaymentStatus,
+			&eTicket.DaoEntity.Deleted,
+			&eTicket.DaoEntity.DataHash,
+			&eTicket.DaoEntity.CreatedAt,
+			&eTicket.DaoEntity.UpdatedAt,
+		); err != nil {
+			d.log.Error(ctx, "eTicketDAO.Search.Scan", zap.Error(err))
+			return nil, err
+		}
+		eTickets = append(eTickets, eTicket)
+	}
+
+	if err := rows.Err(); err != nil {
+		d.log.Error(ctx, "eTicketDAO.Search.RowsErr", zap.Error(err))
+		return nil, err
+	}
+
+	return eTickets, nil
+}
+
+func (d eTicketDAO) Insert(ctx context.Context, eTickets entity.ETickets) error {
+	if len(eTickets) < 1 {
+		return fmt.Errorf("empty e-ticket data")
+	}
+
+	sqlInsert := sqlgo.NewSQLGoInsert().
+		SetSQLInsert("e_tickets").
+		SetSQLInsertColumn(
+			"id", "event_id", "order_id", "registrant_id", "attendee_id", "ticket_id",
+			"holder_name", "seat_number", "code", "status", "scan_count", "checked_in", "checked_in_at",
+			"deleted", "data_hash", "created_at",
+		)
+
+	for i, eTicket := range eTickets {
+		eTicket.DaoEntity.CreatedAt = time.Now()
+
+		if eTicket.ID == "" {
+			eTicket.ID = pubEntity.MakeUUID("E_TICKET", string(eTicket.OrderID), fmt.Sprint(eTicket.SeatNumber))
+		}
+		if eTicket.Status == "" {
+			eTicket.Status = entity.ETicketStatusActive
+		}
+
+		sqlInsert.SetSQLInsertValue(
+			eTicket.ID,
+			eTicket.EventID,
+			eTicket.OrderID,
+			eTicket.RegistrantID,
+			eTicket.AttendeeID,
+			eTicket.TicketID,
+			eTicket.HolderName,
+			eTicket.SeatNumber,
+			eTicket.Code,
+			eTicket.Status,
+			eTicket.ScanCount,
+			eTicket.CheckedIn,
+			eTicket.CheckedInAt,
+			eTicket.DaoEntity.Deleted,
+			eTicket.DaoEntity.DataHash,
+			eTicket.DaoEntity.CreatedAt,
+		)
+
+		eTickets[i] = eTicket
+	}
+
+	sqlStmt := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoInsert(sqlInsert)
+
+	sqlStr := sqlStmt.BuildSQL()
+	sqlParams := sqlStmt.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "eTicketDAO.Insert",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...); err != nil {
+		d.log.Error(ctx, "eTicketDAO.Insert",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return nil
+}
+
+func (d eTicketDAO) UpdateScanState(ctx context.Context, eTicket entity.ETicket) error {
+	now := time.Now()
+	eTicket.DaoEntity.UpdatedAt = &now
+
+	sqlStmt := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLUpdate("e_tickets").
+		SetSQLUpdateValue("status", eTicket.Status).
+		SetSQLUpdateValue("scan_count", eTicket.ScanCount).
+		SetSQLUpdateValue("checked_in", eTicket.CheckedIn).
+		SetSQLUpdateValue("checked_in_at", eTicket.CheckedInAt).
+		SetSQLUpdateValue("checked_out_at", eTicket.CheckedOutAt).
+		SetSQLUpdateValue("updated_at", eTicket.DaoEntity.UpdatedAt).
+		SetSQLWhere("AND", "id", "=", eTicket.ID)
+
+	sqlStr := sqlStmt.BuildSQL()
+	sqlParams := sqlStmt.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "eTicketDAO.UpdateScanState",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...); err != nil {
+		d.log.Error(ctx, "eTicketDAO.UpdateScanState",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return nil
+}
@@ -17,10 +17,14 @@ import (
 type OrderDAO interface {
 	Search(ctx context.Context, query entity.OrderQuery) (entity.Orders, error)
 	SearchForUpdate(ctx context.Context, query entity.OrderQuery) (entity.Orders, error)
+	// SearchForUpdateSkipLocked melewati order yang sedang dikunci transaksi lain (mis. webhook pembayaran)
+	SearchForUpdateSkipLocked(ctx context.Context, query entity.OrderQuery) (entity.Orders, error)
 	Insert(ctx context.Context, orders entity.Orders) error
 	Update(ctx context.Context, orders entity.Orders) error
 	Delete(ctx context.Context, id pubEntity.UUID) error
 	SoftDelete(ctx context.Context, id pubEntity.UUID) error
+	// MarkPaymentReminderSent mengembalikan false jika pengingat untuk order ini sudah pernah dikirim
+	MarkPaymentReminderSent(ctx context.Context, id pubEntity.UUID) (bool, error)
 }
 
 type orderDAO struct {
@@ -44,6 +48,8 @@ func (d orderDAO) Search(ctx context.Context, query entity.OrderQuery) (entity.O
 		SetSQLSelect("o.order_number", "order_number").
 		SetSQLSelect("o.amount", "amount").
 		SetSQLSelect("o.currency", "currency").
+		SetSQLSelect("o.discount_amount", "discount_amount").
+		SetSQLSelect("o.promo_code", "promo_code").
 		SetSQLSelect("o.payment_type", "payment_type").
 		SetSQLSelect("o.payment_gateway", "payment_gateway").
 		SetSQLSelect("o.payment_method", "payment_method").
@@ -98,6 +104,10 @@ func (d orderDAO) Search(ctx context.Context, query entity.OrderQuery) (entity.O
 		sqlWhere.SetSQLWhere("AND", "o.expires_at", "<", query.ExpiredBefore)
 	}
 
+	if query.ExpiredAfter != nil {
+		sqlWhere.SetSQLWhere("AND", "o.expires_at", ">=", query.ExpiredAfter)
+	}
+
 	sql := sqlgo.NewSQLGo().
 		SetSQLSchema("public").
 		SetSQLGoSelect(sqlSelect).
@@ -134,6 +144,8 @@ func (d orderDAO) Search(ctx context.Context, query entity.OrderQuery) (entity.O
 			&order.OrderNumber,
 			&order.Amount,
 			&order.Currency,
+			&order.DiscountAmount,
+			&order.PromoCode,
 			&order.PaymentType,
 			&order.PaymentGateway,
 			&order.PaymentMethod,
@@ -165,6 +177,14 @@ func (d orderDAO) Search(ctx context.Context, query entity.OrderQuery) (entity.O
 }
 
 func (d orderDAO) SearchForUpdate(ctx context.Context, query entity.OrderQuery) (entity.Orders, error) {
+	return d.searchForUpdate(ctx, query, " FOR UPDATE")
+}
+
+func (d orderDAO) SearchForUpdateSkipLocked(ctx context.Context, query entity.OrderQuery) (entity.Orders, error) {
+	return d.searchForUpdate(ctx, query, " FOR UPDATE SKIP LOCKED")
+}
+
+func (d orderDAO) searchForUpdate(ctx context.Context, query entity.OrderQuery, lockClause string) (entity.Orders, error) {
 
 	sqlSelect := sqlgo.NewSQLGoSelect().
 		SetSQLSelect("o.id", "id").
@@ -173,6 +193,8 @@ func (d orderDAO) SearchForUpdate(ctx context.Context, query entity.OrderQuery)
 		SetSQLSelect("o.order_number", "order_number").
 		SetSQLSelect("o.amount", "amount").
 		SetSQLSelect("o.currency", "currency").
+		SetSQLSelect("o.discount_amount", "discount_amount").
+		SetSQLSelect("o.promo_code", "promo_code").
 		SetSQLSelect("o.payment_type", "payment_type").
 		SetSQLSelect("o.payment_gateway", "payment_gateway").
 		SetSQLSelect("o.payment_method", "payment_method").
@@ -223,13 +245,21 @@ func (d orderDAO) SearchForUpdate(ctx context.Context, query entity.OrderQuery)
 		sqlWhere.SetSQLWhere("AND", "o.payment_gateway", "IN", query.PaymentGateways)
 	}
 
+	if query.ExpiredBefore != nil {
+		sqlWhere.SetSQLWhere("AND", "o.expires_at", "<", query.ExpiredBefore)
+	}
+
+	if query.ExpiredAfter != nil {
+		sqlWhere.SetSQLWhere("AND", "o.expires_at", ">=", query.ExpiredAfter)
+	}
+
 	sql := sqlgo.NewSQLGo().
 		SetSQLSchema("public").
 		SetSQLGoSelect(sqlSelect).
 		SetSQLGoFrom(sqlFrom).
 		SetSQLGoWhere(sqlWhere)
 
-	sqlStr := sql.BuildSQL() + " FOR UPDATE"
+	sqlStr := sql.BuildSQL() + lockClause
 	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
 
 	d.log.Debug(ctx, "orderDAO.SearchForUpdate",
@@ -259,6 +289,8 @@ func (d orderDAO) SearchForUpdate(ctx context.Context, query entity.OrderQuery)
 			&order.OrderNumber,
 			&order.Amount,
 			&order.Currency,
+			&order.DiscountAmount,
+			&order.PromoCode,
 			&order.PaymentType,
 			&order.PaymentGateway,
 			&order.PaymentMethod,
@@ -298,6 +330,7 @@ func (d orderDAO) Insert(ctx context.Context, orders entity.Orders) error {
 		SetSQLInsert("orders").
 		SetSQLInsertColumn(
 			"id", "event_id", "registrant_id", "order_number", "amount", "currency",
+			"discount_amount", "promo_code",
 			"payment_type", "payment_gateway", "payment_status", "payment_token", "payment_url",
 			"payment_proof_url", "payment_proof_filename", "verified_by", "verified_at",
 			"expires_at", "deleted", "data_hash", "created_at",
@@ -321,6 +354,8 @@ func (d orderDAO) Insert(ctx context.Context, orders entity.Orders) error {
 			order.OrderNumber,
 			order.Amount,
 			order.Currency,
+			order.DiscountAmount,
+			order.PromoCode,
 			order.PaymentType,
 			order.PaymentGateway,
 			order.PaymentStatus,
@@ -506,3 +541,30 @@ func (d orderDAO) SoftDelete(ctx context.Context, id pubEntity.UUID) error {
 
 	return nil
 }
+
+func (d orderDAO) MarkPaymentReminderSent(ctx context.Context, id pubEntity.UUID) (bool, error) {
+	query := `
+        UPDATE orders
+        SET
+            payment_reminder_sent_at = $1,
+            updated_at               = $1
+        WHERE id = $2
+        AND payment_reminder_sent_at IS NULL
+        AND deleted = false
+    `
+
+	d.log.Debug(ctx, "orderDAO.MarkPaymentReminderSent", zap.String("ID", string(id)))
+
+	result, err := d.dbTrx.GetSqlTx().ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		d.log.Error(ctx, "orderDAO.MarkPaymentReminderSent", zap.Error(err))
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
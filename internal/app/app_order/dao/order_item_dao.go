@@ -0,0 +1,193 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	baseDao "rakit-tiket-be/internal/pkg/dao"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	entity "rakit-tiket-be/pkg/entity/app_order"
+	"rakit-tiket-be/pkg/util"
+
+	"gitlab.com/threetopia/sqlgo/v2"
+	"go.uber.org/zap"
+)
+
+type OrderItemDAO interface {
+	Search(ctx context.Context, query entity.OrderItemQuery) (entity.OrderItems, error)
+	Insert(ctx context.Context, items entity.OrderItems) error
+}
+
+type orderItemDAO struct {
+	log   util.LogUtil
+	dbTrx baseDao.DBTransaction
+}
+
+func MakeOrderItemDAO(log util.LogUtil, dbTrx baseDao.DBTransaction) OrderItemDAO {
+	return orderItemDAO{
+		log:   log,
+		dbTrx: dbTrx,
+	}
+}
+
+func (d orderItemDAO) Search(ctx context.Context, query entity.OrderItemQuery) (entity.OrderItems, error) {
+
+	sqlSelect := sqlgo.NewSQLGoSelect().
+		SetSQLSelect("oi.id", "id").
+		SetSQLSelect("oi.order_id", "order_id").
+		SetSQLSelect("oi.ticket_id", "ticket_id").
+		SetSQLSelect("oi.ticket_title", "ticket_title").
+		SetSQLSelect("oi.quantity", "quantity").
+		SetSQLSelect("oi.unit_price", "unit_price").
+		SetSQLSelect("oi.original_price", "original_price").
+		SetSQLSelect("oi.is_flash_sale", "is_flash_sale").
+		SetSQLSelect("oi.subtotal", "subtotal").
+		SetSQLSelect("oi.tier_id", "tier_id").
+		SetSQLSelect("oi.tier_name", "tier_name").
+		SetSQLSelect("oi.deleted", "deleted").
+		SetSQLSelect("oi.data_hash", "data_hash").
+		SetSQLSelect("oi.created_at", "created_at").
+		SetSQLSelect("oi.updated_at", "updated_at")
+
+	sqlFrom := sqlgo.NewSQLGoFrom().
+		SetSQLFrom("order_items", "oi")
+
+	sqlWhere := sqlgo.NewSQLGoWhere()
+	sqlWhere.SetSQLWhere("AND", "oi.deleted", "=", false)
+
+	if len(query.IDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "oi.id", "IN", query.IDs)
+	}
+
+	if len(query.OrderIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "oi.order_id", "IN", query.OrderIDs)
+	}
+
+	sqlOrder := sqlgo.NewSQLGoOrder().
+		SetSQLOrder("oi.created_at", "ASC")
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoSelect(sqlSelect).
+		SetSQLGoFrom(sqlFrom).
+		SetSQLGoWhere(sqlWhere).
+		SetSQLGoOrder(sqlOrder)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "orderItemDAO.Search",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	rows, err := d.dbTrx.GetSqlDB().QueryContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "orderItemDAO.Search",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items entity.OrderItems
+	for rows.Next() {
+		var item entity.OrderItem
+
+		if err := rows.Scan(
+			&item.ID,
+			&item.OrderID,
+			&item.TicketID,
+			&item.TicketTitle,
+			&item.Quantity,
+			&item.UnitPrice,
+			&item.OriginalPrice,
+			&item.IsFlashSale,
+			&item.Subtotal,
+			&item.TierID,
+			&item.TierName,
+			&item.DaoEntity.Deleted,
+			&item.DaoEntity.DataHash,
+			&item.DaoEntity.CreatedAt,
+			&item.DaoEntity.UpdatedAt,
+		); err != nil {
+			d.log.Error(ctx, "orderItemDAO.Search.Scan", zap.Error(err))
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (d orderItemDAO) Insert(ctx context.Context, items entity.OrderItems) error {
+	if len(items) < 1 {
+		return fmt.Errorf("empty order item data")
+	}
+
+	sqlInsert := sqlgo.NewSQLGoInsert().
+		SetSQLInsert("order_items").
+		SetSQLInsertColumn(
+			"id", "order_id", "ticket_id", "ticket_title", "quantity",
+			"unit_price", "original_price", "is_flash_sale", "subtotal",
+			"tier_id", "tier_name", "deleted", "data_hash", "created_at",
+		)
+
+	for i, item := range items {
+		item.CreatedAt = time.Now()
+
+		if item.ID == "" {
+			item.ID = pubEntity.MakeUUID("ORDER_ITEM", string(item.OrderID), string(item.TicketID))
+			if item.TierID != nil {
+				item.ID = pubEntity.MakeUUID("ORDER_ITEM", string(item.OrderID), string(item.TicketID), string(*item.TierID))
+			}
+		}
+
+		sqlInsert.SetSQLInsertValue(
+			item.ID,
+			item.OrderID,
+			item.TicketID,
+			item.TicketTitle,
+			item.Quantity,
+			item.UnitPrice,
+			item.OriginalPrice,
+			item.IsFlashSale,
+			item.Subtotal,
+			item.TierID,
+			item.TierName,
+			item.DaoEntity.Deleted,
+			item.DaoEntity.DataHash,
+			item.CreatedAt,
+		)
+
+		items[i] = item
+	}
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoInsert(sqlInsert)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "orderItemDAO.Insert",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	_, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "orderItemDAO.Insert",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	baseDao "rakit-tiket-be/internal/pkg/dao"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	entity "rakit-tiket-be/pkg/entity/app_order"
+	"rakit-tiket-be/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+type PaymentNotificationDAO interface {
+	// Insert mengembalikan false jika notifikasi yang sama sudah pernah diproses
+	Insert(ctx context.Context, notif entity.PaymentNotification) (bool, error)
+}
+
+type paymentNotificationDAO struct {
+	log   util.LogUtil
+	dbTrx baseDao.DBTransaction
+}
+
+func MakePaymentNotificationDAO(log util.LogUtil, dbTrx baseDao.DBTransaction) PaymentNotificationDAO {
+	return paymentNotificationDAO{
+		log:   log,
+		dbTrx: dbTrx,
+	}
+}
+
+func (d paymentNotificationDAO) Insert(ctx context.Context, notif entity.PaymentNotification) (bool, error) {
+	notif.CreatedAt = time.Now()
+	if notif.ID == "" {
+		notif.ID = pubEntity.MakeUUID("PAYMENT_NOTIFICATION", notif.Gateway, notif.NotificationID)
+	}
+
+	sqlStr := `
+		INSERT INTO payment_notifications (id, gateway, notification_id, order_number, payment_status, gross_amount, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (gateway, notification_id) DO NOTHING
+	`
+	sqlParams := []interface{}{
+		notif.ID,
+		notif.Gateway,
+		notif.NotificationID,
+		notif.OrderNumber,
+		notif.PaymentStatus,
+		notif.GrossAmount,
+		notif.CreatedAt,
+	}
+
+	d.log.Debug(ctx, "paymentNotificationDAO.Insert",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	result, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "paymentNotificationDAO.Insert",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+			zap.Error(err),
+		)
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"io"
 	"net/http"
 	"strings"
@@ -40,7 +41,7 @@ func (h orderHandler) RegisterRouter(g *echo.Group) {
 	admin := g.Group("/v1/admin")
 	admin.Use(h.authMiddleware.VerifyToken)
 	admin.Use(h.authMiddleware.RequireAdmin)
-	admin.POST("/tickets/scan", h.scanTicket)
+	admin.POST("/orders/reconcile", h.reconcileOrders)
 }
 
 func (h orderHandler) handleWebhook(c echo.Context) error {
@@ -52,6 +53,8 @@ func (h orderHandler) handleWebhook(c echo.Context) error {
 		gateway = payment.GatewayMidtrans
 	} else if gatewayParam == "xendit" {
 		gateway = payment.GatewayXendit
+	} else if gatewayParam == "doku" {
+		gateway = payment.GatewayDoku
 	} else {
 		return echo.NewHTTPError(http.StatusBadRequest, "unsupported payment gateway")
 	}
@@ -63,8 +66,19 @@ func (h orderHandler) handleWebhook(c echo.Context) error {
 	}
 	defer c.Request().Body.Close()
 
-	if err := h.orderService.HandleWebhook(ctx, gateway, body); err != nil {
-		h.log.Warn(ctx, "Webhook processed with warning", zap.Error(err), zap.String("gateway", gatewayParam))
+	if err := h.orderService.HandleWebhook(ctx, gateway, body, c.Request().Header); err != nil {
+		switch {
+		case errors.Is(err, payment.ErrInvalidSignature):
+			h.log.Warn(ctx, "Webhook rejected: invalid signature", zap.String("gateway", gatewayParam), zap.String("ip", c.RealIP()))
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid signature")
+		case errors.Is(err, service.ErrInvalidNotification), errors.Is(err, service.ErrOrderNotFound), errors.Is(err, service.ErrAmountMismatch):
+			// Hasil final: dikirim ulang pun tetap gagal, jadi gateway tidak perlu retry
+			h.log.Warn(ctx, "Webhook processed with warning", zap.Error(err), zap.String("gateway", gatewayParam))
+		default:
+			// Gagal sementara (DB, stok, dsb.); 5xx agar gateway mengirim ulang notifikasi
+			h.log.Error(ctx, "Webhook processing failed", zap.Error(err), zap.String("gateway", gatewayParam))
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to process webhook")
+		}
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{
@@ -98,28 +112,17 @@ func (h orderHandler) getOrderStatus(c echo.Context) error {
 	})
 }
 
-func (h orderHandler) scanTicket(c echo.Context) error {
-	var req struct {
-		OrderNumber string `json:"order_number"`
-	}
-
-	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
-	}
-
-	if req.OrderNumber == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "order_number is required")
-	}
+func (h orderHandler) reconcileOrders(c echo.Context) error {
+	ctx := c.Request().Context()
 
-	data, err := h.orderService.ScanTicket(c.Request().Context(), req.OrderNumber)
+	report, err := h.orderService.ReconcileOrders(ctx)
 	if err != nil {
-		h.log.Error(c.Request().Context(), "scanTicket error", zap.Error(err))
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-
-	if !data.Success {
-		return c.JSON(http.StatusBadRequest, data)
+		h.log.Error(ctx, "reconcileOrders error", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to reconcile orders")
 	}
 
-	return c.JSON(http.StatusOK, data)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    report,
+	})
 }
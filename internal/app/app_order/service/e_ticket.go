@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	regDao "rakit-tiket-be/internal/app/app_registrant/dao"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	orderEntity "rakit-tiket-be/pkg/entity/app_order"
+	regEntity "rakit-tiket-be/pkg/entity/app_registrant"
+	"rakit-tiket-be/pkg/util"
+)
+
+// eTicketCodeBytes menghasilkan 24 karakter base32 (120 bit) sehingga kode tidak bisa ditebak
+const eTicketCodeBytes = 15
+
+// IssueETickets menerbitkan satu e-ticket per kursi untuk order yang baru lunas.
+// Harus dipanggil di dalam transaksi yang memegang row lock order; jika order sudah
+// punya e-ticket, data yang ada dikembalikan apa adanya.
+func IssueETickets(ctx context.Context, dbTrx regDao.DBTransaction, order orderEntity.Order, registrant regEntity.Registrant, attendees regEntity.Attendees) (orderEntity.ETickets, error) {
+	existing, err := dbTrx.GetETicketDAO().Search(ctx, orderEntity.ETicketQuery{
+		OrderIDs: []string{string(order.ID)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return existing, nil
+	}
+
+	var eTickets orderEntity.ETickets
+	newETicket := func(attendeeID *pubEntity.UUID, ticketID pubEntity.UUID, holderName string) error {
+		code, err := util.MakeRandomCode(eTicketCodeBytes)
+		if err != nil {
+			return fmt.Errorf("gagal membuat kode e-ticket: %v", err)
+		}
+		eTickets = append(eTickets, orderEntity.ETicket{
+			EventID:      order.EventID,
+			OrderID:      order.ID,
+			RegistrantID: registrant.ID,
+			AttendeeID:   attendeeID,
+			TicketID:     ticketID,
+			HolderName:   holderName,
+			SeatNumber:   len(eTickets) + 1,
+			Code:         "ET" + code,
+		})
+		return nil
+	}
+
+	if registrant.TicketID != nil {
+		if err := newETicket(nil, *registrant.TicketID, registrant.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, att := range attendees {
+		attendeeID := att.ID
+		if err := newETicket(&attendeeID, att.TicketID, att.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(eTickets) == 0 {
+		return nil, nil
+	}
+
+	if err := dbTrx.GetETicketDAO().Insert(ctx, eTickets); err != nil {
+		return nil, err
+	}
+
+	return eTickets, nil
+}
@@ -5,14 +5,19 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"math"
+	"net/http"
+	"sort"
 	"time"
 
+	addonSvc "rakit-tiket-be/internal/app/app_addon/service"
 	regDao "rakit-tiket-be/internal/app/app_registrant/dao"
-	"rakit-tiket-be/internal/pkg/email"
+	waitlistSvc "rakit-tiket-be/internal/app/app_waitlist/service"
 	"rakit-tiket-be/internal/pkg/payment"
 	pubEntity "rakit-tiket-be/pkg/entity"
 	eventEntity "rakit-tiket-be/pkg/entity/app_event"
 	orderEntity "rakit-tiket-be/pkg/entity/app_order"
+	outboxEntity "rakit-tiket-be/pkg/entity/app_outbox"
 	regEntity "rakit-tiket-be/pkg/entity/app_registrant"
 	ticketEntity "rakit-tiket-be/pkg/entity/app_ticket"
 	model "rakit-tiket-be/pkg/model/app_order"
@@ -21,53 +26,106 @@ import (
 	"go.uber.org/zap"
 )
 
+var (
+	ErrAmountMismatch      = errors.New("gross amount tidak sesuai dengan nominal order")
+	ErrOrderNotFound       = errors.New("order tidak ditemukan")
+	ErrInvalidNotification = errors.New("payload notifikasi tidak valid")
+)
+
+// Order expired dalam rentang ini masih dicek ke gateway saat rekonsiliasi
+const reconcileExpiredLookback = 24 * time.Hour
+
+// Pengingat pembayaran dikirim ke order pending yang akan expired dalam rentang ini
+const paymentReminderLeadTime = 5 * time.Minute
+
 type OrderService interface {
-	HandleWebhook(ctx context.Context, gateway payment.GatewayType, payload []byte) error
+	HandleWebhook(ctx context.Context, gateway payment.GatewayType, payload []byte, headers http.Header) error
 	GetOrderStatus(ctx context.Context, orderNumber string) (*model.OrderStatusResponse, error)
 	UpdateExpiredOrders(ctx context.Context) (int64, error)
-	ScanTicket(ctx context.Context, orderNumber string) (*model.ScanTicketResponse, error)
+	ReconcileOrders(ctx context.Context) (*model.ReconciliationReport, error)
+	SendPaymentReminders(ctx context.Context) (int64, error)
 }
 
 type orderService struct {
 	log            util.LogUtil
 	sqlDB          *sql.DB
 	paymentFactory *payment.PaymentFactory
-	emailService   email.EmailService
 }
 
-func MakeOrderService(log util.LogUtil, sqlDB *sql.DB, paymentFactory *payment.PaymentFactory, emailService email.EmailService) OrderService {
+func MakeOrderService(log util.LogUtil, sqlDB *sql.DB, paymentFactory *payment.PaymentFactory) OrderService {
 	return orderService{
 		log:            log,
 		sqlDB:          sqlDB,
 		paymentFactory: paymentFactory,
-		emailService:   emailService,
 	}
 }
 
-func (s orderService) HandleWebhook(ctx context.Context, gateway payment.GatewayType, payload []byte) error {
+func (s orderService) HandleWebhook(ctx context.Context, gateway payment.GatewayType, payload []byte, headers http.Header) error {
 	provider, err := s.paymentFactory.GetProvider(gateway)
 	if err != nil {
 		return err
 	}
 
+	// Tolak notifikasi yang tidak ditandatangani oleh gateway
+	if err := provider.VerifyWebhook(ctx, payload, headers); err != nil {
+		return err
+	}
+
 	notif, err := provider.ParseWebhook(ctx, payload)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %v", ErrInvalidNotification, err)
 	}
 
+	_, err = s.applyNotification(ctx, gateway, notif, false)
+	return err
+}
+
+// applyNotification menjalankan transisi status order dari notifikasi gateway.
+// Dipakai bersama oleh HandleWebhook dan ReconcileOrders; return true jika status order berubah.
+func (s orderService) applyNotification(ctx context.Context, gateway payment.GatewayType, notif *payment.WebhookNotification, reviveExpired bool) (bool, error) {
 	dbTrx := regDao.NewTransactionRegistrant(ctx, s.log, s.sqlDB)
 	defer dbTrx.GetSqlTx().Rollback()
 
+	// Replay protection: notifikasi yang sama hanya diproses sekali
+	isNew, err := dbTrx.GetPaymentNotificationDAO().Insert(ctx, orderEntity.PaymentNotification{
+		Gateway:        string(gateway),
+		NotificationID: notif.NotificationID,
+		OrderNumber:    notif.OrderID,
+		PaymentStatus:  notif.PaymentStatus,
+		GrossAmount:    notif.GrossAmount,
+	})
+	if err != nil {
+		return false, err
+	}
+	// Rekonsiliasi tetap lanjut karena webhook yang sama bisa saja tiba saat order sudah expired
+	if !isNew && !reviveExpired {
+		s.log.Info(ctx, "Duplicate webhook notification ignored",
+			zap.String("gateway", string(gateway)),
+			zap.String("notification_id", notif.NotificationID),
+		)
+		return false, nil
+	}
+
 	orders, err := dbTrx.GetOrderDAO().SearchForUpdate(ctx, orderEntity.OrderQuery{
 		OrderNumbers: []string{notif.OrderID},
 	})
-	if err != nil || len(orders) == 0 {
-		return fmt.Errorf("order %s tidak ditemukan", notif.OrderID)
+	if err != nil {
+		return false, err
+	}
+	if len(orders) == 0 {
+		return false, fmt.Errorf("%w: %s", ErrOrderNotFound, notif.OrderID)
 	}
 	orderData := orders[0]
 
-	if orderData.PaymentStatus == "paid" || orderData.PaymentStatus == "failed" || orderData.PaymentStatus == "expired" {
-		return nil
+	if math.Abs(notif.GrossAmount-orderData.Amount) > 0.01 {
+		return false, fmt.Errorf("%w: order %s (notified %.2f, expected %.2f)", ErrAmountMismatch, notif.OrderID, notif.GrossAmount, orderData.Amount)
+	}
+
+	// Order expired yang ternyata sudah dibayar (webhook hilang) hanya dipulihkan lewat rekonsiliasi
+	revive := reviveExpired && orderData.PaymentStatus == orderEntity.OrderStatusExpired && notif.PaymentStatus == orderEntity.OrderStatusPaid
+
+	if !revive && (orderData.PaymentStatus == "paid" || orderData.PaymentStatus == "failed" || orderData.PaymentStatus == "expired") {
+		return false, dbTrx.GetSqlTx().Commit()
 	}
 
 	if notif.PaymentStatus == "pending" {
@@ -75,8 +133,10 @@ func (s orderService) HandleWebhook(ctx context.Context, gateway payment.Gateway
 		orderData.PaymentChannel = &notif.PaymentChannel
 		orderData.PaymentTransactionID = &notif.TransactionID
 		orderData.PaymentMetadata = &notif.RawPayload
-		dbTrx.GetOrderDAO().Update(ctx, []orderEntity.Order{orderData})
-		return dbTrx.GetSqlTx().Commit()
+		if err := dbTrx.GetOrderDAO().Update(ctx, []orderEntity.Order{orderData}); err != nil {
+			return false, err
+		}
+		return false, dbTrx.GetSqlTx().Commit()
 	}
 
 	registrants, _, err := dbTrx.GetRegistrantDAO().Search(ctx, regEntity.RegistrantQuery{
@@ -84,7 +144,7 @@ func (s orderService) HandleWebhook(ctx context.Context, gateway payment.Gateway
 	})
 
 	if err != nil || len(registrants) == 0 {
-		return fmt.Errorf("registrant untuk order %s tidak ditemukan", notif.OrderID)
+		return false, fmt.Errorf("registrant untuk order %s tidak ditemukan", notif.OrderID)
 	}
 
 	registrantData := registrants[0]
@@ -94,7 +154,7 @@ func (s orderService) HandleWebhook(ctx context.Context, gateway payment.Gateway
 	})
 
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	ticketQtyMap := make(map[string]int)
@@ -110,79 +170,62 @@ func (s orderService) HandleWebhook(ctx context.Context, gateway payment.Gateway
 	now := time.Now()
 
 	if notif.PaymentStatus == "paid" {
+		if revive {
+			// Stok order expired sudah dilepas, booking ulang sebelum dikonfirmasi terjual
+			for tID, qty := range ticketQtyMap {
+				if err := dbTrx.GetTicketDAO().BookStock(ctx, pubEntity.UUID(tID), qty); err != nil {
+					return false, fmt.Errorf("gagal BookStock tiket %s: %v", tID, err)
+				}
+			}
+			// Potongan promo sudah dibayar, pemakaian dicatat ulang walau kuota sudah penuh
+			if err := dbTrx.GetPromoRedemptionDAO().Reinstate(ctx, orderData.ID); err != nil {
+				return false, fmt.Errorf("gagal memulihkan pemakaian kode promo: %v", err)
+			}
+			if err := addonSvc.RebookOrderAddons(ctx, dbTrx, []pubEntity.UUID{orderData.ID}); err != nil {
+				return false, err
+			}
+		}
+
 		for tID, qty := range ticketQtyMap {
 			err := dbTrx.GetTicketDAO().ConfirmSold(ctx, pubEntity.UUID(tID), qty)
 			if err != nil {
-				return fmt.Errorf("gagal ConfirmSold tiket %s: %v", tID, err)
+				return false, fmt.Errorf("gagal ConfirmSold tiket %s: %v", tID, err)
 			}
 		}
+		if err := addonSvc.ConfirmOrderAddons(ctx, dbTrx, []pubEntity.UUID{orderData.ID}); err != nil {
+			return false, err
+		}
 		orderData.PaymentTime = &now
 
-		var ticketIDs []string
-		for tID := range ticketQtyMap {
-			ticketIDs = append(ticketIDs, tID)
+		if _, err := IssueETickets(ctx, dbTrx, orderData, registrantData, attendees); err != nil {
+			return false, fmt.Errorf("gagal menerbitkan e-ticket: %v", err)
 		}
 
-		ticketMap := make(map[string]ticketEntity.Ticket)
-		if len(ticketIDs) > 0 {
-			tickets, err := dbTrx.GetTicketDAO().Search(ctx, ticketEntity.TicketQuery{
-				IDs: ticketIDs,
-			})
-
-			if err != nil {
-				s.log.Error(ctx, "Failed to fetch ticket data for PDF", zap.Error(err))
-			} else {
-				for _, t := range tickets {
-					ticketMap[string(t.ID)] = t
-				}
-			}
-		}
-
-		dynamicEvent := EventDynamicData{
-			EventName:      "Rakit Tiket Event",
-			EventDate:      "Belum Ditentukan",
-			EventTimeStart: "-",
-			EventTimeEnd:   "-",
-			EventLocation:  "Venue Terpilih",
-		}
-
-		_ = s.sqlDB.QueryRowContext(ctx, "SELECT name FROM events WHERE id = $1", orderData.EventID).Scan(&dynamicEvent.EventName)
-
-		_ = s.sqlDB.QueryRowContext(ctx, "SELECT event_date, event_time_start, event_time_end, event_location FROM landing_pages WHERE event_id = $1", orderData.EventID).
-			Scan(&dynamicEvent.EventDate, &dynamicEvent.EventTimeStart, &dynamicEvent.EventTimeEnd, &dynamicEvent.EventLocation)
-
-		attachments, err := GenerateTicketsPDF(orderData, registrantData, ticketMap, dynamicEvent)
-		if err != nil {
-			s.log.Error(ctx, "Failed to generate PDF tickets", zap.Error(err))
-		} else {
-			s.log.Info(ctx, "Successfully generated PDF tickets", zap.Int("total", len(attachments)))
-
-			var emailAtts []email.Attachment
-			for _, att := range attachments {
-				emailAtts = append(emailAtts, email.Attachment{
-					FileName: att.FileName,
-					Data:     att.Data,
-				})
-			}
-
-			go func(targetEmail, ordNum, evtName, ownerName string, atts []email.Attachment) {
-				bgCtx := context.Background()
-
-				err := s.emailService.SendTicketEmail(bgCtx, targetEmail, ordNum, evtName, ownerName, atts)
-				if err != nil {
-					s.log.Error(bgCtx, "Gagal mengirim email PDF asinkronus", zap.Error(err))
-				} else {
-					s.log.Info(bgCtx, "Email PDF E-Ticket berhasil terkirim!", zap.String("to", targetEmail))
-				}
-			}(registrantData.Email, orderData.OrderNumber, dynamicEvent.EventName, registrantData.Name, emailAtts)
+		// E-ticket dikirim worker outbox setelah transaksi ini commit
+		if err := dbTrx.GetEmailOutboxDAO().Enqueue(ctx, outboxEntity.EmailTypeTicket, registrantData.Email, &orderData.ID, outboxEntity.EmailPayload{
+			OrderNumber: orderData.OrderNumber,
+		}); err != nil {
+			return false, fmt.Errorf("gagal mengantrikan email tiket: %v", err)
 		}
 
 	} else if notif.PaymentStatus == "failed" || notif.PaymentStatus == "expired" {
+		var releasedTicketIDs []string
 		for tID, qty := range ticketQtyMap {
 			err := dbTrx.GetTicketDAO().ReleaseBooked(ctx, pubEntity.UUID(tID), qty)
 			if err != nil {
-				return fmt.Errorf("gagal ReleaseBooked tiket %s: %v", tID, err)
+				return false, fmt.Errorf("gagal ReleaseBooked tiket %s: %v", tID, err)
 			}
+			releasedTicketIDs = append(releasedTicketIDs, tID)
+		}
+		if err := dbTrx.GetPromoRedemptionDAO().ReleaseByOrder(ctx, orderData.ID); err != nil {
+			return false, fmt.Errorf("gagal melepas kode promo: %v", err)
+		}
+		if err := addonSvc.ReleaseOrderAddons(ctx, dbTrx, []pubEntity.UUID{orderData.ID}); err != nil {
+			return false, err
+		}
+		// Stok yang dilepas ditawarkan dulu ke antrean waitlist tiket tersebut
+		if _, err := waitlistSvc.OfferReleasedStock(ctx, dbTrx, releasedTicketIDs, now); err != nil {
+			return false, fmt.Errorf("gagal menawarkan stok ke waitlist: %v", err)
 		}
 	}
 
@@ -192,20 +235,24 @@ func (s orderService) HandleWebhook(ctx context.Context, gateway payment.Gateway
 	orderData.PaymentTransactionID = &notif.TransactionID
 	orderData.PaymentMetadata = &notif.RawPayload
 
+	// registrants_status_check tidak mengenal 'expired'; registrant order expired dicatat failed
 	registrantData.Status = notif.PaymentStatus
+	if notif.PaymentStatus == orderEntity.OrderStatusExpired {
+		registrantData.Status = "failed"
+	}
 
 	if err := dbTrx.GetOrderDAO().Update(ctx, []orderEntity.Order{orderData}); err != nil {
-		return err
+		return false, err
 	}
 	if err := dbTrx.GetRegistrantDAO().Update(ctx, []regEntity.Registrant{registrantData}); err != nil {
-		return err
+		return false, err
 	}
 
 	if err := dbTrx.GetSqlTx().Commit(); err != nil {
-		return err
+		return false, err
 	}
 
-	return nil
+	return true, nil
 }
 
 func derefString(s *string) string {
@@ -217,6 +264,7 @@ func derefString(s *string) string {
 
 func (s orderService) GetOrderStatus(ctx context.Context, orderNumber string) (*model.OrderStatusResponse, error) {
 	dbTrx := regDao.NewTransactionRegistrant(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
 
 	orders, err := dbTrx.GetOrderDAO().Search(ctx, orderEntity.OrderQuery{
 		OrderNumbers: []string{orderNumber},
@@ -228,14 +276,9 @@ func (s orderService) GetOrderStatus(ctx context.Context, orderNumber string) (*
 
 	orderData := orders[0]
 
-	if orderData.PaymentStatus == "pending" && orderData.ExpiresAt != nil && time.Now().After(*orderData.ExpiresAt) {
-		orderData.PaymentStatus = "expired"
-		if err := dbTrx.GetOrderDAO().Update(ctx, []orderEntity.Order{orderData}); err != nil {
-			s.log.Error(ctx, "Failed to update expired order", zap.Error(err))
-		}
-		if err := dbTrx.GetSqlTx().Commit(); err != nil {
-			s.log.Error(ctx, "Failed to commit expired order update", zap.Error(err))
-		}
+	// Hanya tampilan: order tetap pending di DB sampai job UpdateExpiredOrders melepas stoknya
+	if orderData.PaymentStatus == orderEntity.OrderStatusPending && orderData.ExpiresAt != nil && time.Now().After(*orderData.ExpiresAt) {
+		orderData.PaymentStatus = orderEntity.OrderStatusExpired
 	}
 
 	registrants, _, err := dbTrx.GetRegistrantDAO().Search(ctx, regEntity.RegistrantQuery{
@@ -342,8 +385,10 @@ func (s orderService) UpdateExpiredOrders(ctx context.Context) (int64, error) {
 	dbTrx := regDao.NewTransactionRegistrant(ctx, s.log, s.sqlDB)
 	defer dbTrx.GetSqlTx().Rollback()
 
+	// Order dikunci seperti di applyNotification; order yang sedang diproses webhook dilewati
+	// dan diambil lagi di jadwal berikutnya bila masih pending
 	now := time.Now()
-	orders, err := dbTrx.GetOrderDAO().Search(ctx, orderEntity.OrderQuery{
+	orders, err := dbTrx.GetOrderDAO().SearchForUpdateSkipLocked(ctx, orderEntity.OrderQuery{
 		Statuses:      []string{orderEntity.OrderStatusPending},
 		ExpiredBefore: &now,
 	})
@@ -351,20 +396,24 @@ func (s orderService) UpdateExpiredOrders(ctx context.Context) (int64, error) {
 		return 0, fmt.Errorf("failed to search expired orders: %w", err)
 	}
 
-	if len(orders) == 0 {
-		return 0, nil
-	}
-
 	var expiredOrders []orderEntity.Order
+	var orderIDs []string
 	var registrantIDs []string
 
 	for _, order := range orders {
+		if order.PaymentStatus != orderEntity.OrderStatusPending {
+			continue
+		}
 		order.PaymentStatus = orderEntity.OrderStatusExpired
 		expiredOrders = append(expiredOrders, order)
+		orderIDs = append(orderIDs, string(order.ID))
 		registrantIDs = append(registrantIDs, string(order.RegistrantID))
 	}
 
-	ticketQtyMap := make(map[string]int)
+	if len(expiredOrders) == 0 {
+		return 0, nil
+	}
+
 	registrants, _, err := dbTrx.GetRegistrantDAO().Search(ctx, regEntity.RegistrantQuery{
 		IDs: registrantIDs,
 	})
@@ -375,123 +424,283 @@ func (s orderService) UpdateExpiredOrders(ctx context.Context) (int64, error) {
 	registrantMap := make(map[string]regEntity.Registrant)
 	for _, reg := range registrants {
 		registrantMap[string(reg.ID)] = reg
-		if reg.TicketID != nil {
-			ticketQtyMap[string(*reg.TicketID)]++
-		}
 	}
 
-	attendees, err := dbTrx.GetAttendeeDAO().Search(ctx, regEntity.AttendeeQuery{
-		RegistrantIDs: registrantIDs,
+	// Stok dilepas sesuai quantity order_items, sama dengan yang di-booking saat registrasi
+	orderItems, err := dbTrx.GetOrderItemDAO().Search(ctx, orderEntity.OrderItemQuery{
+		OrderIDs: orderIDs,
 	})
 	if err != nil {
-		return 0, fmt.Errorf("failed to search attendees: %w", err)
+		return 0, fmt.Errorf("failed to search order items: %w", err)
 	}
 
-	for _, att := range attendees {
-		ticketQtyMap[string(att.TicketID)]++
+	ticketQtyMap := make(map[string]int)
+	for _, item := range orderItems {
+		ticketQtyMap[string(item.TicketID)] += item.Quantity
 	}
 
-	for tID, qty := range ticketQtyMap {
-		if err := dbTrx.GetTicketDAO().ReleaseBooked(ctx, pubEntity.UUID(tID), qty); err != nil {
-			s.log.Error(ctx, "failed to release booked tickets", zap.String("ticket_id", tID), zap.Int("qty", qty), zap.Error(err))
+	releasedTicketIDs := make([]string, 0, len(ticketQtyMap))
+	for tID := range ticketQtyMap {
+		releasedTicketIDs = append(releasedTicketIDs, tID)
+	}
+	sort.Strings(releasedTicketIDs)
+
+	for _, tID := range releasedTicketIDs {
+		if err := dbTrx.GetTicketDAO().ReleaseBooked(ctx, pubEntity.UUID(tID), ticketQtyMap[tID]); err != nil {
+			return 0, fmt.Errorf("failed to release booked tickets %s: %w", tID, err)
+		}
+	}
+
+	expiredOrderIDs := make([]pubEntity.UUID, 0, len(expiredOrders))
+	for _, order := range expiredOrders {
+		if err := dbTrx.GetPromoRedemptionDAO().ReleaseByOrder(ctx, order.ID); err != nil {
+			return 0, fmt.Errorf("failed to release promo redemption: %w", err)
 		}
+		expiredOrderIDs = append(expiredOrderIDs, order.ID)
+	}
+
+	if err := addonSvc.ReleaseOrderAddons(ctx, dbTrx, expiredOrderIDs); err != nil {
+		return 0, fmt.Errorf("failed to release booked addons: %w", err)
+	}
+
+	// Stok yang dilepas ditawarkan dulu ke antrean waitlist tiket tersebut
+	if _, err := waitlistSvc.OfferReleasedStock(ctx, dbTrx, releasedTicketIDs, now); err != nil {
+		return 0, fmt.Errorf("failed to offer released stock to waitlist: %w", err)
 	}
 
 	if err := dbTrx.GetOrderDAO().Update(ctx, expiredOrders); err != nil {
 		return 0, fmt.Errorf("failed to update expired orders: %w", err)
 	}
 
-	if err := dbTrx.GetSqlTx().Commit(); err != nil {
-		return 0, fmt.Errorf("failed to commit expired orders update: %w", err)
+	// Email expired diantrikan di transaksi yang sama, dikirim worker outbox setelah commit
+	eventNames, err := searchEventNames(ctx, dbTrx, expiredOrders)
+	if err != nil {
+		return 0, err
 	}
-
 	for _, order := range expiredOrders {
 		reg, ok := registrantMap[string(order.RegistrantID)]
 		if !ok {
 			continue
 		}
 
-		events, err := dbTrx.GetEventDAO().Search(ctx, eventEntity.EventQuery{
-			IDs: []string{string(order.EventID)},
-		})
-		eventName := "Event"
-		if err == nil && len(events) > 0 {
-			eventName = events[0].Name
+		if err := dbTrx.GetEmailOutboxDAO().Enqueue(ctx, outboxEntity.EmailTypeOrderExpired, reg.Email, &order.ID, outboxEntity.EmailPayload{
+			OrderNumber: order.OrderNumber,
+			EventName:   eventNames[string(order.EventID)],
+			OwnerName:   reg.Name,
+		}); err != nil {
+			return 0, fmt.Errorf("failed to enqueue order expired email: %w", err)
 		}
+	}
 
-		go func(o orderEntity.Order, r regEntity.Registrant, eName string) {
-			_ = s.emailService.SendOrderExpiredEmail(ctx, r.Email, o.OrderNumber, eName, r.Name)
-		}(order, reg, eventName)
+	if err := dbTrx.GetSqlTx().Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit expired orders update: %w", err)
 	}
 
 	s.log.Info(ctx, "Updated expired orders", zap.Int("count", len(expiredOrders)))
 	return int64(len(expiredOrders)), nil
 }
 
-func (s orderService) ScanTicket(ctx context.Context, orderNumber string) (*model.ScanTicketResponse, error) {
+// ReconcileOrders mencocokkan order pending dan yang baru expired dengan status di gateway,
+// lalu menerapkan transisi yang sama dengan HandleWebhook untuk notifikasi yang terlewat.
+func (s orderService) ReconcileOrders(ctx context.Context) (*model.ReconciliationReport, error) {
+	report := &model.ReconciliationReport{
+		StartedAt:     time.Now(),
+		Discrepancies: []model.ReconciliationDiscrepancy{},
+	}
+
 	dbTrx := regDao.NewTransactionRegistrant(ctx, s.log, s.sqlDB)
 	defer dbTrx.GetSqlTx().Rollback()
 
+	since := report.StartedAt.Add(-reconcileExpiredLookback)
 	orders, err := dbTrx.GetOrderDAO().Search(ctx, orderEntity.OrderQuery{
-		OrderNumbers: []string{orderNumber},
+		Statuses:        []string{orderEntity.OrderStatusPending, orderEntity.OrderStatusExpired},
+		PaymentGateways: []string{string(payment.GatewayMidtrans), string(payment.GatewayXendit), string(payment.GatewayDoku)},
+		ExpiredAfter:    &since,
 	})
-	if err != nil || len(orders) == 0 {
-		return &model.ScanTicketResponse{
-			Success: false,
-			Message: "Order tidak ditemukan",
-		}, nil
+	if err != nil {
+		return nil, fmt.Errorf("failed to search orders for reconciliation: %w", err)
+	}
+
+	for _, order := range orders {
+		report.Checked++
+		gateway := payment.GatewayType(*order.PaymentGateway)
+
+		provider, err := s.paymentFactory.GetProvider(gateway)
+		if err != nil {
+			report.Failed++
+			continue
+		}
+
+		transactionID := derefString(order.PaymentTransactionID)
+		if transactionID == "" {
+			transactionID = derefString(order.PaymentToken)
+		}
+
+		notif, err := provider.GetTransactionStatus(ctx, payment.TransactionStatusRequest{
+			OrderID:       order.OrderNumber,
+			TransactionID: transactionID,
+		})
+		if err != nil {
+			// Transaksi yang belum pernah dibuka di halaman pembayaran umumnya belum ada di gateway
+			s.log.Warn(ctx, "Reconciliation status check failed", zap.String("order_number", order.OrderNumber), zap.Error(err))
+			report.Failed++
+			continue
+		}
+
+		if isSamePaymentOutcome(order.PaymentStatus, notif.PaymentStatus) {
+			continue
+		}
+
+		discrepancy := model.ReconciliationDiscrepancy{
+			OrderNumber:   order.OrderNumber,
+			Gateway:       string(gateway),
+			LocalStatus:   order.PaymentStatus,
+			GatewayStatus: notif.PaymentStatus,
+			LocalAmount:   order.Amount,
+			GatewayAmount: notif.GrossAmount,
+		}
+
+		applied, err := s.applyNotification(ctx, gateway, notif, true)
+		if err != nil {
+			s.log.Error(ctx, "Reconciliation failed to apply gateway status", zap.String("order_number", order.OrderNumber), zap.Error(err))
+			discrepancy.Error = err.Error()
+			report.Failed++
+		}
+		if applied {
+			discrepancy.Applied = true
+			report.Applied++
+		}
+
+		report.Discrepancies = append(report.Discrepancies, discrepancy)
 	}
-	order := orders[0]
 
-	if order.PaymentStatus != "paid" {
-		return &model.ScanTicketResponse{
-			Success: false,
-			Message: "Pembayaran belum lunas",
-		}, nil
+	report.FinishedAt = time.Now()
+
+	s.log.Info(ctx, "Order reconciliation completed",
+		zap.Int("checked", report.Checked),
+		zap.Int("applied", report.Applied),
+		zap.Int("discrepancies", len(report.Discrepancies)),
+	)
+
+	return report, nil
+}
+
+// SendPaymentReminders mengirim email pengingat satu kali untuk order pending yang hampir expired
+func (s orderService) SendPaymentReminders(ctx context.Context) (int64, error) {
+	dbTrx := regDao.NewTransactionRegistrant(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	now := time.Now()
+	deadline := now.Add(paymentReminderLeadTime)
+	orders, err := dbTrx.GetOrderDAO().Search(ctx, orderEntity.OrderQuery{
+		Statuses:      []string{orderEntity.OrderStatusPending},
+		ExpiredAfter:  &now,
+		ExpiredBefore: &deadline,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to search orders for payment reminder: %w", err)
+	}
+
+	if len(orders) == 0 {
+		return 0, nil
+	}
+
+	var claimedOrders []orderEntity.Order
+	var registrantIDs []string
+	for _, order := range orders {
+		claimed, err := dbTrx.GetOrderDAO().MarkPaymentReminderSent(ctx, order.ID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to mark payment reminder: %w", err)
+		}
+		if !claimed {
+			continue
+		}
+		claimedOrders = append(claimedOrders, order)
+		registrantIDs = append(registrantIDs, string(order.RegistrantID))
+	}
+
+	if len(claimedOrders) == 0 {
+		return 0, nil
 	}
 
 	registrants, _, err := dbTrx.GetRegistrantDAO().Search(ctx, regEntity.RegistrantQuery{
-		IDs: []string{string(order.RegistrantID)},
+		IDs: registrantIDs,
 	})
-	if err != nil || len(registrants) == 0 {
-		return &model.ScanTicketResponse{
-			Success: false,
-			Message: "Registrant tidak ditemukan",
-		}, nil
+	if err != nil {
+		return 0, fmt.Errorf("failed to search registrants: %w", err)
 	}
-	registrant := registrants[0]
 
-	if registrant.CheckedIn {
-		return &model.ScanTicketResponse{
-			Success:     false,
-			Message:     "Tiket sudah pernah di-scan",
-			OrderNumber: orderNumber,
-			Registrant:  registrant.Name,
-			TotalTickets: registrant.TotalTickets,
-			CheckedIn:   true,
-		}, nil
+	registrantMap := make(map[string]regEntity.Registrant)
+	for _, reg := range registrants {
+		registrantMap[string(reg.ID)] = reg
 	}
 
-	now := time.Now()
-	registrant.CheckedIn = true
-	registrant.CheckedInAt = &now
+	eventNames, err := searchEventNames(ctx, dbTrx, claimedOrders)
+	if err != nil {
+		return 0, err
+	}
 
-	if err := dbTrx.GetRegistrantDAO().Update(ctx, []regEntity.Registrant{registrant}); err != nil {
-		return nil, fmt.Errorf("failed to update check-in status: %w", err)
+	// Pengingat diantrikan bersama penanda payment_reminder_sent_at agar tidak hilang maupun terkirim dua kali
+	for _, order := range claimedOrders {
+		reg, ok := registrantMap[string(order.RegistrantID)]
+		if !ok {
+			continue
+		}
+
+		if err := dbTrx.GetEmailOutboxDAO().Enqueue(ctx, outboxEntity.EmailTypePaymentReminder, reg.Email, &order.ID, outboxEntity.EmailPayload{
+			OrderNumber: order.OrderNumber,
+			EventName:   eventNames[string(order.EventID)],
+			OwnerName:   reg.Name,
+			Amount:      FormatRupiah(order.Amount),
+			PaymentURL:  derefString(order.PaymentURL),
+			ExpiresAt:   order.ExpiresAt.Format("02 Jan 2006 15:04"),
+		}); err != nil {
+			return 0, fmt.Errorf("failed to enqueue payment reminder email: %w", err)
+		}
 	}
 
 	if err := dbTrx.GetSqlTx().Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit check-in: %w", err)
+		return 0, fmt.Errorf("failed to commit payment reminders: %w", err)
 	}
 
-	s.log.Info(ctx, "Ticket scanned successfully", zap.String("order_number", orderNumber), zap.String("registrant", registrant.Name))
+	s.log.Info(ctx, "Payment reminders sent", zap.Int("count", len(claimedOrders)))
+	return int64(len(claimedOrders)), nil
+}
 
-	return &model.ScanTicketResponse{
-		Success:     true,
-		Message:     "Check-in berhasil",
-		OrderNumber: orderNumber,
-		Registrant:  registrant.Name,
-		TotalTickets: registrant.TotalTickets,
-		CheckedIn:   true,
-	}, nil
+// searchEventNames mengambil nama event untuk template email, default "Event"
+func searchEventNames(ctx context.Context, dbTrx regDao.DBTransaction, orders []orderEntity.Order) (map[string]string, error) {
+	eventNames := make(map[string]string)
+	var eventIDs []string
+	for _, order := range orders {
+		if _, ok := eventNames[string(order.EventID)]; ok {
+			continue
+		}
+		eventNames[string(order.EventID)] = "Event"
+		eventIDs = append(eventIDs, string(order.EventID))
+	}
+
+	events, err := dbTrx.GetEventDAO().Search(ctx, eventEntity.EventQuery{
+		IDs: eventIDs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search events: %w", err)
+	}
+	for _, event := range events {
+		eventNames[string(event.ID)] = event.Name
+	}
+
+	return eventNames, nil
+}
+
+// failed dan expired sama-sama berarti order tidak dibayar
+func isSamePaymentOutcome(localStatus, gatewayStatus string) bool {
+	if localStatus == gatewayStatus {
+		return true
+	}
+
+	unpaid := map[string]bool{
+		orderEntity.OrderStatusFailed:  true,
+		orderEntity.OrderStatusExpired: true,
+	}
+	return unpaid[localStatus] && unpaid[gatewayStatus]
 }
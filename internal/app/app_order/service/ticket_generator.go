@@ -6,9 +6,13 @@ import (
 	"html/template"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
+	"rakit-tiket-be/internal/pkg/qrsign"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	addonEntity "rakit-tiket-be/pkg/entity/app_addon"
 	orderEntity "rakit-tiket-be/pkg/entity/app_order"
 	regEntity "rakit-tiket-be/pkg/entity/app_registrant"
 	ticketEntity "rakit-tiket-be/pkg/entity/app_ticket"
@@ -37,6 +41,8 @@ type TicketTemplateData struct {
 	TicketTitle    string
 	TicketPrice    string
 	OrderNumber    string
+	TicketCode     string
+	SeatNumber     int
 	PaymentTime    string
 	PaymentStatus  string
 	Amount         string
@@ -47,10 +53,19 @@ type TicketTemplateData struct {
 	EventLocation  string
 	QRCodePath     string
 	CurrentYear    string
+
+	// Add-on order hanya dicetak di halaman kursi registrant bersama QR pengambilannya
+	Addons          []TicketAddonLine
+	AddonQRCodePath string
+}
+
+type TicketAddonLine struct {
+	Name     string
+	Quantity int
 }
 
 // Helper Format Rupiah
-func formatRupiah(amount float64) string {
+func FormatRupiah(amount float64) string {
 	s := fmt.Sprintf("%.0f", amount)
 	var res string
 	for i, v := range s {
@@ -62,11 +77,18 @@ func formatRupiah(amount float64) string {
 	return "Rp " + res
 }
 
+// GenerateTicketsPDF membuat satu PDF per kursi; QR setiap halaman berisi payload bertanda tangan kursi tersebut.
+// Harga kursi diambil dari order_items (harga tier saat registrasi), bukan harga tiket saat ini.
+// Add-on order beserta QR pengambilannya dicetak sekali di halaman kursi registrant.
 func GenerateTicketsPDF(
 	order orderEntity.Order,
 	registrant regEntity.Registrant,
+	eTickets orderEntity.ETickets,
 	ticketMap map[string]ticketEntity.Ticket,
+	orderItems orderEntity.OrderItems,
 	eventData EventDynamicData,
+	orderAddons addonEntity.OrderAddons,
+	qrSigner qrsign.Signer,
 ) ([]TicketAttachment, error) {
 
 	var attachments []TicketAttachment
@@ -76,33 +98,46 @@ func GenerateTicketsPDF(
 		return nil, fmt.Errorf("failed to parse template: %v", err)
 	}
 
-	type Owner struct {
-		Name     string
-		TicketID string
-	}
-	owners := []Owner{}
-
-	if registrant.TicketID != nil {
-		owners = append(owners, Owner{Name: registrant.Name, TicketID: string(*registrant.TicketID)})
-	}
-
 	// Persiapkan Folder Asset Storage (Supaya Admin Bisa Download)
 	ticketDir := filepath.Join(envgo.GetString("APP_FILE_PATH", "./assets/app_file"), "tickets")
 	_ = os.MkdirAll(ticketDir, 0755)
 
-	for _, owner := range owners {
-		ticketInfo, exists := ticketMap[owner.TicketID]
+	addonLines, addonQRPath, addonTicketID, err := prepareAddonPickup(order, eTickets, ticketMap, orderAddons, qrSigner, filepath.Join(ticketDir, "qrcodes"))
+	if err != nil {
+		return nil, err
+	}
+
+	prices := seatPrices(eTickets, orderItems)
+
+	for _, eTicket := range eTickets {
+		ticketInfo, exists := ticketMap[string(eTicket.TicketID)]
 		if !exists {
 			continue
 		}
 
+		// Order lama tanpa order_items memakai harga tiket
+		price, ok := prices[eTicket.ID]
+		if !ok {
+			price = ticketInfo.Price
+		}
+
 		// Generate QR Code file for PDF rendering
-		safeName := strings.ReplaceAll(owner.Name, " ", "_")
-		qrFileName := fmt.Sprintf("qr-%s-%s.png", order.OrderNumber, safeName)
+		safeName := fmt.Sprintf("%d-%s", eTicket.SeatNumber, strings.ReplaceAll(eTicket.HolderName, " ", "_"))
+		qrFileName := fmt.Sprintf("qr-%s.png", eTicket.Code)
 		qrDir := filepath.Join(ticketDir, "qrcodes")
 		qrFilePath := filepath.Join(qrDir, qrFileName)
 
-		_, err = util.GenerateQRCodeFile(order.OrderNumber, 300, qrFilePath)
+		qrContent, err := qrSigner.Sign(qrsign.Payload{
+			Kind:     qrsign.KindETicket,
+			EventID:  eTicket.EventID,
+			TicketID: eTicket.ID,
+			Seat:     eTicket.SeatNumber,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign QR: %v", err)
+		}
+
+		_, err = util.GenerateQRCodeFile(qrContent, 300, qrFilePath)
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate QR: %v", err)
 		}
@@ -115,13 +150,15 @@ func GenerateTicketsPDF(
 		// Mapping Data ke Struct
 		data := TicketTemplateData{
 			EventName:      strings.ToUpper(eventData.EventName),
-			OwnerName:      strings.ToUpper(owner.Name),
+			OwnerName:      strings.ToUpper(eTicket.HolderName),
 			TicketTitle:    strings.ToUpper(ticketInfo.Title),
-			TicketPrice:    formatRupiah(ticketInfo.Price),
+			TicketPrice:    FormatRupiah(price),
 			OrderNumber:    strings.ToUpper(order.OrderNumber),
+			TicketCode:     eTicket.Code,
+			SeatNumber:     eTicket.SeatNumber,
 			PaymentTime:    paymentTimeStr,
 			PaymentStatus:  strings.ToUpper(order.PaymentStatus),
-			Amount:         formatRupiah(order.Amount),
+			Amount:         FormatRupiah(order.Amount),
 			RegistrantName: strings.ToUpper(registrant.Name),
 			EventDate:      eventData.EventDate,
 			EventTimeStart: eventData.EventTimeStart,
@@ -130,6 +167,10 @@ func GenerateTicketsPDF(
 			QRCodePath:     qrFilePath,
 			CurrentYear:    time.Now().Format("2006"),
 		}
+		if eTicket.ID == addonTicketID {
+			data.Addons = addonLines
+			data.AddonQRCodePath = addonQRPath
+		}
 
 		var renderedHTML bytes.Buffer
 		if err := tmpl.Execute(&renderedHTML, data); err != nil {
@@ -176,3 +217,87 @@ func GenerateTicketsPDF(
 
 	return attachments, nil
 }
+
+// seatPrices membagi kursi ke order_items tiketnya sesuai urutan kursi. Pembelian yang dipecah per tier
+// disimpan berurutan (tier awal lebih dulu), sehingga kursi dengan nomor kecil mendapat harga tier awal.
+func seatPrices(eTickets orderEntity.ETickets, orderItems orderEntity.OrderItems) map[pubEntity.UUID]float64 {
+	remaining := make(map[pubEntity.UUID]orderEntity.OrderItems)
+	for _, item := range orderItems {
+		remaining[item.TicketID] = append(remaining[item.TicketID], item)
+	}
+
+	seats := make(orderEntity.ETickets, len(eTickets))
+	copy(seats, eTickets)
+	sort.SliceStable(seats, func(i, j int) bool { return seats[i].SeatNumber < seats[j].SeatNumber })
+
+	prices := make(map[pubEntity.UUID]float64)
+	for _, seat := range seats {
+		items := remaining[seat.TicketID]
+		for len(items) > 0 && items[0].Quantity <= 0 {
+			items = items[1:]
+		}
+		if len(items) == 0 {
+			continue
+		}
+		prices[seat.ID] = items[0].UnitPrice
+		items[0].Quantity--
+		remaining[seat.TicketID] = items
+	}
+	return prices
+}
+
+// prepareAddonPickup memilih halaman kursi registrant (fallback kursi pertama) untuk mencetak add-on
+// lalu membuat QR pengambilan bertanda tangan berisi ID order
+func prepareAddonPickup(
+	order orderEntity.Order,
+	eTickets orderEntity.ETickets,
+	ticketMap map[string]ticketEntity.Ticket,
+	orderAddons addonEntity.OrderAddons,
+	qrSigner qrsign.Signer,
+	qrDir string,
+) ([]TicketAddonLine, string, pubEntity.UUID, error) {
+	if len(orderAddons) == 0 {
+		return nil, "", "", nil
+	}
+
+	var ticketID pubEntity.UUID
+	for _, eTicket := range eTickets {
+		if _, exists := ticketMap[string(eTicket.TicketID)]; !exists {
+			continue
+		}
+		if ticketID == "" {
+			ticketID = eTicket.ID
+		}
+		if eTicket.AttendeeID == nil {
+			ticketID = eTicket.ID
+			break
+		}
+	}
+	if ticketID == "" {
+		return nil, "", "", nil
+	}
+
+	lines := make([]TicketAddonLine, 0, len(orderAddons))
+	for _, addon := range orderAddons {
+		lines = append(lines, TicketAddonLine{
+			Name:     strings.ToUpper(addon.DisplayName()),
+			Quantity: addon.Quantity,
+		})
+	}
+
+	qrContent, err := qrSigner.Sign(qrsign.Payload{
+		Kind:     qrsign.KindMerchPickup,
+		EventID:  order.EventID,
+		TicketID: order.ID,
+	})
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to sign addon pickup QR: %v", err)
+	}
+
+	qrFilePath := filepath.Join(qrDir, fmt.Sprintf("qr-addon-%s.png", order.OrderNumber))
+	if _, err := util.GenerateQRCodeFile(qrContent, 300, qrFilePath); err != nil {
+		return nil, "", "", fmt.Errorf("failed to generate addon pickup QR: %v", err)
+	}
+
+	return lines, qrFilePath, ticketID, nil
+}
@@ -192,6 +192,14 @@ const ticketPDFTemplate = `
       color: #64748b;
     }
 
+    .addon-panel {
+      margin-top: 10px;
+    }
+
+    .addon-panel .qr-panel {
+      margin-top: 0;
+    }
+
     .terms {
       margin-top: 10px;
       border: 1px solid #e2e8f0;
@@ -246,6 +254,7 @@ const ticketPDFTemplate = `
               <table class="kv">
                 <tr><td>Nama</td><td>{{ .OwnerName }}</td></tr>
                 <tr><td>Tipe Tiket</td><td>{{ .TicketTitle }}</td></tr>
+                <tr><td>Kursi</td><td>{{ .SeatNumber }}</td></tr>
                 <tr><td>Harga Tiket</td><td>{{ .TicketPrice }}</td></tr>
               </table>
             </div>
@@ -281,11 +290,33 @@ const ticketPDFTemplate = `
             <div class="qr-panel">
               <img src="{{ .QRCodePath }}" alt="QR Code">
               <p class="qr-title">Scan QR saat check-in</p>
+              <p class="qr-sub">{{ .TicketCode }}</p>
+            </div>
+          </td>
+        </tr>
+      </table>
+
+      {{ if .Addons }}
+      <table class="split addon-panel">
+        <tr>
+          <td>
+            <div class="panel panel-soft">
+              <h3 class="panel-title">Add-on / Merchandise</h3>
+              <table class="kv">
+                {{ range .Addons }}<tr><td>{{ .Name }}</td><td>x{{ .Quantity }}</td></tr>{{ end }}
+              </table>
+            </div>
+          </td>
+          <td>
+            <div class="qr-panel">
+              <img src="{{ .AddonQRCodePath }}" alt="QR Pickup Add-on">
+              <p class="qr-title">Scan QR di booth pengambilan add-on</p>
               <p class="qr-sub">{{ .OrderNumber }}</p>
             </div>
           </td>
         </tr>
       </table>
+      {{ end }}
 
       <div class="terms">
         <h3 class="panel-title" style="margin-top:0;">Syarat & Ketentuan</h3>
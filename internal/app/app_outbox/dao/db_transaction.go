@@ -0,0 +1,34 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+
+	baseDao "rakit-tiket-be/internal/pkg/dao"
+	"rakit-tiket-be/pkg/util"
+)
+
+type DBTransaction interface {
+	baseDao.DBTransaction
+
+	GetEmailOutboxDAO() EmailOutboxDAO
+}
+
+type dbTransaction struct {
+	baseDao.DBTransaction
+
+	emailOutboxDAO EmailOutboxDAO
+}
+
+func NewTransactionOutbox(ctx context.Context, log util.LogUtil, sqlDB *sql.DB) DBTransaction {
+	dbTrx := &dbTransaction{
+		DBTransaction: baseDao.NewTransaction(ctx, sqlDB),
+	}
+
+	dbTrx.emailOutboxDAO = MakeEmailOutboxDAO(log, dbTrx)
+	return dbTrx
+}
+
+func (dbTrx *dbTransaction) GetEmailOutboxDAO() EmailOutboxDAO {
+	return dbTrx.emailOutboxDAO
+}
@@ -0,0 +1,390 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	baseDao "rakit-tiket-be/internal/pkg/dao"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	entity "rakit-tiket-be/pkg/entity/app_outbox"
+	"rakit-tiket-be/pkg/util"
+
+	"gitlab.com/threetopia/sqlgo/v2"
+	"go.uber.org/zap"
+)
+
+// DefaultMaxAttempts dengan backoff eksponensial (1m, 2m, 4m, ...) mencakup kurang lebih empat jam percobaan
+const DefaultMaxAttempts = 8
+
+type EmailOutboxDAO interface {
+	Search(ctx context.Context, query entity.EmailOutboxQuery) (entity.EmailOutboxes, int, error)
+	Count(ctx context.Context, query entity.EmailOutboxQuery) (int, error)
+	Insert(ctx context.Context, messages entity.EmailOutboxes) error
+	Update(ctx context.Context, messages entity.EmailOutboxes) error
+
+	// Enqueue menulis satu email ke outbox di dalam transaksi yang sedang berjalan
+	Enqueue(ctx context.Context, emailType entity.EmailType, recipient string, orderID *pubEntity.UUID, payload entity.EmailPayload) error
+	// ClaimDue mengambil pesan yang jatuh tempo (atau macet di PROCESSING sejak staleBefore) dan menandainya PROCESSING
+	ClaimDue(ctx context.Context, limit int, staleBefore time.Time) (entity.EmailOutboxes, error)
+}
+
+type emailOutboxDAO struct {
+	log   util.LogUtil
+	dbTrx baseDao.DBTransaction
+}
+
+func MakeEmailOutboxDAO(log util.LogUtil, dbTrx baseDao.DBTransaction) EmailOutboxDAO {
+	return emailOutboxDAO{
+		log:   log,
+		dbTrx: dbTrx,
+	}
+}
+
+const emailOutboxColumns = `id, type, order_id, recipient, payload, status, attempts, max_attempts,
+            next_attempt_at, locked_at, last_error, sent_at, deleted, data_hash, created_at, updated_at`
+
+func (d emailOutboxDAO) buildWhere(query entity.EmailOutboxQuery) sqlgo.SQLGoWhere {
+	sqlWhere := sqlgo.NewSQLGoWhere()
+	sqlWhere.SetSQLWhere("AND", "eo.deleted", "=", false)
+
+	if len(query.IDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "eo.id", "IN", query.IDs)
+	}
+
+	if len(query.OrderIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "eo.order_id", "IN", query.OrderIDs)
+	}
+
+	if len(query.Types) > 0 {
+		sqlWhere.SetSQLWhere("AND", "eo.type", "IN", query.Types)
+	}
+
+	if len(query.Statuses) > 0 {
+		sqlWhere.SetSQLWhere("AND", "eo.status", "IN", query.Statuses)
+	}
+
+	return sqlWhere
+}
+
+func (d emailOutboxDAO) Search(ctx context.Context, query entity.EmailOutboxQuery) (entity.EmailOutboxes, int, error) {
+	sqlSelect := sqlgo.NewSQLGoSelect().
+		SetSQLSelect("eo.id", "id").
+		SetSQLSelect("eo.type", "type").
+		SetSQLSelect("eo.order_id", "order_id").
+		SetSQLSelect("eo.recipient", "recipient").
+		SetSQLSelect("eo.payload", "payload").
+		SetSQLSelect("eo.status", "status").
+		SetSQLSelect("eo.attempts", "attempts").
+		SetSQLSelect("eo.max_attempts", "max_attempts").
+		SetSQLSelect("eo.next_attempt_at", "next_attempt_at").
+		SetSQLSelect("eo.locked_at", "locked_at").
+		SetSQLSelect("eo.last_error", "last_error").
+		SetSQLSelect("eo.sent_at", "sent_at").
+		SetSQLSelect("eo.deleted", "deleted").
+		SetSQLSelect("eo.data_hash", "data_hash").
+		SetSQLSelect("eo.created_at", "created_at").
+		SetSQLSelect("eo.updated_at", "updated_at")
+
+	sqlFrom := sqlgo.NewSQLGoFrom().
+		SetSQLFrom("email_outbox", "eo")
+
+	sqlOrder := sqlgo.NewSQLGoOrder()
+	sqlOrder.SetSQLOrder("eo.created_at", "DESC")
+
+	sqlOffsetLimit := sqlgo.NewSQLGoOffsetLimit()
+	if !query.PagingQuery.NoLimit {
+		if query.PagingQuery.Page > 0 {
+			sqlOffsetLimit.SQLPageLimit(query.PagingQuery.Page.Int(), query.PagingQuery.Limit.Int())
+		} else {
+			sqlOffsetLimit.SetSQLLimit(query.PagingQuery.Limit.Int())
+		}
+	}
+
+	sqlStmt := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoSelect(sqlSelect).
+		SetSQLGoFrom(sqlFrom).
+		SetSQLGoWhere(d.buildWhere(query)).
+		SetSQLGoOrder(sqlOrder).
+		SetSQLGoOffsetLimit(sqlOffsetLimit)
+
+	sqlStr := sqlStmt.BuildSQL()
+	sqlParams := sqlStmt.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "emailOutboxDAO.Search",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	rows, err := d.dbTrx.GetSqlDB().QueryContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "emailOutboxDAO.Search",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+			zap.Error(err),
+		)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	messages, err := d.scanRows(ctx, rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	totalCount, err := d.Count(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return messages, totalCount, nil
+}
+
+func (d emailOutboxDAO) Count(ctx context.Context, query entity.EmailOutboxQuery) (int, error) {
+	sqlSelect := sqlgo.NewSQLGoSelect()
+	sqlSelect.SetSQLSelect("COUNT(eo.id)", "count")
+
+	sqlFrom := sqlgo.NewSQLGoFrom()
+	sqlFrom.SetSQLFrom("email_outbox", "eo")
+
+	sqlStmt := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoSelect(sqlSelect).
+		SetSQLGoFrom(sqlFrom).
+		SetSQLGoWhere(d.buildWhere(query))
+
+	sqlStr := sqlStmt.BuildSQL()
+	sqlParams := sqlStmt.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "emailOutboxDAO.Count",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	var totalCount int
+	if err := d.dbTrx.GetSqlDB().QueryRowContext(ctx, sqlStr, sqlParams...).Scan(&totalCount); err != nil {
+		d.log.Error(ctx, "emailOutboxDAO.Count",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+			zap.Error(err),
+		)
+		return 0, err
+	}
+
+	return totalCount, nil
+}
+
+func (d emailOutboxDAO) Insert(ctx context.Context, messages entity.EmailOutboxes) error {
+	if len(messages) < 1 {
+		return fmt.Errorf("empty email outbox data")
+	}
+
+	sqlInsert := sqlgo.NewSQLGoInsert().
+		SetSQLInsert("email_outbox").
+		SetSQLInsertColumn(
+			"id", "type", "order_id", "recipient", "payload", "status", "attempts", "max_attempts",
+			"next_attempt_at", "deleted", "data_hash", "created_at",
+		)
+
+	for i, message := range messages {
+		message.DaoEntity.CreatedAt = time.Now()
+
+		if message.ID == "" {
+			message.ID = pubEntity.MakeUUID("EMAIL_OUTBOX", string(message.Type), message.Recipient, message.DaoEntity.CreatedAt.String())
+		}
+		if message.Status == "" {
+			message.Status = entity.EmailStatusPending
+		}
+		if message.MaxAttempts <= 0 {
+			message.MaxAttempts = DefaultMaxAttempts
+		}
+		if message.NextAttemptAt.IsZero() {
+			message.NextAttemptAt = message.DaoEntity.CreatedAt
+		}
+
+		payloadJSON, err := json.Marshal(message.Payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal email payload: %w", err)
+		}
+
+		sqlInsert.SetSQLInsertValue(
+			message.ID,
+			message.Type,
+			message.OrderID,
+			message.Recipient,
+			payloadJSON,
+			message.Status,
+			message.Attempts,
+			message.MaxAttempts,
+			message.NextAttemptAt,
+			message.DaoEntity.Deleted,
+			message.DaoEntity.DataHash,
+			message.DaoEntity.CreatedAt,
+		)
+
+		messages[i] = message
+	}
+
+	sqlStmt := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoInsert(sqlInsert)
+
+	sqlStr := sqlStmt.BuildSQL()
+	sqlParams := sqlStmt.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "emailOutboxDAO.Insert",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...); err != nil {
+		d.log.Error(ctx, "emailOutboxDAO.Insert",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return nil
+}
+
+func (d emailOutboxDAO) Update(ctx context.Context, messages entity.EmailOutboxes) error {
+	if len(messages) < 1 {
+		return fmt.Errorf("empty email outbox data")
+	}
+
+	for i, message := range messages {
+		now := time.Now()
+		message.DaoEntity.UpdatedAt = &now
+
+		sqlStmt := sqlgo.NewSQLGo().
+			SetSQLSchema("public").
+			SetSQLUpdate("email_outbox").
+			SetSQLUpdateValue("status", message.Status).
+			SetSQLUpdateValue("attempts", message.Attempts).
+			SetSQLUpdateValue("next_attempt_at", message.NextAttemptAt).
+			SetSQLUpdateValue("locked_at", message.LockedAt).
+			SetSQLUpdateValue("last_error", message.LastError).
+			SetSQLUpdateValue("sent_at", message.SentAt).
+			SetSQLUpdateValue("updated_at", message.DaoEntity.UpdatedAt).
+			SetSQLWhere("AND", "id", "=", message.ID)
+
+		sqlStr := sqlStmt.BuildSQL()
+		sqlParams := sqlStmt.GetSQLGoParameter().GetSQLParameter()
+
+		d.log.Debug(ctx, "emailOutboxDAO.Update",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+		)
+
+		if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...); err != nil {
+			d.log.Error(ctx, "emailOutboxDAO.Update",
+				zap.String("SQL", sqlStr),
+				zap.Any("Params", sqlParams),
+				zap.Error(err),
+			)
+			return err
+		}
+
+		messages[i] = message
+	}
+
+	return nil
+}
+
+func (d emailOutboxDAO) Enqueue(ctx context.Context, emailType entity.EmailType, recipient string, orderID *pubEntity.UUID, payload entity.EmailPayload) error {
+	return d.Insert(ctx, entity.EmailOutboxes{{
+		Type:      emailType,
+		OrderID:   orderID,
+		Recipient: recipient,
+		Payload:   payload,
+	}})
+}
+
+func (d emailOutboxDAO) ClaimDue(ctx context.Context, limit int, staleBefore time.Time) (entity.EmailOutboxes, error) {
+	query := `
+        UPDATE email_outbox
+        SET
+            status     = 'PROCESSING',
+            attempts   = attempts + 1,
+            locked_at  = $1,
+            updated_at = $1
+        WHERE id IN (
+            SELECT id FROM email_outbox
+            WHERE deleted = false
+            AND (
+                (status = 'PENDING' AND next_attempt_at <= $1)
+                OR (status = 'PROCESSING' AND locked_at < $2)
+            )
+            ORDER BY next_attempt_at ASC
+            LIMIT $3
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING ` + emailOutboxColumns
+
+	now := time.Now()
+
+	d.log.Debug(ctx, "emailOutboxDAO.ClaimDue",
+		zap.String("SQL", query),
+		zap.Int("Limit", limit),
+	)
+
+	rows, err := d.dbTrx.GetSqlTx().QueryContext(ctx, query, now, staleBefore, limit)
+	if err != nil {
+		d.log.Error(ctx, "emailOutboxDAO.ClaimDue", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	return d.scanRows(ctx, rows)
+}
+
+func (d emailOutboxDAO) scanRows(ctx context.Context, rows *sql.Rows) (entity.EmailOutboxes, error) {
+	var messages entity.EmailOutboxes
+	for rows.Next() {
+		var message entity.EmailOutbox
+		var payloadJSON []byte
+
+		if err := rows.Scan(
+			&message.ID,
+			&message.Type,
+			&message.OrderID,
+			&message.Recipient,
+			&payloadJSON,
+			&message.Status,
+			&message.Attempts,
+			&message.MaxAttempts,
+			&message.NextAttemptAt,
+			&message.LockedAt,
+			&message.LastError,
+			&message.SentAt,
+			&message.DaoEntity.Deleted,
+			&message.DaoEntity.DataHash,
+			&message.DaoEntity.CreatedAt,
+			&message.DaoEntity.UpdatedAt,
+		); err != nil {
+			d.log.Error(ctx, "emailOutboxDAO.Scan", zap.Error(err))
+			return nil, err
+		}
+
+		if len(payloadJSON) > 0 {
+			if err := json.Unmarshal(payloadJSON, &message.Payload); err != nil {
+				d.log.Error(ctx, "emailOutboxDAO.Scan.Payload", zap.Error(err))
+				return nil, err
+			}
+		}
+
+		messages = append(messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		d.log.Error(ctx, "emailOutboxDAO.Scan.RowsErr", zap.Error(err))
+		return nil, err
+	}
+
+	return messages, nil
+}
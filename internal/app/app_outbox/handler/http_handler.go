@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"rakit-tiket-be/internal/app/app_outbox/service"
+	"rakit-tiket-be/internal/pkg/middleware"
+	"rakit-tiket-be/pkg/util"
+
+	"github.com/labstack/echo/v4"
+)
+
+type HttpHandler interface {
+	RegisterRoute(g *echo.Group)
+}
+
+type httpHandler struct {
+	outboxHandler OutboxHandler
+}
+
+func MakeHttpAdapter(log util.LogUtil, outboxService service.OutboxService, authMiddleware middleware.AuthMiddleware) HttpHandler {
+	return httpHandler{
+		outboxHandler: MakeOutboxHandler(log, outboxService, authMiddleware),
+	}
+}
+
+func (h httpHandler) RegisterRoute(g *echo.Group) {
+	h.outboxHandler.RegisterRouter(g)
+}
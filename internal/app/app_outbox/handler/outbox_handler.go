@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"rakit-tiket-be/internal/app/app_outbox/service"
+	"rakit-tiket-be/internal/pkg/middleware"
+	entity "rakit-tiket-be/pkg/entity/app_outbox"
+	"rakit-tiket-be/pkg/util"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type OutboxHandler interface {
+	RegisterRouter(g *echo.Group)
+}
+
+type outboxHandler struct {
+	log            util.LogUtil
+	outboxService  service.OutboxService
+	authMiddleware middleware.AuthMiddleware
+}
+
+func MakeOutboxHandler(log util.LogUtil, outboxService service.OutboxService, authMiddleware middleware.AuthMiddleware) OutboxHandler {
+	return &outboxHandler{
+		log:            log,
+		outboxService:  outboxService,
+		authMiddleware: authMiddleware,
+	}
+}
+
+func (h *outboxHandler) RegisterRouter(g *echo.Group) {
+	admin := g.Group("/v1/admin")
+	admin.Use(h.authMiddleware.VerifyToken)
+	admin.Use(h.authMiddleware.RequireAdmin)
+
+	admin.GET("/outbox/emails", h.getEmails)
+	admin.POST("/outbox/emails/:id/retry", h.retryEmail)
+	admin.POST("/orders/:order_number/resend-ticket", h.resendTicketEmail)
+}
+
+// getEmails menampilkan pesan DEAD secara default, gunakan ?status= untuk status lain
+func (h *outboxHandler) getEmails(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var query entity.EmailOutboxQuery
+	if err := c.Bind(&query); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid query parameters")
+	}
+	if len(query.Statuses) == 0 {
+		query.Statuses = []string{string(entity.EmailStatusDead)}
+	}
+
+	messages, total, err := h.outboxService.GetMessages(ctx, query)
+	if err != nil {
+		h.log.Error(ctx, "getEmails error", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to get outbox emails")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    messages,
+		"total":   total,
+		"page":    query.PagingQuery.Page,
+		"limit":   query.PagingQuery.Limit,
+	})
+}
+
+func (h *outboxHandler) retryEmail(c echo.Context) error {
+	ctx := c.Request().Context()
+	id := c.Param("id")
+
+	if err := h.outboxService.RetryMessage(ctx, id); err != nil {
+		switch {
+		case errors.Is(err, service.ErrOutboxMessageNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case errors.Is(err, service.ErrOutboxMessageNotDead):
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		h.log.Error(ctx, "retryEmail error", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to retry email")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Email dijadwalkan untuk dikirim ulang",
+	})
+}
+
+func (h *outboxHandler) resendTicketEmail(c echo.Context) error {
+	ctx := c.Request().Context()
+	orderNumber := c.Param("order_number")
+
+	if err := h.outboxService.ResendTicketEmail(ctx, orderNumber); err != nil {
+		switch {
+		case errors.Is(err, service.ErrOrderNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case errors.Is(err, service.ErrOrderNotPaid):
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		h.log.Error(ctx, "resendTicketEmail error", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to resend ticket email")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "E-ticket dijadwalkan untuk dikirim ulang",
+	})
+}
@@ -0,0 +1,400 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	orderSvc "rakit-tiket-be/internal/app/app_order/service"
+	"rakit-tiket-be/internal/app/app_outbox/dao"
+	regDao "rakit-tiket-be/internal/app/app_registrant/dao"
+	"rakit-tiket-be/internal/pkg/email"
+	"rakit-tiket-be/internal/pkg/qrsign"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	addonEntity "rakit-tiket-be/pkg/entity/app_addon"
+	orderEntity "rakit-tiket-be/pkg/entity/app_order"
+	entity "rakit-tiket-be/pkg/entity/app_outbox"
+	regEntity "rakit-tiket-be/pkg/entity/app_registrant"
+	ticketEntity "rakit-tiket-be/pkg/entity/app_ticket"
+	"rakit-tiket-be/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrOutboxMessageNotFound = errors.New("email outbox message not found")
+	ErrOutboxMessageNotDead  = errors.New("only dead messages can be retried")
+	ErrOrderNotFound         = errors.New("order not found")
+	ErrOrderNotPaid          = errors.New("ticket email can only be sent for paid orders")
+)
+
+const (
+	defaultOutboxLimit = 50
+	outboxBatchSize    = 20
+
+	// Pesan PROCESSING lebih lama dari ini dianggap worker-nya mati dan diambil ulang
+	outboxStaleAfter = 10 * time.Minute
+
+	outboxBaseBackoff = time.Minute
+	outboxMaxBackoff  = 2 * time.Hour
+)
+
+type OutboxService interface {
+	GetMessages(ctx context.Context, query entity.EmailOutboxQuery) (entity.EmailOutboxes, int, error)
+	ProcessDue(ctx context.Context) (int64, error)
+	RetryMessage(ctx context.Context, id string) error
+	ResendTicketEmail(ctx context.Context, orderNumber string) error
+}
+
+type outboxService struct {
+	log          util.LogUtil
+	sqlDB        *sql.DB
+	emailService email.EmailService
+	qrSigner     qrsign.Signer
+
+	// waitlistClaimURL adalah halaman klaim frontend, token klaim ditambahkan sebagai path terakhir
+	waitlistClaimURL string
+}
+
+func MakeOutboxService(log util.LogUtil, sqlDB *sql.DB, emailService email.EmailService, qrSigner qrsign.Signer, waitlistClaimURL string) OutboxService {
+	return &outboxService{
+		log:              log,
+		sqlDB:            sqlDB,
+		emailService:     emailService,
+		qrSigner:         qrSigner,
+		waitlistClaimURL: waitlistClaimURL,
+	}
+}
+
+func (s *outboxService) GetMessages(ctx context.Context, query entity.EmailOutboxQuery) (entity.EmailOutboxes, int, error) {
+	dbTrx := dao.NewTransactionOutbox(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	if query.PagingQuery.Limit <= 0 {
+		query.PagingQuery.Limit = defaultOutboxLimit
+	}
+
+	return dbTrx.GetEmailOutboxDAO().Search(ctx, query)
+}
+
+// ProcessDue mengirim email yang jatuh tempo. Pesan diklaim dan di-commit dulu agar
+// replica lain tidak mengirim ulang, lalu hasil setiap pesan disimpan terpisah.
+func (s *outboxService) ProcessDue(ctx context.Context) (int64, error) {
+	claimTrx := dao.NewTransactionOutbox(ctx, s.log, s.sqlDB)
+	defer claimTrx.GetSqlTx().Rollback()
+
+	messages, err := claimTrx.GetEmailOutboxDAO().ClaimDue(ctx, outboxBatchSize, time.Now().Add(-outboxStaleAfter))
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+
+	if err := claimTrx.GetSqlTx().Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit outbox claim: %w", err)
+	}
+
+	var sent int64
+	for _, message := range messages {
+		sendErr := s.send(ctx, message)
+
+		now := time.Now()
+		message.LockedAt = nil
+		if sendErr == nil {
+			message.Status = entity.EmailStatusSent
+			message.SentAt = &now
+			message.LastError = nil
+			sent++
+		} else {
+			errMsg := sendErr.Error()
+			message.LastError = &errMsg
+			message.Status = entity.EmailStatusPending
+			message.NextAttemptAt = now.Add(outboxBackoff(message.Attempts))
+			if message.Attempts >= message.MaxAttempts {
+				message.Status = entity.EmailStatusDead
+			}
+
+			s.log.Error(ctx, "Gagal mengirim email outbox",
+				zap.String("id", string(message.ID)),
+				zap.String("type", string(message.Type)),
+				zap.Int("attempts", message.Attempts),
+				zap.String("status", string(message.Status)),
+				zap.Error(sendErr),
+			)
+		}
+
+		if err := s.saveResult(ctx, message); err != nil {
+			s.log.Error(ctx, "Failed to save outbox result", zap.String("id", string(message.ID)), zap.Error(err))
+		}
+	}
+
+	return sent, nil
+}
+
+func (s *outboxService) saveResult(ctx context.Context, message entity.EmailOutbox) error {
+	dbTrx := dao.NewTransactionOutbox(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	if err := dbTrx.GetEmailOutboxDAO().Update(ctx, entity.EmailOutboxes{message}); err != nil {
+		return err
+	}
+
+	return dbTrx.GetSqlTx().Commit()
+}
+
+// RetryMessage menjadwalkan ulang pesan DEAD dengan jatah percobaan baru
+func (s *outboxService) RetryMessage(ctx context.Context, id string) error {
+	dbTrx := dao.NewTransactionOutbox(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	messages, _, err := dbTrx.GetEmailOutboxDAO().Search(ctx, entity.EmailOutboxQuery{
+		IDs:         []string{id},
+		PagingQuery: pubEntity.PagingQuery{NoLimit: true},
+	})
+	if err != nil {
+		return err
+	}
+	if len(messages) == 0 {
+		return ErrOutboxMessageNotFound
+	}
+
+	message := messages[0]
+	if message.Status != entity.EmailStatusDead {
+		return ErrOutboxMessageNotDead
+	}
+
+	message.Status = entity.EmailStatusPending
+	message.Attempts = 0
+	message.NextAttemptAt = time.Now()
+	message.LockedAt = nil
+
+	if err := dbTrx.GetEmailOutboxDAO().Update(ctx, entity.EmailOutboxes{message}); err != nil {
+		return err
+	}
+
+	return dbTrx.GetSqlTx().Commit()
+}
+
+// ResendTicketEmail mengantrikan ulang e-ticket untuk order yang sudah dibayar
+func (s *outboxService) ResendTicketEmail(ctx context.Context, orderNumber string) error {
+	dbTrx := regDao.NewTransactionRegistrant(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	orders, err := dbTrx.GetOrderDAO().Search(ctx, orderEntity.OrderQuery{
+		OrderNumbers: []string{orderNumber},
+	})
+	if err != nil {
+		return err
+	}
+	if len(orders) == 0 {
+		return ErrOrderNotFound
+	}
+
+	order := orders[0]
+	if order.PaymentStatus != orderEntity.OrderStatusPaid {
+		return ErrOrderNotPaid
+	}
+
+	registrants, _, err := dbTrx.GetRegistrantDAO().Search(ctx, regEntity.RegistrantQuery{
+		IDs: []string{string(order.RegistrantID)},
+	})
+	if err != nil {
+		return err
+	}
+	if len(registrants) == 0 {
+		return fmt.Errorf("registrant untuk order %s tidak ditemukan", orderNumber)
+	}
+
+	if err := dbTrx.GetEmailOutboxDAO().Enqueue(ctx, entity.EmailTypeTicket, registrants[0].Email, &order.ID, entity.EmailPayload{
+		OrderNumber: order.OrderNumber,
+	}); err != nil {
+		return err
+	}
+
+	return dbTrx.GetSqlTx().Commit()
+}
+
+func (s *outboxService) send(ctx context.Context, message entity.EmailOutbox) error {
+	p := message.Payload
+
+	switch message.Type {
+	case entity.EmailTypeTicket, entity.EmailTypeTransferApproved:
+		if message.OrderID == nil {
+			return fmt.Errorf("order_id kosong untuk email %s", message.Type)
+		}
+
+		ticketEmail, err := s.buildTicketEmail(ctx, *message.OrderID)
+		if err != nil {
+			return err
+		}
+
+		if message.Type == entity.EmailTypeTransferApproved {
+			return s.emailService.SendTransferApprovalEmail(ctx, message.Recipient, ticketEmail.orderNumber, ticketEmail.eventName, ticketEmail.ownerName, ticketEmail.attachments)
+		}
+		return s.emailService.SendTicketEmail(ctx, message.Recipient, ticketEmail.orderNumber, ticketEmail.eventName, ticketEmail.ownerName, ticketEmail.attachments)
+
+	case entity.EmailTypePaymentRejected:
+		return s.emailService.SendPaymentRejectedEmail(ctx, message.Recipient, p.OrderNumber, p.EventName, p.OwnerName, p.Reason)
+
+	case entity.EmailTypePaymentCancelled:
+		return s.emailService.SendPaymentCancelledEmail(ctx, message.Recipient, p.OrderNumber, p.EventName, p.OwnerName, p.Reason)
+
+	case entity.EmailTypeRefund:
+		return s.emailService.SendRefundEmail(ctx, message.Recipient, p.OrderNumber, p.EventName, p.OwnerName, p.Amount, p.Reason, p.IsFull, p.ViaBankTransfer)
+
+	case entity.EmailTypeOrderExpired:
+		return s.emailService.SendOrderExpiredEmail(ctx, message.Recipient, p.OrderNumber, p.EventName, p.OwnerName)
+
+	case entity.EmailTypePaymentReminder:
+		return s.emailService.SendPaymentReminderEmail(ctx, message.Recipient, p.OrderNumber, p.EventName, p.OwnerName, p.Amount, p.PaymentURL, p.ExpiresAt)
+
+	case entity.EmailTypeWaitlistOffer:
+		if p.ClaimToken == "" {
+			return fmt.Errorf("claim_token kosong untuk email %s", message.Type)
+		}
+		claimURL := strings.TrimRight(s.waitlistClaimURL, "/") + "/" + url.PathEscape(p.ClaimToken)
+		return s.emailService.SendWaitlistOfferEmail(ctx, message.Recipient, p.EventName, p.OwnerName, p.TicketTitle, p.Quantity, claimURL, p.ExpiresAt)
+	}
+
+	return fmt.Errorf("tipe email outbox tidak dikenal: %s", message.Type)
+}
+
+type ticketEmail struct {
+	orderNumber string
+	eventName   string
+	ownerName   string
+	attachments []email.Attachment
+}
+
+// buildTicketEmail membuat PDF e-ticket dari data order terbaru saat email dikirim
+func (s *outboxService) buildTicketEmail(ctx context.Context, orderID pubEntity.UUID) (*ticketEmail, error) {
+	dbTrx := regDao.NewTransactionRegistrant(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	orders, err := dbTrx.GetOrderDAO().Search(ctx, orderEntity.OrderQuery{
+		IDs: []string{string(orderID)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, ErrOrderNotFound
+	}
+	order := orders[0]
+
+	registrants, _, err := dbTrx.GetRegistrantDAO().Search(ctx, regEntity.RegistrantQuery{
+		IDs: []string{string(order.RegistrantID)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(registrants) == 0 {
+		return nil, fmt.Errorf("registrant untuk order %s tidak ditemukan", order.OrderNumber)
+	}
+	registrant := registrants[0]
+
+	attendees, err := dbTrx.GetAttendeeDAO().Search(ctx, regEntity.AttendeeQuery{
+		RegistrantIDs: []string{string(registrant.ID)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Order lunas sebelum e-ticket per kursi ada akan diterbitkan saat email pertama dibuat
+	eTickets, err := orderSvc.IssueETickets(ctx, dbTrx, order, registrant, attendees)
+	if err != nil {
+		return nil, err
+	}
+
+	ticketIDSet := make(map[string]bool)
+	for _, eTicket := range eTickets {
+		ticketIDSet[string(eTicket.TicketID)] = true
+	}
+
+	var ticketIDs []string
+	for tID := range ticketIDSet {
+		ticketIDs = append(ticketIDs, tID)
+	}
+
+	ticketMap := make(map[string]ticketEntity.Ticket)
+	if len(ticketIDs) > 0 {
+		tickets, err := dbTrx.GetTicketDAO().Search(ctx, ticketEntity.TicketQuery{
+			IDs: ticketIDs,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tickets {
+			ticketMap[string(t.ID)] = t
+		}
+	}
+
+	orderItems, err := dbTrx.GetOrderItemDAO().Search(ctx, orderEntity.OrderItemQuery{
+		OrderIDs: []string{string(order.ID)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	orderAddons, err := dbTrx.GetOrderAddonDAO().Search(ctx, addonEntity.OrderAddonQuery{
+		OrderIDs: []string{string(order.ID)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dynamicEvent := orderSvc.EventDynamicData{
+		EventName:      "Rakit Tiket Event",
+		EventDate:      "Belum Ditentukan",
+		EventTimeStart: "-",
+		EventTimeEnd:   "-",
+		EventLocation:  "Venue Terpilih",
+	}
+
+	_ = s.sqlDB.QueryRowContext(ctx, "SELECT name FROM events WHERE id = $1", order.EventID).Scan(&dynamicEvent.EventName)
+	_ = s.sqlDB.QueryRowContext(ctx, "SELECT event_date, event_time_start, event_time_end, event_location FROM landing_pages WHERE event_id = $1", order.EventID).
+		Scan(&dynamicEvent.EventDate, &dynamicEvent.EventTimeStart, &dynamicEvent.EventTimeEnd, &dynamicEvent.EventLocation)
+
+	attachments, err := orderSvc.GenerateTicketsPDF(order, registrant, eTickets, ticketMap, orderItems, dynamicEvent, orderAddons, s.qrSigner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate PDF tickets: %w", err)
+	}
+
+	if err := dbTrx.GetSqlTx().Commit(); err != nil {
+		return nil, err
+	}
+
+	var emailAtts []email.Attachment
+	for _, att := range attachments {
+		emailAtts = append(emailAtts, email.Attachment{
+			FileName: att.FileName,
+			Data:     att.Data,
+		})
+	}
+
+	return &ticketEmail{
+		orderNumber: order.OrderNumber,
+		eventName:   dynamicEvent.EventName,
+		ownerName:   registrant.Name,
+		attachments: emailAtts,
+	}, nil
+}
+
+// outboxBackoff: 1m, 2m, 4m, ... dibatasi outboxMaxBackoff
+func outboxBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	backoff := outboxBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= outboxMaxBackoff {
+			return outboxMaxBackoff
+		}
+	}
+
+	return backoff
+}
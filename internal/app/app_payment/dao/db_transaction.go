@@ -4,10 +4,15 @@ import (
 	"context"
 	"database/sql"
 
+	addonDao "rakit-tiket-be/internal/app/app_addon/dao"
 	eventDao "rakit-tiket-be/internal/app/app_event/dao"
 	orderDao "rakit-tiket-be/internal/app/app_order/dao"
+	outboxDao "rakit-tiket-be/internal/app/app_outbox/dao"
+	presaleDao "rakit-tiket-be/internal/app/app_presale/dao"
+	promoDao "rakit-tiket-be/internal/app/app_promo/dao"
 	regDao "rakit-tiket-be/internal/app/app_registrant/dao"
 	ticketDao "rakit-tiket-be/internal/app/app_ticket/dao"
+	waitlistDao "rakit-tiket-be/internal/app/app_waitlist/dao"
 	"rakit-tiket-be/internal/pkg/dao"
 	"rakit-tiket-be/pkg/util"
 )
@@ -19,25 +24,53 @@ type DBTransaction interface {
 	GetManualTransferDAO() ManualTransferDAO
 	GetGatewayDAO() GatewayDAO
 	GetPaymentSettingDAO() PaymentSettingDAO
+	GetRefundDAO() RefundDAO
 	GetRegistrantDAO() regDao.RegistrantDAO
 	GetAttendeeDAO() regDao.AttendeeDAO
 	GetOrderDAO() orderDao.OrderDAO
+	GetOrderItemDAO() orderDao.OrderItemDAO
+	GetPaymentNotificationDAO() orderDao.PaymentNotificationDAO
+	GetETicketDAO() orderDao.ETicketDAO
 	GetTicketDAO() ticketDao.TicketDAO
+	GetPriceTierDAO() ticketDao.PriceTierDAO
 	GetEventDAO() eventDao.EventDAO
+	GetEmailOutboxDAO() outboxDao.EmailOutboxDAO
+	GetPromoCodeDAO() promoDao.PromoCodeDAO
+	GetPromoRedemptionDAO() promoDao.PromoRedemptionDAO
+	GetPresaleAccessCodeDAO() presaleDao.AccessCodeDAO
+	GetPresaleAllowlistDAO() presaleDao.AllowlistDAO
+	GetWaitlistDAO() waitlistDao.WaitlistDAO
+	GetAddonDAO() addonDao.AddonDAO
+	GetAddonVariantDAO() addonDao.AddonVariantDAO
+	GetOrderAddonDAO() addonDao.OrderAddonDAO
 }
 
 type dbTransaction struct {
 	dao.DBTransaction
 
-	bankAccountDAO    BankAccountDAO
-	manualTransferDAO ManualTransferDAO
-	gatewayDAO        GatewayDAO
-	paymentSettingDAO PaymentSettingDAO
-	registrantDAO     regDao.RegistrantDAO
-	attendeeDAO       regDao.AttendeeDAO
-	orderDAO          orderDao.OrderDAO
-	ticketDAO         ticketDao.TicketDAO
-	eventDAO          eventDao.EventDAO
+	bankAccountDAO         BankAccountDAO
+	manualTransferDAO      ManualTransferDAO
+	gatewayDAO             GatewayDAO
+	paymentSettingDAO      PaymentSettingDAO
+	refundDAO              RefundDAO
+	registrantDAO          regDao.RegistrantDAO
+	attendeeDAO            regDao.AttendeeDAO
+	orderDAO               orderDao.OrderDAO
+	orderItemDAO           orderDao.OrderItemDAO
+	paymentNotificationDAO orderDao.PaymentNotificationDAO
+	eTicketDAO             orderDao.ETicketDAO
+	ticketDAO              ticketDao.TicketDAO
+	priceTierDAO           ticketDao.PriceTierDAO
+	eventDAO               eventDao.EventDAO
+	emailOutboxDAO         outboxDao.EmailOutboxDAO
+	promoCodeDAO           promoDao.PromoCodeDAO
+	promoRedemptionDAO     promoDao.PromoRedemptionDAO
+	presaleAccessCodeDAO   presaleDao.AccessCodeDAO
+	presaleAllowlistDAO    presaleDao.AllowlistDAO
+	waitlistDAO            waitlistDao.WaitlistDAO
+	addonDAO               addonDao.AddonDAO
+	addonVariantDAO        addonDao.AddonVariantDAO
+	orderAddonDAO          addonDao.OrderAddonDAO
 }
 
 func NewTransactionPayment(ctx context.Context, log util.LogUtil, sqlDB *sql.DB) DBTransaction {
@@ -49,11 +82,25 @@ func NewTransactionPayment(ctx context.Context, log util.LogUtil, sqlDB *sql.DB)
 	dbTrx.manualTransferDAO = MakeManualTransferDAO(log, dbTrx)
 	dbTrx.gatewayDAO = MakeGatewayDAO(log, dbTrx)
 	dbTrx.paymentSettingDAO = MakePaymentSettingDAO(log, dbTrx)
+	dbTrx.refundDAO = MakeRefundDAO(log, dbTrx)
 	dbTrx.registrantDAO = regDao.MakeRegistrantDAO(log, dbTrx)
 	dbTrx.attendeeDAO = regDao.MakeAttendeeDAO(log, dbTrx)
 	dbTrx.orderDAO = orderDao.MakeOrderDAO(log, dbTrx)
+	dbTrx.orderItemDAO = orderDao.MakeOrderItemDAO(log, dbTrx)
+	dbTrx.paymentNotificationDAO = orderDao.MakePaymentNotificationDAO(log, dbTrx)
+	dbTrx.eTicketDAO = orderDao.MakeETicketDAO(log, dbTrx)
 	dbTrx.ticketDAO = ticketDao.MakeTicketDAO(log, dbTrx)
+	dbTrx.priceTierDAO = ticketDao.MakePriceTierDAO(log, dbTrx)
 	dbTrx.eventDAO = eventDao.MakeEventDAO(log, dbTrx)
+	dbTrx.emailOutboxDAO = outboxDao.MakeEmailOutboxDAO(log, dbTrx)
+	dbTrx.promoCodeDAO = promoDao.MakePromoCodeDAO(log, dbTrx)
+	dbTrx.promoRedemptionDAO = promoDao.MakePromoRedemptionDAO(log, dbTrx)
+	dbTrx.presaleAccessCodeDAO = presaleDao.MakeAccessCodeDAO(log, dbTrx)
+	dbTrx.presaleAllowlistDAO = presaleDao.MakeAllowlistDAO(log, dbTrx)
+	dbTrx.waitlistDAO = waitlistDao.MakeWaitlistDAO(log, dbTrx)
+	dbTrx.addonDAO = addonDao.MakeAddonDAO(log, dbTrx)
+	dbTrx.addonVariantDAO = addonDao.MakeAddonVariantDAO(log, dbTrx)
+	dbTrx.orderAddonDAO = addonDao.MakeOrderAddonDAO(log, dbTrx)
 
 	return dbTrx
 }
@@ -78,10 +125,26 @@ func (dbTrx *dbTransaction) GetOrderDAO() orderDao.OrderDAO {
 	return dbTrx.orderDAO
 }
 
+func (dbTrx *dbTransaction) GetOrderItemDAO() orderDao.OrderItemDAO {
+	return dbTrx.orderItemDAO
+}
+
+func (dbTrx *dbTransaction) GetPaymentNotificationDAO() orderDao.PaymentNotificationDAO {
+	return dbTrx.paymentNotificationDAO
+}
+
+func (dbTrx *dbTransaction) GetETicketDAO() orderDao.ETicketDAO {
+	return dbTrx.eTicketDAO
+}
+
 func (dbTrx *dbTransaction) GetTicketDAO() ticketDao.TicketDAO {
 	return dbTrx.ticketDAO
 }
 
+func (dbTrx *dbTransaction) GetPriceTierDAO() ticketDao.PriceTierDAO {
+	return dbTrx.priceTierDAO
+}
+
 func (dbTrx *dbTransaction) GetEventDAO() eventDao.EventDAO {
 	return dbTrx.eventDAO
 }
@@ -93,3 +156,43 @@ func (dbTrx *dbTransaction) GetGatewayDAO() GatewayDAO {
 func (dbTrx *dbTransaction) GetPaymentSettingDAO() PaymentSettingDAO {
 	return dbTrx.paymentSettingDAO
 }
+
+func (dbTrx *dbTransaction) GetRefundDAO() RefundDAO {
+	return dbTrx.refundDAO
+}
+
+func (dbTrx *dbTransaction) GetEmailOutboxDAO() outboxDao.EmailOutboxDAO {
+	return dbTrx.emailOutboxDAO
+}
+
+func (dbTrx *dbTransaction) GetPromoCodeDAO() promoDao.PromoCodeDAO {
+	return dbTrx.promoCodeDAO
+}
+
+func (dbTrx *dbTransaction) GetPromoRedemptionDAO() promoDao.PromoRedemptionDAO {
+	return dbTrx.promoRedemptionDAO
+}
+
+func (dbTrx *dbTransaction) GetPresaleAccessCodeDAO() presaleDao.AccessCodeDAO {
+	return dbTrx.presaleAccessCodeDAO
+}
+
+func (dbTrx *dbTransaction) GetPresaleAllowlistDAO() presaleDao.AllowlistDAO {
+	return dbTrx.presaleAllowlistDAO
+}
+
+func (dbTrx *dbTransaction) GetWaitlistDAO() waitlistDao.WaitlistDAO {
+	return dbTrx.waitlistDAO
+}
+
+func (dbTrx *dbTransaction) GetAddonDAO() addonDao.AddonDAO {
+	return dbTrx.addonDAO
+}
+
+func (dbTrx *dbTransaction) GetAddonVariantDAO() addonDao.AddonVariantDAO {
+	return dbTrx.addonVariantDAO
+}
+
+func (dbTrx *dbTransaction) GetOrderAddonDAO() addonDao.OrderAddonDAO {
+	return dbTrx.orderAddonDAO
+}
@@ -0,0 +1,269 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	baseDao "rakit-tiket-be/internal/pkg/dao"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	appPayment "rakit-tiket-be/pkg/entity/app_payment"
+	"rakit-tiket-be/pkg/util"
+
+	"gitlab.com/threetopia/sqlgo/v2"
+	"go.uber.org/zap"
+)
+
+type RefundDAO interface {
+	Search(ctx context.Context, query appPayment.RefundQuery) (appPayment.Refunds, error)
+	GetByID(ctx context.Context, id pubEntity.UUID) (*appPayment.Refund, error)
+	Insert(ctx context.Context, refunds appPayment.Refunds) error
+	Update(ctx context.Context, refunds appPayment.Refunds) error
+}
+
+type refundDAO struct {
+	log   util.LogUtil
+	dbTrx baseDao.DBTransaction
+}
+
+func MakeRefundDAO(log util.LogUtil, dbTrx baseDao.DBTransaction) RefundDAO {
+	return refundDAO{
+		log:   log,
+		dbTrx: dbTrx,
+	}
+}
+
+func (d refundDAO) Search(ctx context.Context, query appPayment.RefundQuery) (appPayment.Refunds, error) {
+	sqlSelect := sqlgo.NewSQLGoSelect().
+		SetSQLSelect("rf.id", "id").
+		SetSQLSelect("rf.order_id", "order_id").
+		SetSQLSelect("rf.type", "type").
+		SetSQLSelect("rf.method", "method").
+		SetSQLSelect("rf.status", "status").
+		SetSQLSelect("rf.amount", "amount").
+		SetSQLSelect("rf.is_full", "is_full").
+		SetSQLSelect("rf.reason", "reason").
+		SetSQLSelect("rf.gateway", "gateway").
+		SetSQLSelect("rf.refund_key", "refund_key").
+		SetSQLSelect("rf.gateway_refund_id", "gateway_refund_id").
+		SetSQLSelect("rf.submitted_at", "submitted_at").
+		SetSQLSelect("rf.bank_name", "bank_name").
+		SetSQLSelect("rf.account_number", "account_number").
+		SetSQLSelect("rf.account_holder", "account_holder").
+		SetSQLSelect("rf.processed_by", "processed_by").
+		SetSQLSelect("rf.completed_at", "completed_at").
+		SetSQLSelect("rf.deleted", "deleted").
+		SetSQLSelect("rf.data_hash", "data_hash").
+		SetSQLSelect("rf.created_at", "created_at").
+		SetSQLSelect("rf.updated_at", "updated_at")
+
+	sqlFrom := sqlgo.NewSQLGoFrom().
+		SetSQLFrom("refunds", "rf")
+
+	sqlWhere := sqlgo.NewSQLGoWhere()
+	sqlWhere.SetSQLWhere("AND", "rf.deleted", "=", false)
+
+	if len(query.IDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "rf.id", "IN", query.IDs)
+	}
+
+	if len(query.OrderIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "rf.order_id", "IN", query.OrderIDs)
+	}
+
+	if len(query.Statuses) > 0 {
+		sqlWhere.SetSQLWhere("AND", "rf.status", "IN", query.Statuses)
+	}
+
+	sqlOrder := sqlgo.NewSQLGoOrder()
+	sqlOrder.SetSQLOrder("rf.created_at", "ASC")
+
+	sqlStmt := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoSelect(sqlSelect).
+		SetSQLGoFrom(sqlFrom).
+		SetSQLGoWhere(sqlWhere).
+		SetSQLGoOrder(sqlOrder)
+
+	sqlStr := sqlStmt.BuildSQL()
+	sqlParams := sqlStmt.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "refundDAO.Search",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	// Refund dibaca di dalam transaksi agar total refund konsisten dengan row lock order
+	rows, err := d.dbTrx.GetSqlTx().QueryContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "refundDAO.Search", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refunds appPayment.Refunds
+	for rows.Next() {
+		var refund appPayment.Refund
+		if err := rows.Scan(
+			&refund.ID,
+			&refund.OrderID,
+			&refund.Type,
+			&refund.Method,
+			&refund.Status,
+			&refund.Amount,
+			&refund.IsFull,
+			&refund.Reason,
+			&refund.Gateway,
+			&refund.RefundKey,
+			&refund.GatewayRefundID,
+			&refund.SubmittedAt,
+			&refund.BankName,
+			&refund.AccountNumber,
+			&refund.AccountHolder,
+			&refund.ProcessedBy,
+			&refund.CompletedAt,
+			&refund.DaoEntity.Deleted,
+			&refund.DaoEntity.DataHash,
+			&refund.DaoEntity.CreatedAt,
+			&refund.DaoEntity.UpdatedAt,
+		); err != nil {
+			d.log.Error(ctx, "refundDAO.Search.Scan", zap.Error(err))
+			return nil, err
+		}
+		refunds = append(refunds, refund)
+	}
+
+	if err := rows.Err(); err != nil {
+		d.log.Error(ctx, "refundDAO.Search.RowsErr", zap.Error(err))
+		return nil, err
+	}
+
+	return refunds, nil
+}
+
+func (d refundDAO) GetByID(ctx context.Context, id pubEntity.UUID) (*appPayment.Refund, error) {
+	refunds, err := d.Search(ctx, appPayment.RefundQuery{IDs: []string{string(id)}})
+	if err != nil {
+		return nil, err
+	}
+	if len(refunds) == 0 {
+		return nil, nil
+	}
+	return &refunds[0], nil
+}
+
+func (d refundDAO) Insert(ctx context.Context, refunds appPayment.Refunds) error {
+	if len(refunds) < 1 {
+		return fmt.Errorf("empty refund data")
+	}
+
+	sqlInsert := sqlgo.NewSQLGoInsert().
+		SetSQLInsert("refunds").
+		SetSQLInsertColumn(
+			"id", "order_id", "type", "method", "status", "amount", "is_full", "reason",
+			"gateway", "refund_key", "gateway_refund_id", "submitted_at",
+			"bank_name", "account_number", "account_holder",
+			"processed_by", "completed_at", "deleted", "data_hash", "created_at",
+		)
+
+	for i, refund := range refunds {
+		refund.DaoEntity.CreatedAt = time.Now()
+
+		if refund.ID == "" {
+			refund.ID = pubEntity.MakeUUID("RF", string(refund.OrderID), refund.DaoEntity.CreatedAt.String())
+		}
+
+		sqlInsert.SetSQLInsertValue(
+			refund.ID,
+			refund.OrderID,
+			refund.Type,
+			refund.Method,
+			refund.Status,
+			refund.Amount,
+			refund.IsFull,
+			refund.Reason,
+			refund.Gateway,
+			refund.RefundKey,
+			refund.GatewayRefundID,
+			refund.SubmittedAt,
+			refund.BankName,
+			refund.AccountNumber,
+			refund.AccountHolder,
+			refund.ProcessedBy,
+			refund.CompletedAt,
+			refund.DaoEntity.Deleted,
+			refund.DaoEntity.DataHash,
+			refund.DaoEntity.CreatedAt,
+		)
+
+		refunds[i] = refund
+	}
+
+	sqlStmt := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoInsert(sqlInsert)
+
+	sqlStr := sqlStmt.BuildSQL()
+	sqlParams := sqlStmt.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "refundDAO.Insert",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	_, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "refundDAO.Insert",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return nil
+}
+
+func (d refundDAO) Update(ctx context.Context, refunds appPayment.Refunds) error {
+	if len(refunds) < 1 {
+		return fmt.Errorf("empty refund data")
+	}
+
+	for i, refund := range refunds {
+		now := time.Now()
+		refund.DaoEntity.UpdatedAt = &now
+
+		sqlStmt := sqlgo.NewSQLGo().
+			SetSQLSchema("public").
+			SetSQLUpdate("refunds").
+			SetSQLUpdateValue("status", refund.Status).
+			SetSQLUpdateValue("gateway_refund_id", refund.GatewayRefundID).
+			SetSQLUpdateValue("submitted_at", refund.SubmittedAt).
+			SetSQLUpdateValue("processed_by", refund.ProcessedBy).
+			SetSQLUpdateValue("completed_at", refund.CompletedAt).
+			SetSQLUpdateValue("data_hash", refund.DaoEntity.DataHash).
+			SetSQLUpdateValue("updated_at", refund.DaoEntity.UpdatedAt).
+			SetSQLWhere("AND", "id", "=", refund.ID)
+
+		sqlStr := sqlStmt.BuildSQL()
+		sqlParams := sqlStmt.GetSQLGoParameter().GetSQLParameter()
+
+		d.log.Debug(ctx, "refundDAO.Update",
+			zap.String("SQL", sqlStr),
+			zap.Any("Params", sqlParams),
+		)
+
+		if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...); err != nil {
+			d.log.Error(ctx, "refundDAO.Update",
+				zap.String("SQL", sqlStr),
+				zap.Any("Params", sqlParams),
+				zap.Error(err),
+			)
+			return err
+		}
+
+		refunds[i] = refund
+	}
+
+	return nil
+}
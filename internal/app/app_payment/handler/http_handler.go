@@ -18,6 +18,7 @@ type httpHandler struct {
 	manualTransferService paymentSvc.ManualTransferService
 	checkoutService       paymentSvc.CheckoutService
 	paymentConfigService  paymentSvc.PaymentConfigService
+	refundService         paymentSvc.RefundService
 	fileService           fileSvc.FileService
 	authMiddleware        middleware.AuthMiddleware
 	log                   util.LogUtil
@@ -29,6 +30,7 @@ func MakeHttpAdapter(
 	manualTransferService paymentSvc.ManualTransferService,
 	checkoutService paymentSvc.CheckoutService,
 	paymentConfigService paymentSvc.PaymentConfigService,
+	refundService paymentSvc.RefundService,
 	fileService fileSvc.FileService,
 	authMiddleware middleware.AuthMiddleware,
 ) HttpHandler {
@@ -38,6 +40,7 @@ func MakeHttpAdapter(
 		manualTransferService: manualTransferService,
 		checkoutService:       checkoutService,
 		paymentConfigService:  paymentConfigService,
+		refundService:         refundService,
 		fileService:           fileService,
 		authMiddleware:        authMiddleware,
 	}
@@ -50,6 +53,7 @@ func (h httpHandler) RegisterRoute(g *echo.Group) {
 		h.manualTransferService,
 		h.checkoutService,
 		h.paymentConfigService,
+		h.refundService,
 		h.fileService,
 		h.authMiddleware,
 	)
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 	fileSvc "rakit-tiket-be/internal/app/app_file/service"
 	paymentSvc "rakit-tiket-be/internal/app/app_payment/service"
 	"rakit-tiket-be/internal/pkg/middleware"
+	"rakit-tiket-be/internal/pkg/payment"
 	pubEntity "rakit-tiket-be/pkg/entity"
 	fileEntity "rakit-tiket-be/pkg/entity/app_file"
 	"rakit-tiket-be/pkg/util"
@@ -29,6 +31,7 @@ type paymentHandler struct {
 	manualTransferService paymentSvc.ManualTransferService
 	checkoutService       paymentSvc.CheckoutService
 	paymentConfigService  paymentSvc.PaymentConfigService
+	refundService         paymentSvc.RefundService
 	fileService           fileSvc.FileService
 	authMiddleware        middleware.AuthMiddleware
 }
@@ -39,6 +42,7 @@ func MakePaymentHandler(
 	manualTransferService paymentSvc.ManualTransferService,
 	checkoutService paymentSvc.CheckoutService,
 	paymentConfigService paymentSvc.PaymentConfigService,
+	refundService paymentSvc.RefundService,
 	fileService fileSvc.FileService,
 	authMiddleware middleware.AuthMiddleware,
 ) PaymentHandler {
@@ -48,6 +52,7 @@ func MakePaymentHandler(
 		manualTransferService: manualTransferService,
 		checkoutService:       checkoutService,
 		paymentConfigService:  paymentConfigService,
+		refundService:         refundService,
 		fileService:           fileService,
 		authMiddleware:        authMiddleware,
 	}
@@ -70,6 +75,11 @@ func (h *paymentHandler) RegisterRouter(g *echo.Group) {
 	admin.POST("/transfers/:transfer_id/reject", h.rejectTransfer)
 	admin.POST("/transfers/:transfer_id/cancel", h.cancelTransfer)
 
+	admin.GET("/orders/:order_number/refunds", h.getOrderRefunds)
+	admin.POST("/orders/:order_number/refund", h.refundOrder)
+	admin.POST("/orders/:order_number/cancel", h.cancelOrder)
+	admin.POST("/refunds/:refund_id/complete", h.completeRefund)
+
 	admin.POST("/bank-accounts", h.createBankAccount)
 	admin.PUT("/bank-accounts/:bank_account_id", h.updateBankAccount)
 	admin.DELETE("/bank-accounts/:bank_account_id", h.deleteBankAccount)
@@ -379,6 +389,131 @@ func (h *paymentHandler) cancelTransfer(c echo.Context) error {
 	})
 }
 
+func (h *paymentHandler) getOrderRefunds(c echo.Context) error {
+	ctx := c.Request().Context()
+	orderNumber := c.Param("order_number")
+
+	refunds, err := h.refundService.GetRefundsByOrderNumber(ctx, orderNumber)
+	if err != nil {
+		h.log.Error(ctx, "getOrderRefunds error", zap.Error(err))
+		if err == paymentSvc.ErrOrderNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, "Order not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to get refunds")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    refunds,
+	})
+}
+
+func (h *paymentHandler) refundOrder(c echo.Context) error {
+	ctx := c.Request().Context()
+	orderNumber := c.Param("order_number")
+
+	adminID := ""
+	if userID, ok := c.Get("user_id").(string); ok {
+		adminID = userID
+	}
+
+	var req paymentSvc.RefundOrderRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Reason is required")
+	}
+
+	refund, err := h.refundService.RefundOrder(ctx, orderNumber, req, adminID)
+	if err != nil {
+		h.log.Error(ctx, "refundOrder error", zap.Error(err))
+		return h.refundError(err, "Failed to refund order")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Refund processed",
+		"data":    refund,
+	})
+}
+
+func (h *paymentHandler) cancelOrder(c echo.Context) error {
+	ctx := c.Request().Context()
+	orderNumber := c.Param("order_number")
+
+	adminID := ""
+	if userID, ok := c.Get("user_id").(string); ok {
+		adminID = userID
+	}
+
+	var req paymentSvc.CancelOrderRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Reason is required")
+	}
+
+	refund, err := h.refundService.CancelOrder(ctx, orderNumber, req, adminID)
+	if err != nil {
+		h.log.Error(ctx, "cancelOrder error", zap.Error(err))
+		return h.refundError(err, "Failed to cancel order")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Order cancelled",
+		"data":    refund,
+	})
+}
+
+func (h *paymentHandler) completeRefund(c echo.Context) error {
+	ctx := c.Request().Context()
+	refundID := c.Param("refund_id")
+
+	adminID := ""
+	if userID, ok := c.Get("user_id").(string); ok {
+		adminID = userID
+	}
+
+	err := h.refundService.CompleteRefund(ctx, refundID, adminID)
+	if err != nil {
+		h.log.Error(ctx, "completeRefund error", zap.Error(err))
+		if err == paymentSvc.ErrRefundNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, "Refund not found")
+		}
+		if err == paymentSvc.ErrInvalidStatus {
+			return echo.NewHTTPError(http.StatusBadRequest, "Refund is not in pending status")
+		}
+		if err == paymentSvc.ErrRefundInProgress {
+			return echo.NewHTTPError(http.StatusConflict, "Gateway refund has not been confirmed by the gateway yet")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to complete refund")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Refund completed",
+	})
+}
+
+func (h *paymentHandler) refundError(err error, fallback string) error {
+	switch {
+	case errors.Is(err, paymentSvc.ErrOrderNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "Order not found")
+	case errors.Is(err, paymentSvc.ErrOrderNotRefundable),
+		errors.Is(err, paymentSvc.ErrInvalidRefundAmount),
+		errors.Is(err, paymentSvc.ErrRefundAmountExceeded):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, paymentSvc.ErrRefundInProgress):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, payment.ErrOperationNotSupported):
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, "Payment gateway does not support this operation, process it from the gateway dashboard")
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, fallback)
+}
+
 func (h *paymentHandler) createBankAccount(c echo.Context) error {
 	ctx := c.Request().Context()
 
@@ -11,10 +11,10 @@ import (
 	"rakit-tiket-be/internal/app/app_payment/dao"
 	"rakit-tiket-be/internal/app/app_payment/model"
 	"rakit-tiket-be/internal/pkg/payment"
+	appAddon "rakit-tiket-be/pkg/entity/app_addon"
 	"rakit-tiket-be/pkg/entity/app_order"
 	appPayment "rakit-tiket-be/pkg/entity/app_payment"
 	appRegistrant "rakit-tiket-be/pkg/entity/app_registrant"
-	ticketEntity "rakit-tiket-be/pkg/entity/app_ticket"
 	"rakit-tiket-be/pkg/util"
 )
 
@@ -72,54 +72,24 @@ func (s *checkoutInitiator) InitiateGatewayPayment(ctx context.Context, order *a
 	}
 	reg := registrants[0]
 
-	attendees, err := dbTrx.GetAttendeeDAO().Search(ctx, appRegistrant.AttendeeQuery{
-		RegistrantIDs: []string{string(order.RegistrantID)},
+	orderItems, err := dbTrx.GetOrderItemDAO().Search(ctx, app_order.OrderItemQuery{
+		OrderIDs: []string{string(order.ID)},
 	})
 	if err != nil {
 		return nil, err
 	}
-
-	ticketIDs := []string{}
-	if reg.TicketID != nil {
-		ticketIDs = append(ticketIDs, string(*reg.TicketID))
-	}
-	for _, att := range attendees {
-		ticketIDs = append(ticketIDs, string(att.TicketID))
-	}
-
-	ticketQtyMap := make(map[string]int)
-	for _, tID := range ticketIDs {
-		ticketQtyMap[tID]++
-	}
-
-	var uniqueTicketIDs []string
-	for tID := range ticketQtyMap {
-		uniqueTicketIDs = append(uniqueTicketIDs, tID)
+	if len(orderItems) == 0 {
+		return nil, errors.New("order items not found")
 	}
 
-	tickets, err := dbTrx.GetTicketDAO().Search(ctx, ticketEntity.TicketQuery{
-		IDs: uniqueTicketIDs,
+	orderAddons, err := dbTrx.GetOrderAddonDAO().Search(ctx, appAddon.OrderAddonQuery{
+		OrderIDs: []string{string(order.ID)},
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	ticketMap := make(map[string]ticketEntity.Ticket)
-	for _, t := range tickets {
-		ticketMap[string(t.ID)] = t
-	}
-
-	var paymentItems []payment.Item
-	for tID, qty := range ticketQtyMap {
-		if t, ok := ticketMap[tID]; ok {
-			paymentItems = append(paymentItems, payment.Item{
-				ID:       string(t.ID),
-				Name:     t.Title,
-				Price:    t.Price,
-				Quantity: qty,
-			})
-		}
-	}
+	paymentItems := buildPaymentItems(order, orderItems, orderAddons)
 
 	provider, err := s.paymentFactory.GetProviderByCode(activeGateway.Code)
 	if err != nil {
@@ -152,6 +122,9 @@ func (s *checkoutInitiator) InitiateGatewayPayment(ctx context.Context, order *a
 	order.PaymentType = func() *string { v := app_order.PaymentTypeGateway; return &v }()
 	order.PaymentToken = &paymentResp.Token
 	order.PaymentURL = &paymentResp.RedirectURL
+	if paymentResp.TransactionID != "" {
+		order.PaymentTransactionID = &paymentResp.TransactionID
+	}
 
 	if err := dbTrx.GetOrderDAO().Update(ctx, []app_order.Order{*order}); err != nil {
 		return nil, err
@@ -217,3 +190,46 @@ func IsGatewayPaymentType(paymentType string) bool {
 	}
 	return false
 }
+
+// buildPaymentItems memakai harga yang tersimpan di order_items (per tier), bukan harga tiket saat ini.
+// Add-on dikirim sebagai item tersendiri per varian, potongan promo sebagai item negatif
+// agar total item sama dengan order.Amount.
+func buildPaymentItems(order *app_order.Order, orderItems app_order.OrderItems, orderAddons appAddon.OrderAddons) []payment.Item {
+	paymentItems := make([]payment.Item, 0, len(orderItems)+len(orderAddons)+1)
+	for _, item := range orderItems {
+		name := item.TicketTitle
+		if item.TierName != nil && *item.TierName != "" {
+			name += " - " + *item.TierName
+		}
+		paymentItems = append(paymentItems, payment.Item{
+			ID:       string(item.TicketID),
+			Name:     name,
+			Price:    item.UnitPrice,
+			Quantity: item.Quantity,
+		})
+	}
+
+	for _, addon := range orderAddons {
+		paymentItems = append(paymentItems, payment.Item{
+			ID:       string(addon.VariantID),
+			Name:     addon.DisplayName(),
+			Price:    addon.UnitPrice,
+			Quantity: addon.Quantity,
+		})
+	}
+
+	if order.DiscountAmount > 0 {
+		name := "Diskon promo"
+		if order.PromoCode != nil && *order.PromoCode != "" {
+			name += " " + *order.PromoCode
+		}
+		paymentItems = append(paymentItems, payment.Item{
+			ID:       "PROMO",
+			Name:     name,
+			Price:    -order.DiscountAmount,
+			Quantity: 1,
+		})
+	}
+
+	return paymentItems
+}
@@ -11,10 +11,10 @@ import (
 	"rakit-tiket-be/internal/app/app_payment/model"
 	regDao "rakit-tiket-be/internal/app/app_registrant/dao"
 	"rakit-tiket-be/internal/pkg/payment"
+	appAddon "rakit-tiket-be/pkg/entity/app_addon"
 	"rakit-tiket-be/pkg/entity/app_order"
 	appPayment "rakit-tiket-be/pkg/entity/app_payment"
 	appRegistrant "rakit-tiket-be/pkg/entity/app_registrant"
-	ticketEntity "rakit-tiket-be/pkg/entity/app_ticket"
 	"rakit-tiket-be/pkg/util"
 )
 
@@ -91,54 +91,24 @@ func (s *checkoutService) InitiateCheckout(ctx context.Context, orderID string,
 		}
 		reg := registrants[0]
 
-		attendees, err := dbTrx.GetAttendeeDAO().Search(ctx, appRegistrant.AttendeeQuery{
-			RegistrantIDs: []string{string(order.RegistrantID)},
+		orderItems, err := dbTrx.GetOrderItemDAO().Search(ctx, app_order.OrderItemQuery{
+			OrderIDs: []string{string(order.ID)},
 		})
 		if err != nil {
 			return nil, err
 		}
-
-		ticketIDs := []string{}
-		if reg.TicketID != nil {
-			ticketIDs = append(ticketIDs, string(*reg.TicketID))
-		}
-		for _, att := range attendees {
-			ticketIDs = append(ticketIDs, string(att.TicketID))
-		}
-
-		ticketQtyMap := make(map[string]int)
-		for _, tID := range ticketIDs {
-			ticketQtyMap[tID]++
-		}
-
-		var uniqueTicketIDs []string
-		for tID := range ticketQtyMap {
-			uniqueTicketIDs = append(uniqueTicketIDs, tID)
+		if len(orderItems) == 0 {
+			return nil, errors.New("order items not found")
 		}
 
-		tickets, err := dbTrx.GetTicketDAO().Search(ctx, ticketEntity.TicketQuery{
-			IDs: uniqueTicketIDs,
+		orderAddons, err := dbTrx.GetOrderAddonDAO().Search(ctx, appAddon.OrderAddonQuery{
+			OrderIDs: []string{string(order.ID)},
 		})
 		if err != nil {
 			return nil, err
 		}
 
-		ticketMap := make(map[string]ticketEntity.Ticket)
-		for _, t := range tickets {
-			ticketMap[string(t.ID)] = t
-		}
-
-		var paymentItems []payment.Item
-		for tID, qty := range ticketQtyMap {
-			if t, ok := ticketMap[tID]; ok {
-				paymentItems = append(paymentItems, payment.Item{
-					ID:       string(t.ID),
-					Name:     t.Title,
-					Price:    t.Price,
-					Quantity: qty,
-				})
-			}
-		}
+		paymentItems := buildPaymentItems(&order, orderItems, orderAddons)
 
 		provider, err := s.paymentFactory.GetProviderByCode(gateway.Code)
 		if err != nil {
@@ -173,6 +143,9 @@ func (s *checkoutService) InitiateCheckout(ctx context.Context, orderID string,
 		order.PaymentType = &paymentTypeStr
 		order.PaymentToken = &paymentResp.Token
 		order.PaymentURL = &paymentResp.RedirectURL
+		if paymentResp.TransactionID != "" {
+			order.PaymentTransactionID = &paymentResp.TransactionID
+		}
 
 		if err := dbTrx.GetOrderDAO().Update(ctx, []app_order.Order{order}); err != nil {
 			return nil, err
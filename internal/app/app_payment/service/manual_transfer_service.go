@@ -7,14 +7,15 @@ import (
 	"fmt"
 	"time"
 
-	orderSvc "rakit-tiket-be/internal/app/app_order/service"
+	addonSvc "rakit-tiket-be/internal/app/app_addon/service"
+	orderService "rakit-tiket-be/internal/app/app_order/service"
 	"rakit-tiket-be/internal/app/app_payment/dao"
-	"rakit-tiket-be/internal/pkg/email"
+	waitlistSvc "rakit-tiket-be/internal/app/app_waitlist/service"
 	pubEntity "rakit-tiket-be/pkg/entity"
 	orderEntity "rakit-tiket-be/pkg/entity/app_order"
+	outboxEntity "rakit-tiket-be/pkg/entity/app_outbox"
 	appPayment "rakit-tiket-be/pkg/entity/app_payment"
 	regEntity "rakit-tiket-be/pkg/entity/app_registrant"
-	ticketEntity "rakit-tiket-be/pkg/entity/app_ticket"
 	"rakit-tiket-be/pkg/util"
 
 	"go.uber.org/zap"
@@ -37,16 +38,14 @@ type ManualTransferService interface {
 }
 
 type manualTransferService struct {
-	log          util.LogUtil
-	sqlDB        *sql.DB
-	emailService email.EmailService
+	log   util.LogUtil
+	sqlDB *sql.DB
 }
 
-func MakeManualTransferService(log util.LogUtil, sqlDB *sql.DB, emailService email.EmailService) ManualTransferService {
+func MakeManualTransferService(log util.LogUtil, sqlDB *sql.DB) ManualTransferService {
 	return &manualTransferService{
-		log:          log,
-		sqlDB:        sqlDB,
-		emailService: emailService,
+		log:   log,
+		sqlDB: sqlDB,
 	}
 }
 
@@ -180,6 +179,9 @@ func (s *manualTransferService) ApproveTransfer(ctx context.Context, transferID
 			return fmt.Errorf("failed to confirm sold: %w", err)
 		}
 	}
+	if err := addonSvc.ConfirmOrderAddons(ctx, dbTrx, []pubEntity.UUID{order.ID}); err != nil {
+		return fmt.Errorf("failed to confirm sold addons: %w", err)
+	}
 
 	order.PaymentStatus = "paid"
 	order.PaymentTime = &now
@@ -204,12 +206,20 @@ func (s *manualTransferService) ApproveTransfer(ctx context.Context, transferID
 		return fmt.Errorf("failed to update transfer: %w", err)
 	}
 
+	if _, err := orderService.IssueETickets(ctx, dbTrx, order, registrant, attendees); err != nil {
+		return fmt.Errorf("failed to issue e-tickets: %w", err)
+	}
+
+	if err := dbTrx.GetEmailOutboxDAO().Enqueue(ctx, outboxEntity.EmailTypeTransferApproved, registrant.Email, &order.ID, outboxEntity.EmailPayload{
+		OrderNumber: order.OrderNumber,
+	}); err != nil {
+		return fmt.Errorf("failed to enqueue approval email: %w", err)
+	}
+
 	if err := dbTrx.GetSqlTx().Commit(); err != nil {
 		return fmt.Errorf("failed to commit: %w", err)
 	}
 
-	s.sendTicketEmailAsync(ctx, order, registrant, ticketQtyMap)
-
 	return nil
 }
 
@@ -267,12 +277,14 @@ func (s *manualTransferService) RejectTransfer(ctx context.Context, transferID s
 		return fmt.Errorf("failed to update transfer: %w", err)
 	}
 
+	if err := s.enqueuePaymentEmail(ctx, dbTrx, outboxEntity.EmailTypePaymentRejected, order, registrant, notes); err != nil {
+		return fmt.Errorf("failed to enqueue rejection email: %w", err)
+	}
+
 	if err := dbTrx.GetSqlTx().Commit(); err != nil {
 		return fmt.Errorf("failed to commit: %w", err)
 	}
 
-	s.sendRejectionEmailAsync(ctx, order, registrant, notes)
-
 	return nil
 }
 
@@ -322,13 +334,28 @@ func (s *manualTransferService) CancelTransfer(ctx context.Context, transferID s
 		ticketQtyMap[string(att.TicketID)]++
 	}
 
+	var releasedTicketIDs []string
 	for tID, qty := range ticketQtyMap {
 		if err := dbTrx.GetTicketDAO().ReleaseBooked(ctx, pubEntity.UUID(tID), qty); err != nil {
 			s.log.Error(ctx, "failed to release booked tickets during cancellation", zap.String("ticket_id", tID), zap.Int("qty", qty), zap.Error(err))
 		}
+		releasedTicketIDs = append(releasedTicketIDs, tID)
+	}
+
+	if err := dbTrx.GetPromoRedemptionDAO().ReleaseByOrder(ctx, order.ID); err != nil {
+		return fmt.Errorf("failed to release promo redemption: %w", err)
+	}
+
+	if err := addonSvc.ReleaseOrderAddons(ctx, dbTrx, []pubEntity.UUID{order.ID}); err != nil {
+		s.log.Error(ctx, "failed to release booked addons during cancellation", zap.Error(err))
 	}
 
 	now := time.Now()
+
+	// Stok yang dilepas ditawarkan dulu ke antrean waitlist tiket tersebut
+	if _, err := waitlistSvc.OfferReleasedStock(ctx, dbTrx, releasedTicketIDs, now); err != nil {
+		return fmt.Errorf("failed to offer released stock to waitlist: %w", err)
+	}
 	order.PaymentStatus = orderEntity.OrderStatusFailed
 	order.PaymentMethod = strPtr("MANUAL_TRANSFER")
 
@@ -351,108 +378,29 @@ func (s *manualTransferService) CancelTransfer(ctx context.Context, transferID s
 		return fmt.Errorf("failed to update transfer: %w", err)
 	}
 
+	if err := s.enqueuePaymentEmail(ctx, dbTrx, outboxEntity.EmailTypePaymentCancelled, order, registrant, notes); err != nil {
+		return fmt.Errorf("failed to enqueue cancellation email: %w", err)
+	}
+
 	if err := dbTrx.GetSqlTx().Commit(); err != nil {
 		return fmt.Errorf("failed to commit: %w", err)
 	}
 
-	s.sendCancellationEmailAsync(ctx, order, registrant, notes)
-
 	return nil
 }
 
-func (s *manualTransferService) sendTicketEmailAsync(ctx context.Context, order orderEntity.Order, registrant regEntity.Registrant, ticketQtyMap map[string]int) {
-	var ticketIDs []string
-	for tID := range ticketQtyMap {
-		ticketIDs = append(ticketIDs, tID)
-	}
-
-	ticketMap := make(map[string]ticketEntity.Ticket)
-	if len(ticketIDs) > 0 {
-		dbTrx := dao.NewTransactionPayment(ctx, s.log, s.sqlDB)
-		tickets, err := dbTrx.GetTicketDAO().Search(ctx, ticketEntity.TicketQuery{
-			IDs: ticketIDs,
-		})
-		if err != nil {
-			s.log.Error(ctx, "Failed to fetch ticket data for PDF", zap.Error(err))
-		} else {
-			for _, t := range tickets {
-				ticketMap[string(t.ID)] = t
-			}
-		}
-	}
-
-	dynamicEvent := orderSvc.EventDynamicData{
-		EventName:      "Rakit Tiket Event",
-		EventDate:      "Belum Ditentukan",
-		EventTimeStart: "-",
-		EventTimeEnd:   "-",
-		EventLocation:  "Venue Terpilih",
-	}
-
-	_ = s.sqlDB.QueryRowContext(ctx, "SELECT name FROM events WHERE id = $1", order.EventID).Scan(&dynamicEvent.EventName)
-	_ = s.sqlDB.QueryRowContext(ctx, "SELECT event_date, event_time_start, event_time_end, event_location FROM landing_pages WHERE event_id = $1", order.EventID).
-		Scan(&dynamicEvent.EventDate, &dynamicEvent.EventTimeStart, &dynamicEvent.EventTimeEnd, &dynamicEvent.EventLocation)
-
-	attachments, err := orderSvc.GenerateTicketsPDF(order, registrant, ticketMap, dynamicEvent)
-	if err != nil {
-		s.log.Error(ctx, "Failed to generate PDF tickets", zap.Error(err))
-		return
-	}
-
-	s.log.Info(ctx, "Successfully generated PDF tickets", zap.Int("total", len(attachments)))
-
-	var emailAtts []email.Attachment
-	for _, att := range attachments {
-		emailAtts = append(emailAtts, email.Attachment{
-			FileName: att.FileName,
-			Data:     att.Data,
-		})
-	}
-
-	go func(targetEmail, ordNum, evtName, ownerName string, atts []email.Attachment) {
-		bgCtx := context.Background()
-
-		err := s.emailService.SendTransferApprovalEmail(bgCtx, targetEmail, ordNum, evtName, ownerName, atts)
-		if err != nil {
-			s.log.Error(bgCtx, "Gagal mengirim email approval transfer", zap.Error(err))
-		} else {
-			s.log.Info(bgCtx, "Email approval transfer berhasil terkirim!", zap.String("to", targetEmail))
-		}
-	}(registrant.Email, order.OrderNumber, dynamicEvent.EventName, registrant.Name, emailAtts)
-}
-
-func (s *manualTransferService) sendRejectionEmailAsync(ctx context.Context, order orderEntity.Order, registrant regEntity.Registrant, reason string) {
+// enqueuePaymentEmail menulis email penolakan / pembatalan ke outbox dalam transaksi yang sama
+func (s *manualTransferService) enqueuePaymentEmail(ctx context.Context, dbTrx dao.DBTransaction, emailType outboxEntity.EmailType, order orderEntity.Order, registrant regEntity.Registrant, reason string) error {
 	eventName := "Rakit Tiket Event"
 
 	_ = s.sqlDB.QueryRowContext(ctx, "SELECT name FROM events WHERE id = $1", order.EventID).Scan(&eventName)
 
-	go func(ordNum, evtName, ownerName, rejectionReason string) {
-		bgCtx := context.Background()
-
-		err := s.emailService.SendPaymentRejectedEmail(bgCtx, registrant.Email, ordNum, evtName, ownerName, rejectionReason)
-		if err != nil {
-			s.log.Error(bgCtx, "Gagal mengirim email penolakan transfer", zap.Error(err))
-		} else {
-			s.log.Info(bgCtx, "Email penolakan transfer berhasil terkirim!", zap.String("to", registrant.Email))
-		}
-	}(order.OrderNumber, eventName, registrant.Name, reason)
-}
-
-func (s *manualTransferService) sendCancellationEmailAsync(ctx context.Context, order orderEntity.Order, registrant regEntity.Registrant, reason string) {
-	eventName := "Rakit Tiket Event"
-
-	_ = s.sqlDB.QueryRowContext(ctx, "SELECT name FROM events WHERE id = $1", order.EventID).Scan(&eventName)
-
-	go func(ordNum, evtName, ownerName, cancelReason string) {
-		bgCtx := context.Background()
-
-		err := s.emailService.SendPaymentCancelledEmail(bgCtx, registrant.Email, ordNum, evtName, ownerName, cancelReason)
-		if err != nil {
-			s.log.Error(bgCtx, "Gagal mengirim email pembatalan transfer", zap.Error(err))
-		} else {
-			s.log.Info(bgCtx, "Email pembatalan transfer berhasil terkirim!", zap.String("to", registrant.Email))
-		}
-	}(order.OrderNumber, eventName, registrant.Name, reason)
+	return dbTrx.GetEmailOutboxDAO().Enqueue(ctx, emailType, registrant.Email, &order.ID, outboxEntity.EmailPayload{
+		OrderNumber: order.OrderNumber,
+		EventName:   eventName,
+		OwnerName:   registrant.Name,
+		Reason:      reason,
+	})
 }
 
 func (s *manualTransferService) enrichTransferDetails(ctx context.Context, transfer *appPayment.ManualTransfer) (*ManualTransferWithDetails, error) {
@@ -0,0 +1,489 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	addonSvc "rakit-tiket-be/internal/app/app_addon/service"
+	orderSvc "rakit-tiket-be/internal/app/app_order/service"
+	"rakit-tiket-be/internal/app/app_payment/dao"
+	waitlistSvc "rakit-tiket-be/internal/app/app_waitlist/service"
+	"rakit-tiket-be/internal/pkg/payment"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	orderEntity "rakit-tiket-be/pkg/entity/app_order"
+	outboxEntity "rakit-tiket-be/pkg/entity/app_outbox"
+	appPayment "rakit-tiket-be/pkg/entity/app_payment"
+	regEntity "rakit-tiket-be/pkg/entity/app_registrant"
+	"rakit-tiket-be/pkg/util"
+)
+
+var (
+	ErrRefundNotFound       = errors.New("refund not found")
+	ErrOrderNotRefundable   = errors.New("only paid orders can be refunded")
+	ErrInvalidRefundAmount  = errors.New("invalid refund amount")
+	ErrRefundAmountExceeded = errors.New("refund amount exceeds remaining order amount")
+	ErrRefundInProgress     = errors.New("a gateway refund for this order has not been confirmed yet, retry it with the same type and amount")
+)
+
+type RefundService interface {
+	RefundOrder(ctx context.Context, orderNumber string, req RefundOrderRequest, adminID string) (*appPayment.Refund, error)
+	CancelOrder(ctx context.Context, orderNumber string, req CancelOrderRequest, adminID string) (*appPayment.Refund, error)
+	CompleteRefund(ctx context.Context, refundID string, adminID string) error
+	GetRefundsByOrderNumber(ctx context.Context, orderNumber string) (appPayment.Refunds, error)
+}
+
+type refundService struct {
+	log            util.LogUtil
+	sqlDB          *sql.DB
+	paymentFactory *payment.PaymentFactory
+}
+
+func MakeRefundService(log util.LogUtil, sqlDB *sql.DB, paymentFactory *payment.PaymentFactory) RefundService {
+	return &refundService{
+		log:            log,
+		sqlDB:          sqlDB,
+		paymentFactory: paymentFactory,
+	}
+}
+
+// RefundOrder mengembalikan dana order (full atau partial). Amount kosong = full refund.
+func (s *refundService) RefundOrder(ctx context.Context, orderNumber string, req RefundOrderRequest, adminID string) (*appPayment.Refund, error) {
+	return s.processRefund(ctx, orderNumber, appPayment.RefundTypeRefund, req.Amount, req.Reason, req.BankAccount, adminID)
+}
+
+// CancelOrder membatalkan order yang sudah dibayar, selalu full dan tiket dikembalikan ke stok
+func (s *refundService) CancelOrder(ctx context.Context, orderNumber string, req CancelOrderRequest, adminID string) (*appPayment.Refund, error) {
+	return s.processRefund(ctx, orderNumber, appPayment.RefundTypeCancel, nil, req.Reason, req.BankAccount, adminID)
+}
+
+func (s *refundService) processRefund(ctx context.Context, orderNumber string, refundType appPayment.RefundType, amount *float64, reason string, bankAccount *RefundBankAccount, adminID string) (*appPayment.Refund, error) {
+	dbTrx := dao.NewTransactionPayment(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	// Lock row order agar refund paralel tidak melebihi nilai order
+	orders, err := dbTrx.GetOrderDAO().SearchForUpdate(ctx, orderEntity.OrderQuery{
+		OrderNumbers: []string{orderNumber},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, ErrOrderNotFound
+	}
+	order := orders[0]
+
+	if order.PaymentStatus != orderEntity.OrderStatusPaid {
+		return nil, ErrOrderNotRefundable
+	}
+
+	existingRefunds, err := dbTrx.GetRefundDAO().Search(ctx, appPayment.RefundQuery{
+		OrderIDs: []string{string(order.ID)},
+		Statuses: []string{string(appPayment.RefundStatusPending), string(appPayment.RefundStatusCompleted)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Refund gateway yang belum diterima gateway (request sebelumnya gagal / timeout) dikirim ulang
+	// dengan refund_key yang sama agar gateway tidak memproses refund dua kali
+	for _, r := range existingRefunds {
+		if r.Method != appPayment.RefundMethodGateway || r.Status != appPayment.RefundStatusPending || r.SubmittedAt != nil {
+			continue
+		}
+		if r.Type != refundType || (amount != nil && math.Abs(*amount-r.Amount) > 0.01) {
+			return nil, ErrRefundInProgress
+		}
+		if err := dbTrx.GetSqlTx().Commit(); err != nil {
+			return nil, fmt.Errorf("failed to commit: %w", err)
+		}
+		return s.submitGatewayRefund(ctx, order, r)
+	}
+
+	var refundedAmount float64
+	for _, r := range existingRefunds {
+		refundedAmount += r.Amount
+	}
+	remaining := order.Amount - refundedAmount
+
+	refundAmount := remaining
+	if amount != nil {
+		refundAmount = *amount
+	}
+	if refundAmount <= 0 {
+		return nil, ErrInvalidRefundAmount
+	}
+	if refundAmount > remaining+0.01 {
+		return nil, ErrRefundAmountExceeded
+	}
+
+	// Order dianggap full refund jika sisa dana sudah habis dikembalikan
+	isFull := refundType == appPayment.RefundTypeCancel || remaining-refundAmount <= 0.01
+
+	now := time.Now()
+	processedBy := pubEntity.UUID(adminID)
+	refund := appPayment.Refund{
+		ID:          pubEntity.MakeUUID("RF", string(order.ID), now.String()),
+		OrderID:     order.ID,
+		Type:        refundType,
+		Status:      appPayment.RefundStatusPending,
+		Amount:      refundAmount,
+		IsFull:      isFull,
+		Reason:      &reason,
+		ProcessedBy: &processedBy,
+		DaoEntity: pubEntity.DaoEntity{
+			Deleted:   false,
+			CreatedAt: now,
+		},
+	}
+
+	isGateway := order.PaymentType != nil && *order.PaymentType == orderEntity.PaymentTypeGateway && order.PaymentGateway != nil
+	if isGateway {
+		// Refund dicatat PENDING dan di-commit sebelum request ke gateway, sehingga request yang
+		// gagal di tengah jalan tetap tercatat dan bisa dikirim ulang dengan refund_key yang sama
+		gateway := *order.PaymentGateway
+		refundKey := string(refund.ID)
+		refund.Method = appPayment.RefundMethodGateway
+		refund.Gateway = &gateway
+		refund.RefundKey = &refundKey
+
+		if err := dbTrx.GetRefundDAO().Insert(ctx, appPayment.Refunds{refund}); err != nil {
+			return nil, fmt.Errorf("failed to insert refund: %w", err)
+		}
+		if err := dbTrx.GetSqlTx().Commit(); err != nil {
+			return nil, fmt.Errorf("failed to commit: %w", err)
+		}
+		return s.submitGatewayRefund(ctx, order, refund)
+	}
+
+	// Transfer manual: dana dikembalikan oleh admin lewat transfer bank, diselesaikan via CompleteRefund
+	refund.Method = appPayment.RefundMethodBankTransfer
+	if bankAccount != nil {
+		refund.BankName = &bankAccount.BankName
+		refund.AccountNumber = &bankAccount.AccountNumber
+		refund.AccountHolder = &bankAccount.AccountHolder
+	}
+
+	if err := dbTrx.GetRefundDAO().Insert(ctx, appPayment.Refunds{refund}); err != nil {
+		return nil, fmt.Errorf("failed to insert refund: %w", err)
+	}
+
+	if err := s.applyRefund(ctx, dbTrx, order, refund, now); err != nil {
+		return nil, err
+	}
+
+	if err := dbTrx.GetSqlTx().Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit: %w", err)
+	}
+
+	return &refund, nil
+}
+
+// submitGatewayRefund mengirim refund PENDING yang sudah tersimpan ke gateway, lalu mencatat hasilnya.
+// Jika gateway gagal, refund tetap PENDING tanpa submitted_at dan request berikutnya mengirim ulang.
+func (s *refundService) submitGatewayRefund(ctx context.Context, order orderEntity.Order, refund appPayment.Refund) (*appPayment.Refund, error) {
+	gatewayErr := s.refundViaGateway(ctx, order, &refund)
+
+	dbTrx := dao.NewTransactionPayment(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	orders, err := dbTrx.GetOrderDAO().SearchForUpdate(ctx, orderEntity.OrderQuery{
+		IDs: []string{string(order.ID)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, ErrOrderNotFound
+	}
+	order = orders[0]
+
+	current, err := dbTrx.GetRefundDAO().GetByID(ctx, refund.ID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, ErrRefundNotFound
+	}
+	// Request paralel dengan refund_key yang sama sudah mencatat hasil gateway
+	if current.SubmittedAt != nil || current.Status != appPayment.RefundStatusPending {
+		return current, nil
+	}
+
+	if gatewayErr != nil {
+		// Gateway tidak mendukung operasi ini: refund tidak akan pernah diproses, jangan tahan sisa dana order
+		if errors.Is(gatewayErr, payment.ErrOperationNotSupported) {
+			current.Status = appPayment.RefundStatusFailed
+			if err := dbTrx.GetRefundDAO().Update(ctx, appPayment.Refunds{*current}); err != nil {
+				return nil, fmt.Errorf("failed to update refund: %w", err)
+			}
+			if err := dbTrx.GetSqlTx().Commit(); err != nil {
+				return nil, fmt.Errorf("failed to commit: %w", err)
+			}
+		}
+		return nil, gatewayErr
+	}
+
+	now := time.Now()
+	refund.SubmittedAt = &now
+	if refund.Status == appPayment.RefundStatusCompleted {
+		refund.CompletedAt = &now
+	}
+	if err := dbTrx.GetRefundDAO().Update(ctx, appPayment.Refunds{refund}); err != nil {
+		return nil, fmt.Errorf("failed to update refund: %w", err)
+	}
+
+	if err := s.applyRefund(ctx, dbTrx, order, refund, now); err != nil {
+		return nil, err
+	}
+
+	if err := dbTrx.GetSqlTx().Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit: %w", err)
+	}
+
+	return &refund, nil
+}
+
+// applyRefund mengembalikan stok dan menutup order untuk full refund, lalu mengantrikan email refund
+func (s *refundService) applyRefund(ctx context.Context, dbTrx dao.DBTransaction, order orderEntity.Order, refund appPayment.Refund, now time.Time) error {
+	registrants, _, err := dbTrx.GetRegistrantDAO().Search(ctx, regEntity.RegistrantQuery{
+		IDs: []string{string(order.RegistrantID)},
+	})
+	if err != nil || len(registrants) == 0 {
+		return errors.New("registrant not found")
+	}
+	registrant := registrants[0]
+
+	if refund.IsFull {
+		ticketQtyMap, err := s.orderTicketQty(ctx, dbTrx, order, registrant)
+		if err != nil {
+			return err
+		}
+
+		var releasedTicketIDs []string
+		for tID, qty := range ticketQtyMap {
+			if err := dbTrx.GetTicketDAO().ReleaseSold(ctx, pubEntity.UUID(tID), qty); err != nil {
+				return fmt.Errorf("failed to release sold tickets: %w", err)
+			}
+			releasedTicketIDs = append(releasedTicketIDs, tID)
+		}
+
+		if err := addonSvc.RefundOrderAddons(ctx, dbTrx, []pubEntity.UUID{order.ID}); err != nil {
+			return fmt.Errorf("failed to release sold addons: %w", err)
+		}
+
+		// Stok yang dilepas ditawarkan dulu ke antrean waitlist tiket tersebut
+		if _, err := waitlistSvc.OfferReleasedStock(ctx, dbTrx, releasedTicketIDs, now); err != nil {
+			return fmt.Errorf("failed to offer released stock to waitlist: %w", err)
+		}
+
+		// E-ticket otomatis tidak berlaku karena scan gate mensyaratkan order berstatus paid
+		if refund.Type == appPayment.RefundTypeCancel {
+			order.PaymentStatus = orderEntity.OrderStatusCancelled
+		} else {
+			order.PaymentStatus = orderEntity.OrderStatusRefunded
+		}
+		if err := dbTrx.GetOrderDAO().Update(ctx, []orderEntity.Order{order}); err != nil {
+			return fmt.Errorf("failed to update order: %w", err)
+		}
+
+		registrant.Status = orderEntity.OrderStatusRefunded
+		if refund.Type == appPayment.RefundTypeCancel {
+			registrant.Status = orderEntity.OrderStatusCancelled
+		}
+		if err := dbTrx.GetRegistrantDAO().Update(ctx, []regEntity.Registrant{registrant}); err != nil {
+			return fmt.Errorf("failed to update registrant: %w", err)
+		}
+	}
+
+	if err := s.enqueueRefundEmail(ctx, dbTrx, order, registrant, refund); err != nil {
+		return fmt.Errorf("failed to enqueue refund email: %w", err)
+	}
+
+	return nil
+}
+
+func (s *refundService) refundViaGateway(ctx context.Context, order orderEntity.Order, refund *appPayment.Refund) error {
+	provider, err := s.paymentFactory.GetProviderByCode(*order.PaymentGateway)
+	if err != nil {
+		return fmt.Errorf("unsupported gateway: %s", *order.PaymentGateway)
+	}
+
+	transactionID := ""
+	if order.PaymentTransactionID != nil {
+		transactionID = *order.PaymentTransactionID
+	} else if order.PaymentToken != nil {
+		transactionID = *order.PaymentToken
+	}
+
+	if refund.Type == appPayment.RefundTypeCancel {
+		err := provider.Cancel(ctx, payment.CancelRequest{
+			OrderID:       order.OrderNumber,
+			TransactionID: transactionID,
+		})
+		if err != nil {
+			return fmt.Errorf("payment gateway error: %w", err)
+		}
+		refund.Status = appPayment.RefundStatusCompleted
+		return nil
+	}
+
+	refundResp, err := provider.Refund(ctx, payment.RefundRequest{
+		OrderID:       order.OrderNumber,
+		TransactionID: transactionID,
+		RefundKey:     *refund.RefundKey,
+		Amount:        refund.Amount,
+		Reason:        *refund.Reason,
+	})
+	if err != nil {
+		return fmt.Errorf("payment gateway error: %w", err)
+	}
+
+	if refundResp.RefundID != "" {
+		refund.GatewayRefundID = &refundResp.RefundID
+	}
+
+	// Sebagian gateway (Xendit) memproses refund secara asynchronous
+	switch strings.ToUpper(refundResp.Status) {
+	case "PENDING", "REQUESTED":
+		refund.Status = appPayment.RefundStatusPending
+	default:
+		refund.Status = appPayment.RefundStatusCompleted
+	}
+
+	return nil
+}
+
+// orderTicketQty memakai order_items, fallback ke registrant + attendees untuk order lama
+func (s *refundService) orderTicketQty(ctx context.Context, dbTrx dao.DBTransaction, order orderEntity.Order, registrant regEntity.Registrant) (map[string]int, error) {
+	ticketQtyMap := make(map[string]int)
+
+	orderItems, err := dbTrx.GetOrderItemDAO().Search(ctx, orderEntity.OrderItemQuery{
+		OrderIDs: []string{string(order.ID)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(orderItems) > 0 {
+		for _, item := range orderItems {
+			ticketQtyMap[string(item.TicketID)] += item.Quantity
+		}
+		return ticketQtyMap, nil
+	}
+
+	attendees, err := dbTrx.GetAttendeeDAO().Search(ctx, regEntity.AttendeeQuery{
+		RegistrantIDs: []string{string(registrant.ID)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if registrant.TicketID != nil {
+		ticketQtyMap[string(*registrant.TicketID)]++
+	}
+	for _, att := range attendees {
+		ticketQtyMap[string(att.TicketID)]++
+	}
+
+	return ticketQtyMap, nil
+}
+
+// CompleteRefund menandai refund (umumnya transfer bank manual) sudah selesai dikirim
+func (s *refundService) CompleteRefund(ctx context.Context, refundID string, adminID string) error {
+	dbTrx := dao.NewTransactionPayment(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	refund, err := dbTrx.GetRefundDAO().GetByID(ctx, pubEntity.UUID(refundID))
+	if err != nil {
+		return err
+	}
+	if refund == nil {
+		return ErrRefundNotFound
+	}
+	if refund.Status != appPayment.RefundStatusPending {
+		return ErrInvalidStatus
+	}
+	// Refund gateway yang belum diterima gateway diselesaikan dengan mengirim ulang refund / cancel order
+	if refund.Method == appPayment.RefundMethodGateway && refund.SubmittedAt == nil {
+		return ErrRefundInProgress
+	}
+
+	now := time.Now()
+	processedBy := pubEntity.UUID(adminID)
+	refund.Status = appPayment.RefundStatusCompleted
+	refund.ProcessedBy = &processedBy
+	refund.CompletedAt = &now
+
+	if err := dbTrx.GetRefundDAO().Update(ctx, appPayment.Refunds{*refund}); err != nil {
+		return fmt.Errorf("failed to update refund: %w", err)
+	}
+
+	if err := dbTrx.GetSqlTx().Commit(); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+
+	return nil
+}
+
+func (s *refundService) GetRefundsByOrderNumber(ctx context.Context, orderNumber string) (appPayment.Refunds, error) {
+	dbTrx := dao.NewTransactionPayment(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	orders, err := dbTrx.GetOrderDAO().Search(ctx, orderEntity.OrderQuery{
+		OrderNumbers: []string{orderNumber},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, ErrOrderNotFound
+	}
+
+	return dbTrx.GetRefundDAO().Search(ctx, appPayment.RefundQuery{
+		OrderIDs: []string{string(orders[0].ID)},
+	})
+}
+
+// enqueueRefundEmail menulis email refund ke outbox dalam transaksi refund
+func (s *refundService) enqueueRefundEmail(ctx context.Context, dbTrx dao.DBTransaction, order orderEntity.Order, registrant regEntity.Registrant, refund appPayment.Refund) error {
+	eventName := "Rakit Tiket Event"
+
+	_ = s.sqlDB.QueryRowContext(ctx, "SELECT name FROM events WHERE id = $1", order.EventID).Scan(&eventName)
+
+	reason := "-"
+	if refund.Reason != nil && *refund.Reason != "" {
+		reason = *refund.Reason
+	}
+
+	return dbTrx.GetEmailOutboxDAO().Enqueue(ctx, outboxEntity.EmailTypeRefund, registrant.Email, &order.ID, outboxEntity.EmailPayload{
+		OrderNumber:     order.OrderNumber,
+		EventName:       eventName,
+		OwnerName:       registrant.Name,
+		Reason:          reason,
+		Amount:          orderSvc.FormatRupiah(refund.Amount),
+		IsFull:          refund.IsFull,
+		ViaBankTransfer: refund.Method == appPayment.RefundMethodBankTransfer,
+	})
+}
+
+type RefundBankAccount struct {
+	BankName      string `json:"bank_name" validate:"required"`
+	AccountNumber string `json:"account_number" validate:"required"`
+	AccountHolder string `json:"account_holder" validate:"required"`
+}
+
+type RefundOrderRequest struct {
+	Amount      *float64           `json:"amount"` // Kosong = refund sisa dana (full)
+	Reason      string             `json:"reason" validate:"required"`
+	BankAccount *RefundBankAccount `json:"bank_account"` // Rekening tujuan untuk order transfer manual
+}
+
+type CancelOrderRequest struct {
+	Reason      string             `json:"reason" validate:"required"`
+	BankAccount *RefundBankAccount `json:"bank_account"`
+}
@@ -0,0 +1,239 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	baseDao "rakit-tiket-be/internal/pkg/dao"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	entity "rakit-tiket-be/pkg/entity/app_presale"
+	"rakit-tiket-be/pkg/util"
+
+	"gitlab.com/threetopia/sqlgo/v2"
+	"go.uber.org/zap"
+)
+
+type AccessCodeDAO interface {
+	Search(ctx context.Context, query entity.AccessCodeQuery) (entity.AccessCodes, error)
+	Insert(ctx context.Context, accessCodes entity.AccessCodes) error
+	Update(ctx context.Context, accessCodes entity.AccessCodes) error
+	SoftDelete(ctx context.Context, id pubEntity.UUID) error
+}
+
+type accessCodeDAO struct {
+	log   util.LogUtil
+	dbTrx baseDao.DBTransaction
+}
+
+func MakeAccessCodeDAO(log util.LogUtil, dbTrx baseDao.DBTransaction) AccessCodeDAO {
+	return accessCodeDAO{
+		log:   log,
+		dbTrx: dbTrx,
+	}
+}
+
+func (d accessCodeDAO) Search(ctx context.Context, query entity.AccessCodeQuery) (entity.AccessCodes, error) {
+
+	sqlSelect := sqlgo.NewSQLGoSelect().
+		SetSQLSelect("ac.id", "id").
+		SetSQLSelect("ac.event_id", "event_id").
+		SetSQLSelect("ac.code", "code").
+		SetSQLSelect("ac.description", "description").
+		SetSQLSelect("ac.ticket_ids", "ticket_ids").
+		SetSQLSelect("ac.is_active", "is_active").
+		SetSQLSelect("ac.deleted", "deleted").
+		SetSQLSelect("ac.data_hash", "data_hash").
+		SetSQLSelect("ac.created_at", "created_at").
+		SetSQLSelect("ac.updated_at", "updated_at")
+
+	sqlFrom := sqlgo.NewSQLGoFrom().
+		SetSQLFrom("presale_access_codes", "ac")
+
+	sqlWhere := sqlgo.NewSQLGoWhere()
+	sqlWhere.SetSQLWhere("AND", "ac.deleted", "=", false)
+
+	if len(query.IDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "ac.id", "IN", query.IDs)
+	}
+	if len(query.EventIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "ac.event_id", "IN", query.EventIDs)
+	}
+	if len(query.Codes) > 0 {
+		codes := make([]string, 0, len(query.Codes))
+		for _, c := range query.Codes {
+			codes = append(codes, strings.ToUpper(strings.TrimSpace(c)))
+		}
+		sqlWhere.SetSQLWhere("AND", "UPPER(ac.code)", "IN", codes)
+	}
+	if query.IsActive != nil {
+		sqlWhere.SetSQLWhere("AND", "ac.is_active", "=", *query.IsActive)
+	}
+
+	sqlOrder := sqlgo.NewSQLGoOrder()
+	sqlOrder.SetSQLOrder("ac.created_at", "DESC")
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoSelect(sqlSelect).
+		SetSQLGoFrom(sqlFrom).
+		SetSQLGoWhere(sqlWhere).
+		SetSQLGoOrder(sqlOrder)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "accessCodeDAO.Search",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	rows, err := d.dbTrx.GetSqlDB().QueryContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "accessCodeDAO.Search", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	return d.scanRows(ctx, rows)
+}
+
+func (d accessCodeDAO) scanRows(ctx context.Context, rows *sql.Rows) (entity.AccessCodes, error) {
+	var accessCodes entity.AccessCodes
+	for rows.Next() {
+		var accessCode entity.AccessCode
+		var ticketIDsJSON []byte
+
+		if err := rows.Scan(
+			&accessCode.ID, &accessCode.EventID, &accessCode.Code, &accessCode.Description,
+			&ticketIDsJSON, &accessCode.IsActive,
+			&accessCode.Deleted, &accessCode.DataHash,
+			&accessCode.CreatedAt, &accessCode.UpdatedAt,
+		); err != nil {
+			d.log.Error(ctx, "accessCodeDAO.scanRows", zap.Error(err))
+			return nil, err
+		}
+
+		accessCode.TicketIDs = unmarshalTicketIDs(ticketIDsJSON)
+		accessCodes = append(accessCodes, accessCode)
+	}
+
+	return accessCodes, nil
+}
+
+func (d accessCodeDAO) Insert(ctx context.Context, accessCodes entity.AccessCodes) error {
+	if len(accessCodes) < 1 {
+		return fmt.Errorf("empty presale access code data")
+	}
+
+	sqlInsert := sqlgo.NewSQLGoInsert().
+		SetSQLInsert("presale_access_codes").
+		SetSQLInsertColumn(
+			"id", "event_id", "code", "description",
+			"ticket_ids", "is_active",
+			"deleted", "data_hash", "created_at",
+		)
+
+	for i, accessCode := range accessCodes {
+		accessCode.CreatedAt = time.Now()
+		if accessCode.ID == "" {
+			accessCode.ID = pubEntity.MakeUUID("PRESALE_CODE", string(accessCode.EventID), accessCode.Code, accessCode.CreatedAt.String())
+		}
+
+		sqlInsert.SetSQLInsertValue(
+			accessCode.ID, accessCode.EventID, accessCode.Code, accessCode.Description,
+			marshalTicketIDs(accessCode.TicketIDs), accessCode.IsActive,
+			accessCode.Deleted, accessCode.DataHash, accessCode.CreatedAt,
+		)
+		accessCodes[i] = accessCode
+	}
+
+	sql := sqlgo.NewSQLGo().SetSQLSchema("public").SetSQLGoInsert(sqlInsert)
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "accessCodeDAO.Insert", zap.String("SQL", sqlStr), zap.Int("Count", len(accessCodes)))
+
+	_, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "accessCodeDAO.Insert", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Update tidak memindahkan kode ke event lain
+func (d accessCodeDAO) Update(ctx context.Context, accessCodes entity.AccessCodes) error {
+	if len(accessCodes) < 1 {
+		return fmt.Errorf("empty presale access code data")
+	}
+
+	for i, accessCode := range accessCodes {
+		now := time.Now()
+		accessCode.UpdatedAt = &now
+
+		sql := sqlgo.NewSQLGo().
+			SetSQLSchema("public").
+			SetSQLUpdate("presale_access_codes").
+			SetSQLUpdateValue("code", accessCode.Code).
+			SetSQLUpdateValue("description", accessCode.Description).
+			SetSQLUpdateValue("ticket_ids", marshalTicketIDs(accessCode.TicketIDs)).
+			SetSQLUpdateValue("is_active", accessCode.IsActive).
+			SetSQLUpdateValue("data_hash", accessCode.DataHash).
+			SetSQLUpdateValue("updated_at", accessCode.UpdatedAt).
+			SetSQLWhere("AND", "id", "=", accessCode.ID)
+
+		sqlStr := sql.BuildSQL()
+		sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+		d.log.Debug(ctx, "accessCodeDAO.Update", zap.String("ID", string(accessCode.ID)))
+
+		if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...); err != nil {
+			d.log.Error(ctx, "accessCodeDAO.Update", zap.Error(err))
+			return err
+		}
+		accessCodes[i] = accessCode
+	}
+	return nil
+}
+
+func (d accessCodeDAO) SoftDelete(ctx context.Context, id pubEntity.UUID) error {
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLUpdate("presale_access_codes").
+		SetSQLUpdateValue("deleted", true).
+		SetSQLUpdateValue("updated_at", time.Now()).
+		SetSQLWhere("AND", "id", "=", id)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "accessCodeDAO.SoftDelete", zap.String("ID", string(id)))
+
+	if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...); err != nil {
+		d.log.Error(ctx, "accessCodeDAO.SoftDelete", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func marshalTicketIDs(ticketIDs []string) []byte {
+	ticketIDsJSON, err := json.Marshal(ticketIDs)
+	if err != nil || ticketIDs == nil {
+		return []byte("[]")
+	}
+	return ticketIDsJSON
+}
+
+func unmarshalTicketIDs(ticketIDsJSON []byte) []string {
+	ticketIDs := []string{}
+	if len(ticketIDsJSON) > 0 {
+		if err := json.Unmarshal(ticketIDsJSON, &ticketIDs); err != nil {
+			return []string{}
+		}
+	}
+	return ticketIDs
+}
@@ -0,0 +1,277 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	baseDao "rakit-tiket-be/internal/pkg/dao"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	entity "rakit-tiket-be/pkg/entity/app_presale"
+	"rakit-tiket-be/pkg/util"
+
+	"gitlab.com/threetopia/sqlgo/v2"
+	"go.uber.org/zap"
+)
+
+// Batas baris per INSERT email, menjaga jumlah parameter di bawah batas PostgreSQL
+const allowlistEmailChunk = 1000
+
+type AllowlistDAO interface {
+	Search(ctx context.Context, query entity.AllowlistQuery) (entity.Allowlists, error)
+	Insert(ctx context.Context, allowlists entity.Allowlists) error
+	Update(ctx context.Context, allowlists entity.Allowlists) error
+	SoftDelete(ctx context.Context, id pubEntity.UUID) error
+	// AddEmails menambah email ke allowlist, email yang sudah ada dilewati; mengembalikan jumlah email baru
+	AddEmails(ctx context.Context, allowlistID pubEntity.UUID, emails []string) (int64, error)
+	// ClearEmails menghapus semua email allowlist, dipakai saat upload mengganti isi allowlist
+	ClearEmails(ctx context.Context, allowlistID pubEntity.UUID) error
+}
+
+type allowlistDAO struct {
+	log   util.LogUtil
+	dbTrx baseDao.DBTransaction
+}
+
+func MakeAllowlistDAO(log util.LogUtil, dbTrx baseDao.DBTransaction) AllowlistDAO {
+	return allowlistDAO{
+		log:   log,
+		dbTrx: dbTrx,
+	}
+}
+
+func (d allowlistDAO) Search(ctx context.Context, query entity.AllowlistQuery) (entity.Allowlists, error) {
+
+	sqlSelect := sqlgo.NewSQLGoSelect().
+		SetSQLSelect("a.id", "id").
+		SetSQLSelect("a.event_id", "event_id").
+		SetSQLSelect("a.name", "name").
+		SetSQLSelect("a.ticket_ids", "ticket_ids").
+		SetSQLSelect("(SELECT COUNT(*) FROM presale_allowlist_emails c WHERE c.allowlist_id = a.id)", "email_count").
+		SetSQLSelect("a.deleted", "deleted").
+		SetSQLSelect("a.data_hash", "data_hash").
+		SetSQLSelect("a.created_at", "created_at").
+		SetSQLSelect("a.updated_at", "updated_at")
+
+	sqlFrom := sqlgo.NewSQLGoFrom().
+		SetSQLFrom("presale_allowlists", "a")
+
+	sqlWhere := sqlgo.NewSQLGoWhere()
+	sqlWhere.SetSQLWhere("AND", "a.deleted", "=", false)
+
+	if len(query.IDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "a.id", "IN", query.IDs)
+	}
+	if len(query.EventIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "a.event_id", "IN", query.EventIDs)
+	}
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoSelect(sqlSelect).
+		SetSQLGoFrom(sqlFrom)
+
+	if len(query.Emails) > 0 {
+		emails := make([]string, 0, len(query.Emails))
+		for _, e := range query.Emails {
+			emails = append(emails, normalizeEmail(e))
+		}
+
+		// Satu baris per allowlist karena email unik per allowlist; beberapa email bisa menggandakan baris
+		sqlJoin := sqlgo.NewSQLGoJoin()
+		sqlJoin.SetSQLJoin("INNER", "presale_allowlist_emails", "e", sqlgo.SetSQLJoinWhere("AND", "e.allowlist_id", "=", "a.id"))
+		sql.SetSQLGoJoin(sqlJoin)
+
+		sqlWhere.SetSQLWhere("AND", "e.email", "IN", emails)
+	}
+
+	sqlOrder := sqlgo.NewSQLGoOrder()
+	sqlOrder.SetSQLOrder("a.created_at", "DESC")
+
+	sql.SetSQLGoWhere(sqlWhere).
+		SetSQLGoOrder(sqlOrder)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "allowlistDAO.Search",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	rows, err := d.dbTrx.GetSqlDB().QueryContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "allowlistDAO.Search", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var allowlists entity.Allowlists
+	for rows.Next() {
+		var allowlist entity.Allowlist
+		var ticketIDsJSON []byte
+
+		if err := rows.Scan(
+			&allowlist.ID, &allowlist.EventID, &allowlist.Name,
+			&ticketIDsJSON, &allowlist.EmailCount,
+			&allowlist.Deleted, &allowlist.DataHash,
+			&allowlist.CreatedAt, &allowlist.UpdatedAt,
+		); err != nil {
+			d.log.Error(ctx, "allowlistDAO.Search.Scan", zap.Error(err))
+			return nil, err
+		}
+
+		allowlist.TicketIDs = unmarshalTicketIDs(ticketIDsJSON)
+		allowlists = append(allowlists, allowlist)
+	}
+
+	return allowlists, nil
+}
+
+func (d allowlistDAO) Insert(ctx context.Context, allowlists entity.Allowlists) error {
+	if len(allowlists) < 1 {
+		return fmt.Errorf("empty presale allowlist data")
+	}
+
+	sqlInsert := sqlgo.NewSQLGoInsert().
+		SetSQLInsert("presale_allowlists").
+		SetSQLInsertColumn(
+			"id", "event_id", "name", "ticket_ids",
+			"deleted", "data_hash", "created_at",
+		)
+
+	for i, allowlist := range allowlists {
+		allowlist.CreatedAt = time.Now()
+		if allowlist.ID == "" {
+			allowlist.ID = pubEntity.MakeUUID("PRESALE_ALLOWLIST", string(allowlist.EventID), allowlist.Name, allowlist.CreatedAt.String())
+		}
+
+		sqlInsert.SetSQLInsertValue(
+			allowlist.ID, allowlist.EventID, allowlist.Name, marshalTicketIDs(allowlist.TicketIDs),
+			allowlist.Deleted, allowlist.DataHash, allowlist.CreatedAt,
+		)
+		allowlists[i] = allowlist
+	}
+
+	sql := sqlgo.NewSQLGo().SetSQLSchema("public").SetSQLGoInsert(sqlInsert)
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "allowlistDAO.Insert", zap.String("SQL", sqlStr), zap.Int("Count", len(allowlists)))
+
+	_, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "allowlistDAO.Insert", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (d allowlistDAO) Update(ctx context.Context, allowlists entity.Allowlists) error {
+	if len(allowlists) < 1 {
+		return fmt.Errorf("empty presale allowlist data")
+	}
+
+	for i, allowlist := range allowlists {
+		now := time.Now()
+		allowlist.UpdatedAt = &now
+
+		sql := sqlgo.NewSQLGo().
+			SetSQLSchema("public").
+			SetSQLUpdate("presale_allowlists").
+			SetSQLUpdateValue("name", allowlist.Name).
+			SetSQLUpdateValue("ticket_ids", marshalTicketIDs(allowlist.TicketIDs)).
+			SetSQLUpdateValue("data_hash", allowlist.DataHash).
+			SetSQLUpdateValue("updated_at", allowlist.UpdatedAt).
+			SetSQLWhere("AND", "id", "=", allowlist.ID)
+
+		sqlStr := sql.BuildSQL()
+		sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+		d.log.Debug(ctx, "allowlistDAO.Update", zap.String("ID", string(allowlist.ID)))
+
+		if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...); err != nil {
+			d.log.Error(ctx, "allowlistDAO.Update", zap.Error(err))
+			return err
+		}
+		allowlists[i] = allowlist
+	}
+	return nil
+}
+
+func (d allowlistDAO) SoftDelete(ctx context.Context, id pubEntity.UUID) error {
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLUpdate("presale_allowlists").
+		SetSQLUpdateValue("deleted", true).
+		SetSQLUpdateValue("updated_at", time.Now()).
+		SetSQLWhere("AND", "id", "=", id)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "allowlistDAO.SoftDelete", zap.String("ID", string(id)))
+
+	if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...); err != nil {
+		d.log.Error(ctx, "allowlistDAO.SoftDelete", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (d allowlistDAO) AddEmails(ctx context.Context, allowlistID pubEntity.UUID, emails []string) (int64, error) {
+	var added int64
+	now := time.Now()
+
+	for start := 0; start < len(emails); start += allowlistEmailChunk {
+		end := start + allowlistEmailChunk
+		if end > len(emails) {
+			end = len(emails)
+		}
+
+		sqlInsert := sqlgo.NewSQLGoInsert().
+			SetSQLInsert("presale_allowlist_emails").
+			SetSQLInsertColumn("allowlist_id", "email", "created_at")
+
+		for _, email := range emails[start:end] {
+			sqlInsert.SetSQLInsertValue(allowlistID, normalizeEmail(email), now)
+		}
+
+		sql := sqlgo.NewSQLGo().SetSQLSchema("public").SetSQLGoInsert(sqlInsert)
+		sqlStr := sql.BuildSQL() + " ON CONFLICT DO NOTHING"
+		sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+		d.log.Debug(ctx, "allowlistDAO.AddEmails", zap.String("ID", string(allowlistID)), zap.Int("Count", end-start))
+
+		result, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...)
+		if err != nil {
+			d.log.Error(ctx, "allowlistDAO.AddEmails", zap.Error(err))
+			return 0, err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		added += rows
+	}
+
+	return added, nil
+}
+
+func (d allowlistDAO) ClearEmails(ctx context.Context, allowlistID pubEntity.UUID) error {
+	query := `DELETE FROM presale_allowlist_emails WHERE allowlist_id = $1`
+
+	d.log.Debug(ctx, "allowlistDAO.ClearEmails", zap.String("ID", string(allowlistID)))
+
+	if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, query, allowlistID); err != nil {
+		d.log.Error(ctx, "allowlistDAO.ClearEmails", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,62 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+
+	ticketDao "rakit-tiket-be/internal/app/app_ticket/dao"
+	baseDao "rakit-tiket-be/internal/pkg/dao"
+	"rakit-tiket-be/pkg/util"
+)
+
+// AccessDAOs cukup untuk resolve kode akses / allowlist, dipenuhi juga oleh transaksi modul lain (registrant)
+type AccessDAOs interface {
+	GetPresaleAccessCodeDAO() AccessCodeDAO
+	GetPresaleAllowlistDAO() AllowlistDAO
+}
+
+type DBTransaction interface {
+	baseDao.DBTransaction
+	AccessDAOs
+
+	GetTicketDAO() ticketDao.TicketDAO
+	GetPriceTierDAO() ticketDao.PriceTierDAO
+}
+
+type dbTransaction struct {
+	baseDao.DBTransaction
+
+	accessCodeDAO AccessCodeDAO
+	allowlistDAO  AllowlistDAO
+	ticketDAO     ticketDao.TicketDAO
+	priceTierDAO  ticketDao.PriceTierDAO
+}
+
+func NewTransactionPresale(ctx context.Context, log util.LogUtil, sqlDB *sql.DB) DBTransaction {
+	dbTrx := &dbTransaction{
+		DBTransaction: baseDao.NewTransaction(ctx, sqlDB),
+	}
+
+	dbTrx.accessCodeDAO = MakeAccessCodeDAO(log, dbTrx)
+	dbTrx.allowlistDAO = MakeAllowlistDAO(log, dbTrx)
+	dbTrx.ticketDAO = ticketDao.MakeTicketDAO(log, dbTrx)
+	dbTrx.priceTierDAO = ticketDao.MakePriceTierDAO(log, dbTrx)
+
+	return dbTrx
+}
+
+func (dbTrx *dbTransaction) GetPresaleAccessCodeDAO() AccessCodeDAO {
+	return dbTrx.accessCodeDAO
+}
+
+func (dbTrx *dbTransaction) GetPresaleAllowlistDAO() AllowlistDAO {
+	return dbTrx.allowlistDAO
+}
+
+func (dbTrx *dbTransaction) GetTicketDAO() ticketDao.TicketDAO {
+	return dbTrx.ticketDAO
+}
+
+func (dbTrx *dbTransaction) GetPriceTierDAO() ticketDao.PriceTierDAO {
+	return dbTrx.priceTierDAO
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"rakit-tiket-be/internal/app/app_presale/service"
+	"rakit-tiket-be/internal/pkg/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+type HttpHandler interface {
+	RegisterRoute(g *echo.Group)
+}
+
+type httpHandler struct {
+	presaleService service.PresaleService
+
+	presaleHandler PresaleHandler
+}
+
+func MakeHttpAdapter(
+	presaleService service.PresaleService,
+	authMiddleware middleware.AuthMiddleware,
+) HttpHandler {
+	return httpHandler{
+		presaleService: presaleService,
+		presaleHandler: MakePresaleHandler(presaleService, authMiddleware),
+	}
+}
+
+func (h httpHandler) RegisterRoute(g *echo.Group) {
+	h.presaleHandler.RegisterRouter(g)
+}
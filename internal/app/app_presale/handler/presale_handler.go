@@ -0,0 +1,245 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"rakit-tiket-be/internal/app/app_presale/service"
+	"rakit-tiket-be/internal/pkg/middleware"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	entity "rakit-tiket-be/pkg/entity/app_presale"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PresaleHandler interface {
+	RegisterRouter(g *echo.Group)
+}
+
+type presaleHandler struct {
+	presaleService service.PresaleService
+	middleware     middleware.AuthMiddleware
+}
+
+func MakePresaleHandler(
+	presaleService service.PresaleService,
+	middleware middleware.AuthMiddleware,
+) presaleHandler {
+	return presaleHandler{
+		presaleService: presaleService,
+		middleware:     middleware,
+	}
+}
+
+func (h presaleHandler) RegisterRouter(g *echo.Group) {
+	restricted := g.Group("/v1/admin")
+
+	restricted.Use(h.middleware.VerifyToken)
+	restricted.Use(h.middleware.RequireAdmin)
+
+	restricted.GET("/presale-codes", h.searchAccessCodes)
+	restricted.POST("/presale-codes", h.insertAccessCode)
+	restricted.PUT("/presale-code/:id", h.updateAccessCode)
+	restricted.DELETE("/presale-code/:id", h.softDeleteAccessCode)
+
+	restricted.GET("/presale-allowlists", h.searchAllowlists)
+	restricted.POST("/presale-allowlists", h.insertAllowlist)
+	restricted.PUT("/presale-allowlist/:id", h.updateAllowlist)
+	restricted.DELETE("/presale-allowlist/:id", h.softDeleteAllowlist)
+	restricted.POST("/presale-allowlist/:id/emails", h.uploadAllowlistEmails)
+}
+
+func (h presaleHandler) searchAccessCodes(c echo.Context) error {
+	var query entity.AccessCodeQuery
+
+	if err := c.Bind(&query); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.presaleService.SearchAccessCodes(c.Request().Context(), query)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+		"count":   len(data),
+	})
+}
+
+func (h presaleHandler) insertAccessCode(c echo.Context) error {
+	var accessCode entity.AccessCode
+
+	if err := c.Bind(&accessCode); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := h.presaleService.InsertAccessCode(c.Request().Context(), &accessCode); err != nil {
+		return presaleError(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    accessCode,
+	})
+}
+
+func (h presaleHandler) updateAccessCode(c echo.Context) error {
+	var accessCode entity.AccessCode
+
+	if err := c.Bind(&accessCode); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Force ID dari URL
+	accessCode.ID = pubEntity.UUID(c.Param("id"))
+
+	if err := h.presaleService.UpdateAccessCode(c.Request().Context(), &accessCode); err != nil {
+		return presaleError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    accessCode,
+	})
+}
+
+func (h presaleHandler) softDeleteAccessCode(c echo.Context) error {
+	if err := h.presaleService.SoftDeleteAccessCode(c.Request().Context(), pubEntity.UUID(c.Param("id"))); err != nil {
+		return presaleError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Presale access code deleted successfully",
+	})
+}
+
+func (h presaleHandler) searchAllowlists(c echo.Context) error {
+	var query entity.AllowlistQuery
+
+	if err := c.Bind(&query); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.presaleService.SearchAllowlists(c.Request().Context(), query)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+		"count":   len(data),
+	})
+}
+
+func (h presaleHandler) insertAllowlist(c echo.Context) error {
+	var allowlist entity.Allowlist
+
+	if err := c.Bind(&allowlist); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := h.presaleService.InsertAllowlist(c.Request().Context(), &allowlist); err != nil {
+		return presaleError(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    allowlist,
+	})
+}
+
+func (h presaleHandler) updateAllowlist(c echo.Context) error {
+	var allowlist entity.Allowlist
+
+	if err := c.Bind(&allowlist); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Force ID dari URL
+	allowlist.ID = pubEntity.UUID(c.Param("id"))
+
+	if err := h.presaleService.UpdateAllowlist(c.Request().Context(), &allowlist); err != nil {
+		return presaleError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    allowlist,
+	})
+}
+
+func (h presaleHandler) softDeleteAllowlist(c echo.Context) error {
+	if err := h.presaleService.SoftDeleteAllowlist(c.Request().Context(), pubEntity.UUID(c.Param("id"))); err != nil {
+		return presaleError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Presale allowlist deleted successfully",
+	})
+}
+
+// uploadAllowlistEmails menerima file CSV (multipart field "file", email di kolom pertama)
+// atau JSON {"emails": [...], "replace": false}
+func (h presaleHandler) uploadAllowlistEmails(c echo.Context) error {
+	var emails []string
+	var replace bool
+
+	if strings.Contains(c.Request().Header.Get("Content-Type"), "multipart/form-data") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "file is required")
+		}
+
+		src, err := fileHeader.Open()
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to open uploaded file: "+err.Error())
+		}
+		defer src.Close()
+
+		emails, err = service.ReadEmailsCSV(src)
+		if err != nil {
+			return presaleError(err)
+		}
+
+		replace, _ = strconv.ParseBool(c.FormValue("replace"))
+	} else {
+		var req struct {
+			Emails  []string `json:"emails"`
+			Replace bool     `json:"replace"`
+		}
+		if err := c.Bind(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		emails = req.Emails
+		replace = req.Replace
+	}
+
+	data, err := h.presaleService.UploadEmails(c.Request().Context(), pubEntity.UUID(c.Param("id")), emails, replace)
+	if err != nil {
+		return presaleError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+	})
+}
+
+func presaleError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrAccessCodeNotFound), errors.Is(err, service.ErrAllowlistNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidPresale):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrAccessCodeExists):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
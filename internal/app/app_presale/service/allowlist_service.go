@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"strings"
+
+	"rakit-tiket-be/internal/app/app_presale/dao"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	entity "rakit-tiket-be/pkg/entity/app_presale"
+	"rakit-tiket-be/pkg/util"
+)
+
+// Batas email per upload agar satu request tidak menahan transaksi terlalu lama
+const maxUploadEmails = 50000
+
+type UploadEmailsResult struct {
+	Received   int      `json:"received"`    // email valid unik di file / body
+	Added      int64    `json:"added"`       // email baru yang masuk allowlist
+	Invalid    []string `json:"invalid"`     // baris yang bukan alamat email
+	EmailCount int      `json:"email_count"` // total email allowlist setelah upload
+}
+
+func (s presaleService) SearchAllowlists(ctx context.Context, query entity.AllowlistQuery) (entity.Allowlists, error) {
+	dbTrx := dao.NewTransactionPresale(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	allowlists, err := dbTrx.GetPresaleAllowlistDAO().Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if allowlists == nil {
+		allowlists = entity.Allowlists{}
+	}
+	return allowlists, nil
+}
+
+func (s presaleService) InsertAllowlist(ctx context.Context, allowlist *entity.Allowlist) error {
+	if err := normalizeAllowlist(allowlist); err != nil {
+		return err
+	}
+	allowlist.ID = ""
+	allowlist.EmailCount = 0
+
+	dbTrx := dao.NewTransactionPresale(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	if err := checkTicketScope(ctx, dbTrx, allowlist.EventID, allowlist.TicketIDs); err != nil {
+		return err
+	}
+
+	allowlists := entity.Allowlists{*allowlist}
+	if err := dbTrx.GetPresaleAllowlistDAO().Insert(ctx, allowlists); err != nil {
+		return err
+	}
+	*allowlist = allowlists[0]
+
+	return dbTrx.GetSqlTx().Commit()
+}
+
+// UpdateAllowlist mengganti nama dan scope; isi email diubah lewat UploadEmails
+func (s presaleService) UpdateAllowlist(ctx context.Context, allowlist *entity.Allowlist) error {
+	if !util.IsValidUUID(string(allowlist.ID)) {
+		return ErrAllowlistNotFound
+	}
+
+	dbTrx := dao.NewTransactionPresale(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	existing, err := dbTrx.GetPresaleAllowlistDAO().Search(ctx, entity.AllowlistQuery{IDs: []string{string(allowlist.ID)}})
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		return ErrAllowlistNotFound
+	}
+
+	allowlist.EventID = existing[0].EventID
+	allowlist.EmailCount = existing[0].EmailCount
+	allowlist.CreatedAt = existing[0].CreatedAt
+	if err := normalizeAllowlist(allowlist); err != nil {
+		return err
+	}
+
+	if err := checkTicketScope(ctx, dbTrx, allowlist.EventID, allowlist.TicketIDs); err != nil {
+		return err
+	}
+
+	if err := dbTrx.GetPresaleAllowlistDAO().Update(ctx, entity.Allowlists{*allowlist}); err != nil {
+		return err
+	}
+
+	return dbTrx.GetSqlTx().Commit()
+}
+
+func (s presaleService) SoftDeleteAllowlist(ctx context.Context, id pubEntity.UUID) error {
+	if !util.IsValidUUID(string(id)) {
+		return ErrAllowlistNotFound
+	}
+
+	dbTrx := dao.NewTransactionPresale(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	existing, err := dbTrx.GetPresaleAllowlistDAO().Search(ctx, entity.AllowlistQuery{IDs: []string{string(id)}})
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		return ErrAllowlistNotFound
+	}
+
+	if err := dbTrx.GetPresaleAllowlistDAO().SoftDelete(ctx, id); err != nil {
+		return err
+	}
+
+	return dbTrx.GetSqlTx().Commit()
+}
+
+// UploadEmails menambah email ke allowlist; replace=true mengosongkan allowlist lebih dulu.
+// Email yang tidak valid dilaporkan di hasil dan tidak menggagalkan upload.
+func (s presaleService) UploadEmails(ctx context.Context, id pubEntity.UUID, emails []string, replace bool) (*UploadEmailsResult, error) {
+	if !util.IsValidUUID(string(id)) {
+		return nil, ErrAllowlistNotFound
+	}
+
+	valid, invalid := SplitEmails(emails)
+	if len(valid) == 0 && !replace {
+		return nil, fmt.Errorf("%w: tidak ada email valid", ErrInvalidPresale)
+	}
+	if len(valid) > maxUploadEmails {
+		return nil, fmt.Errorf("%w: maksimal %d email per upload", ErrInvalidPresale, maxUploadEmails)
+	}
+
+	dbTrx := dao.NewTransactionPresale(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	existing, err := dbTrx.GetPresaleAllowlistDAO().Search(ctx, entity.AllowlistQuery{IDs: []string{string(id)}})
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) == 0 {
+		return nil, ErrAllowlistNotFound
+	}
+
+	emailCount := existing[0].EmailCount
+	if replace {
+		if err := dbTrx.GetPresaleAllowlistDAO().ClearEmails(ctx, id); err != nil {
+			return nil, err
+		}
+		emailCount = 0
+	}
+
+	added, err := dbTrx.GetPresaleAllowlistDAO().AddEmails(ctx, id, valid)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dbTrx.GetSqlTx().Commit(); err != nil {
+		return nil, err
+	}
+
+	return &UploadEmailsResult{
+		Received:   len(valid),
+		Added:      added,
+		Invalid:    invalid,
+		EmailCount: emailCount + int(added),
+	}, nil
+}
+
+// ReadEmailsCSV membaca kolom pertama file CSV (mis. export member fan club).
+// Baris header atau baris kosong ikut terbaca dan nantinya masuk daftar invalid / dilewati.
+func ReadEmailsCSV(r io.Reader) ([]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var emails []string
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: file CSV tidak bisa dibaca: %v", ErrInvalidPresale, err)
+		}
+		if len(record) > 0 {
+			emails = append(emails, record[0])
+		}
+	}
+	return emails, nil
+}
+
+// SplitEmails menormalkan email (huruf kecil, tanpa spasi), membuang duplikat dan baris kosong,
+// lalu memisahkan yang bukan alamat email
+func SplitEmails(emails []string) (valid []string, invalid []string) {
+	seen := make(map[string]bool, len(emails))
+	valid = []string{}
+	invalid = []string{}
+
+	for _, e := range emails {
+		e = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(e, "\ufeff")))
+		if e == "" || seen[e] {
+			continue
+		}
+		seen[e] = true
+
+		addr, err := mail.ParseAddress(e)
+		if err != nil || addr.Address != e {
+			invalid = append(invalid, e)
+			continue
+		}
+		valid = append(valid, e)
+	}
+	return valid, invalid
+}
+
+func normalizeAllowlist(al *entity.Allowlist) error {
+	if !util.IsValidUUID(string(al.EventID)) {
+		return fmt.Errorf("%w: event_id wajib diisi", ErrInvalidPresale)
+	}
+
+	al.Name = strings.TrimSpace(al.Name)
+	if al.Name == "" || len(al.Name) > 255 {
+		return fmt.Errorf("%w: name wajib diisi, maksimal 255 karakter", ErrInvalidPresale)
+	}
+
+	al.TicketIDs = dedupe(al.TicketIDs)
+	return nil
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"rakit-tiket-be/internal/app/app_presale/dao"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	entity "rakit-tiket-be/pkg/entity/app_presale"
+	ticketEntity "rakit-tiket-be/pkg/entity/app_ticket"
+	"rakit-tiket-be/pkg/util"
+)
+
+var (
+	// ErrPresaleLocked dipakai Register saat tiket presale dibeli tanpa kode akses / email allowlist yang cocok
+	ErrPresaleLocked = errors.New("tiket presale membutuhkan kode akses atau email yang terdaftar")
+
+	ErrAccessCodeNotFound = errors.New("kode akses presale tidak ditemukan")
+	ErrAccessCodeExists   = errors.New("kode akses presale sudah dipakai")
+	ErrAllowlistNotFound  = errors.New("allowlist presale tidak ditemukan")
+	ErrInvalidPresale     = errors.New("data presale tidak valid")
+)
+
+type PresaleService interface {
+	// ResolveAccess membuka tiket presale untuk credential pada event yang diberikan
+	ResolveAccess(ctx context.Context, eventIDs []string, cred entity.Credential) (entity.Access, error)
+
+	SearchAccessCodes(ctx context.Context, query entity.AccessCodeQuery) (entity.AccessCodes, error)
+	InsertAccessCode(ctx context.Context, accessCode *entity.AccessCode) error
+	UpdateAccessCode(ctx context.Context, accessCode *entity.AccessCode) error
+	SoftDeleteAccessCode(ctx context.Context, id pubEntity.UUID) error
+
+	SearchAllowlists(ctx context.Context, query entity.AllowlistQuery) (entity.Allowlists, error)
+	InsertAllowlist(ctx context.Context, allowlist *entity.Allowlist) error
+	UpdateAllowlist(ctx context.Context, allowlist *entity.Allowlist) error
+	SoftDeleteAllowlist(ctx context.Context, id pubEntity.UUID) error
+	UploadEmails(ctx context.Context, id pubEntity.UUID, emails []string, replace bool) (*UploadEmailsResult, error)
+}
+
+type presaleService struct {
+	log   util.LogUtil
+	sqlDB *sql.DB
+}
+
+func MakePresaleService(log util.LogUtil, sqlDB *sql.DB) PresaleService {
+	return presaleService{
+		log:   log,
+		sqlDB: sqlDB,
+	}
+}
+
+func (s presaleService) ResolveAccess(ctx context.Context, eventIDs []string, cred entity.Credential) (entity.Access, error) {
+	if len(eventIDs) == 0 || cred.IsEmpty() {
+		return entity.Access{}, nil
+	}
+
+	dbTrx := dao.NewTransactionPresale(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	return ResolveAccess(ctx, dbTrx, eventIDs, cred)
+}
+
+// ResolveAccess dipakai langsung oleh Register agar memakai transaksi registrasi.
+// Kode akses hanya berlaku untuk event pemiliknya; email dicocokkan dengan semua allowlist event.
+func ResolveAccess(ctx context.Context, daos dao.AccessDAOs, eventIDs []string, cred entity.Credential) (entity.Access, error) {
+	var access entity.Access
+	if len(eventIDs) == 0 {
+		return access, nil
+	}
+
+	if code := strings.TrimSpace(cred.AccessCode); code != "" {
+		isActive := true
+		accessCodes, err := daos.GetPresaleAccessCodeDAO().Search(ctx, entity.AccessCodeQuery{
+			EventIDs: eventIDs,
+			Codes:    []string{code},
+			IsActive: &isActive,
+		})
+		if err != nil {
+			return access, err
+		}
+		for _, ac := range accessCodes {
+			access.Grant(ac.EventID, ac.TicketIDs)
+		}
+	}
+
+	if email := strings.TrimSpace(cred.Email); email != "" {
+		allowlists, err := daos.GetPresaleAllowlistDAO().Search(ctx, entity.AllowlistQuery{
+			EventIDs: eventIDs,
+			Emails:   []string{email},
+		})
+		if err != nil {
+			return access, err
+		}
+		for _, al := range allowlists {
+			access.Grant(al.EventID, al.TicketIDs)
+		}
+	}
+
+	return access, nil
+}
+
+func (s presaleService) SearchAccessCodes(ctx context.Context, query entity.AccessCodeQuery) (entity.AccessCodes, error) {
+	dbTrx := dao.NewTransactionPresale(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	accessCodes, err := dbTrx.GetPresaleAccessCodeDAO().Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if accessCodes == nil {
+		accessCodes = entity.AccessCodes{}
+	}
+	return accessCodes, nil
+}
+
+func (s presaleService) InsertAccessCode(ctx context.Context, accessCode *entity.AccessCode) error {
+	if err := normalizeAccessCode(accessCode); err != nil {
+		return err
+	}
+	accessCode.ID = ""
+
+	dbTrx := dao.NewTransactionPresale(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	if err := checkTicketScope(ctx, dbTrx, accessCode.EventID, accessCode.TicketIDs); err != nil {
+		return err
+	}
+	if err := checkCodeAvailable(ctx, dbTrx, *accessCode); err != nil {
+		return err
+	}
+
+	accessCodes := entity.AccessCodes{*accessCode}
+	if err := dbTrx.GetPresaleAccessCodeDAO().Insert(ctx, accessCodes); err != nil {
+		return err
+	}
+	*accessCode = accessCodes[0]
+
+	return dbTrx.GetSqlTx().Commit()
+}
+
+// UpdateAccessCode mengganti kode, scope dan status; event pemilik kode tidak bisa diubah
+func (s presaleService) UpdateAccessCode(ctx context.Context, accessCode *entity.AccessCode) error {
+	if !util.IsValidUUID(string(accessCode.ID)) {
+		return ErrAccessCodeNotFound
+	}
+
+	dbTrx := dao.NewTransactionPresale(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	existing, err := dbTrx.GetPresaleAccessCodeDAO().Search(ctx, entity.AccessCodeQuery{IDs: []string{string(accessCode.ID)}})
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		return ErrAccessCodeNotFound
+	}
+
+	accessCode.EventID = existing[0].EventID
+	accessCode.CreatedAt = existing[0].CreatedAt
+	if err := normalizeAccessCode(accessCode); err != nil {
+		return err
+	}
+
+	if err := checkTicketScope(ctx, dbTrx, accessCode.EventID, accessCode.TicketIDs); err != nil {
+		return err
+	}
+	if !strings.EqualFold(existing[0].Code, accessCode.Code) {
+		if err := checkCodeAvailable(ctx, dbTrx, *accessCode); err != nil {
+			return err
+		}
+	}
+
+	if err := dbTrx.GetPresaleAccessCodeDAO().Update(ctx, entity.AccessCodes{*accessCode}); err != nil {
+		return err
+	}
+
+	return dbTrx.GetSqlTx().Commit()
+}
+
+func (s presaleService) SoftDeleteAccessCode(ctx context.Context, id pubEntity.UUID) error {
+	if !util.IsValidUUID(string(id)) {
+		return ErrAccessCodeNotFound
+	}
+
+	dbTrx := dao.NewTransactionPresale(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	existing, err := dbTrx.GetPresaleAccessCodeDAO().Search(ctx, entity.AccessCodeQuery{IDs: []string{string(id)}})
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		return ErrAccessCodeNotFound
+	}
+
+	if err := dbTrx.GetPresaleAccessCodeDAO().SoftDelete(ctx, id); err != nil {
+		return err
+	}
+
+	return dbTrx.GetSqlTx().Commit()
+}
+
+func checkCodeAvailable(ctx context.Context, dbTrx dao.DBTransaction, accessCode entity.AccessCode) error {
+	existing, err := dbTrx.GetPresaleAccessCodeDAO().Search(ctx, entity.AccessCodeQuery{
+		EventIDs: []string{string(accessCode.EventID)},
+		Codes:    []string{accessCode.Code},
+	})
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return fmt.Errorf("%w: %s", ErrAccessCodeExists, existing[0].Code)
+	}
+	return nil
+}
+
+// checkTicketScope memastikan ticket_ids milik event dan memang tiket presale
+func checkTicketScope(ctx context.Context, dbTrx dao.DBTransaction, eventID pubEntity.UUID, ticketIDs []string) error {
+	if len(ticketIDs) == 0 {
+		return nil
+	}
+
+	for _, id := range ticketIDs {
+		if !util.IsValidUUID(id) {
+			return fmt.Errorf("%w: ticket_id %s tidak valid", ErrInvalidPresale, id)
+		}
+	}
+
+	isPresale := true
+	tickets, err := dbTrx.GetTicketDAO().Search(ctx, ticketEntity.TicketQuery{
+		IDs:       ticketIDs,
+		EventIDs:  []string{string(eventID)},
+		IsPresale: &isPresale,
+	})
+	if err != nil {
+		return err
+	}
+
+	found := make(map[string]bool, len(tickets))
+	for _, t := range tickets {
+		found[string(t.ID)] = true
+	}
+	for _, id := range ticketIDs {
+		if !found[id] {
+			return fmt.Errorf("%w: tiket %s bukan tiket presale event ini", ErrInvalidPresale, id)
+		}
+	}
+	return nil
+}
+
+// normalizeAccessCode menyeragamkan kode (huruf besar, tanpa spasi)
+func normalizeAccessCode(ac *entity.AccessCode) error {
+	if !util.IsValidUUID(string(ac.EventID)) {
+		return fmt.Errorf("%w: event_id wajib diisi", ErrInvalidPresale)
+	}
+
+	ac.Code = strings.ToUpper(strings.TrimSpace(ac.Code))
+	if ac.Code == "" || strings.ContainsAny(ac.Code, " \t") || len(ac.Code) > 64 {
+		return fmt.Errorf("%w: code wajib diisi, tanpa spasi, maksimal 64 karakter", ErrInvalidPresale)
+	}
+
+	ac.TicketIDs = dedupe(ac.TicketIDs)
+	return nil
+}
+
+func dedupe(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	return result
+}
@@ -0,0 +1,42 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+
+	baseDao "rakit-tiket-be/internal/pkg/dao"
+	"rakit-tiket-be/pkg/util"
+)
+
+type DBTransaction interface {
+	baseDao.DBTransaction
+
+	GetPromoCodeDAO() PromoCodeDAO
+	GetPromoRedemptionDAO() PromoRedemptionDAO
+}
+
+type dbTransaction struct {
+	baseDao.DBTransaction
+
+	promoCodeDAO       PromoCodeDAO
+	promoRedemptionDAO PromoRedemptionDAO
+}
+
+func NewTransactionPromo(ctx context.Context, log util.LogUtil, sqlDB *sql.DB) DBTransaction {
+	dbTrx := &dbTransaction{
+		DBTransaction: baseDao.NewTransaction(ctx, sqlDB),
+	}
+
+	dbTrx.promoCodeDAO = MakePromoCodeDAO(log, dbTrx)
+	dbTrx.promoRedemptionDAO = MakePromoRedemptionDAO(log, dbTrx)
+
+	return dbTrx
+}
+
+func (dbTrx *dbTransaction) GetPromoCodeDAO() PromoCodeDAO {
+	return dbTrx.promoCodeDAO
+}
+
+func (dbTrx *dbTransaction) GetPromoRedemptionDAO() PromoRedemptionDAO {
+	return dbTrx.promoRedemptionDAO
+}
@@ -0,0 +1,327 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	baseDao "rakit-tiket-be/internal/pkg/dao"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	entity "rakit-tiket-be/pkg/entity/app_promo"
+	"rakit-tiket-be/pkg/util"
+
+	"gitlab.com/threetopia/sqlgo/v2"
+	"go.uber.org/zap"
+)
+
+type PromoCodeDAO interface {
+	Search(ctx context.Context, query entity.PromoCodeQuery) (entity.PromoCodes, error)
+	SearchForUpdate(ctx context.Context, query entity.PromoCodeQuery) (entity.PromoCodes, error)
+	Insert(ctx context.Context, promoCodes entity.PromoCodes) error
+	Update(ctx context.Context, promoCodes entity.PromoCodes) error
+	SoftDelete(ctx context.Context, id pubEntity.UUID) error
+	// Redeem menambah used_count secara atomik, gagal jika max_uses sudah tercapai
+	Redeem(ctx context.Context, id pubEntity.UUID) error
+}
+
+type promoCodeDAO struct {
+	log   util.LogUtil
+	dbTrx baseDao.DBTransaction
+}
+
+func MakePromoCodeDAO(log util.LogUtil, dbTrx baseDao.DBTransaction) PromoCodeDAO {
+	return promoCodeDAO{
+		log:   log,
+		dbTrx: dbTrx,
+	}
+}
+
+func (d promoCodeDAO) buildSearch(query entity.PromoCodeQuery) sqlgo.SQLGo {
+	sqlSelect := sqlgo.NewSQLGoSelect().
+		SetSQLSelect("p.id", "id").
+		SetSQLSelect("p.code", "code").
+		SetSQLSelect("p.description", "description").
+		SetSQLSelect("p.discount_type", "discount_type").
+		SetSQLSelect("p.discount_value", "discount_value").
+		SetSQLSelect("p.max_discount", "max_discount").
+		SetSQLSelect("p.event_ids", "event_ids").
+		SetSQLSelect("p.ticket_types", "ticket_types").
+		SetSQLSelect("p.max_uses", "max_uses").
+		SetSQLSelect("p.max_uses_per_email", "max_uses_per_email").
+		SetSQLSelect("p.used_count", "used_count").
+		SetSQLSelect("p.min_qty", "min_qty").
+		SetSQLSelect("p.starts_at", "starts_at").
+		SetSQLSelect("p.ends_at", "ends_at").
+		SetSQLSelect("p.is_active", "is_active").
+		SetSQLSelect("p.deleted", "deleted").
+		SetSQLSelect("p.data_hash", "data_hash").
+		SetSQLSelect("p.created_at", "created_at").
+		SetSQLSelect("p.updated_at", "updated_at")
+
+	sqlFrom := sqlgo.NewSQLGoFrom().
+		SetSQLFrom("promo_codes", "p")
+
+	sqlWhere := sqlgo.NewSQLGoWhere()
+	sqlWhere.SetSQLWhere("AND", "p.deleted", "=", false)
+
+	if len(query.IDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "p.id", "IN", query.IDs)
+	}
+	if len(query.Codes) > 0 {
+		codes := make([]string, 0, len(query.Codes))
+		for _, c := range query.Codes {
+			codes = append(codes, strings.ToUpper(strings.TrimSpace(c)))
+		}
+		sqlWhere.SetSQLWhere("AND", "UPPER(p.code)", "IN", codes)
+	}
+	if query.IsActive != nil {
+		sqlWhere.SetSQLWhere("AND", "p.is_active", "=", *query.IsActive)
+	}
+
+	sqlOrder := sqlgo.NewSQLGoOrder()
+	sqlOrder.SetSQLOrder("p.created_at", "DESC")
+
+	return sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoSelect(sqlSelect).
+		SetSQLGoFrom(sqlFrom).
+		SetSQLGoWhere(sqlWhere).
+		SetSQLGoOrder(sqlOrder)
+}
+
+func (d promoCodeDAO) Search(ctx context.Context, query entity.PromoCodeQuery) (entity.PromoCodes, error) {
+	sql := d.buildSearch(query)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "promoCodeDAO.Search",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	rows, err := d.dbTrx.GetSqlDB().QueryContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "promoCodeDAO.Search", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	return d.scanRows(ctx, rows)
+}
+
+// SearchForUpdate mengunci baris promo sampai transaksi selesai agar cek kuota per email tidak balapan
+func (d promoCodeDAO) SearchForUpdate(ctx context.Context, query entity.PromoCodeQuery) (entity.PromoCodes, error) {
+	sql := d.buildSearch(query)
+
+	sqlStr := sql.BuildSQL() + " FOR UPDATE"
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "promoCodeDAO.SearchForUpdate",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	rows, err := d.dbTrx.GetSqlTx().QueryContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "promoCodeDAO.SearchForUpdate", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	return d.scanRows(ctx, rows)
+}
+
+func (d promoCodeDAO) scanRows(ctx context.Context, rows *sql.Rows) (entity.PromoCodes, error) {
+	var promoCodes entity.PromoCodes
+	for rows.Next() {
+		var promo entity.PromoCode
+		var eventIDsJSON, ticketTypesJSON []byte
+
+		if err := rows.Scan(
+			&promo.ID, &promo.Code, &promo.Description,
+			&promo.DiscountType, &promo.DiscountValue, &promo.MaxDiscount,
+			&eventIDsJSON, &ticketTypesJSON,
+			&promo.MaxUses, &promo.MaxUsesPerEmail, &promo.UsedCount, &promo.MinQty,
+			&promo.StartsAt, &promo.EndsAt, &promo.IsActive,
+			&promo.Deleted, &promo.DataHash,
+			&promo.CreatedAt, &promo.UpdatedAt,
+		); err != nil {
+			d.log.Error(ctx, "promoCodeDAO.scanRows", zap.Error(err))
+			return nil, err
+		}
+
+		if len(eventIDsJSON) > 0 {
+			if err := json.Unmarshal(eventIDsJSON, &promo.EventIDs); err != nil {
+				promo.EventIDs = []string{}
+			}
+		}
+		if len(ticketTypesJSON) > 0 {
+			if err := json.Unmarshal(ticketTypesJSON, &promo.TicketTypes); err != nil {
+				promo.TicketTypes = []string{}
+			}
+		}
+
+		promoCodes = append(promoCodes, promo)
+	}
+
+	return promoCodes, nil
+}
+
+func (d promoCodeDAO) Insert(ctx context.Context, promoCodes entity.PromoCodes) error {
+	if len(promoCodes) < 1 {
+		return fmt.Errorf("empty promo code data")
+	}
+
+	sqlInsert := sqlgo.NewSQLGoInsert().
+		SetSQLInsert("promo_codes").
+		SetSQLInsertColumn(
+			"id", "code", "description",
+			"discount_type", "discount_value", "max_discount",
+			"event_ids", "ticket_types",
+			"max_uses", "max_uses_per_email", "used_count", "min_qty",
+			"starts_at", "ends_at", "is_active",
+			"deleted", "data_hash", "created_at",
+		)
+
+	for i, promo := range promoCodes {
+		promo.CreatedAt = time.Now()
+		if promo.ID == "" {
+			promo.ID = pubEntity.MakeUUID("PROMO", promo.Code, promo.CreatedAt.String())
+		}
+
+		eventIDsJSON, ticketTypesJSON := marshalScope(promo)
+
+		sqlInsert.SetSQLInsertValue(
+			promo.ID, promo.Code, promo.Description,
+			promo.DiscountType, promo.DiscountValue, promo.MaxDiscount,
+			eventIDsJSON, ticketTypesJSON,
+			promo.MaxUses, promo.MaxUsesPerEmail, promo.UsedCount, promo.MinQty,
+			promo.StartsAt, promo.EndsAt, promo.IsActive,
+			promo.Deleted, promo.DataHash, promo.CreatedAt,
+		)
+		promoCodes[i] = promo
+	}
+
+	sql := sqlgo.NewSQLGo().SetSQLSchema("public").SetSQLGoInsert(sqlInsert)
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "promoCodeDAO.Insert", zap.String("SQL", sqlStr), zap.Int("Count", len(promoCodes)))
+
+	_, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "promoCodeDAO.Insert", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Update tidak menyentuh used_count; kolom itu hanya diubah lewat Redeem dan pelepasan redemption
+func (d promoCodeDAO) Update(ctx context.Context, promoCodes entity.PromoCodes) error {
+	if len(promoCodes) < 1 {
+		return fmt.Errorf("empty promo code data")
+	}
+
+	for i, promo := range promoCodes {
+		now := time.Now()
+		promo.UpdatedAt = &now
+
+		eventIDsJSON, ticketTypesJSON := marshalScope(promo)
+
+		sql := sqlgo.NewSQLGo().
+			SetSQLSchema("public").
+			SetSQLUpdate("promo_codes").
+			SetSQLUpdateValue("code", promo.Code).
+			SetSQLUpdateValue("description", promo.Description).
+			SetSQLUpdateValue("discount_type", promo.DiscountType).
+			SetSQLUpdateValue("discount_value", promo.DiscountValue).
+			SetSQLUpdateValue("max_discount", promo.MaxDiscount).
+			SetSQLUpdateValue("event_ids", eventIDsJSON).
+			SetSQLUpdateValue("ticket_types", ticketTypesJSON).
+			SetSQLUpdateValue("max_uses", promo.MaxUses).
+			SetSQLUpdateValue("max_uses_per_email", promo.MaxUsesPerEmail).
+			SetSQLUpdateValue("min_qty", promo.MinQty).
+			SetSQLUpdateValue("starts_at", promo.StartsAt).
+			SetSQLUpdateValue("ends_at", promo.EndsAt).
+			SetSQLUpdateValue("is_active", promo.IsActive).
+			SetSQLUpdateValue("data_hash", promo.DataHash).
+			SetSQLUpdateValue("updated_at", promo.UpdatedAt).
+			SetSQLWhere("AND", "id", "=", promo.ID)
+
+		sqlStr := sql.BuildSQL()
+		sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+		d.log.Debug(ctx, "promoCodeDAO.Update", zap.String("ID", string(promo.ID)))
+
+		if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...); err != nil {
+			d.log.Error(ctx, "promoCodeDAO.Update", zap.Error(err))
+			return err
+		}
+		promoCodes[i] = promo
+	}
+	return nil
+}
+
+func (d promoCodeDAO) SoftDelete(ctx context.Context, id pubEntity.UUID) error {
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLUpdate("promo_codes").
+		SetSQLUpdateValue("deleted", true).
+		SetSQLUpdateValue("updated_at", time.Now()).
+		SetSQLWhere("AND", "id", "=", id)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "promoCodeDAO.SoftDelete", zap.String("ID", string(id)))
+
+	if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...); err != nil {
+		d.log.Error(ctx, "promoCodeDAO.SoftDelete", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (d promoCodeDAO) Redeem(ctx context.Context, id pubEntity.UUID) error {
+	query := `
+        UPDATE promo_codes
+        SET
+            used_count = used_count + 1,
+            updated_at = $1
+        WHERE id = $2
+        AND (max_uses IS NULL OR used_count < max_uses)
+        AND deleted = false
+    `
+
+	d.log.Debug(ctx, "promoCodeDAO.Redeem", zap.String("ID", string(id)))
+
+	result, err := d.dbTrx.GetSqlTx().ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		d.log.Error(ctx, "promoCodeDAO.Redeem", zap.Error(err))
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil || rows == 0 {
+		d.log.Warn(ctx, "promoCodeDAO.Redeem.NoRowsAffected", zap.String("ID", string(id)))
+		return fmt.Errorf("promo code usage limit reached")
+	}
+
+	return nil
+}
+
+func marshalScope(promo entity.PromoCode) ([]byte, []byte) {
+	eventIDsJSON, err := json.Marshal(promo.EventIDs)
+	if err != nil || promo.EventIDs == nil {
+		eventIDsJSON = []byte("[]")
+	}
+	ticketTypesJSON, err := json.Marshal(promo.TicketTypes)
+	if err != nil || promo.TicketTypes == nil {
+		ticketTypesJSON = []byte("[]")
+	}
+	return eventIDsJSON, ticketTypesJSON
+}
@@ -0,0 +1,268 @@
+package dao
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	baseDao "rakit-tiket-be/internal/pkg/dao"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	entity "rakit-tiket-be/pkg/entity/app_promo"
+	"rakit-tiket-be/pkg/util"
+
+	"gitlab.com/threetopia/sqlgo/v2"
+	"go.uber.org/zap"
+)
+
+type PromoRedemptionDAO interface {
+	Search(ctx context.Context, query entity.PromoRedemptionQuery) (entity.PromoRedemptions, error)
+	// CountActive menghitung redemption ACTIVE satu email untuk kuota per email
+	CountActive(ctx context.Context, promoCodeID pubEntity.UUID, email string) (int, error)
+	Insert(ctx context.Context, redemptions entity.PromoRedemptions) error
+	// ReleaseByOrder melepas redemption order yang expired / gagal dan mengembalikan kuota promo
+	ReleaseByOrder(ctx context.Context, orderID pubEntity.UUID) error
+	// Reinstate mengaktifkan kembali redemption order expired yang ternyata dibayar, tanpa cek max_uses
+	Reinstate(ctx context.Context, orderID pubEntity.UUID) error
+}
+
+type promoRedemptionDAO struct {
+	log   util.LogUtil
+	dbTrx baseDao.DBTransaction
+}
+
+func MakePromoRedemptionDAO(log util.LogUtil, dbTrx baseDao.DBTransaction) PromoRedemptionDAO {
+	return promoRedemptionDAO{
+		log:   log,
+		dbTrx: dbTrx,
+	}
+}
+
+func (d promoRedemptionDAO) Search(ctx context.Context, query entity.PromoRedemptionQuery) (entity.PromoRedemptions, error) {
+
+	sqlSelect := sqlgo.NewSQLGoSelect().
+		SetSQLSelect("pr.id", "id").
+		SetSQLSelect("pr.promo_code_id", "promo_code_id").
+		SetSQLSelect("pr.order_id", "order_id").
+		SetSQLSelect("pr.email", "email").
+		SetSQLSelect("pr.discount_amount", "discount_amount").
+		SetSQLSelect("pr.status", "status").
+		SetSQLSelect("pr.released_at", "released_at").
+		SetSQLSelect("pr.created_at", "created_at").
+		SetSQLSelect("o.order_number", "order_number")
+
+	sqlFrom := sqlgo.NewSQLGoFrom().
+		SetSQLFrom("promo_redemptions", "pr")
+
+	sqlJoin := sqlgo.NewSQLGoJoin()
+	sqlJoin.SetSQLJoin("INNER", "orders", "o", sqlgo.SetSQLJoinWhere("AND", "o.id", "=", "pr.order_id"))
+
+	sqlWhere := sqlgo.NewSQLGoWhere()
+
+	if len(query.PromoCodeIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "pr.promo_code_id", "IN", query.PromoCodeIDs)
+	}
+	if len(query.OrderIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "pr.order_id", "IN", query.OrderIDs)
+	}
+	if len(query.Emails) > 0 {
+		emails := make([]string, 0, len(query.Emails))
+		for _, e := range query.Emails {
+			emails = append(emails, normalizeEmail(e))
+		}
+		sqlWhere.SetSQLWhere("AND", "pr.email", "IN", emails)
+	}
+	if len(query.Statuses) > 0 {
+		sqlWhere.SetSQLWhere("AND", "pr.status", "IN", query.Statuses)
+	}
+
+	sqlOrder := sqlgo.NewSQLGoOrder()
+	sqlOrder.SetSQLOrder("pr.created_at", "DESC")
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoSelect(sqlSelect).
+		SetSQLGoFrom(sqlFrom).
+		SetSQLGoJoin(sqlJoin).
+		SetSQLGoWhere(sqlWhere).
+		SetSQLGoOrder(sqlOrder)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "promoRedemptionDAO.Search",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	rows, err := d.dbTrx.GetSqlDB().QueryContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "promoRedemptionDAO.Search", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var redemptions entity.PromoRedemptions
+	for rows.Next() {
+		var r entity.PromoRedemption
+
+		if err := rows.Scan(
+			&r.ID,
+			&r.PromoCodeID,
+			&r.OrderID,
+			&r.Email,
+			&r.DiscountAmount,
+			&r.Status,
+			&r.ReleasedAt,
+			&r.CreatedAt,
+			&r.OrderNumber,
+		); err != nil {
+			d.log.Error(ctx, "promoRedemptionDAO.Search.Scan", zap.Error(err))
+			return nil, err
+		}
+
+		redemptions = append(redemptions, r)
+	}
+
+	return redemptions, nil
+}
+
+func (d promoRedemptionDAO) CountActive(ctx context.Context, promoCodeID pubEntity.UUID, email string) (int, error) {
+	query := `
+        SELECT COUNT(*)
+        FROM promo_redemptions
+        WHERE promo_code_id = $1
+        AND email = $2
+        AND status = 'ACTIVE'
+    `
+
+	var count int
+	if err := d.dbTrx.GetSqlTx().QueryRowContext(ctx, query, promoCodeID, normalizeEmail(email)).Scan(&count); err != nil {
+		d.log.Error(ctx, "promoRedemptionDAO.CountActive", zap.Error(err))
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (d promoRedemptionDAO) Insert(ctx context.Context, redemptions entity.PromoRedemptions) error {
+	if len(redemptions) < 1 {
+		return nil
+	}
+
+	sqlInsert := sqlgo.NewSQLGoInsert().
+		SetSQLInsert("promo_redemptions").
+		SetSQLInsertColumn(
+			"id",
+			"promo_code_id",
+			"order_id",
+			"email",
+			"discount_amount",
+			"status",
+			"created_at",
+		)
+
+	for i, r := range redemptions {
+		if r.CreatedAt.IsZero() {
+			r.CreatedAt = time.Now()
+		}
+		if r.ID == "" {
+			r.ID = pubEntity.MakeUUID("PROMO_REDEMPTION", string(r.OrderID), r.CreatedAt.String())
+		}
+		if r.Status == "" {
+			r.Status = entity.RedemptionStatusActive
+		}
+		r.Email = normalizeEmail(r.Email)
+
+		sqlInsert.SetSQLInsertValue(
+			r.ID,
+			r.PromoCodeID,
+			r.OrderID,
+			r.Email,
+			r.DiscountAmount,
+			r.Status,
+			r.CreatedAt,
+		)
+		redemptions[i] = r
+	}
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoInsert(sqlInsert)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "promoRedemptionDAO.Insert",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...); err != nil {
+		d.log.Error(ctx, "promoRedemptionDAO.Insert", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (d promoRedemptionDAO) ReleaseByOrder(ctx context.Context, orderID pubEntity.UUID) error {
+	query := `
+        WITH released AS (
+            UPDATE promo_redemptions
+            SET
+                status      = 'RELEASED',
+                released_at = $1
+            WHERE order_id = $2
+            AND status = 'ACTIVE'
+            RETURNING promo_code_id
+        )
+        UPDATE promo_codes p
+        SET
+            used_count = GREATEST(p.used_count - 1, 0),
+            updated_at = $1
+        FROM released r
+        WHERE p.id = r.promo_code_id
+    `
+
+	d.log.Debug(ctx, "promoRedemptionDAO.ReleaseByOrder", zap.String("OrderID", string(orderID)))
+
+	if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, query, time.Now(), orderID); err != nil {
+		d.log.Error(ctx, "promoRedemptionDAO.ReleaseByOrder", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (d promoRedemptionDAO) Reinstate(ctx context.Context, orderID pubEntity.UUID) error {
+	query := `
+        WITH reinstated AS (
+            UPDATE promo_redemptions
+            SET
+                status      = 'ACTIVE',
+                released_at = NULL
+            WHERE order_id = $2
+            AND status = 'RELEASED'
+            RETURNING promo_code_id
+        )
+        UPDATE promo_codes p
+        SET
+            used_count = p.used_count + 1,
+            updated_at = $1
+        FROM reinstated r
+        WHERE p.id = r.promo_code_id
+    `
+
+	d.log.Debug(ctx, "promoRedemptionDAO.Reinstate", zap.String("OrderID", string(orderID)))
+
+	if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, query, time.Now(), orderID); err != nil {
+		d.log.Error(ctx, "promoRedemptionDAO.Reinstate", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"rakit-tiket-be/internal/app/app_promo/service"
+	"rakit-tiket-be/internal/pkg/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+type HttpHandler interface {
+	RegisterRoute(g *echo.Group)
+}
+
+type httpHandler struct {
+	promoService service.PromoService
+
+	promoHandler PromoHandler
+}
+
+func MakeHttpAdapter(
+	promoService service.PromoService,
+	authMiddleware middleware.AuthMiddleware,
+) HttpHandler {
+	return httpHandler{
+		promoService: promoService,
+		promoHandler: MakePromoHandler(promoService, authMiddleware),
+	}
+}
+
+func (h httpHandler) RegisterRoute(g *echo.Group) {
+	h.promoHandler.RegisterRouter(g)
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"rakit-tiket-be/internal/app/app_promo/service"
+	"rakit-tiket-be/internal/pkg/middleware"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	entity "rakit-tiket-be/pkg/entity/app_promo"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PromoHandler interface {
+	RegisterRouter(g *echo.Group)
+}
+
+type promoHandler struct {
+	promoService service.PromoService
+	middleware   middleware.AuthMiddleware
+}
+
+func MakePromoHandler(
+	promoService service.PromoService,
+	middleware middleware.AuthMiddleware,
+) promoHandler {
+	return promoHandler{
+		promoService: promoService,
+		middleware:   middleware,
+	}
+}
+
+func (h promoHandler) RegisterRouter(g *echo.Group) {
+	restricted := g.Group("/v1/admin")
+
+	restricted.Use(h.middleware.VerifyToken)
+	restricted.Use(h.middleware.RequireAdmin)
+
+	restricted.GET("/promo-codes", h.searchPromoCodes)
+	restricted.POST("/promo-codes", h.insertPromoCodes)
+	restricted.PUT("/promo-code/:id", h.updatePromoCode)
+	restricted.DELETE("/promo-code/:id", h.softDeletePromoCode)
+	restricted.GET("/promo-code/:id/redemptions", h.getRedemptions)
+}
+
+func (h promoHandler) searchPromoCodes(c echo.Context) error {
+	var query entity.PromoCodeQuery
+
+	if err := c.Bind(&query); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.promoService.Search(c.Request().Context(), query)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+		"count":   len(data),
+	})
+}
+
+func (h promoHandler) insertPromoCodes(c echo.Context) error {
+	var promoCodes entity.PromoCodes
+
+	if err := c.Bind(&promoCodes); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := h.promoService.Insert(c.Request().Context(), promoCodes); err != nil {
+		return promoError(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    promoCodes,
+	})
+}
+
+func (h promoHandler) updatePromoCode(c echo.Context) error {
+	var promo entity.PromoCode
+
+	if err := c.Bind(&promo); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// Force ID dari URL
+	promo.ID = pubEntity.UUID(c.Param("id"))
+
+	if err := h.promoService.Update(c.Request().Context(), &promo); err != nil {
+		return promoError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    promo,
+	})
+}
+
+func (h promoHandler) softDeletePromoCode(c echo.Context) error {
+	if err := h.promoService.SoftDelete(c.Request().Context(), pubEntity.UUID(c.Param("id"))); err != nil {
+		return promoError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Promo code deleted successfully",
+	})
+}
+
+func (h promoHandler) getRedemptions(c echo.Context) error {
+	data, err := h.promoService.GetRedemptions(c.Request().Context(), pubEntity.UUID(c.Param("id")))
+	if err != nil {
+		return promoError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+		"count":   len(data),
+	})
+}
+
+func promoError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrPromoCodeNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidPromoCode):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrPromoCodeExists):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"rakit-tiket-be/internal/app/app_promo/dao"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	entity "rakit-tiket-be/pkg/entity/app_promo"
+	"rakit-tiket-be/pkg/util"
+)
+
+var (
+	ErrPromoCodeNotFound = errors.New("kode promo tidak ditemukan")
+	ErrPromoCodeExists   = errors.New("kode promo sudah dipakai")
+	ErrInvalidPromoCode  = errors.New("data kode promo tidak valid")
+)
+
+type PromoService interface {
+	Search(ctx context.Context, query entity.PromoCodeQuery) (entity.PromoCodes, error)
+	Insert(ctx context.Context, promoCodes entity.PromoCodes) error
+	Update(ctx context.Context, promo *entity.PromoCode) error
+	SoftDelete(ctx context.Context, id pubEntity.UUID) error
+	GetRedemptions(ctx context.Context, id pubEntity.UUID) (entity.PromoRedemptions, error)
+}
+
+type promoService struct {
+	log   util.LogUtil
+	sqlDB *sql.DB
+}
+
+func MakePromoService(log util.LogUtil, sqlDB *sql.DB) PromoService {
+	return promoService{
+		log:   log,
+		sqlDB: sqlDB,
+	}
+}
+
+func (s promoService) Search(ctx context.Context, query entity.PromoCodeQuery) (entity.PromoCodes, error) {
+	dbTrx := dao.NewTransactionPromo(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	promoCodes, err := dbTrx.GetPromoCodeDAO().Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if promoCodes == nil {
+		promoCodes = entity.PromoCodes{}
+	}
+	return promoCodes, nil
+}
+
+func (s promoService) Insert(ctx context.Context, promoCodes entity.PromoCodes) error {
+	if len(promoCodes) == 0 {
+		return fmt.Errorf("%w: data kosong", ErrInvalidPromoCode)
+	}
+
+	dbTrx := dao.NewTransactionPromo(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	seen := make(map[string]bool)
+	for i := range promoCodes {
+		if err := normalizePromo(&promoCodes[i]); err != nil {
+			return err
+		}
+		promoCodes[i].ID = ""
+		promoCodes[i].UsedCount = 0
+
+		code := promoCodes[i].Code
+		if seen[code] {
+			return fmt.Errorf("%w: %s", ErrPromoCodeExists, code)
+		}
+		seen[code] = true
+	}
+
+	if err := s.checkCodesAvailable(ctx, dbTrx, promoCodes); err != nil {
+		return err
+	}
+
+	if err := dbTrx.GetPromoCodeDAO().Insert(ctx, promoCodes); err != nil {
+		return err
+	}
+
+	return dbTrx.GetSqlTx().Commit()
+}
+
+// Update mengganti konfigurasi promo; used_count tetap dihitung dari redemption
+func (s promoService) Update(ctx context.Context, promo *entity.PromoCode) error {
+	if !util.IsValidUUID(string(promo.ID)) {
+		return ErrPromoCodeNotFound
+	}
+	if err := normalizePromo(promo); err != nil {
+		return err
+	}
+
+	dbTrx := dao.NewTransactionPromo(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	existing, err := dbTrx.GetPromoCodeDAO().SearchForUpdate(ctx, entity.PromoCodeQuery{IDs: []string{string(promo.ID)}})
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		return ErrPromoCodeNotFound
+	}
+
+	if !strings.EqualFold(existing[0].Code, promo.Code) {
+		if err := s.checkCodesAvailable(ctx, dbTrx, entity.PromoCodes{*promo}); err != nil {
+			return err
+		}
+	}
+
+	promo.UsedCount = existing[0].UsedCount
+	promo.CreatedAt = existing[0].CreatedAt
+
+	if err := dbTrx.GetPromoCodeDAO().Update(ctx, entity.PromoCodes{*promo}); err != nil {
+		return err
+	}
+
+	return dbTrx.GetSqlTx().Commit()
+}
+
+// SoftDelete tidak melepas redemption yang sudah ada; order pending tetap memakai potongannya
+func (s promoService) SoftDelete(ctx context.Context, id pubEntity.UUID) error {
+	if !util.IsValidUUID(string(id)) {
+		return ErrPromoCodeNotFound
+	}
+
+	dbTrx := dao.NewTransactionPromo(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	existing, err := dbTrx.GetPromoCodeDAO().Search(ctx, entity.PromoCodeQuery{IDs: []string{string(id)}})
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		return ErrPromoCodeNotFound
+	}
+
+	if err := dbTrx.GetPromoCodeDAO().SoftDelete(ctx, id); err != nil {
+		return err
+	}
+
+	return dbTrx.GetSqlTx().Commit()
+}
+
+func (s promoService) GetRedemptions(ctx context.Context, id pubEntity.UUID) (entity.PromoRedemptions, error) {
+	if !util.IsValidUUID(string(id)) {
+		return nil, ErrPromoCodeNotFound
+	}
+
+	dbTrx := dao.NewTransactionPromo(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	existing, err := dbTrx.GetPromoCodeDAO().Search(ctx, entity.PromoCodeQuery{IDs: []string{string(id)}})
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) == 0 {
+		return nil, ErrPromoCodeNotFound
+	}
+
+	redemptions, err := dbTrx.GetPromoRedemptionDAO().Search(ctx, entity.PromoRedemptionQuery{
+		PromoCodeIDs: []string{string(id)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if redemptions == nil {
+		redemptions = entity.PromoRedemptions{}
+	}
+	return redemptions, nil
+}
+
+func (s promoService) checkCodesAvailable(ctx context.Context, dbTrx dao.DBTransaction, promoCodes entity.PromoCodes) error {
+	codes := make([]string, 0, len(promoCodes))
+	for _, p := range promoCodes {
+		codes = append(codes, p.Code)
+	}
+
+	existing, err := dbTrx.GetPromoCodeDAO().Search(ctx, entity.PromoCodeQuery{Codes: codes})
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return fmt.Errorf("%w: %s", ErrPromoCodeExists, existing[0].Code)
+	}
+
+	return nil
+}
+
+// normalizePromo menyeragamkan kode (huruf besar, tanpa spasi) dan memvalidasi aturan diskon
+func normalizePromo(p *entity.PromoCode) error {
+	p.Code = strings.ToUpper(strings.TrimSpace(p.Code))
+	if p.Code == "" || strings.ContainsAny(p.Code, " \t") || len(p.Code) > 64 {
+		return fmt.Errorf("%w: code wajib diisi, tanpa spasi, maksimal 64 karakter", ErrInvalidPromoCode)
+	}
+
+	switch p.DiscountType {
+	case entity.DiscountTypePercent:
+		if p.DiscountValue <= 0 || p.DiscountValue > 100 {
+			return fmt.Errorf("%w: discount_value persen harus di antara 0 dan 100", ErrInvalidPromoCode)
+		}
+	case entity.DiscountTypeFixed:
+		if p.DiscountValue <= 0 {
+			return fmt.Errorf("%w: discount_value harus lebih dari 0", ErrInvalidPromoCode)
+		}
+		p.MaxDiscount = nil
+	default:
+		return fmt.Errorf("%w: discount_type harus PERCENT atau FIXED", ErrInvalidPromoCode)
+	}
+
+	if p.MaxDiscount != nil && *p.MaxDiscount <= 0 {
+		return fmt.Errorf("%w: max_discount harus lebih dari 0", ErrInvalidPromoCode)
+	}
+	if p.MaxUses != nil && *p.MaxUses <= 0 {
+		return fmt.Errorf("%w: max_uses harus lebih dari 0", ErrInvalidPromoCode)
+	}
+	if p.MaxUsesPerEmail != nil && *p.MaxUsesPerEmail <= 0 {
+		return fmt.Errorf("%w: max_uses_per_email harus lebih dari 0", ErrInvalidPromoCode)
+	}
+	if p.MinQty < 1 {
+		p.MinQty = 1
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		return fmt.Errorf("%w: ends_at harus setelah starts_at", ErrInvalidPromoCode)
+	}
+
+	for _, id := range p.EventIDs {
+		if !util.IsValidUUID(id) {
+			return fmt.Errorf("%w: event_id %q tidak valid", ErrInvalidPromoCode, id)
+		}
+	}
+
+	return nil
+}
@@ -4,9 +4,14 @@ import (
 	"context"
 	"database/sql"
 
+	addonDao "rakit-tiket-be/internal/app/app_addon/dao"
 	eventDao "rakit-tiket-be/internal/app/app_event/dao"
 	orderDao "rakit-tiket-be/internal/app/app_order/dao"
+	outboxDao "rakit-tiket-be/internal/app/app_outbox/dao"
+	presaleDao "rakit-tiket-be/internal/app/app_presale/dao"
+	promoDao "rakit-tiket-be/internal/app/app_promo/dao"
 	ticketDao "rakit-tiket-be/internal/app/app_ticket/dao"
+	waitlistDao "rakit-tiket-be/internal/app/app_waitlist/dao"
 	"rakit-tiket-be/internal/pkg/dao"
 	"rakit-tiket-be/pkg/util"
 )
@@ -18,18 +23,44 @@ type DBTransaction interface {
 	GetAttendeeDAO() AttendeeDAO
 
 	GetOrderDAO() orderDao.OrderDAO
+	GetOrderItemDAO() orderDao.OrderItemDAO
+	GetPaymentNotificationDAO() orderDao.PaymentNotificationDAO
+	GetETicketDAO() orderDao.ETicketDAO
 	GetTicketDAO() ticketDao.TicketDAO
+	GetPriceTierDAO() ticketDao.PriceTierDAO
 	GetEventDAO() eventDao.EventDAO
+	GetEmailOutboxDAO() outboxDao.EmailOutboxDAO
+	GetPromoCodeDAO() promoDao.PromoCodeDAO
+	GetPromoRedemptionDAO() promoDao.PromoRedemptionDAO
+	GetPresaleAccessCodeDAO() presaleDao.AccessCodeDAO
+	GetPresaleAllowlistDAO() presaleDao.AllowlistDAO
+	GetWaitlistDAO() waitlistDao.WaitlistDAO
+	GetAddonDAO() addonDao.AddonDAO
+	GetAddonVariantDAO() addonDao.AddonVariantDAO
+	GetOrderAddonDAO() addonDao.OrderAddonDAO
 }
 
 type dbTransaction struct {
 	dao.DBTransaction
 
-	registrantDAO RegistrantDAO
-	attendeeDAO   AttendeeDAO
-	orderDAO      orderDao.OrderDAO
-	ticketDAO     ticketDao.TicketDAO
-	eventDAO      eventDao.EventDAO
+	registrantDAO          RegistrantDAO
+	attendeeDAO            AttendeeDAO
+	orderDAO               orderDao.OrderDAO
+	orderItemDAO           orderDao.OrderItemDAO
+	paymentNotificationDAO orderDao.PaymentNotificationDAO
+	eTicketDAO             orderDao.ETicketDAO
+	ticketDAO              ticketDao.TicketDAO
+	priceTierDAO           ticketDao.PriceTierDAO
+	eventDAO               eventDao.EventDAO
+	emailOutboxDAO         outboxDao.EmailOutboxDAO
+	promoCodeDAO           promoDao.PromoCodeDAO
+	promoRedemptionDAO     promoDao.PromoRedemptionDAO
+	presaleAccessCodeDAO   presaleDao.AccessCodeDAO
+	presaleAllowlistDAO    presaleDao.AllowlistDAO
+	waitlistDAO            waitlistDao.WaitlistDAO
+	addonDAO               addonDao.AddonDAO
+	addonVariantDAO        addonDao.AddonVariantDAO
+	orderAddonDAO          addonDao.OrderAddonDAO
 }
 
 func NewTransactionRegistrant(ctx context.Context, log util.LogUtil, sqlDB *sql.DB) DBTransaction {
@@ -40,8 +71,21 @@ func NewTransactionRegistrant(ctx context.Context, log util.LogUtil, sqlDB *sql.
 	dbTrx.registrantDAO = MakeRegistrantDAO(log, dbTrx)
 	dbTrx.attendeeDAO = MakeAttendeeDAO(log, dbTrx)
 	dbTrx.orderDAO = orderDao.MakeOrderDAO(log, dbTrx)
+	dbTrx.orderItemDAO = orderDao.MakeOrderItemDAO(log, dbTrx)
+	dbTrx.paymentNotificationDAO = orderDao.MakePaymentNotificationDAO(log, dbTrx)
+	dbTrx.eTicketDAO = orderDao.MakeETicketDAO(log, dbTrx)
 	dbTrx.ticketDAO = ticketDao.MakeTicketDAO(log, dbTrx)
+	dbTrx.priceTierDAO = ticketDao.MakePriceTierDAO(log, dbTrx)
 	dbTrx.eventDAO = eventDao.MakeEventDAO(log, dbTrx)
+	dbTrx.emailOutboxDAO = outboxDao.MakeEmailOutboxDAO(log, dbTrx)
+	dbTrx.promoCodeDAO = promoDao.MakePromoCodeDAO(log, dbTrx)
+	dbTrx.promoRedemptionDAO = promoDao.MakePromoRedemptionDAO(log, dbTrx)
+	dbTrx.presaleAccessCodeDAO = presaleDao.MakeAccessCodeDAO(log, dbTrx)
+	dbTrx.presaleAllowlistDAO = presaleDao.MakeAllowlistDAO(log, dbTrx)
+	dbTrx.waitlistDAO = waitlistDao.MakeWaitlistDAO(log, dbTrx)
+	dbTrx.addonDAO = addonDao.MakeAddonDAO(log, dbTrx)
+	dbTrx.addonVariantDAO = addonDao.MakeAddonVariantDAO(log, dbTrx)
+	dbTrx.orderAddonDAO = addonDao.MakeOrderAddonDAO(log, dbTrx)
 
 	return dbTrx
 }
@@ -58,10 +102,62 @@ func (dbTrx *dbTransaction) GetOrderDAO() orderDao.OrderDAO {
 	return dbTrx.orderDAO
 }
 
+func (dbTrx *dbTransaction) GetOrderItemDAO() orderDao.OrderItemDAO {
+	return dbTrx.orderItemDAO
+}
+
+func (dbTrx *dbTransaction) GetPaymentNotificationDAO() orderDao.PaymentNotificationDAO {
+	return dbTrx.paymentNotificationDAO
+}
+
+func (dbTrx *dbTransaction) GetETicketDAO() orderDao.ETicketDAO {
+	return dbTrx.eTicketDAO
+}
+
 func (dbTrx *dbTransaction) GetTicketDAO() ticketDao.TicketDAO {
 	return dbTrx.ticketDAO
 }
 
+func (dbTrx *dbTransaction) GetPriceTierDAO() ticketDao.PriceTierDAO {
+	return dbTrx.priceTierDAO
+}
+
 func (dbTrx *dbTransaction) GetEventDAO() eventDao.EventDAO {
 	return dbTrx.eventDAO
 }
+
+func (dbTrx *dbTransaction) GetEmailOutboxDAO() outboxDao.EmailOutboxDAO {
+	return dbTrx.emailOutboxDAO
+}
+
+func (dbTrx *dbTransaction) GetPromoCodeDAO() promoDao.PromoCodeDAO {
+	return dbTrx.promoCodeDAO
+}
+
+func (dbTrx *dbTransaction) GetPromoRedemptionDAO() promoDao.PromoRedemptionDAO {
+	return dbTrx.promoRedemptionDAO
+}
+
+func (dbTrx *dbTransaction) GetPresaleAccessCodeDAO() presaleDao.AccessCodeDAO {
+	return dbTrx.presaleAccessCodeDAO
+}
+
+func (dbTrx *dbTransaction) GetPresaleAllowlistDAO() presaleDao.AllowlistDAO {
+	return dbTrx.presaleAllowlistDAO
+}
+
+func (dbTrx *dbTransaction) GetWaitlistDAO() waitlistDao.WaitlistDAO {
+	return dbTrx.waitlistDAO
+}
+
+func (dbTrx *dbTransaction) GetAddonDAO() addonDao.AddonDAO {
+	return dbTrx.addonDAO
+}
+
+func (dbTrx *dbTransaction) GetAddonVariantDAO() addonDao.AddonVariantDAO {
+	return dbTrx.addonVariantDAO
+}
+
+func (dbTrx *dbTransaction) GetOrderAddonDAO() addonDao.OrderAddonDAO {
+	return dbTrx.orderAddonDAO
+}
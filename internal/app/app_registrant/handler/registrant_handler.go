@@ -1,12 +1,17 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"os"
 	"path/filepath"
 
+	addonSvc "rakit-tiket-be/internal/app/app_addon/service"
+	presaleSvc "rakit-tiket-be/internal/app/app_presale/service"
 	"rakit-tiket-be/internal/app/app_registrant/service"
+	waitlistSvc "rakit-tiket-be/internal/app/app_waitlist/service"
 	"rakit-tiket-be/internal/pkg/middleware"
+	"rakit-tiket-be/internal/pkg/pricing"
 	model "rakit-tiket-be/pkg/model/app_registrant"
 
 	"github.com/labstack/echo/v4"
@@ -56,6 +61,27 @@ func (h registrantHandler) register(c echo.Context) error {
 
 	resp, err := h.registrantService.Register(c.Request().Context(), req)
 	if err != nil {
+		if errors.Is(err, pricing.ErrPromoInvalid) {
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+		}
+		if errors.Is(err, presaleSvc.ErrPresaleLocked) {
+			return echo.NewHTTPError(http.StatusForbidden, err.Error())
+		}
+		if errors.Is(err, service.ErrTicketSoldOut) {
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+		if errors.Is(err, pricing.ErrSaleNotStarted) || errors.Is(err, pricing.ErrSaleEnded) {
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+		if errors.Is(err, waitlistSvc.ErrOfferInvalid) {
+			return echo.NewHTTPError(http.StatusGone, err.Error())
+		}
+		if errors.Is(err, addonSvc.ErrAddonUnavailable) {
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+		}
+		if errors.Is(err, addonSvc.ErrAddonSoldOut) {
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
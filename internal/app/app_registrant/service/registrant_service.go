@@ -10,13 +10,21 @@ import (
 	"strings"
 	"time"
 
+	addonSvc "rakit-tiket-be/internal/app/app_addon/service"
 	paymentSvc "rakit-tiket-be/internal/app/app_payment/service"
+	presaleSvc "rakit-tiket-be/internal/app/app_presale/service"
 	"rakit-tiket-be/internal/app/app_registrant/dao"
+	ticketDao "rakit-tiket-be/internal/app/app_ticket/dao"
+	waitlistSvc "rakit-tiket-be/internal/app/app_waitlist/service"
+	"rakit-tiket-be/internal/pkg/pricing"
 	pubEntity "rakit-tiket-be/pkg/entity"
 	eventEntity "rakit-tiket-be/pkg/entity/app_event"
 	orderEntity "rakit-tiket-be/pkg/entity/app_order"
+	presaleEntity "rakit-tiket-be/pkg/entity/app_presale"
+	promoEntity "rakit-tiket-be/pkg/entity/app_promo"
 	regEntity "rakit-tiket-be/pkg/entity/app_registrant"
 	ticketEntity "rakit-tiket-be/pkg/entity/app_ticket"
+	waitlistEntity "rakit-tiket-be/pkg/entity/app_waitlist"
 	model "rakit-tiket-be/pkg/model/app_registrant"
 	httpModel "rakit-tiket-be/pkg/model/http"
 	"rakit-tiket-be/pkg/util"
@@ -24,6 +32,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// ErrTicketSoldOut dipakai saat BookStock gagal; pembeli bisa masuk waitlist tiket tersebut
+var ErrTicketSoldOut = errors.New("stok tiket tidak mencukupi (habis), silakan gabung waitlist")
+
 type RegistrantService interface {
 	Register(ctx context.Context, req model.RegisterRequest) (*model.RegisterResponse, error)
 	List(ctx context.Context, req model.SearchRegistrantsRequestModel) (int, model.SearchRegistrantsResponseModel)
@@ -88,6 +99,11 @@ func (s registrantService) Register(ctx context.Context, req model.RegisterReque
 		return nil, errors.New("tiket tidak ditemukan")
 	}
 
+	// Tier harga dibaca di transaksi yang sama dengan lock tiket agar rollover tier konsisten
+	if err := ticketDao.LoadPriceTiers(ctx, dbTrx.GetPriceTierDAO(), tickets); err != nil {
+		return nil, fmt.Errorf("failed to fetch price tiers: %v", err)
+	}
+
 	for i, t := range tickets {
 		ticketMap[string(t.ID)] = t
 		if i == 0 {
@@ -116,8 +132,41 @@ func (s registrantService) Register(ctx context.Context, req model.RegisterReque
 		return nil, fmt.Errorf("maksimal %d tiket per registrasi untuk event ini", eventData.MaxTicketPerTx)
 	}
 
+	now := time.Now()
+
+	// Penawaran waitlist dikunci setelah tiket, urutan yang sama dengan job penawaran
+	var offer *waitlistEntity.Entry
+	if token := strings.TrimSpace(req.WaitlistToken); token != "" {
+		offer, err = waitlistSvc.LockOffer(ctx, dbTrx, token, req.Registrant.Email, now)
+		if err != nil {
+			return nil, err
+		}
+		if ticketQtyMap[string(offer.TicketID)] == 0 {
+			return nil, fmt.Errorf("%w: tiket penawaran tidak ada di registrasi", waitlistSvc.ErrOfferInvalid)
+		}
+	}
+
+	// Tiket presale hanya untuk pemegang kode akses / email yang ada di allowlist event
+	access, err := presaleSvc.ResolveAccess(ctx, dbTrx, presaleEntity.PresaleEventIDs(tickets), presaleEntity.Credential{
+		AccessCode: req.AccessCode,
+		Email:      req.Registrant.Email,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve presale access: %v", err)
+	}
+	for _, t := range tickets {
+		// Tiket penawaran waitlist sudah lolos cek presale saat join antrean
+		if offer != nil && t.ID == offer.TicketID {
+			continue
+		}
+		if !access.Allows(t) {
+			return nil, fmt.Errorf("%w: %s", presaleSvc.ErrPresaleLocked, t.Title)
+		}
+	}
+
 	// ATOMIC BOOKING STOCK
 	var totalCost float64
+	var orderItems orderEntity.OrderItems
 
 	for tID, qty := range ticketQtyMap {
 		ticketData, exists := ticketMap[tID]
@@ -125,18 +174,78 @@ func (s registrantService) Register(ctx context.Context, req model.RegisterReque
 			return nil, fmt.Errorf("tiket %s tidak ditemukan", tID)
 		}
 
+		// Validasi Window Penjualan
+		if err := pricing.CheckSaleWindow(ticketData, now); err != nil {
+			return nil, fmt.Errorf("%w: %s", err, ticketData.Title)
+		}
+
+		// Stok penawaran waitlist sudah di-booking, hanya kelebihannya yang dipesan
+		bookQty := qty
+		taken := ticketData.BookedQty + ticketData.SoldQty
+		if offer != nil && offer.TicketID == ticketData.ID {
+			held := min(qty, offer.Quantity)
+			bookQty -= held
+			taken -= held
+		}
+
 		// Eksekusi Atomic Booking!
-		err := dbTrx.GetTicketDAO().BookStock(ctx, pubEntity.UUID(tID), qty)
+		if bookQty > 0 {
+			if err := dbTrx.GetTicketDAO().BookStock(ctx, pubEntity.UUID(tID), bookQty); err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrTicketSoldOut, ticketData.Title)
+			}
+		}
+
+		// Hitung Harga (tier / flash sale / normal) dan simpan snapshot per item.
+		// Pembelian yang melewati batas quantity tier dipecah menjadi satu item per tier.
+		lines, err := pricing.AllocateTiers(ticketData, qty, taken, now)
 		if err != nil {
-			return nil, fmt.Errorf("stok tiket %s tidak mencukupi (habis)", ticketData.Title)
+			return nil, fmt.Errorf("%w: %s", err, ticketData.Title)
 		}
 
-		// Hitung Harga
-		totalCost += ticketData.Price * float64(qty)
+		for _, line := range lines {
+			subtotal := line.UnitPrice * float64(line.Quantity)
+			totalCost += subtotal
+
+			item := orderEntity.OrderItem{
+				TicketID:      ticketData.ID,
+				TicketTitle:   ticketData.Title,
+				Quantity:      line.Quantity,
+				UnitPrice:     line.UnitPrice,
+				OriginalPrice: line.OriginalPrice,
+				IsFlashSale:   line.IsFlashSale,
+				Subtotal:      subtotal,
+			}
+			if line.Tier != nil {
+				item.TierID = &line.Tier.ID
+				item.TierName = &line.Tier.Name
+			}
+			orderItems = append(orderItems, item)
+		}
+	}
+
+	// Add-on dikunci setelah tiket agar urutan lock tetap konsisten antar registrasi
+	orderAddons, err := addonSvc.BookAddons(ctx, dbTrx, eventID, req.Addons)
+	if err != nil {
+		return nil, err
+	}
+
+	// Kode promo dikunci dan dipakai di transaksi yang sama dengan BookStock
+	var promo *promoEntity.PromoCode
+	var discount float64
+	if code := strings.TrimSpace(req.PromoCode); code != "" {
+		promo, discount, err = s.redeemPromo(ctx, dbTrx, code, req.Registrant.Email, string(eventID), ticketMap, orderItems, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// Diskon promo hanya berlaku untuk tiket; add-on selalu dibayar penuh
+	amount := totalCost + orderAddons.Total() - discount
+	// Gateway menolak transaksi bernilai 0, promo yang menutupi seluruh pembayaran ditolak di sini
+	if promo != nil && amount <= 0 {
+		return nil, fmt.Errorf("%w: potongan promo menutupi seluruh nilai order", pricing.ErrPromoInvalid)
 	}
 
 	// Generate Identifier Dinamis (Menggunakan Prefix dari Event)
-	now := time.Now()
 	registrantID := pubEntity.MakeUUID(req.Registrant.Email, now.String())
 	orderID := pubEntity.MakeUUID("ORDER", req.Registrant.Email, now.String())
 
@@ -168,7 +277,7 @@ func (s registrantService) Register(ctx context.Context, req model.RegisterReque
 		Phone:        req.Registrant.Phone,
 		Gender:       req.Registrant.Gender,
 		Birthdate:    regBirthdate,
-		TotalCost:    totalCost,
+		TotalCost:    amount,
 		TotalTickets: totalRequestedTickets,
 		Status:       "pending",
 	}
@@ -208,14 +317,18 @@ func (s registrantService) Register(ctx context.Context, req model.RegisterReque
 	expiresAt := now.Add(15 * time.Minute)
 
 	order := orderEntity.Order{
-		ID:            orderID,
-		EventID:       eventID,
-		RegistrantID:  registrantID,
-		OrderNumber:   orderNumber,
-		Amount:        totalCost,
-		Currency:      "IDR",
-		PaymentStatus: orderEntity.OrderStatusPending,
-		ExpiresAt:     &expiresAt,
+		ID:             orderID,
+		EventID:        eventID,
+		RegistrantID:   registrantID,
+		OrderNumber:    orderNumber,
+		Amount:         amount,
+		DiscountAmount: discount,
+		Currency:       "IDR",
+		PaymentStatus:  orderEntity.OrderStatusPending,
+		ExpiresAt:      &expiresAt,
+	}
+	if promo != nil {
+		order.PromoCode = &promo.Code
 	}
 	order.CreatedAt = now
 
@@ -223,18 +336,58 @@ func (s registrantService) Register(ctx context.Context, req model.RegisterReque
 		return nil, err
 	}
 
+	for i := range orderItems {
+		orderItems[i].OrderID = orderID
+	}
+
+	if err := dbTrx.GetOrderItemDAO().Insert(ctx, orderItems); err != nil {
+		return nil, err
+	}
+
+	for i := range orderAddons {
+		orderAddons[i].OrderID = orderID
+		orderAddons[i].OrderNumber = orderNumber
+		orderAddons[i].PaymentStatus = orderEntity.OrderStatusPending
+	}
+
+	if err := dbTrx.GetOrderAddonDAO().Insert(ctx, orderAddons); err != nil {
+		return nil, err
+	}
+
+	if offer != nil {
+		if err := waitlistSvc.ClaimOffer(ctx, dbTrx, *offer, orderID, ticketQtyMap[string(offer.TicketID)], now); err != nil {
+			return nil, fmt.Errorf("gagal mengklaim penawaran waitlist: %v", err)
+		}
+	}
+
+	if promo != nil {
+		if err := dbTrx.GetPromoRedemptionDAO().Insert(ctx, promoEntity.PromoRedemptions{{
+			PromoCodeID:    promo.ID,
+			OrderID:        orderID,
+			Email:          req.Registrant.Email,
+			DiscountAmount: discount,
+			CreatedAt:      now,
+		}}); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := dbTrx.GetSqlTx().Commit(); err != nil {
 		return nil, err
 	}
 
 	response := &model.RegisterResponse{
 		Order: model.OrderInfo{
-			OrderID:       string(order.ID),
-			OrderNumber:   order.OrderNumber,
-			Amount:        order.Amount,
-			Currency:      order.Currency,
-			PaymentStatus: order.PaymentStatus,
-			ExpiresAt:     order.ExpiresAt,
+			OrderID:        string(order.ID),
+			OrderNumber:    order.OrderNumber,
+			Amount:         order.Amount,
+			DiscountAmount: order.DiscountAmount,
+			PromoCode:      order.PromoCode,
+			AddonAmount:    orderAddons.Total(),
+			Currency:       order.Currency,
+			PaymentStatus:  order.PaymentStatus,
+			ExpiresAt:      order.ExpiresAt,
+			Addons:         orderAddons,
 		},
 		Registrant: model.RegistrantInfo{
 			ID:         string(registrant.ID),
@@ -277,6 +430,60 @@ func (s registrantService) Register(ctx context.Context, req model.RegisterReque
 	return response, nil
 }
 
+// redeemPromo memvalidasi kode promo terhadap item order lalu menambah pemakaiannya secara atomik.
+// Baris promo dikunci (FOR UPDATE) sehingga cek kuota per email tidak balapan dengan registrasi lain.
+func (s registrantService) redeemPromo(
+	ctx context.Context,
+	dbTrx dao.DBTransaction,
+	code, email, eventID string,
+	ticketMap map[string]ticketEntity.Ticket,
+	orderItems orderEntity.OrderItems,
+	now time.Time,
+) (*promoEntity.PromoCode, float64, error) {
+	promos, err := dbTrx.GetPromoCodeDAO().SearchForUpdate(ctx, promoEntity.PromoCodeQuery{Codes: []string{code}})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch promo code: %v", err)
+	}
+	if len(promos) == 0 {
+		return nil, 0, fmt.Errorf("%w: kode promo tidak ditemukan", pricing.ErrPromoInvalid)
+	}
+	promo := promos[0]
+
+	if err := pricing.CheckPromoWindow(promo, now); err != nil {
+		return nil, 0, err
+	}
+
+	if promo.MaxUsesPerEmail != nil {
+		used, err := dbTrx.GetPromoRedemptionDAO().CountActive(ctx, promo.ID, email)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to count promo usage: %v", err)
+		}
+		if used >= *promo.MaxUsesPerEmail {
+			return nil, 0, fmt.Errorf("%w: kode promo sudah dipakai maksimal untuk email ini", pricing.ErrPromoInvalid)
+		}
+	}
+
+	lines := make([]pricing.PromoLine, 0, len(orderItems))
+	for _, item := range orderItems {
+		lines = append(lines, pricing.PromoLine{
+			TicketType: ticketMap[string(item.TicketID)].Type,
+			Quantity:   item.Quantity,
+			Subtotal:   item.Subtotal,
+		})
+	}
+
+	discount, err := pricing.ResolveDiscount(promo, eventID, lines)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := dbTrx.GetPromoCodeDAO().Redeem(ctx, promo.ID); err != nil {
+		return nil, 0, fmt.Errorf("%w: kuota kode promo sudah habis", pricing.ErrPromoInvalid)
+	}
+
+	return &promo, discount, nil
+}
+
 func (s registrantService) List(ctx context.Context, req model.SearchRegistrantsRequestModel) (int, model.SearchRegistrantsResponseModel) {
 	dbTrx := dao.NewTransactionRegistrant(ctx, s.log, s.sqlDB)
 
@@ -12,12 +12,14 @@ type DBTransaction interface {
 	baseDao.DBTransaction
 
 	GetTicketDAO() TicketDAO
+	GetPriceTierDAO() PriceTierDAO
 }
 
 type dbTransaction struct {
 	baseDao.DBTransaction
 
-	ticketDAO TicketDAO
+	ticketDAO    TicketDAO
+	priceTierDAO PriceTierDAO
 }
 
 func NewTransactionTicket(ctx context.Context, log util.LogUtil, sqlDB *sql.DB) DBTransaction {
@@ -26,9 +28,14 @@ func NewTransactionTicket(ctx context.Context, log util.LogUtil, sqlDB *sql.DB)
 	}
 
 	dbTrx.ticketDAO = MakeTicketDAO(log, dbTrx)
+	dbTrx.priceTierDAO = MakePriceTierDAO(log, dbTrx)
 	return dbTrx
 }
 
 func (dbTrx *dbTransaction) GetTicketDAO() TicketDAO {
 	return dbTrx.ticketDAO
 }
+
+func (dbTrx *dbTransaction) GetPriceTierDAO() PriceTierDAO {
+	return dbTrx.priceTierDAO
+}
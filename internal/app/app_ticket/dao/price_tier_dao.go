@@ -0,0 +1,272 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	baseDao "rakit-tiket-be/internal/pkg/dao"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	entity "rakit-tiket-be/pkg/entity/app_ticket"
+	"rakit-tiket-be/pkg/util"
+
+	"gitlab.com/threetopia/sqlgo/v2"
+	"go.uber.org/zap"
+)
+
+type PriceTierDAO interface {
+	// Search mengurutkan tier per tiket berdasarkan tier_order
+	Search(ctx context.Context, query entity.PriceTierQuery) (entity.PriceTiers, error)
+	Insert(ctx context.Context, tiers entity.PriceTiers) error
+	Update(ctx context.Context, tiers entity.PriceTiers) error
+	Delete(ctx context.Context, ids []string) error
+
+	// Stats menghitung tiket terjual (order paid) dan dibooking (order pending) per tier dari order_items
+	Stats(ctx context.Context, eventID, ticketID *pubEntity.UUID) (entity.TierStatsList, error)
+}
+
+type priceTierDAO struct {
+	log   util.LogUtil
+	dbTrx baseDao.DBTransaction
+}
+
+func MakePriceTierDAO(log util.LogUtil, dbTrx baseDao.DBTransaction) PriceTierDAO {
+	return priceTierDAO{
+		log:   log,
+		dbTrx: dbTrx,
+	}
+}
+
+func (d priceTierDAO) Search(ctx context.Context, query entity.PriceTierQuery) (entity.PriceTiers, error) {
+	sqlSelect := sqlgo.NewSQLGoSelect().
+		SetSQLSelect("pt.id", "id").
+		SetSQLSelect("pt.ticket_id", "ticket_id").
+		SetSQLSelect("pt.tier_order", "tier_order").
+		SetSQLSelect("pt.name", "name").
+		SetSQLSelect("pt.price", "price").
+		SetSQLSelect("pt.end_sold_qty", "end_sold_qty").
+		SetSQLSelect("pt.end_at", "end_at").
+		SetSQLSelect("pt.created_at", "created_at").
+		SetSQLSelect("pt.updated_at", "updated_at")
+
+	sqlFrom := sqlgo.NewSQLGoFrom().
+		SetSQLFrom("ticket_price_tiers", "pt")
+
+	sqlWhere := sqlgo.NewSQLGoWhere()
+
+	if len(query.IDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "pt.id", "IN", query.IDs)
+	}
+	if len(query.TicketIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "pt.ticket_id", "IN", query.TicketIDs)
+	}
+
+	sqlOrder := sqlgo.NewSQLGoOrder().
+		SetSQLOrder("pt.ticket_id", "ASC").
+		SetSQLOrder("pt.tier_order", "ASC")
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoSelect(sqlSelect).
+		SetSQLGoFrom(sqlFrom).
+		SetSQLGoWhere(sqlWhere).
+		SetSQLGoOrder(sqlOrder)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "priceTierDAO.Search",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	rows, err := d.dbTrx.GetSqlDB().QueryContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "priceTierDAO.Search", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tiers entity.PriceTiers
+	for rows.Next() {
+		var tier entity.PriceTier
+		if err := rows.Scan(
+			&tier.ID, &tier.TicketID, &tier.TierOrder, &tier.Name, &tier.Price,
+			&tier.EndSoldQty, &tier.EndAt, &tier.CreatedAt, &tier.UpdatedAt,
+		); err != nil {
+			d.log.Error(ctx, "priceTierDAO.Search.Scan", zap.Error(err))
+			return nil, err
+		}
+		tiers = append(tiers, tier)
+	}
+
+	return tiers, nil
+}
+
+func (d priceTierDAO) Insert(ctx context.Context, tiers entity.PriceTiers) error {
+	if len(tiers) < 1 {
+		return fmt.Errorf("empty price tier data")
+	}
+
+	sqlInsert := sqlgo.NewSQLGoInsert().
+		SetSQLInsert("ticket_price_tiers").
+		SetSQLInsertColumn(
+			"id", "ticket_id", "tier_order", "name", "price",
+			"end_sold_qty", "end_at", "created_at",
+		)
+
+	for i, tier := range tiers {
+		tier.CreatedAt = time.Now()
+		if tier.ID == "" {
+			tier.ID = pubEntity.MakeUUID("PRICE_TIER", string(tier.TicketID), tier.Name, tier.CreatedAt.String())
+		}
+
+		sqlInsert.SetSQLInsertValue(
+			tier.ID, tier.TicketID, tier.TierOrder, tier.Name, tier.Price,
+			tier.EndSoldQty, tier.EndAt, tier.CreatedAt,
+		)
+		tiers[i] = tier
+	}
+
+	sql := sqlgo.NewSQLGo().SetSQLSchema("public").SetSQLGoInsert(sqlInsert)
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "priceTierDAO.Insert", zap.String("SQL", sqlStr), zap.Int("Count", len(tiers)))
+
+	_, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "priceTierDAO.Insert", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (d priceTierDAO) Update(ctx context.Context, tiers entity.PriceTiers) error {
+	if len(tiers) < 1 {
+		return fmt.Errorf("empty price tier data")
+	}
+
+	for i, tier := range tiers {
+		now := time.Now()
+		tier.UpdatedAt = &now
+
+		sql := sqlgo.NewSQLGo().
+			SetSQLSchema("public").
+			SetSQLUpdate("ticket_price_tiers").
+			SetSQLUpdateValue("tier_order", tier.TierOrder).
+			SetSQLUpdateValue("name", tier.Name).
+			SetSQLUpdateValue("price", tier.Price).
+			SetSQLUpdateValue("end_sold_qty", tier.EndSoldQty).
+			SetSQLUpdateValue("end_at", tier.EndAt).
+			SetSQLUpdateValue("updated_at", tier.UpdatedAt).
+			SetSQLWhere("AND", "id", "=", tier.ID)
+
+		sqlStr := sql.BuildSQL()
+		sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+		d.log.Debug(ctx, "priceTierDAO.Update", zap.String("ID", string(tier.ID)))
+
+		if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...); err != nil {
+			d.log.Error(ctx, "priceTierDAO.Update", zap.Error(err))
+			return err
+		}
+		tiers[i] = tier
+	}
+	return nil
+}
+
+func (d priceTierDAO) Delete(ctx context.Context, ids []string) error {
+	if len(ids) < 1 {
+		return nil
+	}
+
+	sql := sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLDelete("ticket_price_tiers").
+		SetSQLWhere("AND", "id", "IN", ids)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "priceTierDAO.Delete", zap.Strings("IDs", ids))
+
+	if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...); err != nil {
+		d.log.Error(ctx, "priceTierDAO.Delete", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (d priceTierDAO) Stats(ctx context.Context, eventID, ticketID *pubEntity.UUID) (entity.TierStatsList, error) {
+	query := `
+        SELECT
+            pt.id, pt.ticket_id, pt.tier_order, pt.name, pt.price,
+            pt.end_sold_qty, pt.end_at, pt.created_at, pt.updated_at,
+            t.title,
+            COALESCE(SUM(oi.quantity) FILTER (WHERE o.payment_status = 'paid'), 0),
+            COALESCE(SUM(oi.quantity) FILTER (WHERE o.payment_status = 'pending'), 0),
+            COALESCE(SUM(oi.subtotal) FILTER (WHERE o.payment_status = 'paid'), 0)
+        FROM ticket_price_tiers pt
+        JOIN tickets t ON t.id = pt.ticket_id
+        LEFT JOIN order_items oi ON oi.tier_id = pt.id AND oi.deleted = false
+        LEFT JOIN orders o ON o.id = oi.order_id
+        WHERE ($1::uuid IS NULL OR t.event_id = $1::uuid)
+        AND ($2::uuid IS NULL OR pt.ticket_id = $2::uuid)
+        GROUP BY pt.id, t.title, t.order_priority
+        ORDER BY t.order_priority, t.title, pt.tier_order
+    `
+
+	d.log.Debug(ctx, "priceTierDAO.Stats", zap.Any("EventID", eventID), zap.Any("TicketID", ticketID))
+
+	rows, err := d.dbTrx.GetSqlDB().QueryContext(ctx, query, eventID, ticketID)
+	if err != nil {
+		d.log.Error(ctx, "priceTierDAO.Stats", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats entity.TierStatsList
+	for rows.Next() {
+		var s entity.TierStats
+		if err := rows.Scan(
+			&s.ID, &s.TicketID, &s.TierOrder, &s.Name, &s.Price,
+			&s.EndSoldQty, &s.EndAt, &s.CreatedAt, &s.UpdatedAt,
+			&s.TicketTitle, &s.SoldQty, &s.BookedQty, &s.Revenue,
+		); err != nil {
+			d.log.Error(ctx, "priceTierDAO.Stats.Scan", zap.Error(err))
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+
+	return stats, nil
+}
+
+// LoadPriceTiers mengisi PriceTiers setiap tiket agar pricing bisa me-resolve tier aktif
+func LoadPriceTiers(ctx context.Context, priceTierDAO PriceTierDAO, tickets entity.Tickets) error {
+	if len(tickets) == 0 {
+		return nil
+	}
+
+	ticketIDs := make([]string, 0, len(tickets))
+	for _, t := range tickets {
+		ticketIDs = append(ticketIDs, string(t.ID))
+	}
+
+	tiers, err := priceTierDAO.Search(ctx, entity.PriceTierQuery{TicketIDs: ticketIDs})
+	if err != nil {
+		return err
+	}
+
+	tierMap := make(map[pubEntity.UUID]entity.PriceTiers)
+	for _, tier := range tiers {
+		tierMap[tier.TicketID] = append(tierMap[tier.TicketID], tier)
+	}
+
+	for i := range tickets {
+		tickets[i].PriceTiers = tierMap[tickets[i].ID]
+	}
+
+	return nil
+}
@@ -23,6 +23,7 @@ type TicketDAO interface {
 	BookStock(ctx context.Context, id pubEntity.UUID, qty int) error
 	ConfirmSold(ctx context.Context, id pubEntity.UUID, qty int) error
 	ReleaseBooked(ctx context.Context, id pubEntity.UUID, qty int) error
+	ReleaseSold(ctx context.Context, id pubEntity.UUID, qty int) error
 }
 
 type ticketDAO struct {
@@ -83,6 +84,10 @@ func (d ticketDAO) Search(ctx context.Context, query entity.TicketQuery) (entity
 	if query.IsPresale != nil {
 		sqlWhere.SetSQLWhere("AND", "t.is_presale", "=", *query.IsPresale)
 	}
+
+	if query.IsFlashSale != nil {
+		sqlWhere.SetSQLWhere("AND", "t.is_flash_sale", "=", *query.IsFlashSale)
+	}
 	if query.Statuses != nil {
 		sqlWhere.SetSQLWhere("AND", "t.status", "=", query.Statuses)
 	}
@@ -177,6 +182,10 @@ func (d ticketDAO) SearchForUpdate(ctx context.Context, query entity.TicketQuery
 	if query.IsPresale != nil {
 		sqlWhere.SetSQLWhere("AND", "t.is_presale", "=", *query.IsPresale)
 	}
+
+	if query.IsFlashSale != nil {
+		sqlWhere.SetSQLWhere("AND", "t.is_flash_sale", "=", *query.IsFlashSale)
+	}
 	if query.Statuses != nil {
 		sqlWhere.SetSQLWhere("AND", "t.status", "=", query.Statuses)
 	}
@@ -450,3 +459,37 @@ func (d ticketDAO) ReleaseBooked(ctx context.Context, id pubEntity.UUID, qty int
 
 	return nil
 }
+
+func (d ticketDAO) ReleaseSold(ctx context.Context, id pubEntity.UUID, qty int) error {
+	if qty <= 0 {
+		return fmt.Errorf("invalid qty")
+	}
+
+	query := `
+        UPDATE tickets
+        SET 
+            sold_qty      = sold_qty - $1,
+            available_qty = available_qty + $1,
+            status = 'AVAILABLE'::ticket_status_enum,
+            updated_at    = $2
+        WHERE id = $3
+        AND sold_qty >= $1
+        AND deleted = false
+    `
+
+	d.log.Debug(ctx, "ticketDAO.ReleaseSold", zap.String("ID", string(id)), zap.Int("Qty", qty))
+
+	result, err := d.dbTrx.GetSqlTx().ExecContext(ctx, query, qty, time.Now(), id)
+	if err != nil {
+		d.log.Error(ctx, "ticketDAO.ReleaseSold", zap.Error(err))
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil || rows == 0 {
+		d.log.Warn(ctx, "ticketDAO.ReleaseSold.NoRowsAffected", zap.String("ID", string(id)))
+		return fmt.Errorf("insufficient sold stock to release")
+	}
+
+	return nil
+}
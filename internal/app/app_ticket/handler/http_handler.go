@@ -12,21 +12,27 @@ type HttpHandler interface {
 }
 
 type httpHandler struct {
-	ticketService service.TicketService
+	ticketService    service.TicketService
+	priceTierService service.PriceTierService
 
-	ticketHandler TicketHandler
+	ticketHandler    TicketHandler
+	priceTierHandler PriceTierHandler
 }
 
 func MakeHttpAdapter(
 	ticketService service.TicketService,
+	priceTierService service.PriceTierService,
 	authMiddleware middleware.AuthMiddleware,
 ) HttpHandler {
 	return httpHandler{
-		ticketService: ticketService,
-		ticketHandler: MakeTicketHandler(ticketService, authMiddleware),
+		ticketService:    ticketService,
+		priceTierService: priceTierService,
+		ticketHandler:    MakeTicketHandler(ticketService, authMiddleware),
+		priceTierHandler: MakePriceTierHandler(priceTierService, authMiddleware),
 	}
 }
 
 func (h httpHandler) RegisterRoute(g *echo.Group) {
 	h.ticketHandler.RegisterRouter(g)
+	h.priceTierHandler.RegisterRouter(g)
 }
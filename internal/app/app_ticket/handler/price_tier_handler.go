@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"rakit-tiket-be/internal/app/app_ticket/service"
+	"rakit-tiket-be/internal/pkg/middleware"
+	pubEntity "rakit-tiket-be/pkg/entity"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PriceTierHandler interface {
+	RegisterRouter(g *echo.Group)
+}
+
+type priceTierHandler struct {
+	priceTierService service.PriceTierService
+	middleware       middleware.AuthMiddleware
+}
+
+func MakePriceTierHandler(
+	priceTierService service.PriceTierService,
+	middleware middleware.AuthMiddleware,
+) priceTierHandler {
+	return priceTierHandler{
+		priceTierService: priceTierService,
+		middleware:       middleware,
+	}
+}
+
+func (h priceTierHandler) RegisterRouter(g *echo.Group) {
+	restricted := g.Group("/v1/admin")
+
+	restricted.Use(h.middleware.VerifyToken)
+	restricted.Use(h.middleware.RequireAdmin)
+
+	restricted.GET("/tickets/tiers", h.searchStats)
+	restricted.GET("/ticket/:id/tiers", h.getTiers)
+	restricted.PUT("/ticket/:id/tiers", h.setTiers)
+}
+
+func (h priceTierHandler) searchStats(c echo.Context) error {
+	data, err := h.priceTierService.SearchStats(c.Request().Context(), c.QueryParam("event_id"))
+	if err != nil {
+		return priceTierError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+		"count":   len(data),
+	})
+}
+
+func (h priceTierHandler) getTiers(c echo.Context) error {
+	data, err := h.priceTierService.GetTiers(c.Request().Context(), pubEntity.UUID(c.Param("id")))
+	if err != nil {
+		return priceTierError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+		"count":   len(data),
+	})
+}
+
+func (h priceTierHandler) setTiers(c echo.Context) error {
+	var req service.SetPriceTiersRequest
+
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.priceTierService.SetTiers(c.Request().Context(), pubEntity.UUID(c.Param("id")), req.Tiers)
+	if err != nil {
+		return priceTierError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+		"count":   len(data),
+	})
+}
+
+func priceTierError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrTierTicketNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidTier):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrTierInUse):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
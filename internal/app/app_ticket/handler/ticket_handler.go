@@ -6,6 +6,7 @@ import (
 	"rakit-tiket-be/internal/app/app_ticket/service"
 	"rakit-tiket-be/internal/pkg/middleware"
 	pubEntity "rakit-tiket-be/pkg/entity"
+	presaleEntity "rakit-tiket-be/pkg/entity/app_presale"
 	entity "rakit-tiket-be/pkg/entity/app_ticket"
 
 	"github.com/labstack/echo/v4"
@@ -34,11 +35,12 @@ func (h ticketHandler) RegisterRouter(g *echo.Group) {
 	restricted := g.Group("/v1/admin")
 	restrictedPublic := g.Group("/v1")
 
-	restrictedPublic.GET("/tickets", h.searchTickets)
+	restrictedPublic.GET("/tickets", h.searchVisibleTickets)
 
 	restricted.Use(h.middleware.VerifyToken)
 	restricted.Use(h.middleware.RequireAdmin)
 
+	restricted.GET("/tickets", h.searchTickets)
 	restricted.POST("/tickets", h.insertTickets)
 	restricted.PUT("/tickets", h.updateTickets)
 	restricted.PUT("/ticket/:id", h.updateTicket)
@@ -46,6 +48,29 @@ func (h ticketHandler) RegisterRouter(g *echo.Group) {
 	restricted.DELETE("/ticket/:id", h.softDeleteTicket)
 }
 
+// searchVisibleTickets menampilkan tiket presale hanya jika access_code / email membukanya
+func (h ticketHandler) searchVisibleTickets(c echo.Context) error {
+	var query entity.TicketQuery
+
+	if err := c.Bind(&query); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	tickets, err := h.ticketService.SearchVisible(
+		c.Request().Context(),
+		query,
+		presaleEntity.Credential{
+			AccessCode: c.QueryParam("access_code"),
+			Email:      c.QueryParam("email"),
+		},
+	)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, tickets)
+}
+
 func (h ticketHandler) searchTickets(c echo.Context) error {
 	var query entity.TicketQuery
 
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"rakit-tiket-be/internal/app/app_ticket/dao"
+	"rakit-tiket-be/internal/pkg/pricing"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	entity "rakit-tiket-be/pkg/entity/app_ticket"
+	"rakit-tiket-be/pkg/util"
+)
+
+var (
+	ErrTierTicketNotFound = errors.New("tiket tidak ditemukan")
+	ErrInvalidTier        = errors.New("tier harga tidak valid")
+	ErrTierInUse          = errors.New("tier harga sudah dipakai order")
+)
+
+type SetPriceTiersRequest struct {
+	Tiers entity.PriceTiers `json:"tiers"`
+}
+
+type PriceTierService interface {
+	// GetTiers mengembalikan tier group tiket lengkap dengan jumlah terjual per tier
+	GetTiers(ctx context.Context, ticketID pubEntity.UUID) (entity.TierStatsList, error)
+	// SetTiers mengganti seluruh tier group tiket; urutan array menjadi urutan tier
+	SetTiers(ctx context.Context, ticketID pubEntity.UUID, tiers entity.PriceTiers) (entity.TierStatsList, error)
+	SearchStats(ctx context.Context, eventID string) (entity.TierStatsList, error)
+}
+
+type priceTierService struct {
+	log   util.LogUtil
+	sqlDB *sql.DB
+}
+
+func MakePriceTierService(log util.LogUtil, sqlDB *sql.DB) PriceTierService {
+	return priceTierService{
+		log:   log,
+		sqlDB: sqlDB,
+	}
+}
+
+func (s priceTierService) GetTiers(ctx context.Context, ticketID pubEntity.UUID) (entity.TierStatsList, error) {
+	dbTrx := dao.NewTransactionTicket(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	tickets, err := dbTrx.GetTicketDAO().Search(ctx, entity.TicketQuery{IDs: []string{string(ticketID)}})
+	if err != nil {
+		return nil, err
+	}
+	if len(tickets) == 0 {
+		return nil, ErrTierTicketNotFound
+	}
+
+	stats, err := dbTrx.GetPriceTierDAO().Stats(ctx, nil, &ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.decorateStats(ctx, dbTrx, tickets, stats)
+}
+
+func (s priceTierService) SetTiers(ctx context.Context, ticketID pubEntity.UUID, tiers entity.PriceTiers) (entity.TierStatsList, error) {
+	dbTrx := dao.NewTransactionTicket(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	// Lock tiket agar perubahan tier tidak balapan dengan registrasi yang sedang menghitung harga
+	tickets, err := dbTrx.GetTicketDAO().SearchForUpdate(ctx, entity.TicketQuery{IDs: []string{string(ticketID)}})
+	if err != nil {
+		return nil, err
+	}
+	if len(tickets) == 0 {
+		return nil, ErrTierTicketNotFound
+	}
+	ticket := tickets[0]
+
+	if err := validateTiers(ticket, tiers); err != nil {
+		return nil, err
+	}
+
+	existing, err := dbTrx.GetPriceTierDAO().Stats(ctx, nil, &ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	existingMap := make(map[pubEntity.UUID]entity.TierStats)
+	for _, e := range existing {
+		existingMap[e.ID] = e
+	}
+
+	var inserts, updates entity.PriceTiers
+	kept := make(map[pubEntity.UUID]bool)
+	for i, tier := range tiers {
+		tier.TicketID = ticketID
+		tier.TierOrder = i + 1
+		tier.Name = strings.TrimSpace(tier.Name)
+
+		if tier.ID == "" {
+			inserts = append(inserts, tier)
+			continue
+		}
+		if _, ok := existingMap[tier.ID]; !ok {
+			return nil, fmt.Errorf("%w: tier %s bukan milik tiket ini", ErrInvalidTier, tier.ID)
+		}
+		kept[tier.ID] = true
+		updates = append(updates, tier)
+	}
+
+	// Tier yang dihapus tidak boleh punya order aktif agar rekap penjualan per tier tidak hilang
+	var deleteIDs []string
+	for _, e := range existing {
+		if kept[e.ID] {
+			continue
+		}
+		if e.SoldQty+e.BookedQty > 0 {
+			return nil, fmt.Errorf("%w: %s (%d terjual, %d dibooking)", ErrTierInUse, e.Name, e.SoldQty, e.BookedQty)
+		}
+		deleteIDs = append(deleteIDs, string(e.ID))
+	}
+
+	if err := dbTrx.GetPriceTierDAO().Delete(ctx, deleteIDs); err != nil {
+		return nil, err
+	}
+	if len(updates) > 0 {
+		if err := dbTrx.GetPriceTierDAO().Update(ctx, updates); err != nil {
+			return nil, err
+		}
+	}
+	if len(inserts) > 0 {
+		if err := dbTrx.GetPriceTierDAO().Insert(ctx, inserts); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := dbTrx.GetSqlTx().Commit(); err != nil {
+		return nil, err
+	}
+
+	return s.GetTiers(ctx, ticketID)
+}
+
+func (s priceTierService) SearchStats(ctx context.Context, eventID string) (entity.TierStatsList, error) {
+	dbTrx := dao.NewTransactionTicket(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	var eventFilter *pubEntity.UUID
+	if eventID != "" {
+		id := pubEntity.UUID(eventID)
+		eventFilter = &id
+	}
+
+	stats, err := dbTrx.GetPriceTierDAO().Stats(ctx, eventFilter, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(stats) == 0 {
+		return entity.TierStatsList{}, nil
+	}
+
+	var ticketIDs []string
+	seen := make(map[pubEntity.UUID]bool)
+	for _, st := range stats {
+		if !seen[st.TicketID] {
+			seen[st.TicketID] = true
+			ticketIDs = append(ticketIDs, string(st.TicketID))
+		}
+	}
+
+	tickets, err := dbTrx.GetTicketDAO().Search(ctx, entity.TicketQuery{IDs: ticketIDs})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.decorateStats(ctx, dbTrx, tickets, stats)
+}
+
+// decorateStats menandai tier aktif / berakhir berdasarkan stok tiket saat ini
+func (s priceTierService) decorateStats(ctx context.Context, dbTrx dao.DBTransaction, tickets entity.Tickets, stats entity.TierStatsList) (entity.TierStatsList, error) {
+	if err := dao.LoadPriceTiers(ctx, dbTrx.GetPriceTierDAO(), tickets); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	ticketMap := make(map[pubEntity.UUID]entity.Ticket)
+	activeMap := make(map[pubEntity.UUID]pubEntity.UUID)
+	for _, t := range tickets {
+		ticketMap[t.ID] = t
+		if active, _ := pricing.ActiveTier(t, now); active != nil {
+			activeMap[t.ID] = active.ID
+		}
+	}
+
+	result := make(entity.TierStatsList, 0, len(stats))
+	for _, st := range stats {
+		t, ok := ticketMap[st.TicketID]
+		if !ok {
+			continue
+		}
+		st.IsActive = activeMap[st.TicketID] == st.ID
+		st.IsEnded = pricing.TierEnded(st.PriceTier, t.BookedQty+t.SoldQty, now)
+		result = append(result, st)
+	}
+
+	return result, nil
+}
+
+// validateTiers memastikan tier group bisa rollover: batas quantity dan tanggal harus naik berurutan,
+// dan setiap tier selain yang terakhir punya batas akhir
+func validateTiers(ticket entity.Ticket, tiers entity.PriceTiers) error {
+	names := make(map[string]bool)
+	var lastQty int
+	var lastEndAt *time.Time
+
+	for i, tier := range tiers {
+		name := strings.TrimSpace(tier.Name)
+		if name == "" || len(name) > 100 {
+			return fmt.Errorf("%w: nama tier ke-%d wajib diisi (maksimal 100 karakter)", ErrInvalidTier, i+1)
+		}
+		if names[strings.ToLower(name)] {
+			return fmt.Errorf("%w: nama tier %q duplikat", ErrInvalidTier, name)
+		}
+		names[strings.ToLower(name)] = true
+
+		if tier.Price < 0 {
+			return fmt.Errorf("%w: harga tier %s tidak boleh negatif", ErrInvalidTier, name)
+		}
+
+		if tier.EndSoldQty != nil {
+			if *tier.EndSoldQty <= lastQty {
+				return fmt.Errorf("%w: end_sold_qty tier %s harus lebih besar dari tier sebelumnya", ErrInvalidTier, name)
+			}
+			if *tier.EndSoldQty > ticket.Total {
+				return fmt.Errorf("%w: end_sold_qty tier %s melebihi total tiket (%d)", ErrInvalidTier, name, ticket.Total)
+			}
+			lastQty = *tier.EndSoldQty
+		}
+
+		if tier.EndAt != nil {
+			if lastEndAt != nil && !tier.EndAt.After(*lastEndAt) {
+				return fmt.Errorf("%w: end_at tier %s harus setelah tier sebelumnya", ErrInvalidTier, name)
+			}
+			lastEndAt = tier.EndAt
+		}
+
+		if i < len(tiers)-1 && tier.EndSoldQty == nil && tier.EndAt == nil {
+			return fmt.Errorf("%w: tier %s harus punya end_sold_qty atau end_at agar tier berikutnya bisa dibuka", ErrInvalidTier, name)
+		}
+	}
+
+	return nil
+}
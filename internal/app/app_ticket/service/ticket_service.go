@@ -3,35 +3,58 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
 	"rakit-tiket-be/internal/app/app_ticket/dao"
+	"rakit-tiket-be/internal/pkg/pricing"
 	pubEntity "rakit-tiket-be/pkg/entity"
+	presaleEntity "rakit-tiket-be/pkg/entity/app_presale"
 	entity "rakit-tiket-be/pkg/entity/app_ticket"
 	"rakit-tiket-be/pkg/util"
 )
 
 type TicketService interface {
 	Search(ctx context.Context, query entity.TicketQuery) (entity.Tickets, error)
+	// SearchVisible menyembunyikan tiket presale yang tidak dibuka oleh credential pembeli
+	SearchVisible(ctx context.Context, query entity.TicketQuery, cred presaleEntity.Credential) (entity.Tickets, error)
 	Insert(ctx context.Context, tickets entity.Tickets) error
 	Update(ctx context.Context, tickets entity.Tickets) error
 	Delete(ctx context.Context, id pubEntity.UUID) error
 	SoftDelete(ctx context.Context, id pubEntity.UUID) error
 }
 
+type PresaleAccessResolver interface {
+	ResolveAccess(ctx context.Context, eventIDs []string, cred presaleEntity.Credential) (presaleEntity.Access, error)
+}
+
 type ticketService struct {
-	log   util.LogUtil
-	sqlDB *sql.DB
+	log             util.LogUtil
+	sqlDB           *sql.DB
+	presaleResolver PresaleAccessResolver
 }
 
-func MakeTicketService(log util.LogUtil, sqlDB *sql.DB) TicketService {
+func MakeTicketService(log util.LogUtil, sqlDB *sql.DB, presaleResolver PresaleAccessResolver) TicketService {
 	return ticketService{
-		log:   log,
-		sqlDB: sqlDB,
+		log:             log,
+		sqlDB:           sqlDB,
+		presaleResolver: presaleResolver,
+	}
+}
+
+// determineTicketStatus adalah status tiket di response. Tiket yang masih punya stok tetapi penjualannya
+// sudah berakhir (sale_end_time lewat atau semua tier berakhir) dilaporkan SALE_ENDED, bukan AVAILABLE.
+func determineTicketStatus(t entity.Ticket, now time.Time) entity.TicketStatus {
+	status := stockStatus(t.AvailableQty, t.BookedQty)
+	if status == entity.TicketStatusAvailable && errors.Is(pricing.CheckSaleWindow(t, now), pricing.ErrSaleEnded) {
+		return entity.TicketStatusSaleEnded
 	}
+	return status
 }
 
-func determineTicketStatus(available, booked int) entity.TicketStatus {
+// stockStatus adalah status yang disimpan di kolom tickets.status, hanya berdasarkan stok
+func stockStatus(available, booked int) entity.TicketStatus {
 	if available > 0 {
 		return entity.TicketStatusAvailable
 	}
@@ -43,6 +66,12 @@ func determineTicketStatus(available, booked int) entity.TicketStatus {
 	return entity.TicketStatusSold
 }
 
+// determineTierStatus mengisi tier aktif dan tier berikutnya (harga selanjutnya) untuk tiket dengan tier group.
+// Rollover terjadi otomatis: tier yang sudah mencapai end_sold_qty / end_at dilewati.
+func determineTierStatus(t *entity.Ticket, now time.Time) {
+	t.ActiveTier, t.NextTier = pricing.ActiveTier(*t, now)
+}
+
 func (s ticketService) Search(ctx context.Context, query entity.TicketQuery) (entity.Tickets, error) {
 	dbTrx := dao.NewTransactionTicket(ctx, s.log, s.sqlDB)
 	defer dbTrx.GetSqlTx().Rollback()
@@ -52,9 +81,33 @@ func (s ticketService) Search(ctx context.Context, query entity.TicketQuery) (en
 		return nil, err
 	}
 
+	if err := dao.LoadPriceTiers(ctx, dbTrx.GetPriceTierDAO(), tickets); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range tickets {
+		determineTierStatus(&tickets[i], now)
+		tickets[i].Status = determineTicketStatus(tickets[i], now)
+	}
+
 	return tickets, nil
 }
 
+func (s ticketService) SearchVisible(ctx context.Context, query entity.TicketQuery, cred presaleEntity.Credential) (entity.Tickets, error) {
+	tickets, err := s.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	access, err := s.presaleResolver.ResolveAccess(ctx, presaleEntity.PresaleEventIDs(tickets), cred)
+	if err != nil {
+		return nil, err
+	}
+
+	return access.Filter(tickets), nil
+}
+
 func (s ticketService) Insert(ctx context.Context, tickets entity.Tickets) error {
 	dbTrx := dao.NewTransactionTicket(ctx, s.log, s.sqlDB)
 	defer dbTrx.GetSqlTx().Rollback()
@@ -63,7 +116,7 @@ func (s ticketService) Insert(ctx context.Context, tickets entity.Tickets) error
 		t.AvailableQty = t.Total
 		t.BookedQty = 0
 		t.SoldQty = 0
-		t.Status = determineTicketStatus(t.AvailableQty, t.BookedQty)
+		t.Status = stockStatus(t.AvailableQty, t.BookedQty)
 		tickets[i] = t
 	}
 
@@ -112,7 +165,7 @@ func (s ticketService) Update(ctx context.Context, tickets entity.Tickets) error
 		newTicket.AvailableQty = newTicket.Total - lockedQty
 		newTicket.BookedQty = existingData.BookedQty
 		newTicket.SoldQty = existingData.SoldQty
-		newTicket.Status = determineTicketStatus(newTicket.AvailableQty, newTicket.BookedQty)
+		newTicket.Status = stockStatus(newTicket.AvailableQty, newTicket.BookedQty)
 
 		tickets[i] = newTicket
 	}
@@ -0,0 +1,86 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+
+	eventDao "rakit-tiket-be/internal/app/app_event/dao"
+	outboxDao "rakit-tiket-be/internal/app/app_outbox/dao"
+	presaleDao "rakit-tiket-be/internal/app/app_presale/dao"
+	ticketDao "rakit-tiket-be/internal/app/app_ticket/dao"
+	baseDao "rakit-tiket-be/internal/pkg/dao"
+	"rakit-tiket-be/pkg/util"
+)
+
+// OfferDAOs cukup untuk menawarkan stok yang dilepas ke antrean, dipenuhi juga oleh
+// transaksi modul lain (registrant, payment) agar penawaran ikut transaksi pelepasan stok
+type OfferDAOs interface {
+	baseDao.DBTransaction
+
+	GetTicketDAO() ticketDao.TicketDAO
+	GetPriceTierDAO() ticketDao.PriceTierDAO
+	GetEventDAO() eventDao.EventDAO
+	GetEmailOutboxDAO() outboxDao.EmailOutboxDAO
+	GetWaitlistDAO() WaitlistDAO
+}
+
+type DBTransaction interface {
+	OfferDAOs
+	presaleDao.AccessDAOs
+}
+
+type dbTransaction struct {
+	baseDao.DBTransaction
+
+	waitlistDAO          WaitlistDAO
+	ticketDAO            ticketDao.TicketDAO
+	priceTierDAO         ticketDao.PriceTierDAO
+	eventDAO             eventDao.EventDAO
+	emailOutboxDAO       outboxDao.EmailOutboxDAO
+	presaleAccessCodeDAO presaleDao.AccessCodeDAO
+	presaleAllowlistDAO  presaleDao.AllowlistDAO
+}
+
+func NewTransactionWaitlist(ctx context.Context, log util.LogUtil, sqlDB *sql.DB) DBTransaction {
+	dbTrx := &dbTransaction{
+		DBTransaction: baseDao.NewTransaction(ctx, sqlDB),
+	}
+
+	dbTrx.waitlistDAO = MakeWaitlistDAO(log, dbTrx)
+	dbTrx.ticketDAO = ticketDao.MakeTicketDAO(log, dbTrx)
+	dbTrx.priceTierDAO = ticketDao.MakePriceTierDAO(log, dbTrx)
+	dbTrx.eventDAO = eventDao.MakeEventDAO(log, dbTrx)
+	dbTrx.emailOutboxDAO = outboxDao.MakeEmailOutboxDAO(log, dbTrx)
+	dbTrx.presaleAccessCodeDAO = presaleDao.MakeAccessCodeDAO(log, dbTrx)
+	dbTrx.presaleAllowlistDAO = presaleDao.MakeAllowlistDAO(log, dbTrx)
+
+	return dbTrx
+}
+
+func (dbTrx *dbTransaction) GetWaitlistDAO() WaitlistDAO {
+	return dbTrx.waitlistDAO
+}
+
+func (dbTrx *dbTransaction) GetTicketDAO() ticketDao.TicketDAO {
+	return dbTrx.ticketDAO
+}
+
+func (dbTrx *dbTransaction) GetPriceTierDAO() ticketDao.PriceTierDAO {
+	return dbTrx.priceTierDAO
+}
+
+func (dbTrx *dbTransaction) GetEventDAO() eventDao.EventDAO {
+	return dbTrx.eventDAO
+}
+
+func (dbTrx *dbTransaction) GetEmailOutboxDAO() outboxDao.EmailOutboxDAO {
+	return dbTrx.emailOutboxDAO
+}
+
+func (dbTrx *dbTransaction) GetPresaleAccessCodeDAO() presaleDao.AccessCodeDAO {
+	return dbTrx.presaleAccessCodeDAO
+}
+
+func (dbTrx *dbTransaction) GetPresaleAllowlistDAO() presaleDao.AllowlistDAO {
+	return dbTrx.presaleAllowlistDAO
+}
@@ -0,0 +1,349 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	baseDao "rakit-tiket-be/internal/pkg/dao"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	entity "rakit-tiket-be/pkg/entity/app_waitlist"
+	"rakit-tiket-be/pkg/util"
+
+	"gitlab.com/threetopia/sqlgo/v2"
+	"go.uber.org/zap"
+)
+
+type WaitlistDAO interface {
+	// Search mengurutkan entry sesuai antrean (created_at terlama lebih dulu) dan mengisi Position
+	Search(ctx context.Context, query entity.EntryQuery) (entity.Entries, error)
+	SearchForUpdate(ctx context.Context, query entity.EntryQuery) (entity.Entries, error)
+	Insert(ctx context.Context, entries entity.Entries) error
+	Update(ctx context.Context, entries entity.Entries) error
+
+	// SearchOfferableTicketIDs mengembalikan tiket yang punya antrean WAITING dan stok tersedia
+	SearchOfferableTicketIDs(ctx context.Context) ([]string, error)
+	Stats(ctx context.Context, eventID *pubEntity.UUID) (entity.TicketStatsList, error)
+}
+
+type waitlistDAO struct {
+	log   util.LogUtil
+	dbTrx baseDao.DBTransaction
+}
+
+func MakeWaitlistDAO(log util.LogUtil, dbTrx baseDao.DBTransaction) WaitlistDAO {
+	return waitlistDAO{
+		log:   log,
+		dbTrx: dbTrx,
+	}
+}
+
+func (d waitlistDAO) Search(ctx context.Context, query entity.EntryQuery) (entity.Entries, error) {
+	sql := d.buildSearch(query, true)
+
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "waitlistDAO.Search",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	rows, err := d.dbTrx.GetSqlDB().QueryContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "waitlistDAO.Search", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	return d.scanRows(ctx, rows)
+}
+
+// SearchForUpdate mengunci entry; Position tidak dihitung (selalu 0)
+func (d waitlistDAO) SearchForUpdate(ctx context.Context, query entity.EntryQuery) (entity.Entries, error) {
+	sql := d.buildSearch(query, false)
+
+	sqlStr := sql.BuildSQL() + " FOR UPDATE"
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "waitlistDAO.SearchForUpdate",
+		zap.String("SQL", sqlStr),
+		zap.Any("Params", sqlParams),
+	)
+
+	rows, err := d.dbTrx.GetSqlTx().QueryContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "waitlistDAO.SearchForUpdate", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	return d.scanRows(ctx, rows)
+}
+
+func (d waitlistDAO) buildSearch(query entity.EntryQuery, withPosition bool) sqlgo.SQLGo {
+	position := "0"
+	if withPosition {
+		position = `CASE WHEN w.status = 'WAITING' THEN (
+            SELECT COUNT(*) + 1 FROM waitlist_entries q
+            WHERE q.ticket_id = w.ticket_id AND q.status = 'WAITING'
+            AND (q.created_at, q.id) < (w.created_at, w.id)
+        ) ELSE 0 END`
+	}
+
+	sqlSelect := sqlgo.NewSQLGoSelect().
+		SetSQLSelect("w.id", "id").
+		SetSQLSelect("w.event_id", "event_id").
+		SetSQLSelect("w.ticket_id", "ticket_id").
+		SetSQLSelect("w.email", "email").
+		SetSQLSelect("w.name", "name").
+		SetSQLSelect("w.quantity", "quantity").
+		SetSQLSelect("w.status", "status").
+		SetSQLSelect(position, "position").
+		SetSQLSelect("w.token_hash", "token_hash").
+		SetSQLSelect("w.claim_token_hash", "claim_token_hash").
+		SetSQLSelect("w.offered_at", "offered_at").
+		SetSQLSelect("w.offer_expires_at", "offer_expires_at").
+		SetSQLSelect("w.claimed_at", "claimed_at").
+		SetSQLSelect("w.order_id", "order_id").
+		SetSQLSelect("w.created_at", "created_at").
+		SetSQLSelect("w.updated_at", "updated_at")
+
+	sqlFrom := sqlgo.NewSQLGoFrom().
+		SetSQLFrom("waitlist_entries", "w")
+
+	sqlWhere := sqlgo.NewSQLGoWhere()
+
+	if len(query.IDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "w.id", "IN", query.IDs)
+	}
+	if len(query.EventIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "w.event_id", "IN", query.EventIDs)
+	}
+	if len(query.TicketIDs) > 0 {
+		sqlWhere.SetSQLWhere("AND", "w.ticket_id", "IN", query.TicketIDs)
+	}
+	if len(query.Emails) > 0 {
+		emails := make([]string, 0, len(query.Emails))
+		for _, e := range query.Emails {
+			emails = append(emails, NormalizeEmail(e))
+		}
+		sqlWhere.SetSQLWhere("AND", "w.email", "IN", emails)
+	}
+	if len(query.Statuses) > 0 {
+		statuses := make([]string, 0, len(query.Statuses))
+		for _, s := range query.Statuses {
+			statuses = append(statuses, strings.ToUpper(s))
+		}
+		sqlWhere.SetSQLWhere("AND", "w.status", "IN", statuses)
+	}
+	if len(query.TokenHashes) > 0 {
+		sqlWhere.SetSQLWhere("AND", "w.token_hash", "IN", query.TokenHashes)
+	}
+	if len(query.ClaimTokenHashes) > 0 {
+		sqlWhere.SetSQLWhere("AND", "w.claim_token_hash", "IN", query.ClaimTokenHashes)
+	}
+	if query.OfferExpiredBefore != nil {
+		sqlWhere.SetSQLWhere("AND", "w.offer_expires_at", "<=", *query.OfferExpiredBefore)
+	}
+
+	sqlOrder := sqlgo.NewSQLGoOrder()
+	sqlOrder.SetSQLOrder("w.created_at", "ASC")
+	sqlOrder.SetSQLOrder("w.id", "ASC")
+
+	return sqlgo.NewSQLGo().
+		SetSQLSchema("public").
+		SetSQLGoSelect(sqlSelect).
+		SetSQLGoFrom(sqlFrom).
+		SetSQLGoWhere(sqlWhere).
+		SetSQLGoOrder(sqlOrder)
+}
+
+func (d waitlistDAO) scanRows(ctx context.Context, rows *sql.Rows) (entity.Entries, error) {
+	var entries entity.Entries
+	for rows.Next() {
+		var entry entity.Entry
+
+		if err := rows.Scan(
+			&entry.ID, &entry.EventID, &entry.TicketID,
+			&entry.Email, &entry.Name, &entry.Quantity, &entry.Status, &entry.Position,
+			&entry.TokenHash, &entry.ClaimTokenHash,
+			&entry.OfferedAt, &entry.OfferExpiresAt, &entry.ClaimedAt, &entry.OrderID,
+			&entry.CreatedAt, &entry.UpdatedAt,
+		); err != nil {
+			d.log.Error(ctx, "waitlistDAO.scanRows", zap.Error(err))
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (d waitlistDAO) Insert(ctx context.Context, entries entity.Entries) error {
+	if len(entries) < 1 {
+		return fmt.Errorf("empty waitlist entry data")
+	}
+
+	sqlInsert := sqlgo.NewSQLGoInsert().
+		SetSQLInsert("waitlist_entries").
+		SetSQLInsertColumn(
+			"id", "event_id", "ticket_id",
+			"email", "name", "quantity", "status",
+			"token_hash", "created_at",
+		)
+
+	for i, entry := range entries {
+		entry.CreatedAt = time.Now()
+		entry.Email = NormalizeEmail(entry.Email)
+		if entry.ID == "" {
+			entry.ID = pubEntity.MakeUUID("WAITLIST", string(entry.TicketID), entry.Email, entry.CreatedAt.String())
+		}
+		if entry.Status == "" {
+			entry.Status = entity.EntryStatusWaiting
+		}
+
+		sqlInsert.SetSQLInsertValue(
+			entry.ID, entry.EventID, entry.TicketID,
+			entry.Email, entry.Name, entry.Quantity, entry.Status,
+			entry.TokenHash, entry.CreatedAt,
+		)
+		entries[i] = entry
+	}
+
+	sql := sqlgo.NewSQLGo().SetSQLSchema("public").SetSQLGoInsert(sqlInsert)
+	sqlStr := sql.BuildSQL()
+	sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+	d.log.Debug(ctx, "waitlistDAO.Insert", zap.String("SQL", sqlStr), zap.Int("Count", len(entries)))
+
+	_, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...)
+	if err != nil {
+		d.log.Error(ctx, "waitlistDAO.Insert", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Update menyimpan perubahan status dan data penawaran; identitas entry tidak berubah
+func (d waitlistDAO) Update(ctx context.Context, entries entity.Entries) error {
+	if len(entries) < 1 {
+		return fmt.Errorf("empty waitlist entry data")
+	}
+
+	for i, entry := range entries {
+		now := time.Now()
+		entry.UpdatedAt = &now
+
+		sql := sqlgo.NewSQLGo().
+			SetSQLSchema("public").
+			SetSQLUpdate("waitlist_entries").
+			SetSQLUpdateValue("status", entry.Status).
+			SetSQLUpdateValue("claim_token_hash", entry.ClaimTokenHash).
+			SetSQLUpdateValue("offered_at", entry.OfferedAt).
+			SetSQLUpdateValue("offer_expires_at", entry.OfferExpiresAt).
+			SetSQLUpdateValue("claimed_at", entry.ClaimedAt).
+			SetSQLUpdateValue("order_id", entry.OrderID).
+			SetSQLUpdateValue("updated_at", entry.UpdatedAt).
+			SetSQLWhere("AND", "id", "=", entry.ID)
+
+		sqlStr := sql.BuildSQL()
+		sqlParams := sql.GetSQLGoParameter().GetSQLParameter()
+
+		d.log.Debug(ctx, "waitlistDAO.Update", zap.String("ID", string(entry.ID)), zap.String("Status", string(entry.Status)))
+
+		if _, err := d.dbTrx.GetSqlTx().ExecContext(ctx, sqlStr, sqlParams...); err != nil {
+			d.log.Error(ctx, "waitlistDAO.Update", zap.Error(err))
+			return err
+		}
+		entries[i] = entry
+	}
+	return nil
+}
+
+func (d waitlistDAO) SearchOfferableTicketIDs(ctx context.Context) ([]string, error) {
+	query := `
+        SELECT DISTINCT w.ticket_id
+        FROM waitlist_entries w
+        JOIN tickets t ON t.id = w.ticket_id
+        WHERE w.status = 'WAITING'
+        AND t.available_qty > 0
+        AND t.deleted = false
+        ORDER BY w.ticket_id
+    `
+
+	rows, err := d.dbTrx.GetSqlTx().QueryContext(ctx, query)
+	if err != nil {
+		d.log.Error(ctx, "waitlistDAO.SearchOfferableTicketIDs", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ticketIDs []string
+	for rows.Next() {
+		var ticketID string
+		if err := rows.Scan(&ticketID); err != nil {
+			d.log.Error(ctx, "waitlistDAO.SearchOfferableTicketIDs.Scan", zap.Error(err))
+			return nil, err
+		}
+		ticketIDs = append(ticketIDs, ticketID)
+	}
+
+	return ticketIDs, nil
+}
+
+// Stats menghitung antrean per tiket; converted = entry CLAIMED yang order-nya sudah lunas
+func (d waitlistDAO) Stats(ctx context.Context, eventID *pubEntity.UUID) (entity.TicketStatsList, error) {
+	query := `
+        SELECT
+            w.ticket_id,
+            t.title,
+            COUNT(*) FILTER (WHERE w.status = 'WAITING'),
+            COALESCE(SUM(w.quantity) FILTER (WHERE w.status = 'WAITING'), 0),
+            COUNT(*) FILTER (WHERE w.status = 'OFFERED'),
+            COUNT(*) FILTER (WHERE w.status = 'CLAIMED'),
+            COUNT(*) FILTER (WHERE w.status = 'EXPIRED'),
+            COUNT(*) FILTER (WHERE w.status = 'CANCELLED'),
+            COUNT(*) FILTER (WHERE w.offered_at IS NOT NULL),
+            COUNT(*) FILTER (WHERE w.status = 'CLAIMED' AND o.payment_status = 'paid')
+        FROM waitlist_entries w
+        JOIN tickets t ON t.id = w.ticket_id
+        LEFT JOIN orders o ON o.id = w.order_id
+        WHERE ($1::uuid IS NULL OR w.event_id = $1::uuid)
+        GROUP BY w.ticket_id, t.title, t.order_priority
+        ORDER BY t.order_priority, t.title
+    `
+
+	d.log.Debug(ctx, "waitlistDAO.Stats", zap.Any("EventID", eventID))
+
+	rows, err := d.dbTrx.GetSqlDB().QueryContext(ctx, query, eventID)
+	if err != nil {
+		d.log.Error(ctx, "waitlistDAO.Stats", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats entity.TicketStatsList
+	for rows.Next() {
+		var s entity.TicketStats
+		if err := rows.Scan(
+			&s.TicketID, &s.TicketTitle,
+			&s.Waiting, &s.WaitingQty, &s.Offered, &s.Claimed, &s.Expired, &s.Cancelled,
+			&s.EverOffered, &s.Converted,
+		); err != nil {
+			d.log.Error(ctx, "waitlistDAO.Stats.Scan", zap.Error(err))
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+
+	return stats, nil
+}
+
+// NormalizeEmail menyeragamkan email antrean (huruf kecil, tanpa spasi)
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"rakit-tiket-be/internal/app/app_waitlist/service"
+	"rakit-tiket-be/internal/pkg/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+type HttpHandler interface {
+	RegisterRoute(g *echo.Group)
+}
+
+type httpHandler struct {
+	waitlistService service.WaitlistService
+
+	waitlistHandler WaitlistHandler
+}
+
+func MakeHttpAdapter(
+	waitlistService service.WaitlistService,
+	authMiddleware middleware.AuthMiddleware,
+) HttpHandler {
+	return httpHandler{
+		waitlistService: waitlistService,
+		waitlistHandler: MakeWaitlistHandler(waitlistService, authMiddleware),
+	}
+}
+
+func (h httpHandler) RegisterRoute(g *echo.Group) {
+	h.waitlistHandler.RegisterRouter(g)
+}
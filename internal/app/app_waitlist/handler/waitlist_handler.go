@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"rakit-tiket-be/internal/app/app_waitlist/service"
+	"rakit-tiket-be/internal/pkg/middleware"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	entity "rakit-tiket-be/pkg/entity/app_waitlist"
+
+	"github.com/labstack/echo/v4"
+)
+
+type WaitlistHandler interface {
+	RegisterRouter(g *echo.Group)
+}
+
+type waitlistHandler struct {
+	waitlistService service.WaitlistService
+	middleware      middleware.AuthMiddleware
+}
+
+func MakeWaitlistHandler(
+	waitlistService service.WaitlistService,
+	middleware middleware.AuthMiddleware,
+) waitlistHandler {
+	return waitlistHandler{
+		waitlistService: waitlistService,
+		middleware:      middleware,
+	}
+}
+
+func (h waitlistHandler) RegisterRouter(g *echo.Group) {
+	public := g.Group("/v1")
+	public.POST("/waitlist", h.join)
+	public.GET("/waitlist/claim/:claim_token", h.getOffer)
+	public.GET("/waitlist/:token", h.getEntry)
+	public.DELETE("/waitlist/:token", h.leave)
+
+	restricted := g.Group("/v1/admin")
+
+	restricted.Use(h.middleware.VerifyToken)
+	restricted.Use(h.middleware.RequireAdmin)
+
+	restricted.GET("/waitlist", h.searchEntries)
+	restricted.GET("/waitlist/stats", h.getStats)
+	restricted.DELETE("/waitlist/:id", h.cancelEntry)
+}
+
+func (h waitlistHandler) join(c echo.Context) error {
+	var req service.JoinRequest
+
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.waitlistService.Join(c.Request().Context(), req)
+	if err != nil {
+		return waitlistError(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    data,
+	})
+}
+
+func (h waitlistHandler) getEntry(c echo.Context) error {
+	data, err := h.waitlistService.GetEntry(c.Request().Context(), c.Param("token"))
+	if err != nil {
+		return waitlistError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+	})
+}
+
+func (h waitlistHandler) leave(c echo.Context) error {
+	if err := h.waitlistService.Leave(c.Request().Context(), c.Param("token")); err != nil {
+		return waitlistError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Berhasil keluar dari antrean waitlist",
+	})
+}
+
+func (h waitlistHandler) getOffer(c echo.Context) error {
+	data, err := h.waitlistService.GetOffer(c.Request().Context(), c.Param("claim_token"))
+	if err != nil {
+		return waitlistError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+	})
+}
+
+func (h waitlistHandler) searchEntries(c echo.Context) error {
+	var query entity.EntryQuery
+
+	if err := c.Bind(&query); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	data, err := h.waitlistService.SearchEntries(c.Request().Context(), query)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+		"count":   len(data),
+	})
+}
+
+func (h waitlistHandler) getStats(c echo.Context) error {
+	data, err := h.waitlistService.GetStats(c.Request().Context(), c.QueryParam("event_id"))
+	if err != nil {
+		return waitlistError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+		"count":   len(data),
+	})
+}
+
+func (h waitlistHandler) cancelEntry(c echo.Context) error {
+	if err := h.waitlistService.CancelEntry(c.Request().Context(), pubEntity.UUID(c.Param("id"))); err != nil {
+		return waitlistError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Waitlist entry cancelled successfully",
+	})
+}
+
+func waitlistError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrWaitlistNotFound), errors.Is(err, service.ErrTicketNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidWaitlist):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrStockAvailable), errors.Is(err, service.ErrAlreadyJoined), errors.Is(err, service.ErrEntryClosed):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrOfferInvalid):
+		return echo.NewHTTPError(http.StatusGone, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,650 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+
+	presaleSvc "rakit-tiket-be/internal/app/app_presale/service"
+	ticketDao "rakit-tiket-be/internal/app/app_ticket/dao"
+	"rakit-tiket-be/internal/app/app_waitlist/dao"
+	"rakit-tiket-be/internal/pkg/pricing"
+	pubEntity "rakit-tiket-be/pkg/entity"
+	eventEntity "rakit-tiket-be/pkg/entity/app_event"
+	outboxEntity "rakit-tiket-be/pkg/entity/app_outbox"
+	presaleEntity "rakit-tiket-be/pkg/entity/app_presale"
+	ticketEntity "rakit-tiket-be/pkg/entity/app_ticket"
+	entity "rakit-tiket-be/pkg/entity/app_waitlist"
+	"rakit-tiket-be/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrWaitlistNotFound = errors.New("antrean waitlist tidak ditemukan")
+	ErrTicketNotFound   = errors.New("tiket tidak ditemukan")
+	ErrInvalidWaitlist  = errors.New("data waitlist tidak valid")
+	ErrStockAvailable   = errors.New("stok tiket masih tersedia, silakan beli langsung")
+	ErrAlreadyJoined    = errors.New("email sudah ada di antrean tiket ini")
+	ErrEntryClosed      = errors.New("antrean waitlist sudah selesai")
+
+	// ErrOfferInvalid dipakai Register saat waitlist_token tidak berlaku untuk registrasi tersebut
+	ErrOfferInvalid = errors.New("penawaran waitlist tidak berlaku atau sudah kedaluwarsa")
+)
+
+const (
+	// OfferTTL adalah lama stok ditahan untuk entry yang ditawari sebelum dilepas ke antrean berikutnya
+	OfferTTL = 30 * time.Minute
+
+	entryTokenPrefix = "wl_"
+	claimTokenPrefix = "wc_"
+	// waitlistTokenBytes menghasilkan 256 bit entropi untuk token antrean dan token klaim
+	waitlistTokenBytes = 32
+)
+
+type JoinRequest struct {
+	TicketID   pubEntity.UUID `json:"ticket_id"`
+	Email      string         `json:"email"`
+	Name       string         `json:"name"`
+	Quantity   int            `json:"quantity"`
+	AccessCode string         `json:"access_code"` // wajib untuk tiket presale jika email tidak ada di allowlist
+}
+
+type JoinResult struct {
+	// Token hanya dikembalikan sekali, dipakai untuk cek posisi dan keluar antrean
+	Token string       `json:"token"`
+	Entry entity.Entry `json:"entry"`
+}
+
+type WaitlistService interface {
+	Join(ctx context.Context, req JoinRequest) (*JoinResult, error)
+	GetEntry(ctx context.Context, token string) (*entity.Entry, error)
+	Leave(ctx context.Context, token string) error
+	GetOffer(ctx context.Context, claimToken string) (*entity.Offer, error)
+
+	SearchEntries(ctx context.Context, query entity.EntryQuery) (entity.Entries, error)
+	GetStats(ctx context.Context, eventID string) (entity.TicketStatsList, error)
+	CancelEntry(ctx context.Context, id pubEntity.UUID) error
+
+	// ProcessOffers melepas penawaran yang kedaluwarsa dan menawarkan stok tersedia ke antrean (cron)
+	ProcessOffers(ctx context.Context) (int64, error)
+}
+
+type waitlistService struct {
+	log   util.LogUtil
+	sqlDB *sql.DB
+}
+
+func MakeWaitlistService(log util.LogUtil, sqlDB *sql.DB) WaitlistService {
+	return waitlistService{
+		log:   log,
+		sqlDB: sqlDB,
+	}
+}
+
+// Join memasukkan pembeli ke antrean tiket yang stoknya tidak cukup untuk quantity yang diminta
+func (s waitlistService) Join(ctx context.Context, req JoinRequest) (*JoinResult, error) {
+	email := dao.NormalizeEmail(req.Email)
+	name := strings.TrimSpace(req.Name)
+
+	if !util.IsValidUUID(string(req.TicketID)) {
+		return nil, ErrTicketNotFound
+	}
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return nil, fmt.Errorf("%w: email tidak valid", ErrInvalidWaitlist)
+	}
+	if name == "" || len(name) > 255 {
+		return nil, fmt.Errorf("%w: name wajib diisi, maksimal 255 karakter", ErrInvalidWaitlist)
+	}
+	if req.Quantity < 1 {
+		return nil, fmt.Errorf("%w: quantity minimal 1", ErrInvalidWaitlist)
+	}
+
+	dbTrx := dao.NewTransactionWaitlist(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	tickets, err := dbTrx.GetTicketDAO().Search(ctx, ticketEntity.TicketQuery{IDs: []string{string(req.TicketID)}})
+	if err != nil {
+		return nil, err
+	}
+	if len(tickets) == 0 {
+		return nil, ErrTicketNotFound
+	}
+	if err := ticketDao.LoadPriceTiers(ctx, dbTrx.GetPriceTierDAO(), tickets); err != nil {
+		return nil, err
+	}
+	ticket := tickets[0]
+
+	// Tiket presale yang terkunci diperlakukan sama seperti di listing: tidak ditemukan
+	if ticket.IsPresale {
+		access, err := presaleSvc.ResolveAccess(ctx, dbTrx, presaleEntity.PresaleEventIDs(tickets), presaleEntity.Credential{
+			AccessCode: req.AccessCode,
+			Email:      email,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve presale access: %v", err)
+		}
+		if !access.Allows(ticket) {
+			return nil, ErrTicketNotFound
+		}
+	}
+
+	now := time.Now()
+	if err := pricing.CheckSaleWindow(ticket, now); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWaitlist, err)
+	}
+
+	events, err := dbTrx.GetEventDAO().Search(ctx, eventEntity.EventQuery{IDs: []string{string(ticket.EventID)}})
+	if err != nil {
+		return nil, err
+	}
+	if len(events) > 0 && req.Quantity > events[0].MaxTicketPerTx {
+		return nil, fmt.Errorf("%w: maksimal %d tiket per registrasi untuk event ini", ErrInvalidWaitlist, events[0].MaxTicketPerTx)
+	}
+	if req.Quantity > ticket.Total {
+		return nil, fmt.Errorf("%w: quantity melebihi total tiket", ErrInvalidWaitlist)
+	}
+
+	if ticket.AvailableQty >= req.Quantity {
+		return nil, ErrStockAvailable
+	}
+
+	existing, err := dbTrx.GetWaitlistDAO().Search(ctx, entity.EntryQuery{
+		TicketIDs: []string{string(ticket.ID)},
+		Emails:    []string{email},
+		Statuses:  []string{string(entity.EntryStatusWaiting), string(entity.EntryStatusOffered)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, ErrAlreadyJoined
+	}
+
+	token, tokenHash, err := makeWaitlistToken(entryTokenPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := entity.Entries{{
+		EventID:   ticket.EventID,
+		TicketID:  ticket.ID,
+		Email:     email,
+		Name:      name,
+		Quantity:  req.Quantity,
+		Status:    entity.EntryStatusWaiting,
+		TokenHash: tokenHash,
+	}}
+	if err := dbTrx.GetWaitlistDAO().Insert(ctx, entries); err != nil {
+		return nil, err
+	}
+
+	if err := dbTrx.GetSqlTx().Commit(); err != nil {
+		return nil, err
+	}
+
+	result := &JoinResult{Token: token, Entry: entries[0]}
+
+	// Posisi dihitung setelah commit; kegagalan baca tidak membatalkan pendaftaran antrean
+	joined, err := dbTrx.GetWaitlistDAO().Search(ctx, entity.EntryQuery{IDs: []string{string(entries[0].ID)}})
+	if err != nil {
+		s.log.Error(ctx, "Join.Search", zap.Error(err))
+	} else if len(joined) > 0 {
+		result.Entry = joined[0]
+	}
+
+	return result, nil
+}
+
+func (s waitlistService) GetEntry(ctx context.Context, token string) (*entity.Entry, error) {
+	dbTrx := dao.NewTransactionWaitlist(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	return findByToken(ctx, dbTrx, token)
+}
+
+// Leave mengeluarkan entry dari antrean; stok yang sedang ditahan untuknya ditawarkan ke antrean berikutnya
+func (s waitlistService) Leave(ctx context.Context, token string) error {
+	dbTrx := dao.NewTransactionWaitlist(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	entry, err := findByToken(ctx, dbTrx, token)
+	if err != nil {
+		return err
+	}
+
+	if err := cancelEntry(ctx, dbTrx, *entry, time.Now()); err != nil {
+		return err
+	}
+
+	return dbTrx.GetSqlTx().Commit()
+}
+
+func (s waitlistService) GetOffer(ctx context.Context, claimToken string) (*entity.Offer, error) {
+	if !strings.HasPrefix(claimToken, claimTokenPrefix) {
+		return nil, ErrOfferInvalid
+	}
+
+	dbTrx := dao.NewTransactionWaitlist(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	entries, err := dbTrx.GetWaitlistDAO().Search(ctx, entity.EntryQuery{
+		ClaimTokenHashes: []string{util.MakeSHA256(claimToken)},
+		Statuses:         []string{string(entity.EntryStatusOffered)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 || !offerActive(entries[0], time.Now()) {
+		return nil, ErrOfferInvalid
+	}
+	entry := entries[0]
+
+	offer := &entity.Offer{
+		EntryID:        entry.ID,
+		EventID:        entry.EventID,
+		EventName:      eventName(ctx, dbTrx, entry.EventID),
+		TicketID:       entry.TicketID,
+		Email:          entry.Email,
+		Name:           entry.Name,
+		Quantity:       entry.Quantity,
+		OfferExpiresAt: *entry.OfferExpiresAt,
+	}
+
+	tickets, err := dbTrx.GetTicketDAO().Search(ctx, ticketEntity.TicketQuery{IDs: []string{string(entry.TicketID)}})
+	if err != nil {
+		return nil, err
+	}
+	if len(tickets) > 0 {
+		offer.TicketTitle = tickets[0].Title
+	}
+
+	return offer, nil
+}
+
+func (s waitlistService) SearchEntries(ctx context.Context, query entity.EntryQuery) (entity.Entries, error) {
+	dbTrx := dao.NewTransactionWaitlist(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	entries, err := dbTrx.GetWaitlistDAO().Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if entries == nil {
+		entries = entity.Entries{}
+	}
+	return entries, nil
+}
+
+// GetStats menghitung antrean dan konversi per tiket, eventID kosong berarti semua event
+func (s waitlistService) GetStats(ctx context.Context, eventID string) (entity.TicketStatsList, error) {
+	var eventFilter *pubEntity.UUID
+	if eventID != "" {
+		if !util.IsValidUUID(eventID) {
+			return nil, fmt.Errorf("%w: event_id tidak valid", ErrInvalidWaitlist)
+		}
+		id := pubEntity.UUID(eventID)
+		eventFilter = &id
+	}
+
+	dbTrx := dao.NewTransactionWaitlist(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	stats, err := dbTrx.GetWaitlistDAO().Stats(ctx, eventFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range stats {
+		stats[i].ClaimRate = percentage(stats[i].Claimed, stats[i].EverOffered)
+		stats[i].ConversionRate = percentage(stats[i].Converted, stats[i].EverOffered)
+	}
+
+	if stats == nil {
+		stats = entity.TicketStatsList{}
+	}
+	return stats, nil
+}
+
+func (s waitlistService) CancelEntry(ctx context.Context, id pubEntity.UUID) error {
+	if !util.IsValidUUID(string(id)) {
+		return ErrWaitlistNotFound
+	}
+
+	dbTrx := dao.NewTransactionWaitlist(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	entries, err := dbTrx.GetWaitlistDAO().Search(ctx, entity.EntryQuery{IDs: []string{string(id)}})
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return ErrWaitlistNotFound
+	}
+
+	if err := cancelEntry(ctx, dbTrx, entries[0], time.Now()); err != nil {
+		return err
+	}
+
+	return dbTrx.GetSqlTx().Commit()
+}
+
+func (s waitlistService) ProcessOffers(ctx context.Context) (int64, error) {
+	dbTrx := dao.NewTransactionWaitlist(ctx, s.log, s.sqlDB)
+	defer dbTrx.GetSqlTx().Rollback()
+
+	now := time.Now()
+	due, err := dbTrx.GetWaitlistDAO().Search(ctx, entity.EntryQuery{
+		Statuses:           []string{string(entity.EntryStatusOffered)},
+		OfferExpiredBefore: &now,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to search expired waitlist offers: %w", err)
+	}
+
+	var ticketIDs, dueIDs []string
+	for _, e := range due {
+		ticketIDs = append(ticketIDs, string(e.TicketID))
+		dueIDs = append(dueIDs, string(e.ID))
+	}
+
+	var expired int64
+	if len(dueIDs) > 0 {
+		// Tiket dikunci lebih dulu, urutan yang sama dengan Register saat klaim
+		if err := lockTickets(ctx, dbTrx, ticketIDs); err != nil {
+			return 0, err
+		}
+
+		entries, err := dbTrx.GetWaitlistDAO().SearchForUpdate(ctx, entity.EntryQuery{
+			IDs:                dueIDs,
+			Statuses:           []string{string(entity.EntryStatusOffered)},
+			OfferExpiredBefore: &now,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to lock expired waitlist offers: %w", err)
+		}
+
+		for _, e := range entries {
+			if err := dbTrx.GetTicketDAO().ReleaseBooked(ctx, e.TicketID, e.Quantity); err != nil {
+				return 0, fmt.Errorf("gagal ReleaseBooked tiket %s: %v", e.TicketID, err)
+			}
+			e.Status = entity.EntryStatusExpired
+			if err := dbTrx.GetWaitlistDAO().Update(ctx, entity.Entries{e}); err != nil {
+				return 0, err
+			}
+			expired++
+		}
+	}
+
+	// Stok yang tersedia tanpa lewat pelepasan (mis. admin menambah total) ikut ditawarkan
+	offerable, err := dbTrx.GetWaitlistDAO().SearchOfferableTicketIDs(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to search offerable tickets: %w", err)
+	}
+
+	offered, err := OfferReleasedStock(ctx, dbTrx, append(ticketIDs, offerable...), now)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := dbTrx.GetSqlTx().Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit waitlist offers: %w", err)
+	}
+
+	if expired > 0 || offered > 0 {
+		s.log.Info(ctx, "Processed waitlist offers", zap.Int64("expired", expired), zap.Int64("offered", offered))
+	}
+	return expired + offered, nil
+}
+
+// OfferReleasedStock menawarkan stok tersedia tiket ke antrean WAITING secara FIFO. Entry yang
+// quantity-nya melebihi sisa stok dilewati tanpa kehilangan posisi. Stok untuk entry yang ditawari
+// langsung di-booking sehingga tidak bisa direbut pembeli lain selama OfferTTL.
+// Dipanggil di transaksi yang melepas stok (order expired / ditolak / refund) agar penawaran ikut commit.
+func OfferReleasedStock(ctx context.Context, daos dao.OfferDAOs, ticketIDs []string, now time.Time) (int64, error) {
+	var offered int64
+
+	for _, ticketID := range sortedUnique(ticketIDs) {
+		tickets, err := daos.GetTicketDAO().SearchForUpdate(ctx, ticketEntity.TicketQuery{IDs: []string{ticketID}})
+		if err != nil {
+			return offered, fmt.Errorf("failed to lock ticket %s: %v", ticketID, err)
+		}
+		if len(tickets) == 0 {
+			continue
+		}
+		if err := ticketDao.LoadPriceTiers(ctx, daos.GetPriceTierDAO(), tickets); err != nil {
+			return offered, err
+		}
+		ticket := tickets[0]
+
+		// Tier group yang semua tier-nya sudah berakhir juga dianggap di luar window penjualan
+		if ticket.AvailableQty <= 0 || pricing.CheckSaleWindow(ticket, now) != nil {
+			continue
+		}
+
+		entries, err := daos.GetWaitlistDAO().SearchForUpdate(ctx, entity.EntryQuery{
+			TicketIDs: []string{ticketID},
+			Statuses:  []string{string(entity.EntryStatusWaiting)},
+		})
+		if err != nil {
+			return offered, fmt.Errorf("failed to lock waitlist: %v", err)
+		}
+		if len(entries) == 0 {
+			continue
+		}
+
+		name := eventName(ctx, daos, ticket.EventID)
+		available := ticket.AvailableQty
+		for _, e := range entries {
+			if available <= 0 {
+				break
+			}
+			if e.Quantity > available {
+				continue
+			}
+
+			// Tiket dihapus / berubah di luar kunci: lewati tiket ini tanpa menggagalkan pelepasan stok
+			if err := daos.GetTicketDAO().BookStock(ctx, ticket.ID, e.Quantity); err != nil {
+				break
+			}
+			if err := offerEntry(ctx, daos, e, ticket, name, now); err != nil {
+				return offered, err
+			}
+			available -= e.Quantity
+			offered++
+		}
+	}
+
+	return offered, nil
+}
+
+// LockOffer mengunci penawaran milik claimToken untuk diklaim Register. Penawaran hanya bisa dipakai
+// oleh email yang ditawari dan sebelum offer_expires_at.
+func LockOffer(ctx context.Context, daos dao.OfferDAOs, claimToken, email string, now time.Time) (*entity.Entry, error) {
+	claimToken = strings.TrimSpace(claimToken)
+	if !strings.HasPrefix(claimToken, claimTokenPrefix) {
+		return nil, ErrOfferInvalid
+	}
+
+	entries, err := daos.GetWaitlistDAO().SearchForUpdate(ctx, entity.EntryQuery{
+		ClaimTokenHashes: []string{util.MakeSHA256(claimToken)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 || entries[0].Status != entity.EntryStatusOffered || !offerActive(entries[0], now) {
+		return nil, ErrOfferInvalid
+	}
+	if entries[0].Email != dao.NormalizeEmail(email) {
+		return nil, fmt.Errorf("%w: email registrant berbeda dengan email antrean", ErrOfferInvalid)
+	}
+
+	return &entries[0], nil
+}
+
+// ClaimOffer menandai penawaran terpakai oleh order. Stok yang ditahan tapi tidak dipakai
+// (usedQty < quantity) dilepas dan langsung ditawarkan ke antrean berikutnya.
+func ClaimOffer(ctx context.Context, daos dao.OfferDAOs, entry entity.Entry, orderID pubEntity.UUID, usedQty int, now time.Time) error {
+	entry.Status = entity.EntryStatusClaimed
+	entry.ClaimedAt = &now
+	entry.OrderID = &orderID
+	if err := daos.GetWaitlistDAO().Update(ctx, entity.Entries{entry}); err != nil {
+		return err
+	}
+
+	if unused := entry.Quantity - usedQty; unused > 0 {
+		if err := daos.GetTicketDAO().ReleaseBooked(ctx, entry.TicketID, unused); err != nil {
+			return fmt.Errorf("gagal ReleaseBooked tiket %s: %v", entry.TicketID, err)
+		}
+		if _, err := OfferReleasedStock(ctx, daos, []string{string(entry.TicketID)}, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// offerEntry mengirim penawaran untuk entry yang stoknya sudah di-booking
+func offerEntry(ctx context.Context, daos dao.OfferDAOs, entry entity.Entry, ticket ticketEntity.Ticket, eventName string, now time.Time) error {
+	claimToken, claimTokenHash, err := makeWaitlistToken(claimTokenPrefix)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := now.Add(OfferTTL)
+	entry.Status = entity.EntryStatusOffered
+	entry.ClaimTokenHash = &claimTokenHash
+	entry.OfferedAt = &now
+	entry.OfferExpiresAt = &expiresAt
+	if err := daos.GetWaitlistDAO().Update(ctx, entity.Entries{entry}); err != nil {
+		return err
+	}
+
+	// Token klaim hanya ada di payload outbox; link dibuat worker saat email dikirim
+	if err := daos.GetEmailOutboxDAO().Enqueue(ctx, outboxEntity.EmailTypeWaitlistOffer, entry.Email, nil, outboxEntity.EmailPayload{
+		EventName:   eventName,
+		OwnerName:   entry.Name,
+		TicketTitle: ticket.Title,
+		Quantity:    entry.Quantity,
+		ClaimToken:  claimToken,
+		ExpiresAt:   expiresAt.Format("02 Jan 2006 15:04"),
+	}); err != nil {
+		return fmt.Errorf("gagal mengantrikan email penawaran waitlist: %v", err)
+	}
+
+	return nil
+}
+
+// cancelEntry mengunci tiket lalu entry (urutan yang sama dengan Register) sebelum membatalkan
+func cancelEntry(ctx context.Context, dbTrx dao.DBTransaction, entry entity.Entry, now time.Time) error {
+	if err := lockTickets(ctx, dbTrx, []string{string(entry.TicketID)}); err != nil {
+		return err
+	}
+
+	entries, err := dbTrx.GetWaitlistDAO().SearchForUpdate(ctx, entity.EntryQuery{IDs: []string{string(entry.ID)}})
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return ErrWaitlistNotFound
+	}
+	entry = entries[0]
+
+	wasOffered := entry.Status == entity.EntryStatusOffered
+	if entry.Status != entity.EntryStatusWaiting && !wasOffered {
+		return fmt.Errorf("%w: status %s", ErrEntryClosed, entry.Status)
+	}
+
+	if wasOffered {
+		if err := dbTrx.GetTicketDAO().ReleaseBooked(ctx, entry.TicketID, entry.Quantity); err != nil {
+			return fmt.Errorf("gagal ReleaseBooked tiket %s: %v", entry.TicketID, err)
+		}
+	}
+
+	entry.Status = entity.EntryStatusCancelled
+	if err := dbTrx.GetWaitlistDAO().Update(ctx, entity.Entries{entry}); err != nil {
+		return err
+	}
+
+	if wasOffered {
+		if _, err := OfferReleasedStock(ctx, dbTrx, []string{string(entry.TicketID)}, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func findByToken(ctx context.Context, dbTrx dao.DBTransaction, token string) (*entity.Entry, error) {
+	if !strings.HasPrefix(token, entryTokenPrefix) {
+		return nil, ErrWaitlistNotFound
+	}
+
+	entries, err := dbTrx.GetWaitlistDAO().Search(ctx, entity.EntryQuery{
+		TokenHashes: []string{util.MakeSHA256(token)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrWaitlistNotFound
+	}
+	return &entries[0], nil
+}
+
+// lockTickets mengunci tiket satu per satu dengan urutan ID agar tidak deadlock antar transaksi
+func lockTickets(ctx context.Context, daos dao.OfferDAOs, ticketIDs []string) error {
+	for _, id := range sortedUnique(ticketIDs) {
+		if _, err := daos.GetTicketDAO().SearchForUpdate(ctx, ticketEntity.TicketQuery{IDs: []string{id}}); err != nil {
+			return fmt.Errorf("failed to lock ticket %s: %v", id, err)
+		}
+	}
+	return nil
+}
+
+func eventName(ctx context.Context, daos dao.OfferDAOs, eventID pubEntity.UUID) string {
+	events, err := daos.GetEventDAO().Search(ctx, eventEntity.EventQuery{IDs: []string{string(eventID)}})
+	if err != nil || len(events) == 0 {
+		return "Event"
+	}
+	return events[0].Name
+}
+
+func offerActive(entry entity.Entry, now time.Time) bool {
+	return entry.OfferExpiresAt != nil && now.Before(*entry.OfferExpiresAt)
+}
+
+func makeWaitlistToken(prefix string) (string, string, error) {
+	code, err := util.MakeRandomCode(waitlistTokenBytes)
+	if err != nil {
+		return "", "", fmt.Errorf("gagal membuat token waitlist: %v", err)
+	}
+	token := prefix + code
+	return token, util.MakeSHA256(token), nil
+}
+
+func percentage(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*1000) / 10
+}
+
+func sortedUnique(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	sort.Strings(result)
+	return result
+}
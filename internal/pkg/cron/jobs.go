@@ -0,0 +1,67 @@
+package cron
+
+import (
+	"context"
+
+	hypeService "rakit-tiket-be/internal/app/app_hype/service"
+	orderService "rakit-tiket-be/internal/app/app_order/service"
+	outboxService "rakit-tiket-be/internal/app/app_outbox/service"
+	waitlistService "rakit-tiket-be/internal/app/app_waitlist/service"
+)
+
+// JobSpecs berisi cron spec (dengan detik) untuk setiap job, isi DisabledSpec untuk menonaktifkan
+type JobSpecs struct {
+	ExpireOrders    string
+	ReconcileOrders string
+	PaymentReminder string
+	FlashSaleStart  string
+	FlashSaleEnd    string
+	EmailOutbox     string
+	WaitlistOffers  string
+}
+
+func DefaultJobs(specs JobSpecs, orderService orderService.OrderService, hypeService hypeService.HypeService, outboxService outboxService.OutboxService, waitlistService waitlistService.WaitlistService) []Job {
+	return []Job{
+		{
+			Name: "expire_orders",
+			Spec: specs.ExpireOrders,
+			Run:  orderService.UpdateExpiredOrders,
+		},
+		{
+			Name: "reconcile_orders",
+			Spec: specs.ReconcileOrders,
+			Run: func(ctx context.Context) (int64, error) {
+				report, err := orderService.ReconcileOrders(ctx)
+				if err != nil {
+					return 0, err
+				}
+				return int64(report.Applied), nil
+			},
+		},
+		{
+			Name: "payment_reminder",
+			Spec: specs.PaymentReminder,
+			Run:  orderService.SendPaymentReminders,
+		},
+		{
+			Name: "flash_sale_start",
+			Spec: specs.FlashSaleStart,
+			Run:  hypeService.StartScheduledFlashSales,
+		},
+		{
+			Name: "flash_sale_end",
+			Spec: specs.FlashSaleEnd,
+			Run:  hypeService.EndExpiredFlashSales,
+		},
+		{
+			Name: "email_outbox",
+			Spec: specs.EmailOutbox,
+			Run:  outboxService.ProcessDue,
+		},
+		{
+			Name: "waitlist_offers",
+			Spec: specs.WaitlistOffers,
+			Run:  waitlistService.ProcessOffers,
+		},
+	}
+}
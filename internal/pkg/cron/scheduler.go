@@ -2,57 +2,150 @@ package cron
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"hash/fnv"
+	"os"
+	"time"
 
-	"rakit-tiket-be/internal/app/app_order/service"
+	jobService "rakit-tiket-be/internal/app/app_job/service"
 	"rakit-tiket-be/pkg/util"
 
 	"github.com/robfig/cron/v3"
 	"go.uber.org/zap"
 )
 
+const defaultJobTimeout = 5 * time.Minute
+
+// DisabledSpec dipakai di config untuk mematikan job, karena env kosong akan jatuh ke default
+const DisabledSpec = "-"
+
+// JobFunc menjalankan satu siklus job dan mengembalikan jumlah baris yang terdampak
+type JobFunc func(ctx context.Context) (int64, error)
+
+type Job struct {
+	Name    string
+	Spec    string
+	Timeout time.Duration
+	Run     JobFunc
+}
+
 type Scheduler struct {
-	cron         *cron.Cron
-	orderService service.OrderService
-	log          util.LogUtil
+	cron       *cron.Cron
+	log        util.LogUtil
+	sqlDB      *sql.DB
+	jobService jobService.JobService
+	instance   string
+	jobs       []string
 }
 
-func NewScheduler(orderService service.OrderService, log util.LogUtil) *Scheduler {
+func NewScheduler(log util.LogUtil, sqlDB *sql.DB, jobService jobService.JobService) *Scheduler {
+	instance, _ := os.Hostname()
+
 	return &Scheduler{
-		cron:         cron.New(cron.WithSeconds()),
-		orderService: orderService,
-		log:          log,
+		cron:       cron.New(cron.WithSeconds()),
+		log:        log,
+		sqlDB:      sqlDB,
+		jobService: jobService,
+		instance:   instance,
 	}
 }
 
-func (s *Scheduler) Start() error {
-	_, err := s.cron.AddFunc("0 */5 * * * *", s.cleanupExpiredOrders)
-	if err != nil {
-		return fmt.Errorf("failed to add expired orders cleanup cron: %w", err)
+// Register menambahkan job ke scheduler. Spec kosong atau DisabledSpec berarti job dinonaktifkan.
+func (s *Scheduler) Register(job Job) error {
+	if job.Spec == "" || job.Spec == DisabledSpec {
+		s.log.Info(context.Background(), "Cron job disabled", zap.String("job", job.Name))
+		return nil
 	}
 
-	s.cron.Start()
-	//s.log.Info(context.Background(), "Cron scheduler started", zap.Strings("jobs", []string{"cleanupExpiredOrders (every 5 minutes)"}))
+	if job.Timeout <= 0 {
+		job.Timeout = defaultJobTimeout
+	}
+
+	if _, err := s.cron.AddFunc(job.Spec, func() { s.runJob(job) }); err != nil {
+		return fmt.Errorf("failed to register cron job %s: %w", job.Name, err)
+	}
+
+	s.jobs = append(s.jobs, job.Name)
 	return nil
 }
 
-func (s *Scheduler) Stop() {
-	ctx := s.cron.Stop()
-	<-ctx.Done()
-	//s.log.Info(context.Background(), "Cron scheduler stopped")
+func (s *Scheduler) Start() {
+	s.cron.Start()
+	s.log.Info(context.Background(), "Cron scheduler started", zap.Strings("jobs", s.jobs), zap.String("instance", s.instance))
+}
+
+// Stop menghentikan jadwal baru dan menunggu job yang sedang berjalan sampai ctx habis
+func (s *Scheduler) Stop(ctx context.Context) error {
+	stopped := s.cron.Stop()
+
+	select {
+	case <-stopped.Done():
+		s.log.Info(ctx, "Cron scheduler stopped")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("cron jobs still running: %w", ctx.Err())
+	}
 }
 
-func (s *Scheduler) cleanupExpiredOrders() {
-	ctx := context.Background()
-	//s.log.Info(ctx, "Running expired orders cleanup job")
+// runJob hanya dijalankan oleh replica yang berhasil mengambil advisory lock untuk job ini
+func (s *Scheduler) runJob(job Job) {
+	ctx, cancel := context.WithTimeout(context.Background(), job.Timeout)
+	defer cancel()
 
-	count, err := s.orderService.UpdateExpiredOrders(ctx)
+	conn, err := s.sqlDB.Conn(ctx)
 	if err != nil {
-		s.log.Error(ctx, "Failed to cleanup expired orders", zap.Error(err))
+		s.log.Error(ctx, "Failed to get connection for job lock", zap.String("job", job.Name), zap.Error(err))
 		return
 	}
+	defer conn.Close()
 
-	if count > 0 {
-		//s.log.Info(ctx, "Expired orders cleanup completed", zap.Int64("updated", count))
+	lockKey := jobLockKey(job.Name)
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", lockKey).Scan(&locked); err != nil {
+		s.log.Error(ctx, "Failed to acquire job lock", zap.String("job", job.Name), zap.Error(err))
+		return
 	}
+	if !locked {
+		s.log.Debug(ctx, "Job is running on another instance, skipped", zap.String("job", job.Name))
+		return
+	}
+	defer func() {
+		// Unlock tetap dijalankan walau context job sudah timeout
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey); err != nil {
+			s.log.Error(context.Background(), "Failed to release job lock", zap.String("job", job.Name), zap.Error(err))
+		}
+	}()
+
+	jobRun, err := s.jobService.StartRun(ctx, job.Name, s.instance)
+	if err != nil {
+		s.log.Error(ctx, "Failed to record job start", zap.String("job", job.Name), zap.Error(err))
+		return
+	}
+
+	affected, runErr := s.execute(ctx, job)
+	if runErr != nil {
+		s.log.Error(ctx, "Cron job failed", zap.String("job", job.Name), zap.Error(runErr))
+	}
+
+	if err := s.jobService.FinishRun(context.Background(), *jobRun, affected, runErr); err != nil {
+		s.log.Error(ctx, "Failed to record job finish", zap.String("job", job.Name), zap.Error(err))
+	}
+}
+
+func (s *Scheduler) execute(ctx context.Context, job Job) (affected int64, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return job.Run(ctx)
+}
+
+func jobLockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("cron:" + name))
+	return int64(h.Sum64())
 }
@@ -17,6 +17,9 @@ type EmailService interface {
 	SendPaymentRejectedEmail(ctx context.Context, toEmail, orderNumber, eventName, ownerName, reason string) error
 	SendPaymentCancelledEmail(ctx context.Context, toEmail, orderNumber, eventName, ownerName, reason string) error
 	SendOrderExpiredEmail(ctx context.Context, toEmail, orderNumber, eventName, ownerName string) error
+	SendRefundEmail(ctx context.Context, toEmail, orderNumber, eventName, ownerName, amount, reason string, isFull, viaBankTransfer bool) error
+	SendPaymentReminderEmail(ctx context.Context, toEmail, orderNumber, eventName, ownerName, amount, paymentURL, expiresAt string) error
+	SendWaitlistOfferEmail(ctx context.Context, toEmail, eventName, ownerName, ticketTitle string, quantity int, claimURL, expiresAt string) error
 }
 
 type Attachment struct {
@@ -264,3 +267,142 @@ func (s emailService) SendPaymentCancelledEmail(ctx context.Context, toEmail, or
 
 	return nil
 }
+
+func (s emailService) SendRefundEmail(ctx context.Context, toEmail, orderNumber, eventName, ownerName, amount, reason string, isFull, viaBankTransfer bool) error {
+	m := gomail.NewMessage()
+
+	m.SetHeader("From", m.FormatAddress(s.senderEmail, s.senderName))
+	m.SetHeader("To", toEmail)
+	m.SetHeader("Subject", "Refund Pesanan Anda - "+orderNumber)
+
+	ticketNote := "Sebagian dana pesanan Anda dikembalikan. E-Ticket Anda tetap berlaku."
+	if isFull {
+		ticketNote = "Pesanan Anda telah dibatalkan dan <b>E-Ticket sebelumnya tidak lagi berlaku</b> untuk masuk ke area acara."
+	}
+
+	refundNote := "Dana akan dikembalikan ke metode pembayaran yang Anda gunakan sesuai waktu proses dari penyedia pembayaran."
+	if viaBankTransfer {
+		refundNote = "Dana akan ditransfer ke rekening Anda oleh tim kami. Jika kami belum memiliki data rekening Anda, tim kami akan menghubungi Anda."
+	}
+
+	htmlBody := fmt.Sprintf(`
+	<!DOCTYPE html>
+	<html>
+	<body style="font-family: Arial, sans-serif; color: #333; line-height: 1.6; padding: 20px;">
+		<div style="max-width: 600px; margin: 0 auto; border: 1px solid #ddd; border-radius: 10px; padding: 20px; background-color: #f9f9f9;">
+			<h2 style="color: #1e40af; text-align: center;">Refund Diproses 💸</h2>
+			<p>Halo <b>%s</b>,</p>
+			<p>Refund untuk pesanan Anda pada event <strong>%s</strong> sedang kami proses.</p>
+			<p>Nomor pesanan: <b style="color:#1e40af;">%s</b></p>
+			<p>Jumlah refund: <b>%s</b></p>
+			<div style="background-color: #eff6ff; padding: 15px; border-left: 4px solid #1e40af; margin: 20px 0;">
+				<p style="margin: 0;"><b>Alasan refund:</b></p>
+				<p style="margin: 5px 0 0 0;">%s</p>
+			</div>
+			<p>%s</p>
+			<p>%s</p>
+			<p>Jika Anda membutuhkan bantuan, silakan hubungi kami.</p>
+			<p>Salam Hangat,<br><b>Tim %s</b></p>
+		</div>
+	</body>
+	</html>
+	`, ownerName, eventName, orderNumber, amount, reason, ticketNote, refundNote, s.senderName)
+
+	m.SetBody("text/html", htmlBody)
+
+	d := gomail.NewDialer(s.host, s.port, s.user, s.password)
+
+	s.log.Info(ctx, "Mencoba mengirim email refund...", zap.String("to", toEmail))
+	if err := d.DialAndSend(m); err != nil {
+		s.log.Error(ctx, "Gagal mengirim email refund", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (s emailService) SendPaymentReminderEmail(ctx context.Context, toEmail, orderNumber, eventName, ownerName, amount, paymentURL, expiresAt string) error {
+	m := gomail.NewMessage()
+
+	m.SetHeader("From", m.FormatAddress(s.senderEmail, s.senderName))
+	m.SetHeader("To", toEmail)
+	m.SetHeader("Subject", "Segera Selesaikan Pembayaran Anda - "+orderNumber)
+
+	paymentAction := "<p>Silakan selesaikan pembayaran sesuai instruksi yang sudah diberikan saat checkout.</p>"
+	if paymentURL != "" {
+		paymentAction = fmt.Sprintf(`<p style="text-align: center;"><a href="%s" style="background-color: #b20000; color: #fff; padding: 10px 20px; border-radius: 5px; text-decoration: none;">Bayar Sekarang</a></p>`, paymentURL)
+	}
+
+	htmlBody := fmt.Sprintf(`
+	<!DOCTYPE html>
+	<html>
+	<body style="font-family: Arial, sans-serif; color: #333; line-height: 1.6; padding: 20px;">
+		<div style="max-width: 600px; margin: 0 auto; border: 1px solid #ddd; border-radius: 10px; padding: 20px; background-color: #f9f9f9;">
+			<h2 style="color: #d97706; text-align: center;">Pembayaran Belum Selesai ⏳</h2>
+			<p>Halo <b>%s</b>,</p>
+			<p>Pesanan Anda untuk event <strong>%s</strong> belum dibayar.</p>
+			<p>Nomor pesanan: <b style="color:#1e40af;">%s</b></p>
+			<p>Total pembayaran: <b>%s</b></p>
+			<div style="background-color: #fffbeb; padding: 15px; border-left: 4px solid #d97706; margin: 20px 0;">
+				<p style="margin: 0;">Pesanan akan otomatis dibatalkan pada <b>%s</b> dan tiket akan dilepas kembali.</p>
+			</div>
+			%s
+			<p>Abaikan email ini jika Anda sudah melakukan pembayaran.</p>
+			<p>Salam Hangat,<br><b>Tim %s</b></p>
+		</div>
+	</body>
+	</html>
+	`, ownerName, eventName, orderNumber, amount, expiresAt, paymentAction, s.senderName)
+
+	m.SetBody("text/html", htmlBody)
+
+	d := gomail.NewDialer(s.host, s.port, s.user, s.password)
+
+	s.log.Info(ctx, "Mencoba mengirim email pengingat pembayaran...", zap.String("to", toEmail))
+	if err := d.DialAndSend(m); err != nil {
+		s.log.Error(ctx, "Gagal mengirim email pengingat pembayaran", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (s emailService) SendWaitlistOfferEmail(ctx context.Context, toEmail, eventName, ownerName, ticketTitle string, quantity int, claimURL, expiresAt string) error {
+	m := gomail.NewMessage()
+
+	m.SetHeader("From", m.FormatAddress(s.senderEmail, s.senderName))
+	m.SetHeader("To", toEmail)
+	m.SetHeader("Subject", "Tiket Tersedia Untuk Anda - "+eventName)
+
+	htmlBody := fmt.Sprintf(`
+	<!DOCTYPE html>
+	<html>
+	<body style="font-family: Arial, sans-serif; color: #333; line-height: 1.6; padding: 20px;">
+		<div style="max-width: 600px; margin: 0 auto; border: 1px solid #ddd; border-radius: 10px; padding: 20px; background-color: #f9f9f9;">
+			<h2 style="color: #16a34a; text-align: center;">Giliran Anda! 🎟️</h2>
+			<p>Halo <b>%s</b>,</p>
+			<p>Tiket yang Anda tunggu untuk event <strong>%s</strong> kini tersedia.</p>
+			<p>Tiket: <b>%s</b> &times; <b>%d</b></p>
+			<div style="background-color: #f0fdf4; padding: 15px; border-left: 4px solid #16a34a; margin: 20px 0;">
+				<p style="margin: 0;">Tiket ini kami tahan khusus untuk Anda sampai <b>%s</b>. Setelah itu tiket akan ditawarkan ke antrean berikutnya.</p>
+			</div>
+			<p style="text-align: center;"><a href="%s" style="background-color: #16a34a; color: #fff; padding: 10px 20px; border-radius: 5px; text-decoration: none;">Klaim Tiket Sekarang</a></p>
+			<p>Gunakan email ini saat mengisi data pemesan. Link hanya bisa dipakai satu kali.</p>
+			<p>Salam Hangat,<br><b>Tim %s</b></p>
+		</div>
+	</body>
+	</html>
+	`, ownerName, eventName, ticketTitle, quantity, expiresAt, claimURL, s.senderName)
+
+	m.SetBody("text/html", htmlBody)
+
+	d := gomail.NewDialer(s.host, s.port, s.user, s.password)
+
+	s.log.Info(ctx, "Mencoba mengirim email penawaran waitlist...", zap.String("to", toEmail))
+	if err := d.DialAndSend(m); err != nil {
+		s.log.Error(ctx, "Gagal mengirim email penawaran waitlist", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"rakit-tiket-be/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+// BackgroundTasks melacak goroutine background (misalnya kirim email) agar bisa ditunggu saat shutdown
+type BackgroundTasks interface {
+	Go(name string, fn func(ctx context.Context))
+	Wait(ctx context.Context) error
+}
+
+type backgroundTasks struct {
+	log      util.LogUtil
+	wg       sync.WaitGroup
+	inFlight atomic.Int64
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+func MakeBackgroundTasks(log util.LogUtil) BackgroundTasks {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &backgroundTasks{
+		log:    log,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Go menjalankan fn di goroutine baru. Context yang diberikan lepas dari request
+// dan baru di-cancel jika shutdown melewati deadline.
+func (t *backgroundTasks) Go(name string, fn func(ctx context.Context)) {
+	t.wg.Add(1)
+	t.inFlight.Add(1)
+
+	go func() {
+		defer t.wg.Done()
+		defer t.inFlight.Add(-1)
+		defer func() {
+			if r := recover(); r != nil {
+				t.log.Error(t.ctx, "Background task panic", zap.String("task", name), zap.Any("panic", r))
+			}
+		}()
+
+		fn(t.ctx)
+	}()
+}
+
+// Wait menunggu semua task selesai. Dipanggil setelah HTTP dan scheduler berhenti
+// sehingga tidak ada task baru yang masuk.
+func (t *backgroundTasks) Wait(ctx context.Context) error {
+	t.log.Info(ctx, "Waiting for background tasks", zap.Int64("in_flight", t.inFlight.Load()))
+
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		t.cancel()
+		return fmt.Errorf("%d background tasks still running: %w", t.inFlight.Load(), ctx.Err())
+	}
+}
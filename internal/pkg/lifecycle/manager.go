@@ -0,0 +1,87 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"rakit-tiket-be/pkg/util"
+
+	"go.uber.org/zap"
+)
+
+type shutdownHook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Manager menjalankan server lalu mematikan komponen secara berurutan saat SIGINT/SIGTERM
+type Manager struct {
+	log     util.LogUtil
+	timeout time.Duration
+	hooks   []shutdownHook
+}
+
+func NewManager(log util.LogUtil, timeout time.Duration) *Manager {
+	return &Manager{
+		log:     log,
+		timeout: timeout,
+	}
+}
+
+// OnShutdown mendaftarkan hook yang dijalankan sesuai urutan pendaftaran.
+// Semua hook berbagi satu deadline sebesar timeout.
+func (m *Manager) OnShutdown(name string, fn func(ctx context.Context) error) {
+	m.hooks = append(m.hooks, shutdownHook{name: name, fn: fn})
+}
+
+// Run memanggil start (blocking) dan menunggu sinyal shutdown atau start berhenti dengan error
+func (m *Manager) Run(start func() error) error {
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	startErr := make(chan error, 1)
+	go func() {
+		startErr <- start()
+	}()
+
+	var runErr error
+	select {
+	case sig := <-quit:
+		m.log.Info(context.Background(), "Shutdown signal received", zap.String("signal", sig.String()))
+	case err := <-startErr:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			m.log.Error(context.Background(), "Server stopped unexpectedly", zap.Error(err))
+			runErr = err
+		}
+	}
+
+	if err := m.Shutdown(); err != nil && runErr == nil {
+		runErr = err
+	}
+
+	return runErr
+}
+
+func (m *Manager) Shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	var shutdownErr error
+	for _, hook := range m.hooks {
+		started := time.Now()
+		if err := hook.fn(ctx); err != nil {
+			m.log.Error(ctx, "Shutdown step failed", zap.String("step", hook.name), zap.Error(err))
+			shutdownErr = errors.Join(shutdownErr, err)
+			continue
+		}
+		m.log.Info(ctx, "Shutdown step completed", zap.String("step", hook.name), zap.Duration("took", time.Since(started)))
+	}
+
+	return shutdownErr
+}
@@ -14,6 +14,7 @@ import (
 type AuthMiddleware interface {
 	VerifyToken(next echo.HandlerFunc) echo.HandlerFunc
 	RequireAdmin(next echo.HandlerFunc) echo.HandlerFunc
+	RequireRoles(roles ...string) echo.MiddlewareFunc
 }
 
 type authMiddleware struct {
@@ -79,3 +80,24 @@ func (m authMiddleware) RequireAdmin(next echo.HandlerFunc) echo.HandlerFunc {
 		return next(c)
 	}
 }
+
+// RequireRoles: Validasi Role (Apakah role user termasuk salah satu role yang diizinkan?)
+func (m authMiddleware) RequireRoles(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			// Pastikan VerifyToken sudah dijalankan sebelumnya
+			role, ok := c.Get("role").(string)
+			if !ok {
+				return echo.NewHTTPError(http.StatusUnauthorized, "User role not found")
+			}
+
+			for _, allowed := range roles {
+				if role == allowed {
+					return next(c)
+				}
+			}
+
+			return echo.NewHTTPError(http.StatusForbidden, "Access Denied: Role Not Allowed")
+		}
+	}
+}
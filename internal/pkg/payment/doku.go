@@ -0,0 +1,352 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"rakit-tiket-be/pkg/util"
+)
+
+const (
+	DokuDefaultBaseURL          = "https://api.doku.com"
+	DokuDefaultNotificationPath = "/api/v1/webhook/payment/doku"
+
+	dokuCheckoutPath    = "/checkout/v1/payment"
+	dokuOrderStatusPath = "/orders/v1/status/"
+)
+
+type DokuConfig struct {
+	ClientID         string
+	SecretKey        string
+	BaseURL          string // Kosong = production API, bisa diarahkan ke fake server
+	NotificationPath string // Request-Target yang dipakai DOKU saat menandatangani notifikasi
+}
+
+type dokuProvider struct {
+	clientID         string
+	secretKey        string
+	baseURL          string
+	notificationPath string
+	httpClient       *http.Client
+}
+
+func NewDokuProvider(cfg DokuConfig) Provider {
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DokuDefaultBaseURL
+	}
+
+	notificationPath := cfg.NotificationPath
+	if notificationPath == "" {
+		notificationPath = DokuDefaultNotificationPath
+	}
+
+	return &dokuProvider{
+		clientID:         cfg.ClientID,
+		secretKey:        cfg.SecretKey,
+		baseURL:          baseURL,
+		notificationPath: notificationPath,
+		httpClient:       &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type dokuLineItem struct {
+	ID       string  `json:"id,omitempty"`
+	Name     string  `json:"name"`
+	Price    float64 `json:"price"`
+	Quantity int     `json:"quantity"`
+}
+
+type dokuCheckoutRequest struct {
+	Order struct {
+		Amount        float64        `json:"amount"`
+		InvoiceNumber string         `json:"invoice_number"`
+		Currency      string         `json:"currency"`
+		LineItems     []dokuLineItem `json:"line_items,omitempty"`
+	} `json:"order"`
+	Payment struct {
+		PaymentDueDate int `json:"payment_due_date"`
+	} `json:"payment"`
+	Customer struct {
+		Name  string `json:"name,omitempty"`
+		Email string `json:"email,omitempty"`
+		Phone string `json:"phone,omitempty"`
+	} `json:"customer"`
+}
+
+type dokuCheckoutResponse struct {
+	Message  []string `json:"message"`
+	Response struct {
+		Payment struct {
+			URL     string `json:"url"`
+			TokenID string `json:"token_id"`
+		} `json:"payment"`
+	} `json:"response"`
+}
+
+type dokuNotification struct {
+	Order struct {
+		InvoiceNumber string  `json:"invoice_number"`
+		Amount        float64 `json:"amount"`
+	} `json:"order"`
+	Transaction struct {
+		Status            string `json:"status"`
+		Date              string `json:"date"`
+		OriginalRequestID string `json:"original_request_id"`
+	} `json:"transaction"`
+	Service struct {
+		ID string `json:"id"`
+	} `json:"service"`
+	Acquirer struct {
+		ID string `json:"id"`
+	} `json:"acquirer"`
+	Channel struct {
+		ID string `json:"id"`
+	} `json:"channel"`
+}
+
+// Signature DOKU = "HMACSHA256=" + base64(HMAC-SHA256(secret, component))
+func (p *dokuProvider) signature(requestID, timestamp, requestTarget string, body []byte) string {
+	component := fmt.Sprintf("Client-Id:%s\nRequest-Id:%s\nRequest-Timestamp:%s\nRequest-Target:%s",
+		p.clientID, requestID, timestamp, requestTarget)
+
+	if len(body) > 0 {
+		digest := sha256.Sum256(body)
+		component += "\nDigest:" + base64.StdEncoding.EncodeToString(digest[:])
+	}
+
+	mac := hmac.New(sha256.New, []byte(p.secretKey))
+	mac.Write([]byte(component))
+	return "HMACSHA256=" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Implementasi fungsi CreateTransaction dari interface Provider (DOKU Checkout)
+func (p *dokuProvider) CreateTransaction(ctx context.Context, req CreateTransactionRequest) (*CreateTransactionResponse, error) {
+	var checkoutReq dokuCheckoutRequest
+	checkoutReq.Order.Amount = req.Amount
+	checkoutReq.Order.InvoiceNumber = req.OrderID
+	checkoutReq.Order.Currency = "IDR"
+	checkoutReq.Payment.PaymentDueDate = req.ExpiryMinutes
+	checkoutReq.Customer.Name = req.Customer.Name
+	checkoutReq.Customer.Email = req.Customer.Email
+	checkoutReq.Customer.Phone = req.Customer.Phone
+
+	checkoutReq.Order.LineItems = dokuLineItems(req.Items)
+
+	// Total line_items wajib sama dengan order.amount, selain itu checkout ditolak DOKU
+	var lineTotal float64
+	for _, item := range checkoutReq.Order.LineItems {
+		lineTotal += item.Price * float64(item.Quantity)
+	}
+	if math.Abs(lineTotal-req.Amount) > 0.01 {
+		checkoutReq.Order.LineItems = nil
+	}
+
+	body, err := json.Marshal(checkoutReq)
+	if err != nil {
+		return nil, err
+	}
+
+	requestID := util.MakeUUIDv4()
+	timestamp := time.Now().UTC().Format("2006-01-02T15:04:05Z")
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+dokuCheckoutPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Client-Id", p.clientID)
+	httpReq.Header.Set("Request-Id", requestID)
+	httpReq.Header.Set("Request-Timestamp", timestamp)
+	httpReq.Header.Set("Signature", p.signature(requestID, timestamp, dokuCheckoutPath, body))
+
+	httpResp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("doku error: %v", err)
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var checkoutResp dokuCheckoutResponse
+	if err := json.Unmarshal(respBody, &checkoutResp); err != nil {
+		return nil, fmt.Errorf("doku error: invalid response (status %d)", httpResp.StatusCode)
+	}
+
+	if httpResp.StatusCode >= 300 || checkoutResp.Response.Payment.URL == "" {
+		return nil, fmt.Errorf("doku error: %s", strings.Join(checkoutResp.Message, ", "))
+	}
+
+	return &CreateTransactionResponse{
+		Token:         checkoutResp.Response.Payment.TokenID,
+		RedirectURL:   checkoutResp.Response.Payment.URL,
+		TransactionID: requestID,
+	}, nil
+}
+
+// dokuLineItems mengubah item order menjadi line_items DOKU. DOKU menolak harga negatif, sehingga item
+// potongan promo dibebankan berurutan ke baris tiket (selalu di depan add-on) dan nama baris yang
+// terpotong diberi nama potongannya. Baris yang harga satuannya tidak habis dibagi dipecah menjadi dua
+// agar setiap harga tetap bulat rupiah dan total baris sama dengan order.amount.
+func dokuLineItems(items []Item) []dokuLineItem {
+	var discount float64
+	var discountNames []string
+	for _, item := range items {
+		if item.Price < 0 {
+			discount += -item.Price * float64(item.Quantity)
+			discountNames = append(discountNames, item.Name)
+		}
+	}
+	discountLabel := strings.Join(discountNames, ", ")
+
+	var lineItems []dokuLineItem
+	for _, item := range items {
+		if item.Price < 0 {
+			continue
+		}
+
+		lineTotal := item.Price * float64(item.Quantity)
+		cut := math.Min(discount, lineTotal)
+		if cut <= 0 || item.Quantity <= 0 {
+			lineItems = append(lineItems, dokuLineItem{
+				ID:       item.ID,
+				Name:     item.Name,
+				Price:    item.Price,
+				Quantity: item.Quantity,
+			})
+			continue
+		}
+		discount -= cut
+
+		name := fmt.Sprintf("%s (%s)", item.Name, discountLabel)
+		remaining := math.Round(lineTotal - cut)
+		unitPrice := math.Floor(remaining / float64(item.Quantity))
+		lastPrice := remaining - unitPrice*float64(item.Quantity-1)
+
+		if lastPrice == unitPrice {
+			lineItems = append(lineItems, dokuLineItem{ID: item.ID, Name: name, Price: unitPrice, Quantity: item.Quantity})
+			continue
+		}
+		if item.Quantity > 1 {
+			lineItems = append(lineItems, dokuLineItem{ID: item.ID, Name: name, Price: unitPrice, Quantity: item.Quantity - 1})
+		}
+		lineItems = append(lineItems, dokuLineItem{ID: item.ID, Name: name, Price: lastPrice, Quantity: 1})
+	}
+
+	return lineItems
+}
+
+// Implementasi fungsi Webhook (HTTP Notification)
+func (p *dokuProvider) ParseWebhook(ctx context.Context, payload []byte) (*WebhookNotification, error) {
+	var notif dokuNotification
+	if err := json.Unmarshal(payload, &notif); err != nil {
+		return nil, err
+	}
+
+	// Ubah status DOKU ke status universal
+	var mappedStatus string
+	switch strings.ToUpper(notif.Transaction.Status) {
+	case "SUCCESS":
+		mappedStatus = "paid"
+	case "PENDING":
+		mappedStatus = "pending"
+	default:
+		mappedStatus = "failed"
+	}
+
+	paymentChannel := notif.Acquirer.ID
+	if paymentChannel == "" {
+		paymentChannel = notif.Channel.ID
+	}
+
+	return &WebhookNotification{
+		OrderID:        notif.Order.InvoiceNumber,
+		TransactionID:  notif.Transaction.OriginalRequestID,
+		NotificationID: fmt.Sprintf("%s:%s:%s", notif.Order.InvoiceNumber, strings.ToUpper(notif.Transaction.Status), notif.Transaction.Date),
+		GrossAmount:    notif.Order.Amount,
+		PaymentStatus:  mappedStatus,
+		PaymentType:    strings.ToLower(notif.Service.ID),
+		PaymentChannel: strings.ToLower(paymentChannel),
+		Gateway:        GatewayDoku,
+		RawPayload:     string(payload),
+	}, nil
+}
+
+// Verifikasi header Signature dari notifikasi DOKU
+func (p *dokuProvider) VerifyWebhook(ctx context.Context, payload []byte, headers http.Header) error {
+	signature := headers.Get("Signature")
+	requestID := headers.Get("Request-Id")
+	timestamp := headers.Get("Request-Timestamp")
+
+	if signature == "" || requestID == "" || timestamp == "" || p.secretKey == "" {
+		return ErrInvalidSignature
+	}
+
+	if headers.Get("Client-Id") != p.clientID {
+		return ErrInvalidSignature
+	}
+
+	expected := p.signature(requestID, timestamp, p.notificationPath, payload)
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(signature)) != 1 {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// Refund belum tersedia untuk DOKU Checkout, dilakukan manual melalui dashboard DOKU
+func (p *dokuProvider) Refund(ctx context.Context, req RefundRequest) (*RefundResponse, error) {
+	return nil, ErrOperationNotSupported
+}
+
+func (p *dokuProvider) Cancel(ctx context.Context, req CancelRequest) error {
+	return ErrOperationNotSupported
+}
+
+// Status via GET /orders/v1/status/{invoice_number}, response-nya sama dengan payload notifikasi
+func (p *dokuProvider) GetTransactionStatus(ctx context.Context, req TransactionStatusRequest) (*WebhookNotification, error) {
+	requestTarget := dokuOrderStatusPath + url.PathEscape(req.OrderID)
+	requestID := util.MakeUUIDv4()
+	timestamp := time.Now().UTC().Format("2006-01-02T15:04:05Z")
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+requestTarget, nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Client-Id", p.clientID)
+	httpReq.Header.Set("Request-Id", requestID)
+	httpReq.Header.Set("Request-Timestamp", timestamp)
+	httpReq.Header.Set("Signature", p.signature(requestID, timestamp, requestTarget, nil))
+
+	httpResp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("doku error: %v", err)
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if httpResp.StatusCode >= 300 {
+		return nil, fmt.Errorf("doku error: status check failed (status %d)", httpResp.StatusCode)
+	}
+
+	return p.ParseWebhook(ctx, respBody)
+}
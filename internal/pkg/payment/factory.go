@@ -1,20 +1,22 @@
 package payment
 
 import (
-	"context"
 	"fmt"
 )
 
 type PaymentFactory struct {
 	midtransServerKey string
 	isProduction      bool
-	// Xendit, Dokus inisiasi disini
+	xenditConfig      XenditConfig
+	dokuConfig        DokuConfig
 }
 
-func NewPaymentFactory(midtransServerKey string, isProd bool) *PaymentFactory {
+func NewPaymentFactory(midtransServerKey string, isProd bool, xenditConfig XenditConfig, dokuConfig DokuConfig) *PaymentFactory {
 	return &PaymentFactory{
 		midtransServerKey: midtransServerKey,
 		isProduction:      isProd,
+		xenditConfig:      xenditConfig,
+		dokuConfig:        dokuConfig,
 	}
 }
 
@@ -23,9 +25,9 @@ func (f *PaymentFactory) GetProvider(gateway GatewayType) (Provider, error) {
 	case GatewayMidtrans:
 		return NewMidtransProvider(f.midtransServerKey, f.isProduction), nil
 	case GatewayXendit:
-		return NewXenditProvider(), nil
+		return NewXenditProvider(f.xenditConfig), nil
 	case GatewayDoku:
-		return NewDokuProvider(), nil
+		return NewDokuProvider(f.dokuConfig), nil
 	default:
 		return nil, fmt.Errorf("unsupported payment gateway: %s", gateway)
 	}
@@ -34,31 +36,3 @@ func (f *PaymentFactory) GetProvider(gateway GatewayType) (Provider, error) {
 func (f *PaymentFactory) GetProviderByCode(code string) (Provider, error) {
 	return f.GetProvider(GatewayType(code))
 }
-
-func NewXenditProvider() Provider {
-	return &xenditProvider{}
-}
-
-func NewDokuProvider() Provider {
-	return &dokuProvider{}
-}
-
-type xenditProvider struct{}
-
-func (p *xenditProvider) CreateTransaction(ctx context.Context, req CreateTransactionRequest) (*CreateTransactionResponse, error) {
-	return nil, fmt.Errorf("xendit provider not implemented")
-}
-
-func (p *xenditProvider) ParseWebhook(ctx context.Context, payload []byte) (*WebhookNotification, error) {
-	return nil, fmt.Errorf("xendit webhook not implemented")
-}
-
-type dokuProvider struct{}
-
-func (p *dokuProvider) CreateTransaction(ctx context.Context, req CreateTransactionRequest) (*CreateTransactionResponse, error) {
-	return nil, fmt.Errorf("doku provider not implemented")
-}
-
-func (p *dokuProvider) ParseWebhook(ctx context.Context, payload []byte) (*WebhookNotification, error) {
-	return nil, fmt.Errorf("doku webhook not implemented")
-}
@@ -2,8 +2,13 @@ package payment
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"strconv"
+
+	"rakit-tiket-be/pkg/util"
 
 	"github.com/midtrans/midtrans-go"
 	"github.com/midtrans/midtrans-go/coreapi"
@@ -11,6 +16,7 @@ import (
 )
 
 type midtransProvider struct {
+	serverKey  string
 	snapClient snap.Client
 	coreClient coreapi.Client
 }
@@ -28,6 +34,7 @@ func NewMidtransProvider(serverKey string, isProduction bool) Provider {
 	c.New(serverKey, env)
 
 	return &midtransProvider{
+		serverKey:  serverKey,
 		snapClient: s,
 		coreClient: c,
 	}
@@ -88,6 +95,9 @@ func (m *midtransProvider) ParseWebhook(ctx context.Context, payload []byte) (*W
 	orderID, _ := notif["order_id"].(string)
 	transactionID, _ := notif["transaction_id"].(string)
 	transactionStatus, _ := notif["transaction_status"].(string)
+	statusCode, _ := notif["status_code"].(string)
+	grossAmountStr, _ := notif["gross_amount"].(string)
+	grossAmount, _ := strconv.ParseFloat(grossAmountStr, 64)
 	paymentType, _ := notif["payment_type"].(string)
 	paymentChannel := paymentType
 
@@ -132,6 +142,8 @@ func (m *midtransProvider) ParseWebhook(ctx context.Context, payload []byte) (*W
 	return &WebhookNotification{
 		OrderID:        orderID,
 		TransactionID:  transactionID,
+		NotificationID: fmt.Sprintf("%s:%s:%s", transactionID, transactionStatus, statusCode),
+		GrossAmount:    grossAmount,
 		PaymentStatus:  mappedStatus,
 		PaymentType:    paymentType,
 		PaymentChannel: paymentChannel,
@@ -139,3 +151,72 @@ func (m *midtransProvider) ParseWebhook(ctx context.Context, payload []byte) (*W
 		RawPayload:     string(rawPayload),
 	}, nil
 }
+
+// Verifikasi signature_key = SHA512(order_id + status_code + gross_amount + server_key)
+func (m *midtransProvider) VerifyWebhook(ctx context.Context, payload []byte, headers http.Header) error {
+	var notif struct {
+		OrderID      string `json:"order_id"`
+		StatusCode   string `json:"status_code"`
+		GrossAmount  string `json:"gross_amount"`
+		SignatureKey string `json:"signature_key"`
+	}
+	if err := json.Unmarshal(payload, &notif); err != nil {
+		return err
+	}
+
+	if notif.SignatureKey == "" || m.serverKey == "" {
+		return ErrInvalidSignature
+	}
+
+	expected := util.MakeSHA512(notif.OrderID, notif.StatusCode, notif.GrossAmount, m.serverKey)
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(notif.SignatureKey)) != 1 {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// Refund via /v2/{order_id}/refund (full atau partial)
+func (m *midtransProvider) Refund(ctx context.Context, req RefundRequest) (*RefundResponse, error) {
+	refundResp, err := m.coreClient.RefundTransaction(req.OrderID, &coreapi.RefundReq{
+		RefundKey: req.RefundKey,
+		Amount:    int64(req.Amount),
+		Reason:    req.Reason,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("midtrans error: %v", err.GetMessage())
+	}
+
+	refundID := refundResp.RefundKey
+	if refundResp.RefundChargebackUUID != "" {
+		refundID = refundResp.RefundChargebackUUID
+	}
+
+	return &RefundResponse{
+		RefundID: refundID,
+		Status:   refundResp.TransactionStatus,
+	}, nil
+}
+
+// Cancel via /v2/{order_id}/cancel
+func (m *midtransProvider) Cancel(ctx context.Context, req CancelRequest) error {
+	if _, err := m.coreClient.CancelTransaction(req.OrderID); err != nil {
+		return fmt.Errorf("midtrans error: %v", err.GetMessage())
+	}
+	return nil
+}
+
+// Status via /v2/{order_id}/status, response-nya sama dengan payload notifikasi
+func (m *midtransProvider) GetTransactionStatus(ctx context.Context, req TransactionStatusRequest) (*WebhookNotification, error) {
+	statusResp, err := m.coreClient.CheckTransaction(req.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("midtrans error: %v", err.GetMessage())
+	}
+
+	payload, jsonErr := json.Marshal(statusResp)
+	if jsonErr != nil {
+		return nil, jsonErr
+	}
+
+	return m.ParseWebhook(ctx, payload)
+}
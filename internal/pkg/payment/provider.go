@@ -1,6 +1,10 @@
 package payment
 
-import "context"
+import (
+	"context"
+	"errors"
+	"net/http"
+)
 
 type GatewayType string
 
@@ -10,6 +14,11 @@ const (
 	GatewayDoku     GatewayType = "DOKU"
 )
 
+var (
+	ErrInvalidSignature      = errors.New("invalid webhook signature")
+	ErrOperationNotSupported = errors.New("operation not supported by payment gateway")
+)
+
 // DTO Request (Universal)
 type Customer struct {
 	Name  string
@@ -17,6 +26,7 @@ type Customer struct {
 	Phone string
 }
 
+// Item dengan Price negatif adalah potongan (kode promo); total item selalu sama dengan Amount
 type Item struct {
 	ID       string
 	Name     string
@@ -43,15 +53,47 @@ type CreateTransactionResponse struct {
 type WebhookNotification struct {
 	OrderID        string
 	TransactionID  string
-	PaymentStatus  string // "paid", "pending", "failed", "expired"
-	PaymentType    string // "bank_transfer", "gopay", "credit_card"
-	PaymentChannel string // "bca_va", "dana", "visa"
+	NotificationID string  // ID unik notifikasi, dipakai untuk replay protection
+	GrossAmount    float64 // Nominal yang dinotifikasi gateway, dicek terhadap orders.amount
+	PaymentStatus  string  // "paid", "pending", "failed", "expired"
+	PaymentType    string  // "bank_transfer", "gopay", "credit_card"
+	PaymentChannel string  // "bca_va", "dana", "visa"
 	Gateway        GatewayType
 	RawPayload     string // Disimpan ke `payment_metadata` untuk tracking
 }
 
+// DTO Refund / Cancel (Universal)
+type RefundRequest struct {
+	OrderID       string
+	TransactionID string
+	RefundKey     string // Idempotency key, unik per refund
+	Amount        float64
+	Reason        string
+}
+
+type RefundResponse struct {
+	RefundID string // ID refund dari sistem gateway
+	Status   string
+}
+
+type CancelRequest struct {
+	OrderID       string
+	TransactionID string
+}
+
+// DTO Status Transaksi (Universal)
+type TransactionStatusRequest struct {
+	OrderID       string
+	TransactionID string
+}
+
 // 4. THE STRATEGY INTERFACE
 type Provider interface {
 	CreateTransaction(ctx context.Context, req CreateTransactionRequest) (*CreateTransactionResponse, error)
 	ParseWebhook(ctx context.Context, payload []byte) (*WebhookNotification, error)
+	VerifyWebhook(ctx context.Context, payload []byte, headers http.Header) error
+	Refund(ctx context.Context, req RefundRequest) (*RefundResponse, error)
+	Cancel(ctx context.Context, req CancelRequest) error
+	// GetTransactionStatus mengembalikan status terkini dari gateway dalam format yang sama dengan webhook
+	GetTransactionStatus(ctx context.Context, req TransactionStatusRequest) (*WebhookNotification, error)
 }
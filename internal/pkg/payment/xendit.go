@@ -0,0 +1,311 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const XenditDefaultBaseURL = "https://api.xendit.co"
+
+type XenditConfig struct {
+	SecretKey     string
+	CallbackToken string
+	BaseURL       string // Kosong = production API, bisa diarahkan ke stand-in lokal
+}
+
+type xenditProvider struct {
+	secretKey     string
+	callbackToken string
+	baseURL       string
+	httpClient    *http.Client
+}
+
+func NewXenditProvider(cfg XenditConfig) Provider {
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = XenditDefaultBaseURL
+	}
+
+	return &xenditProvider{
+		secretKey:     cfg.SecretKey,
+		callbackToken: cfg.CallbackToken,
+		baseURL:       baseURL,
+		httpClient:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type xenditInvoiceCustomer struct {
+	GivenNames   string `json:"given_names,omitempty"`
+	Email        string `json:"email,omitempty"`
+	MobileNumber string `json:"mobile_number,omitempty"`
+}
+
+type xenditInvoiceItem struct {
+	ReferenceID string  `json:"reference_id,omitempty"`
+	Name        string  `json:"name"`
+	Quantity    int     `json:"quantity"`
+	Price       float64 `json:"price"`
+}
+
+type xenditInvoiceRequest struct {
+	ExternalID      string                `json:"external_id"`
+	Amount          float64               `json:"amount"`
+	PayerEmail      string                `json:"payer_email,omitempty"`
+	Description     string                `json:"description"`
+	InvoiceDuration int                   `json:"invoice_duration,omitempty"`
+	Currency        string                `json:"currency"`
+	Customer        xenditInvoiceCustomer `json:"customer"`
+	Items           []xenditInvoiceItem   `json:"items,omitempty"`
+	Fees            []xenditInvoiceFee    `json:"fees,omitempty"`
+}
+
+// xenditInvoiceFee dengan value negatif ditampilkan Xendit sebagai potongan di halaman invoice
+type xenditInvoiceFee struct {
+	Type  string  `json:"type"`
+	Value float64 `json:"value"`
+}
+
+type xenditInvoiceResponse struct {
+	ID         string `json:"id"`
+	ExternalID string `json:"external_id"`
+	Status     string `json:"status"`
+	InvoiceURL string `json:"invoice_url"`
+}
+
+type xenditCallback struct {
+	ID             string  `json:"id"`
+	ExternalID     string  `json:"external_id"`
+	Status         string  `json:"status"`
+	Amount         float64 `json:"amount"`
+	PaidAmount     float64 `json:"paid_amount"`
+	PaymentMethod  string  `json:"payment_method"`
+	PaymentChannel string  `json:"payment_channel"`
+	BankCode       string  `json:"bank_code"`
+	PaymentID      string  `json:"payment_id"`
+}
+
+// Implementasi fungsi CreateTransaction dari interface Provider (Xendit Invoice)
+func (p *xenditProvider) CreateTransaction(ctx context.Context, req CreateTransactionRequest) (*CreateTransactionResponse, error) {
+	invoiceReq := xenditInvoiceRequest{
+		ExternalID:      req.OrderID,
+		Amount:          req.Amount,
+		PayerEmail:      req.Customer.Email,
+		Description:     fmt.Sprintf("Pembayaran order %s", req.OrderID),
+		InvoiceDuration: req.ExpiryMinutes * 60,
+		Currency:        "IDR",
+		Customer: xenditInvoiceCustomer{
+			GivenNames:   req.Customer.Name,
+			Email:        req.Customer.Email,
+			MobileNumber: req.Customer.Phone,
+		},
+	}
+
+	for _, item := range req.Items {
+		// Xendit tidak menerima harga item negatif, potongan dikirim sebagai fee
+		if item.Price < 0 {
+			invoiceReq.Fees = append(invoiceReq.Fees, xenditInvoiceFee{
+				Type:  item.Name,
+				Value: item.Price * float64(item.Quantity),
+			})
+			continue
+		}
+		invoiceReq.Items = append(invoiceReq.Items, xenditInvoiceItem{
+			ReferenceID: item.ID,
+			Name:        item.Name,
+			Quantity:    item.Quantity,
+			Price:       item.Price,
+		})
+	}
+
+	var invoiceResp xenditInvoiceResponse
+	if err := p.doRequest(ctx, http.MethodPost, "/v2/invoices", invoiceReq, &invoiceResp); err != nil {
+		return nil, err
+	}
+
+	// Invoice ID dipakai sebagai token sekaligus transaction ID
+	return &CreateTransactionResponse{
+		Token:         invoiceResp.ID,
+		RedirectURL:   invoiceResp.InvoiceURL,
+		TransactionID: invoiceResp.ID,
+	}, nil
+}
+
+// Implementasi fungsi Webhook (Invoice callback)
+func (p *xenditProvider) ParseWebhook(ctx context.Context, payload []byte) (*WebhookNotification, error) {
+	var notif xenditCallback
+	if err := json.Unmarshal(payload, &notif); err != nil {
+		return nil, err
+	}
+
+	// Ubah status xendit ke status universal
+	var mappedStatus string
+	switch strings.ToUpper(notif.Status) {
+	case "PAID", "SETTLED":
+		mappedStatus = "paid"
+	case "PENDING":
+		mappedStatus = "pending"
+	case "EXPIRED":
+		mappedStatus = "expired"
+	default:
+		mappedStatus = "failed"
+	}
+
+	paymentChannel := notif.PaymentChannel
+	if paymentChannel == "" {
+		paymentChannel = notif.BankCode
+	}
+
+	grossAmount := notif.Amount
+	if notif.PaidAmount > 0 {
+		grossAmount = notif.PaidAmount
+	}
+
+	return &WebhookNotification{
+		OrderID:        notif.ExternalID,
+		TransactionID:  notif.ID,
+		NotificationID: fmt.Sprintf("%s:%s", notif.ID, strings.ToUpper(notif.Status)),
+		GrossAmount:    grossAmount,
+		PaymentStatus:  mappedStatus,
+		PaymentType:    strings.ToLower(notif.PaymentMethod),
+		PaymentChannel: strings.ToLower(paymentChannel),
+		Gateway:        GatewayXendit,
+		RawPayload:     string(payload),
+	}, nil
+}
+
+// Verifikasi header x-callback-token dengan token dari dashboard Xendit
+func (p *xenditProvider) VerifyWebhook(ctx context.Context, payload []byte, headers http.Header) error {
+	token := headers.Get("x-callback-token")
+	if token == "" || p.callbackToken == "" {
+		return ErrInvalidSignature
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token), []byte(p.callbackToken)) != 1 {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+type xenditRefundRequest struct {
+	InvoiceID   string  `json:"invoice_id"`
+	ReferenceID string  `json:"reference_id"`
+	Amount      float64 `json:"amount"`
+	Currency    string  `json:"currency"`
+	Reason      string  `json:"reason"`
+}
+
+type xenditRefundResponse struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// doRequest mengirim request JSON ke Xendit API dengan Basic Auth secret key
+func (p *xenditProvider) doRequest(ctx context.Context, method, path string, payload interface{}, result interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		raw, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(raw)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.SetBasicAuth(p.secretKey, "")
+
+	httpResp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("xendit error: %v", err)
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return err
+	}
+
+	if httpResp.StatusCode >= 300 {
+		var errResp struct {
+			ErrorCode string `json:"error_code"`
+			Message   string `json:"message"`
+		}
+		_ = json.Unmarshal(respBody, &errResp)
+		return fmt.Errorf("xendit error: %s %s", errResp.ErrorCode, errResp.Message)
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(respBody, result); err != nil {
+			return fmt.Errorf("xendit error: invalid response (status %d)", httpResp.StatusCode)
+		}
+	}
+
+	return nil
+}
+
+// Refund invoice yang sudah dibayar via /refunds
+func (p *xenditProvider) Refund(ctx context.Context, req RefundRequest) (*RefundResponse, error) {
+	if req.TransactionID == "" {
+		return nil, fmt.Errorf("xendit error: invoice id is required for refund")
+	}
+
+	var refundResp xenditRefundResponse
+	err := p.doRequest(ctx, http.MethodPost, "/refunds", xenditRefundRequest{
+		InvoiceID:   req.TransactionID,
+		ReferenceID: req.RefundKey,
+		Amount:      req.Amount,
+		Currency:    "IDR",
+		Reason:      "REQUESTED_BY_CUSTOMER",
+	}, &refundResp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RefundResponse{
+		RefundID: refundResp.ID,
+		Status:   refundResp.Status,
+	}, nil
+}
+
+// Cancel invoice yang belum dibayar (expire invoice)
+func (p *xenditProvider) Cancel(ctx context.Context, req CancelRequest) error {
+	if req.TransactionID == "" {
+		return fmt.Errorf("xendit error: invoice id is required for cancel")
+	}
+
+	return p.doRequest(ctx, http.MethodPost, "/invoices/"+req.TransactionID+"/expire!", nil, nil)
+}
+
+// Status invoice via GET /v2/invoices, field-nya sama dengan payload callback
+func (p *xenditProvider) GetTransactionStatus(ctx context.Context, req TransactionStatusRequest) (*WebhookNotification, error) {
+	var invoice json.RawMessage
+	if req.TransactionID != "" {
+		if err := p.doRequest(ctx, http.MethodGet, "/v2/invoices/"+url.PathEscape(req.TransactionID), nil, &invoice); err != nil {
+			return nil, err
+		}
+	} else {
+		var invoices []json.RawMessage
+		if err := p.doRequest(ctx, http.MethodGet, "/v2/invoices?external_id="+url.QueryEscape(req.OrderID), nil, &invoices); err != nil {
+			return nil, err
+		}
+		if len(invoices) == 0 {
+			return nil, fmt.Errorf("xendit error: invoice for order %s not found", req.OrderID)
+		}
+		// Invoice terbaru ada di urutan pertama
+		invoice = invoices[0]
+	}
+
+	return p.ParseWebhook(ctx, invoice)
+}
@@ -0,0 +1,91 @@
+package pricing
+
+import (
+	"errors"
+	"time"
+
+	ticketEntity "rakit-tiket-be/pkg/entity/app_ticket"
+)
+
+// Reason ketidaktersediaan tiket, dipakai juga oleh endpoint hype
+const (
+	ReasonSoldOut        = "SOLD_OUT"
+	ReasonSaleNotStarted = "SALE_NOT_STARTED"
+	ReasonSaleEnded      = "SALE_ENDED"
+)
+
+var (
+	ErrSaleNotStarted = errors.New("penjualan tiket belum dimulai")
+	ErrSaleEnded      = errors.New("penjualan tiket sudah berakhir")
+)
+
+// Quote adalah hasil resolusi harga satu tiket pada waktu tertentu
+type Quote struct {
+	UnitPrice     float64
+	OriginalPrice float64
+	IsFlashSale   bool
+
+	// Tier yang dipakai, nil untuk tiket tanpa tier group
+	Tier *ticketEntity.PriceTier
+}
+
+// IsFlashSaleActive mengecek apakah flash sale sedang berjalan (start inklusif, end eksklusif)
+func IsFlashSaleActive(t ticketEntity.Ticket, now time.Time) bool {
+	if !t.IsFlashSale || t.FlashSalePrice == nil || t.FlashStartTime == nil || t.FlashEndTime == nil {
+		return false
+	}
+	return !now.Before(*t.FlashStartTime) && now.Before(*t.FlashEndTime)
+}
+
+// ResolvePrice mengembalikan harga efektif tiket pada waktu now.
+// Tiket dengan tier group memakai harga tier aktif sebagai harga normal.
+func ResolvePrice(t ticketEntity.Ticket, now time.Time) Quote {
+	if active, _ := ActiveTier(t, now); active != nil {
+		return tierQuote(t, *active, now)
+	}
+
+	quote := Quote{
+		UnitPrice:     t.Price,
+		OriginalPrice: t.Price,
+	}
+
+	if IsFlashSaleActive(t, now) {
+		quote.UnitPrice = *t.FlashSalePrice
+		quote.IsFlashSale = true
+	}
+
+	return quote
+}
+
+// CheckSaleWindow memvalidasi window penjualan tiket (sale_start_time / sale_end_time).
+// Tiket dengan tier group juga dianggap berakhir jika semua tier sudah berakhir.
+func CheckSaleWindow(t ticketEntity.Ticket, now time.Time) error {
+	if t.SaleStartTime != nil && now.Before(*t.SaleStartTime) {
+		return ErrSaleNotStarted
+	}
+	if t.SaleEndTime != nil && !now.Before(*t.SaleEndTime) {
+		return ErrSaleEnded
+	}
+	if len(t.PriceTiers) > 0 {
+		if active, _ := ActiveTier(t, now); active == nil {
+			return ErrSaleEnded
+		}
+	}
+	return nil
+}
+
+// UnavailableReason mengembalikan alasan tiket tidak bisa dibeli, string kosong jika tersedia
+func UnavailableReason(t ticketEntity.Ticket, now time.Time) string {
+	if t.AvailableQty <= 0 {
+		return ReasonSoldOut
+	}
+
+	switch CheckSaleWindow(t, now) {
+	case ErrSaleNotStarted:
+		return ReasonSaleNotStarted
+	case ErrSaleEnded:
+		return ReasonSaleEnded
+	}
+
+	return ""
+}
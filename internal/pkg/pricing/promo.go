@@ -0,0 +1,80 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	promoEntity "rakit-tiket-be/pkg/entity/app_promo"
+)
+
+// ErrPromoInvalid membungkus semua alasan kode promo ditolak saat registrasi
+var ErrPromoInvalid = errors.New("kode promo tidak dapat dipakai")
+
+// PromoLine adalah satu item order (per jenis tiket) yang dinilai oleh kode promo
+type PromoLine struct {
+	TicketType string
+	Quantity   int
+	Subtotal   float64
+}
+
+// CheckPromoWindow memvalidasi status aktif dan masa berlaku promo (starts_at inklusif, ends_at eksklusif)
+func CheckPromoWindow(p promoEntity.PromoCode, now time.Time) error {
+	if !p.IsActive {
+		return fmt.Errorf("%w: kode promo tidak aktif", ErrPromoInvalid)
+	}
+	if p.StartsAt != nil && now.Before(*p.StartsAt) {
+		return fmt.Errorf("%w: kode promo belum berlaku", ErrPromoInvalid)
+	}
+	if p.EndsAt != nil && !now.Before(*p.EndsAt) {
+		return fmt.Errorf("%w: kode promo sudah berakhir", ErrPromoInvalid)
+	}
+	return nil
+}
+
+// ResolveDiscount menghitung potongan (rupiah, dibulatkan ke bawah) untuk item yang masuk scope promo.
+// Potongan tidak pernah melebihi subtotal item yang memenuhi syarat.
+func ResolveDiscount(p promoEntity.PromoCode, eventID string, lines []PromoLine) (float64, error) {
+	if !p.AppliesToEvent(eventID) {
+		return 0, fmt.Errorf("%w: kode promo tidak berlaku untuk event ini", ErrPromoInvalid)
+	}
+
+	var eligibleQty int
+	var eligibleSubtotal float64
+	for _, line := range lines {
+		if p.AppliesToTicketType(line.TicketType) {
+			eligibleQty += line.Quantity
+			eligibleSubtotal += line.Subtotal
+		}
+	}
+
+	if eligibleQty == 0 {
+		return 0, fmt.Errorf("%w: kode promo tidak berlaku untuk tiket yang dipilih", ErrPromoInvalid)
+	}
+	if eligibleQty < p.MinQty {
+		return 0, fmt.Errorf("%w: minimal %d tiket untuk kode promo ini", ErrPromoInvalid, p.MinQty)
+	}
+
+	var discount float64
+	switch p.DiscountType {
+	case promoEntity.DiscountTypePercent:
+		discount = math.Floor(eligibleSubtotal * p.DiscountValue / 100)
+		if p.MaxDiscount != nil && discount > *p.MaxDiscount {
+			discount = *p.MaxDiscount
+		}
+	case promoEntity.DiscountTypeFixed:
+		discount = p.DiscountValue
+	default:
+		return 0, fmt.Errorf("%w: tipe diskon %q tidak dikenal", ErrPromoInvalid, p.DiscountType)
+	}
+
+	if discount > eligibleSubtotal {
+		discount = eligibleSubtotal
+	}
+	if discount < 0 {
+		discount = 0
+	}
+
+	return discount, nil
+}
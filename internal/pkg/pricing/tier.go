@@ -0,0 +1,111 @@
+package pricing
+
+import (
+	"sort"
+	"time"
+
+	ticketEntity "rakit-tiket-be/pkg/entity/app_ticket"
+)
+
+// TierLine adalah potongan quantity yang dibeli pada satu tier (satu item order)
+type TierLine struct {
+	Quote
+	Quantity int
+}
+
+// TierEnded mengecek apakah tier sudah berakhir untuk jumlah tiket yang sudah dibooking / terjual (end_at eksklusif)
+func TierEnded(tier ticketEntity.PriceTier, taken int, now time.Time) bool {
+	if tier.EndSoldQty != nil && taken >= *tier.EndSoldQty {
+		return true
+	}
+	return tier.EndAt != nil && !now.Before(*tier.EndAt)
+}
+
+// ActiveTier mengembalikan tier yang sedang berlaku dan tier sesudahnya (nil jika tidak ada).
+// Tier yang belum berakhir pertama adalah tier aktif, jadi rollover terjadi otomatis.
+func ActiveTier(t ticketEntity.Ticket, now time.Time) (active, next *ticketEntity.PriceTier) {
+	tiers := sortedTiers(t.PriceTiers)
+	taken := t.BookedQty + t.SoldQty
+
+	for i := range tiers {
+		if TierEnded(tiers[i], taken, now) {
+			continue
+		}
+		if active == nil {
+			active = &tiers[i]
+			continue
+		}
+		next = &tiers[i]
+		break
+	}
+
+	return active, next
+}
+
+// TierRemaining mengembalikan sisa quantity tier aktif sebelum rollover, nil jika tier tidak dibatasi quantity
+func TierRemaining(t ticketEntity.Ticket, tier ticketEntity.PriceTier) *int {
+	if tier.EndSoldQty == nil {
+		return nil
+	}
+	remaining := max(min(*tier.EndSoldQty-t.BookedQty-t.SoldQty, t.AvailableQty), 0)
+	return &remaining
+}
+
+// AllocateTiers memecah qty tiket ke tier berurutan mulai dari jumlah taken (booked + sold sebelum pembelian ini).
+// Tiket tanpa tier menghasilkan satu line dengan harga ResolvePrice. Mengembalikan ErrSaleEnded jika
+// qty melewati tier terakhir.
+func AllocateTiers(t ticketEntity.Ticket, qty, taken int, now time.Time) ([]TierLine, error) {
+	if len(t.PriceTiers) == 0 {
+		return []TierLine{{Quote: ResolvePrice(t, now), Quantity: qty}}, nil
+	}
+
+	var lines []TierLine
+	for _, tier := range sortedTiers(t.PriceTiers) {
+		if qty <= 0 {
+			break
+		}
+		if TierEnded(tier, taken, now) {
+			continue
+		}
+
+		n := qty
+		if tier.EndSoldQty != nil {
+			n = min(qty, *tier.EndSoldQty-taken)
+		}
+
+		lines = append(lines, TierLine{Quote: tierQuote(t, tier, now), Quantity: n})
+		qty -= n
+		taken += n
+	}
+
+	if qty > 0 {
+		return nil, ErrSaleEnded
+	}
+
+	return lines, nil
+}
+
+// tierQuote memakai harga tier sebagai harga normal; flash sale yang aktif tetap menimpa harga tier
+func tierQuote(t ticketEntity.Ticket, tier ticketEntity.PriceTier, now time.Time) Quote {
+	quote := Quote{
+		UnitPrice:     tier.Price,
+		OriginalPrice: tier.Price,
+		Tier:          &tier,
+	}
+
+	if IsFlashSaleActive(t, now) {
+		quote.UnitPrice = *t.FlashSalePrice
+		quote.IsFlashSale = true
+	}
+
+	return quote
+}
+
+func sortedTiers(tiers ticketEntity.PriceTiers) ticketEntity.PriceTiers {
+	sorted := make(ticketEntity.PriceTiers, len(tiers))
+	copy(sorted, tiers)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].TierOrder < sorted[j].TierOrder
+	})
+	return sorted
+}
@@ -0,0 +1,255 @@
+package qrsign
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	pubEntity "rakit-tiket-be/pkg/entity"
+
+	"github.com/google/uuid"
+)
+
+// Format QR bertanda tangan: RT1.<key id>.<base64url(payload || signature)>
+const codePrefix = "RT1."
+
+const payloadSize = 1 + 16 + 16 + 2
+
+var (
+	ErrUnsignedCode     = errors.New("qr code tidak bertanda tangan")
+	ErrMalformedCode    = errors.New("format qr code tidak valid")
+	ErrUnknownKey       = errors.New("kunci qr code tidak dikenal")
+	ErrInvalidSignature = errors.New("tanda tangan qr code tidak valid")
+)
+
+type Kind byte
+
+const (
+	KindETicket        Kind = 'E'
+	KindPhysicalTicket Kind = 'P'
+	// KindMerchPickup adalah QR pengambilan add-on; TicketID berisi ID order dan Seat selalu 0
+	KindMerchPickup Kind = 'M'
+)
+
+// Payload adalah isi QR: event, ID tiket (e-ticket, tiket fisik atau order untuk pickup add-on) dan nomor kursi
+type Payload struct {
+	Kind     Kind
+	EventID  pubEntity.UUID
+	TicketID pubEntity.UUID
+	Seat     int
+}
+
+type PublicKey struct {
+	KeyID     string `json:"key_id"`
+	PublicKey string `json:"public_key"` // base64 std, 32 byte Ed25519
+	Active    bool   `json:"active"`
+}
+
+type Signer interface {
+	Sign(payload Payload) (string, error)
+	// Verify mengembalikan ErrUnsignedCode untuk kode format lama (nomor order / TKT...)
+	Verify(code string) (*Payload, error)
+	IsSigned(code string) bool
+	AllowUnsigned() bool
+	PublicKeys() []PublicKey
+}
+
+type Config struct {
+	// Keys berisi pasangan "<key id>:<base64 seed 32 byte>" dipisah koma.
+	// Kunci lama tetap dicantumkan agar QR yang sudah tercetak masih bisa diverifikasi.
+	Keys string
+	// ActiveKeyID dipakai untuk menandatangani QR baru; kosong = kunci pertama
+	ActiveKeyID string
+	// AllowUnsigned menerima kode lama tanpa tanda tangan selama masa migrasi; default false karena kode lama mudah ditebak
+	AllowUnsigned bool
+}
+
+type signer struct {
+	activeKeyID   string
+	privateKeys   map[string]ed25519.PrivateKey
+	keyOrder      []string
+	allowUnsigned bool
+}
+
+func NewSigner(cfg Config) (Signer, error) {
+	s := &signer{
+		privateKeys:   make(map[string]ed25519.PrivateKey),
+		allowUnsigned: cfg.AllowUnsigned,
+	}
+
+	for _, entry := range strings.Split(cfg.Keys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		keyID, encodedSeed, ok := strings.Cut(entry, ":")
+		if !ok || keyID == "" || strings.Contains(keyID, ".") {
+			return nil, fmt.Errorf("qr signing key %q harus berformat <key id>:<base64 seed>", entry)
+		}
+
+		seed, err := base64.StdEncoding.DecodeString(encodedSeed)
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("qr signing key %s harus berupa base64 dari %d byte", keyID, ed25519.SeedSize)
+		}
+
+		if _, exists := s.privateKeys[keyID]; exists {
+			return nil, fmt.Errorf("qr signing key %s terdaftar lebih dari sekali", keyID)
+		}
+
+		s.privateKeys[keyID] = ed25519.NewKeyFromSeed(seed)
+		s.keyOrder = append(s.keyOrder, keyID)
+	}
+
+	if len(s.keyOrder) == 0 {
+		return nil, errors.New("minimal satu qr signing key harus dikonfigurasi")
+	}
+
+	s.activeKeyID = cfg.ActiveKeyID
+	if s.activeKeyID == "" {
+		s.activeKeyID = s.keyOrder[0]
+	}
+	if _, ok := s.privateKeys[s.activeKeyID]; !ok {
+		return nil, fmt.Errorf("qr signing key aktif %s tidak ditemukan", s.activeKeyID)
+	}
+
+	return s, nil
+}
+
+func (s *signer) Sign(payload Payload) (string, error) {
+	message, err := encodePayload(payload)
+	if err != nil {
+		return "", err
+	}
+
+	signature := ed25519.Sign(s.privateKeys[s.activeKeyID], message)
+
+	return codePrefix + s.activeKeyID + "." + base64.RawURLEncoding.EncodeToString(append(message, signature...)), nil
+}
+
+func (s *signer) Verify(code string) (*Payload, error) {
+	if !s.IsSigned(code) {
+		return nil, ErrUnsignedCode
+	}
+
+	keyID, encoded, ok := strings.Cut(strings.TrimPrefix(code, codePrefix), ".")
+	if !ok {
+		return nil, ErrMalformedCode
+	}
+
+	privateKey, ok := s.privateKeys[keyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(raw) != payloadSize+ed25519.SignatureSize {
+		return nil, ErrMalformedCode
+	}
+
+	message, signature := raw[:payloadSize], raw[payloadSize:]
+	if !ed25519.Verify(privateKey.Public().(ed25519.PublicKey), message, signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	return decodePayload(message)
+}
+
+func (s *signer) IsSigned(code string) bool {
+	return strings.HasPrefix(code, codePrefix)
+}
+
+func (s *signer) AllowUnsigned() bool {
+	return s.allowUnsigned
+}
+
+func (s *signer) PublicKeys() []PublicKey {
+	keys := make([]PublicKey, 0, len(s.keyOrder))
+	for _, keyID := range s.keyOrder {
+		keys = append(keys, PublicKey{
+			KeyID:     keyID,
+			PublicKey: base64.StdEncoding.EncodeToString(s.privateKeys[keyID].Public().(ed25519.PublicKey)),
+			Active:    keyID == s.activeKeyID,
+		})
+	}
+	return keys
+}
+
+// ClaimedPayload membaca isi QR tanpa verifikasi, hanya untuk keperluan audit kode yang ditolak
+func ClaimedPayload(code string) *Payload {
+	if !strings.HasPrefix(code, codePrefix) {
+		return nil
+	}
+
+	_, encoded, ok := strings.Cut(strings.TrimPrefix(code, codePrefix), ".")
+	if !ok {
+		return nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(raw) < payloadSize {
+		return nil
+	}
+
+	payload, err := decodePayload(raw[:payloadSize])
+	if err != nil {
+		return nil
+	}
+	return payload
+}
+
+func encodePayload(payload Payload) ([]byte, error) {
+	if payload.Kind != KindETicket && payload.Kind != KindPhysicalTicket && payload.Kind != KindMerchPickup {
+		return nil, fmt.Errorf("jenis qr code tidak dikenal: %q", payload.Kind)
+	}
+	if payload.Seat < 0 || payload.Seat > 0xFFFF {
+		return nil, fmt.Errorf("nomor kursi di luar jangkauan: %d", payload.Seat)
+	}
+
+	eventID, err := uuid.Parse(string(payload.EventID))
+	if err != nil {
+		return nil, fmt.Errorf("event id tidak valid: %v", err)
+	}
+	ticketID, err := uuid.Parse(string(payload.TicketID))
+	if err != nil {
+		return nil, fmt.Errorf("ticket id tidak valid: %v", err)
+	}
+
+	message := make([]byte, 0, payloadSize)
+	message = append(message, byte(payload.Kind))
+	message = append(message, eventID[:]...)
+	message = append(message, ticketID[:]...)
+	message = binary.BigEndian.AppendUint16(message, uint16(payload.Seat))
+
+	return message, nil
+}
+
+func decodePayload(message []byte) (*Payload, error) {
+	if len(message) != payloadSize {
+		return nil, ErrMalformedCode
+	}
+
+	kind := Kind(message[0])
+	if kind != KindETicket && kind != KindPhysicalTicket && kind != KindMerchPickup {
+		return nil, ErrMalformedCode
+	}
+
+	eventID, err := uuid.FromBytes(message[1:17])
+	if err != nil {
+		return nil, ErrMalformedCode
+	}
+	ticketID, err := uuid.FromBytes(message[17:33])
+	if err != nil {
+		return nil, ErrMalformedCode
+	}
+
+	return &Payload{
+		Kind:     kind,
+		EventID:  pubEntity.UUID(eventID.String()),
+		TicketID: pubEntity.UUID(ticketID.String()),
+		Seat:     int(binary.BigEndian.Uint16(message[33:35])),
+	}, nil
+}
@@ -0,0 +1,6 @@
+-- Rollback order_items table
+
+DROP INDEX IF EXISTS order_items_order_id;
+DROP INDEX IF EXISTS order_items_ticket_id;
+
+DROP TABLE IF EXISTS order_items;
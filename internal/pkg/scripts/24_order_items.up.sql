@@ -0,0 +1,54 @@
+-- order_items table
+-- Snapshot harga per jenis tiket pada saat registrasi (flash sale / harga normal)
+
+DROP TABLE IF EXISTS order_items;
+
+CREATE TABLE order_items (
+    id uuid NOT NULL,
+
+    -- Relation
+    order_id uuid NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    ticket_id uuid NOT NULL REFERENCES tickets(id),
+
+    -- Resolved Price
+    ticket_title varchar(255) NOT NULL,
+    quantity int NOT NULL,
+    unit_price numeric(12, 2) NOT NULL,
+    original_price numeric(12, 2) NOT NULL,
+    is_flash_sale bool NOT NULL DEFAULT false,
+    subtotal numeric(12, 2) NOT NULL,
+
+    -- Metadata
+    deleted bool NOT NULL DEFAULT false,
+    data_hash varchar DEFAULT '-',
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    updated_at timestamptz NULL,
+
+    CONSTRAINT order_items_pkey PRIMARY KEY (id),
+    CONSTRAINT order_items_order_ticket_key UNIQUE (order_id, ticket_id)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS order_items_order_id ON order_items(order_id);
+CREATE INDEX IF NOT EXISTS order_items_ticket_id ON order_items(ticket_id);
+
+-- Backfill order lama dari registrant + attendees (harga normal tiket)
+INSERT INTO order_items (id, order_id, ticket_id, ticket_title, quantity, unit_price, original_price, is_flash_sale, subtotal)
+SELECT
+    md5(o.id::text || s.ticket_id::text)::uuid,
+    o.id,
+    s.ticket_id,
+    t.title,
+    COUNT(*),
+    t.price,
+    t.price,
+    false,
+    t.price * COUNT(*)
+FROM orders o
+JOIN (
+    SELECT r.id AS registrant_id, r.ticket_id FROM registrants r WHERE r.ticket_id IS NOT NULL
+    UNION ALL
+    SELECT a.registrant_id, a.ticket_id FROM attendees a WHERE a.deleted = false
+) s ON s.registrant_id = o.registrant_id
+JOIN tickets t ON t.id = s.ticket_id
+GROUP BY o.id, s.ticket_id, t.title, t.price;
@@ -0,0 +1,5 @@
+-- Rollback payment_notifications table
+
+DROP INDEX IF EXISTS payment_notifications_order_number;
+
+DROP TABLE IF EXISTS payment_notifications;
@@ -0,0 +1,24 @@
+-- payment_notifications table
+-- Catatan notifikasi webhook yang sudah diproses (replay protection)
+
+DROP TABLE IF EXISTS payment_notifications;
+
+CREATE TABLE payment_notifications (
+    id uuid NOT NULL,
+
+    -- Gateway Reference
+    gateway varchar(50) NOT NULL,
+    notification_id varchar(255) NOT NULL,
+    order_number varchar(255) NOT NULL,
+    payment_status varchar(20) NOT NULL,
+    gross_amount numeric(12, 2) NULL,
+
+    -- Metadata
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT payment_notifications_pkey PRIMARY KEY (id),
+    CONSTRAINT payment_notifications_gateway_notification_key UNIQUE (gateway, notification_id)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS payment_notifications_order_number ON payment_notifications(order_number);
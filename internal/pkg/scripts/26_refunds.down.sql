@@ -0,0 +1,13 @@
+-- Rollback refunds table
+
+ALTER TABLE registrants DROP CONSTRAINT IF EXISTS registrants_status_check;
+ALTER TABLE registrants ADD CONSTRAINT registrants_status_check
+    CHECK (status IN ('pending', 'paid', 'failed', 'cancelled', 'rejected'));
+
+DROP INDEX IF EXISTS idx_refunds_order_id;
+DROP INDEX IF EXISTS idx_refunds_status;
+
+DROP TABLE IF EXISTS refunds;
+DROP TYPE IF EXISTS refund_type_enum;
+DROP TYPE IF EXISTS refund_method_enum;
+DROP TYPE IF EXISTS refund_status_enum;
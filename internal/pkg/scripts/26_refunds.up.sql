@@ -0,0 +1,54 @@
+-- refunds table
+-- Catatan refund / cancel order (partial dan full)
+
+DROP TABLE IF EXISTS refunds;
+DROP TYPE IF EXISTS refund_type_enum;
+DROP TYPE IF EXISTS refund_method_enum;
+DROP TYPE IF EXISTS refund_status_enum;
+
+CREATE TYPE refund_type_enum AS ENUM ('REFUND', 'CANCEL');
+CREATE TYPE refund_method_enum AS ENUM ('GATEWAY', 'BANK_TRANSFER');
+CREATE TYPE refund_status_enum AS ENUM ('PENDING', 'COMPLETED', 'FAILED');
+
+CREATE TABLE refunds (
+    id uuid NOT NULL,
+    order_id uuid NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+
+    type refund_type_enum NOT NULL DEFAULT 'REFUND',
+    method refund_method_enum NOT NULL,
+    status refund_status_enum NOT NULL DEFAULT 'PENDING',
+    amount numeric(12, 2) NOT NULL,
+    is_full bool NOT NULL DEFAULT false,
+    reason text NULL,
+
+    -- Gateway
+    gateway varchar(50) NULL,
+    refund_key varchar(255) NULL,
+    gateway_refund_id varchar(255) NULL,
+
+    -- Bank Transfer
+    bank_name varchar(100) NULL,
+    account_number varchar(50) NULL,
+    account_holder varchar(255) NULL,
+
+    -- Audit
+    processed_by uuid NULL,
+    completed_at timestamptz NULL,
+
+    -- Metadata
+    deleted bool NOT NULL DEFAULT false,
+    data_hash varchar NOT NULL DEFAULT '-',
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NULL,
+
+    CONSTRAINT refunds_pkey PRIMARY KEY (id)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_refunds_order_id ON refunds(order_id);
+CREATE INDEX IF NOT EXISTS idx_refunds_status ON refunds(status);
+
+-- Registrant status 'refunded'
+ALTER TABLE registrants DROP CONSTRAINT IF EXISTS registrants_status_check;
+ALTER TABLE registrants ADD CONSTRAINT registrants_status_check
+    CHECK (status IN ('pending', 'paid', 'failed', 'cancelled', 'rejected', 'refunded'));
@@ -0,0 +1,6 @@
+-- Rollback job_runs table
+
+DROP INDEX IF EXISTS idx_job_runs_status;
+DROP INDEX IF EXISTS idx_job_runs_job_name_started_at;
+
+DROP TABLE IF EXISTS job_runs;
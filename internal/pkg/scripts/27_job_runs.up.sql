@@ -0,0 +1,24 @@
+-- job_runs table
+-- Riwayat eksekusi background job (cron)
+
+DROP TABLE IF EXISTS job_runs;
+
+CREATE TABLE job_runs (
+    id uuid NOT NULL,
+
+    job_name varchar(100) NOT NULL,
+    instance varchar(255) NULL, -- Hostname replica yang menjalankan job
+    status varchar(20) NOT NULL DEFAULT 'RUNNING'
+        CHECK (status IN ('RUNNING', 'SUCCESS', 'FAILED')),
+
+    started_at timestamptz NOT NULL,
+    finished_at timestamptz NULL,
+    affected_rows bigint NOT NULL DEFAULT 0,
+    error text NULL,
+
+    CONSTRAINT job_runs_pkey PRIMARY KEY (id)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_job_runs_job_name_started_at ON job_runs(job_name, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_job_runs_status ON job_runs(status);
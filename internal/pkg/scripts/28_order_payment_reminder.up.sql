@@ -0,0 +1,2 @@
+-- Penanda email pengingat pembayaran sudah dikirim (sekali per order)
+ALTER TABLE orders ADD COLUMN payment_reminder_sent_at timestamptz DEFAULT NULL;
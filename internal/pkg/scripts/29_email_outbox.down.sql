@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_email_outbox_order_id;
+DROP INDEX IF EXISTS idx_email_outbox_status_next_attempt;
+DROP TABLE IF EXISTS email_outbox;
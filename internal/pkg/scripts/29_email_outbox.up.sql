@@ -0,0 +1,35 @@
+-- email_outbox table
+-- Outbox email transaksional: ditulis dalam transaksi yang sama dengan perubahan status order,
+-- lalu dikirim oleh worker dengan retry (exponential backoff) dan status DEAD jika gagal terus
+
+DROP TABLE IF EXISTS email_outbox;
+
+CREATE TABLE email_outbox (
+    id uuid NOT NULL,
+
+    type varchar(50) NOT NULL,
+    order_id uuid NULL REFERENCES orders(id) ON DELETE CASCADE,
+    recipient varchar(255) NOT NULL,
+    payload jsonb NOT NULL DEFAULT '{}'::jsonb,
+
+    status varchar(20) NOT NULL DEFAULT 'PENDING'
+        CHECK (status IN ('PENDING', 'PROCESSING', 'SENT', 'DEAD')),
+    attempts int NOT NULL DEFAULT 0,
+    max_attempts int NOT NULL DEFAULT 8,
+    next_attempt_at timestamptz NOT NULL,
+    locked_at timestamptz NULL, -- Waktu diklaim worker, untuk mengambil ulang pesan yang macet
+    last_error text NULL,
+    sent_at timestamptz NULL,
+
+    -- Metadata
+    deleted bool NOT NULL DEFAULT false,
+    data_hash varchar NOT NULL DEFAULT '-',
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NULL,
+
+    CONSTRAINT email_outbox_pkey PRIMARY KEY (id)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_email_outbox_status_next_attempt ON email_outbox(status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_email_outbox_order_id ON email_outbox(order_id);
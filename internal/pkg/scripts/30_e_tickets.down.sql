@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_e_tickets_registrant_id;
+DROP INDEX IF EXISTS idx_e_tickets_event_id;
+DROP INDEX IF EXISTS idx_e_tickets_order_id;
+DROP TABLE IF EXISTS e_tickets;
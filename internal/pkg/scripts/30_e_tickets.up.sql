@@ -0,0 +1,59 @@
+-- e_tickets table
+-- Satu e-ticket per kursi (registrant dan setiap attendee) dengan kode unik masing-masing
+
+DROP TABLE IF EXISTS e_tickets;
+
+CREATE TABLE e_tickets (
+    id uuid NOT NULL,
+    event_id uuid NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    order_id uuid NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    registrant_id uuid NOT NULL REFERENCES registrants(id) ON DELETE CASCADE,
+    attendee_id uuid NULL REFERENCES attendees(id) ON DELETE CASCADE, -- NULL = kursi milik registrant
+    ticket_id uuid NOT NULL REFERENCES tickets(id),
+
+    holder_name varchar(255) NOT NULL,
+    seat_number int NOT NULL,
+    code varchar(64) NOT NULL,
+
+    -- Check-in
+    checked_in bool NOT NULL DEFAULT false,
+    checked_in_at timestamptz NULL,
+
+    -- Metadata
+    deleted bool NOT NULL DEFAULT false,
+    data_hash varchar NOT NULL DEFAULT '-',
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NULL,
+
+    CONSTRAINT e_tickets_pkey PRIMARY KEY (id),
+    CONSTRAINT e_tickets_code_key UNIQUE (code),
+    CONSTRAINT e_tickets_order_seat_key UNIQUE (order_id, seat_number)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_e_tickets_order_id ON e_tickets(order_id);
+CREATE INDEX IF NOT EXISTS idx_e_tickets_event_id ON e_tickets(event_id);
+CREATE INDEX IF NOT EXISTS idx_e_tickets_registrant_id ON e_tickets(registrant_id);
+
+-- Backfill order yang sudah dibayar. Status check-in lama berlaku untuk seluruh grup.
+INSERT INTO e_tickets (id, event_id, order_id, registrant_id, attendee_id, ticket_id, holder_name, seat_number, code, checked_in, checked_in_at, created_at)
+SELECT
+    gen_random_uuid(), o.event_id, o.id, r.id, NULL, r.ticket_id, r.name, 1,
+    'ET' || upper(replace(gen_random_uuid()::text, '-', '')),
+    COALESCE(r.checked_in, false), r.checked_in_at, now()
+FROM orders o
+JOIN registrants r ON r.id = o.registrant_id
+WHERE o.payment_status = 'paid'
+AND r.ticket_id IS NOT NULL;
+
+INSERT INTO e_tickets (id, event_id, order_id, registrant_id, attendee_id, ticket_id, holder_name, seat_number, code, checked_in, checked_in_at, created_at)
+SELECT
+    gen_random_uuid(), o.event_id, o.id, r.id, a.id, a.ticket_id, a.name,
+    (CASE WHEN r.ticket_id IS NOT NULL THEN 1 ELSE 0 END)
+        + ROW_NUMBER() OVER (PARTITION BY a.registrant_id ORDER BY a.created_at, a.id),
+    'ET' || upper(replace(gen_random_uuid()::text, '-', '')),
+    COALESCE(r.checked_in, false), r.checked_in_at, now()
+FROM orders o
+JOIN registrants r ON r.id = o.registrant_id
+JOIN attendees a ON a.registrant_id = r.id AND a.deleted = false
+WHERE o.payment_status = 'paid';
@@ -0,0 +1,7 @@
+ALTER TABLE gate_logs DROP COLUMN IF EXISTS qr_code;
+
+DELETE FROM gate_logs WHERE event_id IS NULL OR physical_ticket_id IS NULL;
+ALTER TABLE gate_logs ALTER COLUMN physical_ticket_id SET NOT NULL;
+ALTER TABLE gate_logs ALTER COLUMN event_id SET NOT NULL;
+
+ALTER TABLE physical_tickets ALTER COLUMN qr_code TYPE varchar(50);
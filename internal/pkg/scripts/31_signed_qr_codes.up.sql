@@ -0,0 +1,9 @@
+-- Signed QR codes
+-- QR bertanda tangan lebih panjang dari format TKT lama, dan scan dengan kode palsu
+-- tetap dicatat di gate_logs walau tidak ada tiket/event yang bisa dirujuk.
+
+ALTER TABLE physical_tickets ALTER COLUMN qr_code TYPE varchar(255);
+
+ALTER TABLE gate_logs ALTER COLUMN event_id DROP NOT NULL;
+ALTER TABLE gate_logs ALTER COLUMN physical_ticket_id DROP NOT NULL;
+ALTER TABLE gate_logs ADD COLUMN IF NOT EXISTS qr_code varchar(255) NULL;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS gate_logs_device_client_scan;
+
+ALTER TABLE gate_logs DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE gate_logs DROP COLUMN IF EXISTS scanned_at;
+ALTER TABLE gate_logs DROP COLUMN IF EXISTS client_scan_id;
+ALTER TABLE gate_logs DROP COLUMN IF EXISTS device_id;
@@ -0,0 +1,12 @@
+-- Gate scan sync
+-- Scan offline dari perangkat gate diunggah belakangan; scanned_at adalah waktu di perangkat,
+-- (device_id, client_scan_id) membuat unggahan ulang batch yang sama idempoten.
+
+ALTER TABLE gate_logs ADD COLUMN IF NOT EXISTS device_id varchar(100) NULL;
+ALTER TABLE gate_logs ADD COLUMN IF NOT EXISTS client_scan_id varchar(100) NULL;
+ALTER TABLE gate_logs ADD COLUMN IF NOT EXISTS scanned_at timestamptz NULL;
+ALTER TABLE gate_logs ADD COLUMN IF NOT EXISTS updated_at timestamptz NULL;
+
+UPDATE gate_logs SET scanned_at = created_at WHERE scanned_at IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS gate_logs_device_client_scan ON gate_logs(device_id, client_scan_id) WHERE client_scan_id IS NOT NULL;
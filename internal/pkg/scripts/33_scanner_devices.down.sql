@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS scanner_devices_event_id;
+DROP TABLE IF EXISTS scanner_devices;
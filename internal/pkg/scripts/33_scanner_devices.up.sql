@@ -0,0 +1,33 @@
+-- scanner_devices table
+-- Perangkat scanner gate yang terdaftar; token hanya disimpan dalam bentuk hash
+
+DROP TABLE IF EXISTS scanner_devices;
+
+CREATE TABLE scanner_devices (
+    id uuid NOT NULL,
+    device_id varchar(100) NOT NULL,
+    name varchar(255) NOT NULL,
+
+    -- Penugasan
+    event_id uuid NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    gate_name varchar(50) NOT NULL,
+
+    -- Token (sha256 hex)
+    token_hash varchar(64) NOT NULL,
+    revoked_at timestamptz NULL,
+    last_seen_at timestamptz NULL,
+    created_by uuid NULL,
+
+    -- Metadata
+    deleted bool NOT NULL DEFAULT false,
+    data_hash varchar NOT NULL DEFAULT '-',
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NULL,
+
+    CONSTRAINT scanner_devices_pkey PRIMARY KEY (id),
+    CONSTRAINT scanner_devices_device_id_key UNIQUE (device_id),
+    CONSTRAINT scanner_devices_token_hash_key UNIQUE (token_hash)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS scanner_devices_event_id ON scanner_devices(event_id);
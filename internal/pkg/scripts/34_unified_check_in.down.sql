@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS gate_logs_e_ticket_id;
+
+ALTER TABLE gate_logs DROP COLUMN IF EXISTS e_ticket_id;
+
+ALTER TABLE e_tickets DROP COLUMN IF EXISTS checked_out_at;
+ALTER TABLE e_tickets DROP COLUMN IF EXISTS scan_count;
+ALTER TABLE e_tickets DROP COLUMN IF EXISTS status;
@@ -0,0 +1,13 @@
+-- Unified check-in
+-- E-ticket order dan tiket fisik melewati pipeline scan yang sama: aturan gate_configs
+-- (mode, max scan) berlaku untuk keduanya dan setiap percobaan scan tercatat di gate_logs.
+
+ALTER TABLE e_tickets ADD COLUMN IF NOT EXISTS status varchar(20) NOT NULL DEFAULT 'ACTIVE';
+ALTER TABLE e_tickets ADD COLUMN IF NOT EXISTS scan_count int NOT NULL DEFAULT 0;
+ALTER TABLE e_tickets ADD COLUMN IF NOT EXISTS checked_out_at timestamptz NULL;
+
+UPDATE e_tickets SET status = 'CHECKED_IN', scan_count = 1 WHERE checked_in = true AND scan_count = 0;
+
+ALTER TABLE gate_logs ADD COLUMN IF NOT EXISTS e_ticket_id uuid NULL REFERENCES e_tickets(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS gate_logs_e_ticket_id ON gate_logs(e_ticket_id);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS gate_logs_zone_id;
+
+ALTER TABLE gate_logs DROP COLUMN IF EXISTS zone_id;
+
+-- Nilai enum 'ACCESS_DENIED' tidak bisa dihapus dari gate_log_action_enum; log lama diubah menjadi INVALID
+UPDATE gate_logs SET action = 'INVALID' WHERE action = 'ACCESS_DENIED';
+
+DROP TABLE IF EXISTS gate_zone_access;
+DROP TABLE IF EXISTS gate_zone_gates;
+DROP TABLE IF EXISTS gate_zones;
@@ -0,0 +1,59 @@
+-- Gate zones
+-- Registry zona dan gate per event, serta matriks akses ticket type -> zona.
+-- gate_zone_gates.gate_name adalah nama gate yang sama dengan scanner_devices.gate_name dan gate_logs.gate_name.
+
+DROP TABLE IF EXISTS gate_zone_access;
+DROP TABLE IF EXISTS gate_zone_gates;
+DROP TABLE IF EXISTS gate_zones;
+
+CREATE TABLE gate_zones (
+    id uuid NOT NULL,
+    event_id uuid NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+
+    code varchar(50) NOT NULL,
+    name varchar(255) NOT NULL,
+    capacity int NULL,
+
+    -- Metadata
+    deleted bool NOT NULL DEFAULT false,
+    data_hash varchar NOT NULL DEFAULT '-',
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NULL,
+
+    CONSTRAINT gate_zones_pkey PRIMARY KEY (id),
+    CONSTRAINT gate_zones_event_code_key UNIQUE (event_id, code)
+);
+
+CREATE TABLE gate_zone_gates (
+    id uuid NOT NULL,
+    event_id uuid NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    zone_id uuid NOT NULL REFERENCES gate_zones(id) ON DELETE CASCADE,
+    gate_name varchar(50) NOT NULL,
+
+    created_at timestamptz NOT NULL,
+
+    CONSTRAINT gate_zone_gates_pkey PRIMARY KEY (id),
+    CONSTRAINT gate_zone_gates_event_gate_key UNIQUE (event_id, gate_name)
+);
+
+CREATE TABLE gate_zone_access (
+    id uuid NOT NULL,
+    event_id uuid NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    zone_id uuid NOT NULL REFERENCES gate_zones(id) ON DELETE CASCADE,
+    ticket_type varchar(50) NOT NULL,
+
+    created_at timestamptz NOT NULL,
+
+    CONSTRAINT gate_zone_access_pkey PRIMARY KEY (id),
+    CONSTRAINT gate_zone_access_event_type_zone_key UNIQUE (event_id, ticket_type, zone_id)
+);
+
+-- Zona tempat scan terjadi; tanpa FK agar riwayat log tetap utuh saat layout zona diganti
+ALTER TABLE gate_logs ADD COLUMN IF NOT EXISTS zone_id uuid NULL;
+
+ALTER TYPE gate_log_action_enum ADD VALUE IF NOT EXISTS 'ACCESS_DENIED';
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS gate_zone_gates_zone_id ON gate_zone_gates(zone_id);
+CREATE INDEX IF NOT EXISTS gate_zone_access_zone_id ON gate_zone_access(zone_id);
+CREATE INDEX IF NOT EXISTS gate_logs_zone_id ON gate_logs(zone_id);
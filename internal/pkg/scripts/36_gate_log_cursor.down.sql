@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS gate_logs_event_id_seq;
+DROP INDEX IF EXISTS gate_logs_seq;
+
+ALTER TABLE gate_logs DROP COLUMN IF EXISTS seq;
@@ -0,0 +1,7 @@
+-- Gate log cursor
+-- seq bertambah monoton per insert dan dipakai sebagai cursor feed gate live serta paging gate logs.
+
+ALTER TABLE gate_logs ADD COLUMN IF NOT EXISTS seq bigserial;
+
+CREATE UNIQUE INDEX IF NOT EXISTS gate_logs_seq ON gate_logs(seq);
+CREATE INDEX IF NOT EXISTS gate_logs_event_id_seq ON gate_logs(event_id, seq);
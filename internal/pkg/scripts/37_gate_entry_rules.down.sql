@@ -0,0 +1,7 @@
+-- Nilai enum 'OUTSIDE_WINDOW' dan 'COOLDOWN' tidak bisa dihapus dari gate_log_action_enum; log lama diubah menjadi INVALID
+UPDATE gate_logs SET action = 'INVALID' WHERE action IN ('OUTSIDE_WINDOW', 'COOLDOWN');
+
+ALTER TABLE gate_configs DROP COLUMN IF EXISTS reentry_cooldown_seconds;
+ALTER TABLE gate_configs DROP COLUMN IF EXISTS daily_reset_time;
+ALTER TABLE gate_configs DROP COLUMN IF EXISTS entry_windows;
+ALTER TABLE gate_configs DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,11 @@
+-- Gate entry rules
+-- Jadwal masuk per ticket type, batas scan harian dan jeda re-entry. Semua jam dihitung
+-- dalam gate_configs.timezone sehingga tiket harian festival multi-hari bisa direset tiap pagi.
+
+ALTER TABLE gate_configs ADD COLUMN IF NOT EXISTS timezone varchar(64) NOT NULL DEFAULT 'Asia/Jakarta';
+ALTER TABLE gate_configs ADD COLUMN IF NOT EXISTS entry_windows jsonb NULL;
+ALTER TABLE gate_configs ADD COLUMN IF NOT EXISTS daily_reset_time varchar(5) NULL;
+ALTER TABLE gate_configs ADD COLUMN IF NOT EXISTS reentry_cooldown_seconds int NOT NULL DEFAULT 0;
+
+ALTER TYPE gate_log_action_enum ADD VALUE IF NOT EXISTS 'OUTSIDE_WINDOW';
+ALTER TYPE gate_log_action_enum ADD VALUE IF NOT EXISTS 'COOLDOWN';
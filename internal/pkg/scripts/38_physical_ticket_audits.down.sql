@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_physical_tickets_replaces_id;
+ALTER TABLE physical_tickets DROP COLUMN IF EXISTS replaces_id;
+
+DROP TABLE IF EXISTS physical_ticket_audits;
+DROP TYPE IF EXISTS physical_ticket_audit_action_enum;
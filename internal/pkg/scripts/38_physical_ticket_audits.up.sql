@@ -0,0 +1,50 @@
+-- physical_ticket_audits table
+-- Audit trail perubahan tiket fisik oleh admin (void, ganti, pindah ticket type, reset scan),
+-- berdampingan dengan gate_logs yang mencatat scan.
+
+DROP TABLE IF EXISTS physical_ticket_audits;
+DROP TYPE IF EXISTS physical_ticket_audit_action_enum;
+
+CREATE TYPE physical_ticket_audit_action_enum AS ENUM (
+    'VOID',
+    'REPLACE',
+    'REISSUE',
+    'REASSIGN',
+    'RESET_SCAN'
+);
+
+CREATE TABLE physical_ticket_audits (
+    id uuid NOT NULL,
+    event_id uuid NOT NULL,
+
+    -- Reference
+    physical_ticket_id uuid NOT NULL,
+    related_ticket_id uuid NULL,
+
+    -- Action
+    action physical_ticket_audit_action_enum NOT NULL,
+
+    -- Before / after
+    from_status physical_ticket_status_enum NOT NULL,
+    to_status physical_ticket_status_enum NOT NULL,
+    from_ticket_type varchar(50) NOT NULL,
+    to_ticket_type varchar(50) NOT NULL,
+    from_scan_count int NOT NULL,
+    to_scan_count int NOT NULL,
+
+    reason varchar(255) NULL,
+    performed_by varchar(100) NOT NULL,
+
+    -- Metadata
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT physical_ticket_audits_pkey PRIMARY KEY (id)
+);
+
+-- Tiket pengganti menunjuk tiket lama yang digantikannya
+ALTER TABLE physical_tickets ADD COLUMN IF NOT EXISTS replaces_id uuid NULL REFERENCES physical_tickets(id);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS physical_ticket_audits_event_id ON physical_ticket_audits(event_id);
+CREATE INDEX IF NOT EXISTS physical_ticket_audits_physical_ticket_id ON physical_ticket_audits(physical_ticket_id);
+CREATE INDEX IF NOT EXISTS idx_physical_tickets_replaces_id ON physical_tickets(replaces_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_physical_tickets_batch_id;
+ALTER TABLE physical_tickets DROP COLUMN IF EXISTS serial;
+ALTER TABLE physical_tickets DROP COLUMN IF EXISTS batch_seq;
+ALTER TABLE physical_tickets DROP COLUMN IF EXISTS batch_id;
+
+DROP TABLE IF EXISTS physical_ticket_batches;
@@ -0,0 +1,35 @@
+-- physical_ticket_batches table
+-- Satu batch = satu kali generate tiket fisik untuk dicetak vendor. Tiket menyimpan batch_id,
+-- urutan cetak per ticket type (batch_seq) dan serial yang dicetak di bawah QR.
+
+DROP TABLE IF EXISTS physical_ticket_batches;
+
+CREATE TABLE physical_ticket_batches (
+    id uuid NOT NULL,
+    event_id uuid NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+
+    -- Print info
+    vendor varchar(255) NOT NULL,
+    print_date date NULL,
+
+    -- Jumlah tiket per ticket type saat batch dibuat
+    ticket_counts jsonb NOT NULL DEFAULT '{}',
+
+    created_by varchar(100) NOT NULL,
+
+    -- Metadata
+    deleted bool NOT NULL DEFAULT false,
+    data_hash varchar NOT NULL DEFAULT '-',
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NULL,
+
+    CONSTRAINT physical_ticket_batches_pkey PRIMARY KEY (id)
+);
+
+ALTER TABLE physical_tickets ADD COLUMN IF NOT EXISTS batch_id uuid NULL REFERENCES physical_ticket_batches(id);
+ALTER TABLE physical_tickets ADD COLUMN IF NOT EXISTS batch_seq int NULL;
+ALTER TABLE physical_tickets ADD COLUMN IF NOT EXISTS serial varchar(64) NULL;
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_physical_ticket_batches_event_id ON physical_ticket_batches(event_id);
+CREATE INDEX IF NOT EXISTS idx_physical_tickets_batch_id ON physical_tickets(batch_id);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_physical_tickets_event_serial;
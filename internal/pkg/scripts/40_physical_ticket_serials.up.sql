@@ -0,0 +1,4 @@
+-- Physical ticket serials
+-- Serial tiket fisik (kode PT-XXXX-XXXX-XXC yang bisa diketik staf) unik per event.
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_physical_tickets_event_serial ON physical_tickets(event_id, serial) WHERE serial IS NOT NULL;
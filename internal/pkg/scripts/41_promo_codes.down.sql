@@ -0,0 +1,13 @@
+-- Rollback promo_codes & promo_redemptions table
+
+ALTER TABLE orders DROP COLUMN IF EXISTS promo_code;
+ALTER TABLE orders DROP COLUMN IF EXISTS discount_amount;
+
+DROP INDEX IF EXISTS promo_redemptions_email;
+DROP INDEX IF EXISTS promo_redemptions_promo_code_id;
+DROP INDEX IF EXISTS promo_codes_code_key;
+
+DROP TABLE IF EXISTS promo_redemptions;
+DROP TABLE IF EXISTS promo_codes;
+DROP TYPE IF EXISTS promo_redemption_status_enum;
+DROP TYPE IF EXISTS promo_discount_type_enum;
@@ -0,0 +1,81 @@
+-- promo_codes & promo_redemptions table
+-- Kode promo / voucher yang dipakai saat registrasi. Pemakaian dicatat per order
+-- dan dilepas kembali saat order expired / gagal bersama stok tiket.
+
+DROP TABLE IF EXISTS promo_redemptions;
+DROP TABLE IF EXISTS promo_codes;
+DROP TYPE IF EXISTS promo_redemption_status_enum;
+DROP TYPE IF EXISTS promo_discount_type_enum;
+
+CREATE TYPE promo_discount_type_enum AS ENUM (
+    'PERCENT',
+    'FIXED'
+);
+
+CREATE TYPE promo_redemption_status_enum AS ENUM (
+    'ACTIVE',
+    'RELEASED'
+);
+
+CREATE TABLE promo_codes (
+    id uuid NOT NULL,
+    code varchar(64) NOT NULL,
+    description text NULL,
+
+    -- Discount
+    discount_type promo_discount_type_enum NOT NULL,
+    discount_value numeric(12, 2) NOT NULL,
+    max_discount numeric(12, 2) NULL,
+
+    -- Scope (kosong = berlaku untuk semua)
+    event_ids jsonb NOT NULL DEFAULT '[]',
+    ticket_types jsonb NOT NULL DEFAULT '[]',
+
+    -- Limit
+    max_uses int NULL,
+    max_uses_per_email int NULL,
+    used_count int NOT NULL DEFAULT 0,
+    min_qty int NOT NULL DEFAULT 1,
+
+    -- Validity
+    starts_at timestamptz NULL,
+    ends_at timestamptz NULL,
+    is_active bool NOT NULL DEFAULT true,
+
+    -- Metadata
+    deleted bool NOT NULL DEFAULT false,
+    data_hash varchar DEFAULT '-',
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    updated_at timestamptz NULL,
+
+    CONSTRAINT promo_codes_pkey PRIMARY KEY (id),
+    CONSTRAINT promo_codes_used_count_check CHECK (used_count >= 0)
+);
+
+CREATE TABLE promo_redemptions (
+    id uuid NOT NULL,
+
+    -- Relation
+    promo_code_id uuid NOT NULL REFERENCES promo_codes(id),
+    order_id uuid NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+
+    email varchar(255) NOT NULL,
+    discount_amount numeric(12, 2) NOT NULL,
+    status promo_redemption_status_enum NOT NULL DEFAULT 'ACTIVE',
+    released_at timestamptz NULL,
+
+    -- Metadata
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT promo_redemptions_pkey PRIMARY KEY (id),
+    CONSTRAINT promo_redemptions_order_id_key UNIQUE (order_id)
+);
+
+-- Potongan harga tersimpan di order agar item gateway (harga tiket - diskon) sama dengan amount
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS discount_amount numeric(12, 2) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS promo_code varchar(64) NULL;
+
+-- Indexes
+CREATE UNIQUE INDEX IF NOT EXISTS promo_codes_code_key ON promo_codes(UPPER(code)) WHERE deleted = false;
+CREATE INDEX IF NOT EXISTS promo_redemptions_promo_code_id ON promo_redemptions(promo_code_id);
+CREATE INDEX IF NOT EXISTS promo_redemptions_email ON promo_redemptions(promo_code_id, email) WHERE status = 'ACTIVE';
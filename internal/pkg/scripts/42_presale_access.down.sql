@@ -0,0 +1,9 @@
+-- Rollback presale_access_codes, presale_allowlists & presale_allowlist_emails table
+
+DROP INDEX IF EXISTS presale_allowlist_emails_email;
+DROP INDEX IF EXISTS presale_allowlists_event_id;
+DROP INDEX IF EXISTS presale_access_codes_code_key;
+
+DROP TABLE IF EXISTS presale_allowlist_emails;
+DROP TABLE IF EXISTS presale_allowlists;
+DROP TABLE IF EXISTS presale_access_codes;
@@ -0,0 +1,63 @@
+-- presale_access_codes, presale_allowlists & presale_allowlist_emails table
+-- Tiket presale (tickets.is_presale) hanya terlihat dan bisa dibeli dengan kode akses
+-- atau email yang terdaftar di allowlist event (mis. member fan club).
+
+DROP TABLE IF EXISTS presale_allowlist_emails;
+DROP TABLE IF EXISTS presale_allowlists;
+DROP TABLE IF EXISTS presale_access_codes;
+
+CREATE TABLE presale_access_codes (
+    id uuid NOT NULL,
+
+    -- Relation
+    event_id uuid NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+
+    code varchar(64) NOT NULL,
+    description text NULL,
+
+    -- Scope (kosong = semua tiket presale event)
+    ticket_ids jsonb NOT NULL DEFAULT '[]',
+
+    is_active bool NOT NULL DEFAULT true,
+
+    -- Metadata
+    deleted bool NOT NULL DEFAULT false,
+    data_hash varchar DEFAULT '-',
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    updated_at timestamptz NULL,
+
+    CONSTRAINT presale_access_codes_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE presale_allowlists (
+    id uuid NOT NULL,
+
+    -- Relation
+    event_id uuid NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+
+    name varchar(255) NOT NULL,
+
+    -- Scope (kosong = semua tiket presale event)
+    ticket_ids jsonb NOT NULL DEFAULT '[]',
+
+    -- Metadata
+    deleted bool NOT NULL DEFAULT false,
+    data_hash varchar DEFAULT '-',
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    updated_at timestamptz NULL,
+
+    CONSTRAINT presale_allowlists_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE presale_allowlist_emails (
+    allowlist_id uuid NOT NULL REFERENCES presale_allowlists(id) ON DELETE CASCADE,
+    email varchar(255) NOT NULL, -- selalu huruf kecil
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT presale_allowlist_emails_pkey PRIMARY KEY (allowlist_id, email)
+);
+
+-- Indexes
+CREATE UNIQUE INDEX IF NOT EXISTS presale_access_codes_code_key ON presale_access_codes(event_id, UPPER(code)) WHERE deleted = false;
+CREATE INDEX IF NOT EXISTS presale_allowlists_event_id ON presale_allowlists(event_id) WHERE deleted = false;
+CREATE INDEX IF NOT EXISTS presale_allowlist_emails_email ON presale_allowlist_emails(email);
@@ -0,0 +1,10 @@
+-- Rollback waitlist_entries table
+
+DROP INDEX IF EXISTS waitlist_entries_event_id;
+DROP INDEX IF EXISTS waitlist_entries_offer_expires_at;
+DROP INDEX IF EXISTS waitlist_entries_queue;
+DROP INDEX IF EXISTS waitlist_entries_active_email_key;
+DROP INDEX IF EXISTS waitlist_entries_claim_token_hash_key;
+DROP INDEX IF EXISTS waitlist_entries_token_hash_key;
+
+DROP TABLE IF EXISTS waitlist_entries;
@@ -0,0 +1,44 @@
+-- waitlist_entries table
+-- Antrean pembeli untuk tiket yang habis. Stok yang dilepas (order expired / ditolak / refund)
+-- ditawarkan ke antrean terdepan dengan link klaim yang menahan stok selama waktu tertentu.
+
+DROP TABLE IF EXISTS waitlist_entries;
+
+CREATE TABLE waitlist_entries (
+    id uuid NOT NULL,
+
+    -- Relation
+    event_id uuid NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    ticket_id uuid NOT NULL REFERENCES tickets(id) ON DELETE CASCADE,
+
+    email varchar(255) NOT NULL, -- selalu huruf kecil
+    name varchar(255) NOT NULL,
+    quantity int NOT NULL CHECK (quantity > 0),
+
+    -- WAITING, OFFERED, CLAIMED, EXPIRED, CANCELLED
+    status varchar(20) NOT NULL DEFAULT 'WAITING',
+
+    -- Hanya hash SHA-256 token yang disimpan
+    token_hash varchar(64) NOT NULL,
+    claim_token_hash varchar(64) NULL,
+
+    -- Penawaran (stok ditahan sebanyak quantity selama OFFERED)
+    offered_at timestamptz NULL,
+    offer_expires_at timestamptz NULL,
+    claimed_at timestamptz NULL,
+    order_id uuid NULL REFERENCES orders(id) ON DELETE SET NULL,
+
+    -- Metadata
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    updated_at timestamptz NULL,
+
+    CONSTRAINT waitlist_entries_pkey PRIMARY KEY (id)
+);
+
+-- Indexes
+CREATE UNIQUE INDEX IF NOT EXISTS waitlist_entries_token_hash_key ON waitlist_entries(token_hash);
+CREATE UNIQUE INDEX IF NOT EXISTS waitlist_entries_claim_token_hash_key ON waitlist_entries(claim_token_hash) WHERE claim_token_hash IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS waitlist_entries_active_email_key ON waitlist_entries(ticket_id, email) WHERE status IN ('WAITING', 'OFFERED');
+CREATE INDEX IF NOT EXISTS waitlist_entries_queue ON waitlist_entries(ticket_id, created_at) WHERE status = 'WAITING';
+CREATE INDEX IF NOT EXISTS waitlist_entries_offer_expires_at ON waitlist_entries(offer_expires_at) WHERE status = 'OFFERED';
+CREATE INDEX IF NOT EXISTS waitlist_entries_event_id ON waitlist_entries(event_id);
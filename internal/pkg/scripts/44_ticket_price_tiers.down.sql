@@ -0,0 +1,14 @@
+-- Rollback ticket_price_tiers table
+
+DROP INDEX IF EXISTS order_items_tier_id;
+DROP INDEX IF EXISTS order_items_order_ticket_tier_key;
+
+-- Gagal jika sudah ada order yang terpecah ke beberapa tier; item tersebut perlu digabung manual dulu
+ALTER TABLE order_items ADD CONSTRAINT order_items_order_ticket_key UNIQUE (order_id, ticket_id);
+
+ALTER TABLE order_items DROP COLUMN IF EXISTS tier_name;
+ALTER TABLE order_items DROP COLUMN IF EXISTS tier_id;
+
+DROP INDEX IF EXISTS ticket_price_tiers_ticket_id;
+
+DROP TABLE IF EXISTS ticket_price_tiers;
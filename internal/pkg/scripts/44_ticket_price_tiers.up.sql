@@ -0,0 +1,39 @@
+-- ticket_price_tiers table
+-- Tier harga berurutan dalam satu kategori tiket (Early Bird -> Presale 1 -> Normal).
+-- Tier aktif adalah tier pertama yang belum berakhir; tier berikutnya otomatis bisa dibeli.
+
+DROP TABLE IF EXISTS ticket_price_tiers;
+
+CREATE TABLE ticket_price_tiers (
+    id uuid NOT NULL,
+
+    -- Relation
+    ticket_id uuid NOT NULL REFERENCES tickets(id) ON DELETE CASCADE,
+
+    tier_order int NOT NULL,
+    name varchar(100) NOT NULL,
+    price numeric(12, 2) NOT NULL CHECK (price >= 0),
+
+    -- Tier berakhir saat booked_qty + sold_qty tiket mencapai end_sold_qty (kumulatif)
+    -- atau saat end_at terlewati, mana yang lebih dulu
+    end_sold_qty int NULL CHECK (end_sold_qty > 0),
+    end_at timestamptz NULL,
+
+    -- Metadata
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    updated_at timestamptz NULL,
+
+    CONSTRAINT ticket_price_tiers_pkey PRIMARY KEY (id),
+    CONSTRAINT ticket_price_tiers_ticket_order_key UNIQUE (ticket_id, tier_order) DEFERRABLE INITIALLY DEFERRED
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS ticket_price_tiers_ticket_id ON ticket_price_tiers(ticket_id);
+
+-- Snapshot tier pada item order; satu jenis tiket bisa terpecah ke beberapa tier dalam satu order
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS tier_id uuid NULL REFERENCES ticket_price_tiers(id) ON DELETE SET NULL;
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS tier_name varchar(100) NULL;
+
+ALTER TABLE order_items DROP CONSTRAINT IF EXISTS order_items_order_ticket_key;
+CREATE UNIQUE INDEX IF NOT EXISTS order_items_order_ticket_tier_key ON order_items(order_id, ticket_id, COALESCE(tier_name, ''));
+CREATE INDEX IF NOT EXISTS order_items_tier_id ON order_items(tier_id) WHERE tier_id IS NOT NULL;
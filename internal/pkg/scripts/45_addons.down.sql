@@ -0,0 +1,10 @@
+-- Rollback addons, addon_variants & order_addons table
+
+DROP INDEX IF EXISTS order_addons_variant_id;
+DROP INDEX IF EXISTS order_addons_order_id;
+DROP INDEX IF EXISTS addon_variants_addon_id;
+DROP INDEX IF EXISTS addons_event_id;
+
+DROP TABLE IF EXISTS order_addons;
+DROP TABLE IF EXISTS addon_variants;
+DROP TABLE IF EXISTS addons;
@@ -0,0 +1,98 @@
+-- addons, addon_variants & order_addons table
+-- Produk tambahan per event (merchandise, parkir, shuttle) yang dibeli bersama tiket.
+-- Stok dihitung per varian (mis. ukuran kaos) dengan siklus booked -> sold yang sama dengan tiket.
+
+DROP TABLE IF EXISTS order_addons;
+DROP TABLE IF EXISTS addon_variants;
+DROP TABLE IF EXISTS addons;
+
+CREATE TABLE addons (
+    id uuid NOT NULL,
+
+    -- Relation
+    event_id uuid NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+
+    name varchar(150) NOT NULL,
+    description text NULL,
+    -- MERCH, PARKING, SHUTTLE, OTHER
+    type varchar(20) NOT NULL DEFAULT 'MERCH',
+
+    -- Batas quantity semua varian add-on ini dalam satu order; NULL = tanpa batas
+    max_per_order int NULL CHECK (max_per_order > 0),
+    order_priority int NOT NULL DEFAULT 0,
+    is_active bool NOT NULL DEFAULT true,
+
+    -- Metadata
+    deleted bool NOT NULL DEFAULT false,
+    data_hash varchar DEFAULT '-',
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    updated_at timestamptz NULL,
+
+    CONSTRAINT addons_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE addon_variants (
+    id uuid NOT NULL,
+
+    -- Relation
+    addon_id uuid NOT NULL REFERENCES addons(id) ON DELETE CASCADE,
+
+    name varchar(100) NOT NULL,
+    price numeric(12, 2) NOT NULL CHECK (price >= 0),
+    variant_order int NOT NULL DEFAULT 0,
+    is_active bool NOT NULL DEFAULT true,
+
+    -- Stock
+    total int NOT NULL,
+    available_qty int NOT NULL,
+    booked_qty int NOT NULL DEFAULT 0,
+    sold_qty int NOT NULL DEFAULT 0,
+
+    -- Metadata
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    updated_at timestamptz NULL,
+
+    CONSTRAINT addon_variants_pkey PRIMARY KEY (id),
+    CONSTRAINT addon_variants_qty_non_negative CHECK (
+        total >= 0 AND
+        available_qty >= 0 AND
+        booked_qty >= 0 AND
+        sold_qty >= 0
+    )
+);
+
+CREATE TABLE order_addons (
+    id uuid NOT NULL,
+
+    -- Relation
+    order_id uuid NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    addon_id uuid NOT NULL REFERENCES addons(id),
+    variant_id uuid NOT NULL REFERENCES addon_variants(id),
+
+    -- Snapshot saat registrasi
+    addon_name varchar(150) NOT NULL,
+    variant_name varchar(100) NOT NULL,
+    addon_type varchar(20) NOT NULL,
+    quantity int NOT NULL CHECK (quantity > 0),
+    unit_price numeric(12, 2) NOT NULL,
+    subtotal numeric(12, 2) NOT NULL,
+
+    -- Pengambilan di booth merchandise (scan QR pickup)
+    redeemed_qty int NOT NULL DEFAULT 0,
+    redeemed_at timestamptz NULL,
+    redeemed_by varchar(100) NULL,
+
+    -- Metadata
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    updated_at timestamptz NULL,
+
+    CONSTRAINT order_addons_pkey PRIMARY KEY (id),
+    CONSTRAINT order_addons_order_variant_key UNIQUE (order_id, variant_id),
+    CONSTRAINT order_addons_redeemed_qty_check CHECK (redeemed_qty >= 0 AND redeemed_qty <= quantity)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS addons_event_id ON addons(event_id) WHERE deleted = false;
+CREATE INDEX IF NOT EXISTS addon_variants_addon_id ON addon_variants(addon_id);
+CREATE INDEX IF NOT EXISTS order_addons_order_id ON order_addons(order_id);
+CREATE INDEX IF NOT EXISTS order_addons_variant_id ON order_addons(variant_id);
@@ -0,0 +1 @@
+ALTER TABLE physical_tickets DROP COLUMN IF EXISTS scans_reset_at;
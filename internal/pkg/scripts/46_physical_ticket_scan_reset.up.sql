@@ -0,0 +1,4 @@
+-- Physical ticket scan reset
+-- Waktu reset scan terakhir; gate_logs sebelum waktu ini tidak lagi dihitung saat sync offline / batas harian.
+
+ALTER TABLE physical_tickets ADD COLUMN IF NOT EXISTS scans_reset_at timestamptz DEFAULT NULL;
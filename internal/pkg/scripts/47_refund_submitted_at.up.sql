@@ -0,0 +1,5 @@
+-- Refund submitted_at
+-- Refund gateway dicatat PENDING sebelum request ke gateway; submitted_at diisi setelah gateway menerima refund.
+-- Refund PENDING tanpa submitted_at dikirim ulang dengan refund_key yang sama.
+
+ALTER TABLE refunds ADD COLUMN IF NOT EXISTS submitted_at timestamptz DEFAULT NULL;
@@ -0,0 +1,100 @@
+package ticketcode
+
+import (
+	"crypto/rand"
+	"errors"
+	"strings"
+)
+
+// Kode tiket fisik yang dicetak di bawah QR dan bisa diketik staf saat QR rusak:
+// PT-XXXX-XXXX-XXC, 10 simbol acak (50 bit) dari alfabet Crockford base32 ditambah 1 check digit Luhn mod 32.
+const (
+	Prefix = "PT"
+
+	alphabet    = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	randomLen   = 10
+	groupLength = 4
+)
+
+var (
+	ErrNotTicketCode = errors.New("bukan kode tiket fisik")
+	ErrCheckDigit    = errors.New("check digit kode tiket tidak cocok")
+)
+
+// Generate membuat kode baru dalam bentuk kanonik (huruf besar, dengan tanda hubung)
+func Generate() (string, error) {
+	random := make([]byte, randomLen)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	// 256 habis dibagi 32 sehingga setiap simbol terdistribusi rata
+	body := make([]byte, randomLen)
+	for i, b := range random {
+		body[i] = alphabet[b&31]
+	}
+
+	return format(string(body) + string(checkSymbol(string(body)))), nil
+}
+
+// Parse menormalkan kode yang diketik (huruf kecil, spasi, tanpa tanda hubung, O/I/L) ke bentuk kanonik.
+// ErrNotTicketCode berarti input bukan kode tiket fisik; ErrCheckDigit berarti kemungkinan salah ketik.
+func Parse(input string) (string, error) {
+	cleaned := strings.Map(func(r rune) rune {
+		switch r {
+		case '-', ' ', '\t':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(input)))
+
+	if !strings.HasPrefix(cleaned, Prefix) || len(cleaned) != len(Prefix)+randomLen+1 {
+		return "", ErrNotTicketCode
+	}
+
+	symbols := []byte(cleaned[len(Prefix):])
+	for i, c := range symbols {
+		switch c {
+		case 'O':
+			c = '0'
+		case 'I', 'L':
+			c = '1'
+		}
+		if strings.IndexByte(alphabet, c) < 0 {
+			return "", ErrNotTicketCode
+		}
+		symbols[i] = c
+	}
+
+	body := string(symbols[:randomLen])
+	if checkSymbol(body) != symbols[randomLen] {
+		return "", ErrCheckDigit
+	}
+
+	return format(string(symbols)), nil
+}
+
+// checkSymbol adalah Luhn mod 32: mendeteksi semua salah ketik satu simbol dan hampir semua pertukaran dua simbol berdekatan
+func checkSymbol(body string) byte {
+	n := len(alphabet)
+	factor := 2
+	sum := 0
+	for i := len(body) - 1; i >= 0; i-- {
+		addend := factor * strings.IndexByte(alphabet, body[i])
+		sum += addend/n + addend%n
+		factor = 3 - factor
+	}
+	return alphabet[(n-sum%n)%n]
+}
+
+func format(symbols string) string {
+	parts := []string{Prefix}
+	for start := 0; start < len(symbols); start += groupLength {
+		end := start + groupLength
+		if end > len(symbols) {
+			end = len(symbols)
+		}
+		parts = append(parts, symbols[start:end])
+	}
+	return strings.Join(parts, "-")
+}
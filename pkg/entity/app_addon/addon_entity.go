@@ -0,0 +1,137 @@
+package app_addon
+
+import (
+	"time"
+
+	pubEntity "rakit-tiket-be/pkg/entity"
+)
+
+type AddonType string
+
+const (
+	AddonTypeMerch   AddonType = "MERCH"
+	AddonTypeParking AddonType = "PARKING"
+	AddonTypeShuttle AddonType = "SHUTTLE"
+	AddonTypeOther   AddonType = "OTHER"
+)
+
+type (
+	AddonQuery struct {
+		IDs      []string `query:"id"`
+		EventIDs []string `query:"event_id"`
+		IsActive *bool    `query:"is_active"`
+	}
+
+	// Addon adalah produk tambahan per event yang dibeli bersama tiket; stok dan harga ada di varian
+	Addon struct {
+		ID          pubEntity.UUID `json:"id"`
+		EventID     pubEntity.UUID `json:"event_id"`
+		Name        string         `json:"name"`
+		Description *string        `json:"description"`
+		Type        AddonType      `json:"type"`
+
+		// MaxPerOrder membatasi total quantity semua varian dalam satu order; nil berarti tanpa batas
+		MaxPerOrder   *int `json:"max_per_order"`
+		OrderPriority int  `json:"order_priority"`
+		IsActive      bool `json:"is_active"`
+
+		Variants AddonVariants `json:"variants"`
+
+		pubEntity.DaoEntity
+	}
+
+	Addons []Addon
+)
+
+type (
+	AddonVariantQuery struct {
+		IDs      []string `query:"id"`
+		AddonIDs []string `query:"addon_id"`
+	}
+
+	// AddonVariant adalah pilihan add-on (mis. ukuran kaos) dengan stok booked -> sold seperti tiket
+	AddonVariant struct {
+		ID      pubEntity.UUID `json:"id"`
+		AddonID pubEntity.UUID `json:"addon_id"`
+
+		Name         string  `json:"name"`
+		Price        float64 `json:"price"`
+		VariantOrder int     `json:"variant_order"`
+		IsActive     bool    `json:"is_active"`
+
+		Total        int `json:"total"`
+		AvailableQty int `json:"available_qty"`
+		BookedQty    int `json:"booked_qty"`
+		SoldQty      int `json:"sold_qty"`
+
+		CreatedAt time.Time  `json:"created_at"`
+		UpdatedAt *time.Time `json:"updated_at"`
+	}
+
+	AddonVariants []AddonVariant
+)
+
+// AddonSelection adalah add-on yang dipilih pembeli saat registrasi
+type AddonSelection struct {
+	VariantID pubEntity.UUID `json:"variant_id"`
+	Quantity  int            `json:"quantity"`
+}
+
+type (
+	OrderAddonQuery struct {
+		IDs        []string `query:"id"`
+		OrderIDs   []string `query:"order_id"`
+		VariantIDs []string `query:"variant_id"`
+	}
+
+	// OrderAddon adalah snapshot add-on yang dibeli satu order, termasuk progres pengambilannya
+	OrderAddon struct {
+		ID        pubEntity.UUID `json:"id"`
+		OrderID   pubEntity.UUID `json:"order_id"`
+		AddonID   pubEntity.UUID `json:"addon_id"`
+		VariantID pubEntity.UUID `json:"variant_id"`
+
+		AddonName   string    `json:"addon_name"`
+		VariantName string    `json:"variant_name"`
+		AddonType   AddonType `json:"addon_type"`
+		Quantity    int       `json:"quantity"`
+		UnitPrice   float64   `json:"unit_price"`
+		Subtotal    float64   `json:"subtotal"`
+
+		RedeemedQty int        `json:"redeemed_qty"`
+		RedeemedAt  *time.Time `json:"redeemed_at"`
+		RedeemedBy  *string    `json:"redeemed_by"`
+
+		CreatedAt time.Time  `json:"created_at"`
+		UpdatedAt *time.Time `json:"updated_at"`
+
+		// Hanya dibaca (join orders), dipakai validasi scan pickup
+		EventID       pubEntity.UUID `json:"event_id"`
+		OrderNumber   string         `json:"order_number"`
+		PaymentStatus string         `json:"payment_status"`
+	}
+
+	OrderAddons []OrderAddon
+)
+
+// DisplayName menggabungkan nama add-on dan varian, mis. "Kaos Official - L"
+func (a OrderAddon) DisplayName() string {
+	if a.VariantName == "" {
+		return a.AddonName
+	}
+	return a.AddonName + " - " + a.VariantName
+}
+
+// RemainingQty adalah quantity yang belum diambil di booth pickup
+func (a OrderAddon) RemainingQty() int {
+	return a.Quantity - a.RedeemedQty
+}
+
+// Total menjumlahkan subtotal semua add-on order
+func (a OrderAddons) Total() float64 {
+	var total float64
+	for _, addon := range a {
+		total += addon.Subtotal
+	}
+	return total
+}
@@ -17,6 +17,14 @@ type GateConfigQuery struct {
 	IsActive *bool    `query:"is_active"`
 }
 
+// EntryWindow adalah rentang jam masuk pada satu tanggal dalam zona waktu event.
+// End yang tidak lebih besar dari Start berarti window berakhir keesokan harinya.
+type EntryWindow struct {
+	Date  string `json:"date"`  // YYYY-MM-DD
+	Start string `json:"start"` // HH:MM
+	End   string `json:"end"`   // HH:MM
+}
+
 type GateConfig struct {
 	ID      pubEntity.UUID `json:"id"`
 	EventID pubEntity.UUID `json:"event_id"`
@@ -26,6 +34,14 @@ type GateConfig struct {
 
 	MaxScanByType map[string]int `json:"max_scan_by_type"`
 
+	// Aturan waktu masuk, dihitung dalam Timezone (nama IANA)
+	Timezone string `json:"timezone"`
+	// EntryWindows per ticket type; ticket type tanpa window boleh masuk kapan saja
+	EntryWindows map[string][]EntryWindow `json:"entry_windows"`
+	// DailyResetTime (HH:MM): batas scan berlaku per hari dan direset pada jam ini; nil = untuk seluruh event
+	DailyResetTime         *string `json:"daily_reset_time"`
+	ReentryCooldownSeconds int     `json:"reentry_cooldown_seconds"`
+
 	IsActive bool `json:"is_active"`
 
 	pubEntity.DaoEntity
@@ -14,32 +14,58 @@ const (
 	GateLogActionInvalid   GateLogAction = "INVALID"
 	GateLogActionDuplicate GateLogAction = "DUPLICATE"
 	GateLogActionExceeded  GateLogAction = "EXCEEDED"
+	// GateLogActionAccessDenied: ticket type tidak punya akses ke zona gate tempat scan
+	GateLogActionAccessDenied GateLogAction = "ACCESS_DENIED"
+	// GateLogActionOutsideWindow: scan di luar jadwal masuk ticket type
+	GateLogActionOutsideWindow GateLogAction = "OUTSIDE_WINDOW"
+	// GateLogActionCooldown: masuk kembali sebelum jeda re-entry berakhir
+	GateLogActionCooldown GateLogAction = "COOLDOWN"
 )
 
 type GateLogQuery struct {
 	IDs               []string `query:"id"`
 	EventIDs          []string `query:"event_id"`
 	PhysicalTicketIDs []string `query:"physical_ticket_id"`
+	ETicketIDs        []string `query:"e_ticket_id"`
 	Actions           []string `query:"action"`
 	GateNames         []string `query:"gate_name"`
+	DeviceIDs         []string `query:"device_id"`
+	ClientScanIDs     []string `query:"client_scan_id"`
+
+	// Paging berbasis cursor seq; Latest mengurutkan dari log terbaru
+	AfterSeq  int64 `query:"after"`
+	BeforeSeq int64 `query:"before"`
+	Limit     int   `query:"limit"`
+	Latest    bool  `query:"-"`
 }
 
 type GateLog struct {
-	ID      pubEntity.UUID `json:"id"`
-	EventID pubEntity.UUID `json:"event_id"`
+	ID      pubEntity.UUID  `json:"id"`
+	Seq     int64           `json:"seq"`      // diisi database, bertambah monoton per insert
+	EventID *pubEntity.UUID `json:"event_id"` // nil jika QR palsu tidak bisa dikaitkan ke event
 
-	PhysicalTicketID pubEntity.UUID `json:"physical_ticket_id"`
-	ScannedBy        string         `json:"scanned_by"`
+	// Satu scan merujuk tiket fisik atau e-ticket order; keduanya nil untuk scan INVALID tanpa tiket
+	PhysicalTicketID *pubEntity.UUID `json:"physical_ticket_id"`
+	ETicketID        *pubEntity.UUID `json:"e_ticket_id"`
+	QRCode           *string         `json:"qr_code"`
+	ScannedBy        string          `json:"scanned_by"`
 
 	Action  GateLogAction `json:"action"`
 	Success bool          `json:"success"`
 	Message string        `json:"message"`
 
-	GateName     string `json:"gate_name"`
-	TicketType   string `json:"ticket_type"`
-	ScanSequence int    `json:"scan_sequence"`
+	GateName     string          `json:"gate_name"`
+	ZoneID       *pubEntity.UUID `json:"zone_id"` // zona gate_name saat scan, nil jika gate tidak terdaftar
+	TicketType   string          `json:"ticket_type"`
+	ScanSequence int             `json:"scan_sequence"`
+
+	// Scan offline: waktu scan di perangkat dan ID scan dari perangkat untuk idempotensi sync
+	DeviceID     *string    `json:"device_id"`
+	ClientScanID *string    `json:"client_scan_id"`
+	ScannedAt    *time.Time `json:"scanned_at"`
 
-	CreatedAt time.Time `json:"created_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
 }
 
 type GateLogs []GateLog
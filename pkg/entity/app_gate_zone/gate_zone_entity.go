@@ -0,0 +1,49 @@
+package app_gate_zone
+
+import (
+	"time"
+
+	pubEntity "rakit-tiket-be/pkg/entity"
+)
+
+type GateZoneQuery struct {
+	IDs      []string `query:"id"`
+	EventIDs []string `query:"event_id"`
+}
+
+type GateZone struct {
+	ID      pubEntity.UUID `json:"id"`
+	EventID pubEntity.UUID `json:"event_id"`
+
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Capacity *int   `json:"capacity"`
+
+	pubEntity.DaoEntity
+}
+
+type GateZones []GateZone
+
+// ZoneGate mendaftarkan gate ke satu zona; satu gate_name hanya boleh ada di satu zona per event
+type ZoneGate struct {
+	ID       pubEntity.UUID `json:"id"`
+	EventID  pubEntity.UUID `json:"event_id"`
+	ZoneID   pubEntity.UUID `json:"zone_id"`
+	GateName string         `json:"gate_name"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ZoneGates []ZoneGate
+
+// ZoneAccess adalah satu sel matriks akses: ticket type boleh masuk ke zona
+type ZoneAccess struct {
+	ID         pubEntity.UUID `json:"id"`
+	EventID    pubEntity.UUID `json:"event_id"`
+	ZoneID     pubEntity.UUID `json:"zone_id"`
+	TicketType string         `json:"ticket_type"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ZoneAccesses []ZoneAccess
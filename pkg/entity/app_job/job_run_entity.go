@@ -0,0 +1,40 @@
+package app_job
+
+import (
+	"time"
+
+	pubEntity "rakit-tiket-be/pkg/entity"
+)
+
+type JobRunStatus string
+
+const (
+	JobRunStatusRunning JobRunStatus = "RUNNING"
+	JobRunStatusSuccess JobRunStatus = "SUCCESS"
+	JobRunStatusFailed  JobRunStatus = "FAILED"
+)
+
+type (
+	JobRunQuery struct {
+		IDs      []string `query:"id"`
+		JobNames []string `query:"job_name"`
+		Statuses []string `query:"status"`
+
+		pubEntity.PagingQuery
+	}
+
+	// JobRun mencatat satu kali eksekusi background job
+	JobRun struct {
+		ID       pubEntity.UUID `json:"id"`
+		JobName  string         `json:"job_name"`
+		Instance *string        `json:"instance"`
+		Status   JobRunStatus   `json:"status"`
+
+		StartedAt    time.Time  `json:"started_at"`
+		FinishedAt   *time.Time `json:"finished_at"`
+		AffectedRows int64      `json:"affected_rows"`
+		Error        *string    `json:"error"`
+	}
+
+	JobRuns []JobRun
+)
@@ -0,0 +1,57 @@
+package app_order
+
+import (
+	"time"
+
+	pubEntity "rakit-tiket-be/pkg/entity"
+)
+
+type ETicketStatus string
+
+// Status e-ticket mengikuti status tiket fisik agar keduanya diproses pipeline scan yang sama
+const (
+	ETicketStatusActive     ETicketStatus = "ACTIVE"
+	ETicketStatusCheckedIn  ETicketStatus = "CHECKED_IN"
+	ETicketStatusCheckedOut ETicketStatus = "CHECKED_OUT"
+	ETicketStatusVoid       ETicketStatus = "VOID"
+)
+
+type (
+	ETicketQuery struct {
+		IDs           []string `query:"id"`
+		EventIDs      []string `query:"event_id"`
+		OrderIDs      []string `query:"order_id"`
+		RegistrantIDs []string `query:"registrant_id"`
+		Codes         []string `query:"code"`
+		OrderNumbers  []string `query:"order_number"`
+	}
+
+	// ETicket adalah tiket satu kursi dalam order (registrant atau salah satu attendee)
+	ETicket struct {
+		ID           pubEntity.UUID  `json:"id"`
+		EventID      pubEntity.UUID  `json:"event_id"`
+		OrderID      pubEntity.UUID  `json:"order_id"`
+		RegistrantID pubEntity.UUID  `json:"registrant_id"`
+		AttendeeID   *pubEntity.UUID `json:"attendee_id"` // nil = kursi milik registrant
+		TicketID     pubEntity.UUID  `json:"ticket_id"`
+
+		HolderName string `json:"holder_name"`
+		SeatNumber int    `json:"seat_number"`
+		Code       string `json:"code"`
+
+		Status       ETicketStatus `json:"status"`
+		ScanCount    int           `json:"scan_count"`
+		CheckedIn    bool          `json:"checked_in"`
+		CheckedInAt  *time.Time    `json:"checked_in_at"`
+		CheckedOutAt *time.Time    `json:"checked_out_at"`
+
+		// Hanya dibaca (join tickets dan orders), dipakai aturan gate dan validasi scan
+		TicketType    string `json:"ticket_type"`
+		OrderNumber   string `json:"order_number"`
+		PaymentStatus string `json:"payment_status"`
+
+		pubEntity.DaoEntity
+	}
+
+	ETickets []ETicket
+)
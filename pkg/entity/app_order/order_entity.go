@@ -8,11 +8,13 @@ import (
 
 // Payment Status Constants
 const (
-	OrderStatusPending  = "pending"
-	OrderStatusPaid     = "paid"
-	OrderStatusExpired  = "expired"
-	OrderStatusFailed   = "failed"
-	OrderStatusRejected = "rejected"
+	OrderStatusPending   = "pending"
+	OrderStatusPaid      = "paid"
+	OrderStatusExpired   = "expired"
+	OrderStatusFailed    = "failed"
+	OrderStatusRejected  = "rejected"
+	OrderStatusRefunded  = "refunded"
+	OrderStatusCancelled = "cancelled"
 )
 
 // Payment Type Constants
@@ -30,6 +32,7 @@ type (
 		PaymentGateways []string   `query:"payment_gateway"`
 		Statuses        []string   `query:"payment_status"`
 		ExpiredBefore   *time.Time `query:"expired_before"`
+		ExpiredAfter    *time.Time `query:"expired_after"`
 	}
 
 	Order struct {
@@ -44,6 +47,10 @@ type (
 		Amount      float64 `json:"amount"`
 		Currency    string  `json:"currency"`
 
+		// Promo: Amount sudah dikurangi DiscountAmount
+		DiscountAmount float64 `json:"discount_amount"`
+		PromoCode      *string `json:"promo_code"`
+
 		// Payment Type: GATEWAY (Midtrans) atau MANUAL (Transfer)
 		PaymentType *string `json:"payment_type"`
 
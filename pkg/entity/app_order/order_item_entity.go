@@ -0,0 +1,34 @@
+package app_order
+
+import (
+	pubEntity "rakit-tiket-be/pkg/entity"
+)
+
+type (
+	OrderItemQuery struct {
+		IDs      []string `query:"id"`
+		OrderIDs []string `query:"order_id"`
+	}
+
+	// OrderItem menyimpan harga yang sudah di-resolve saat registrasi (per jenis tiket dan tier)
+	OrderItem struct {
+		ID       pubEntity.UUID `json:"id"`
+		OrderID  pubEntity.UUID `json:"order_id"`
+		TicketID pubEntity.UUID `json:"ticket_id"`
+
+		TicketTitle   string  `json:"ticket_title"`
+		Quantity      int     `json:"quantity"`
+		UnitPrice     float64 `json:"unit_price"`
+		OriginalPrice float64 `json:"original_price"`
+		IsFlashSale   bool    `json:"is_flash_sale"`
+		Subtotal      float64 `json:"subtotal"`
+
+		// Tier harga saat registrasi, nil untuk tiket tanpa tier group
+		TierID   *pubEntity.UUID `json:"tier_id"`
+		TierName *string         `json:"tier_name"`
+
+		pubEntity.DaoEntity
+	}
+
+	OrderItems []OrderItem
+)
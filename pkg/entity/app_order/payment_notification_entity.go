@@ -0,0 +1,20 @@
+package app_order
+
+import (
+	"time"
+
+	pubEntity "rakit-tiket-be/pkg/entity"
+)
+
+type (
+	// PaymentNotification mencatat notifikasi webhook yang sudah diproses
+	PaymentNotification struct {
+		ID             pubEntity.UUID `json:"id"`
+		Gateway        string         `json:"gateway"`
+		NotificationID string         `json:"notification_id"`
+		OrderNumber    string         `json:"order_number"`
+		PaymentStatus  string         `json:"payment_status"`
+		GrossAmount    float64        `json:"gross_amount"`
+		CreatedAt      time.Time      `json:"created_at"`
+	}
+)
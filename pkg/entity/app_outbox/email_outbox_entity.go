@@ -0,0 +1,78 @@
+package app_outbox
+
+import (
+	"time"
+
+	pubEntity "rakit-tiket-be/pkg/entity"
+)
+
+type EmailType string
+
+const (
+	EmailTypeTicket           EmailType = "TICKET"            // E-ticket setelah pembayaran gateway
+	EmailTypeTransferApproved EmailType = "TRANSFER_APPROVED" // E-ticket setelah transfer manual disetujui
+	EmailTypePaymentRejected  EmailType = "PAYMENT_REJECTED"
+	EmailTypePaymentCancelled EmailType = "PAYMENT_CANCELLED"
+	EmailTypeRefund           EmailType = "REFUND"
+	EmailTypeWaitlistOffer    EmailType = "WAITLIST_OFFER" // Link klaim stok untuk antrean waitlist
+	EmailTypeOrderExpired     EmailType = "ORDER_EXPIRED"
+	EmailTypePaymentReminder  EmailType = "PAYMENT_REMINDER" // Order pending yang hampir expired
+)
+
+type EmailStatus string
+
+const (
+	EmailStatusPending    EmailStatus = "PENDING"
+	EmailStatusProcessing EmailStatus = "PROCESSING"
+	EmailStatusSent       EmailStatus = "SENT"
+	EmailStatusDead       EmailStatus = "DEAD" // Melebihi max_attempts, perlu dikirim ulang manual
+)
+
+type (
+	EmailOutboxQuery struct {
+		IDs      []string `query:"id"`
+		OrderIDs []string `query:"order_id"`
+		Types    []string `query:"type"`
+		Statuses []string `query:"status"`
+
+		pubEntity.PagingQuery
+	}
+
+	// EmailPayload berisi data template email. E-ticket (PDF) dibuat ulang dari order saat dikirim.
+	EmailPayload struct {
+		OrderNumber     string `json:"order_number"`
+		EventName       string `json:"event_name,omitempty"`
+		OwnerName       string `json:"owner_name,omitempty"`
+		Reason          string `json:"reason,omitempty"`
+		Amount          string `json:"amount,omitempty"`
+		IsFull          bool   `json:"is_full,omitempty"`
+		ViaBankTransfer bool   `json:"via_bank_transfer,omitempty"`
+		PaymentURL      string `json:"payment_url,omitempty"`
+
+		// Penawaran waitlist, link klaim dibuat dari ClaimToken saat email dikirim
+		TicketTitle string `json:"ticket_title,omitempty"`
+		Quantity    int    `json:"quantity,omitempty"`
+		ClaimToken  string `json:"claim_token,omitempty"`
+		ExpiresAt   string `json:"expires_at,omitempty"`
+	}
+
+	EmailOutbox struct {
+		ID        pubEntity.UUID  `json:"id"`
+		Type      EmailType       `json:"type"`
+		OrderID   *pubEntity.UUID `json:"order_id"`
+		Recipient string          `json:"recipient"`
+		Payload   EmailPayload    `json:"payload"`
+
+		Status        EmailStatus `json:"status"`
+		Attempts      int         `json:"attempts"`
+		MaxAttempts   int         `json:"max_attempts"`
+		NextAttemptAt time.Time   `json:"next_attempt_at"`
+		LockedAt      *time.Time  `json:"locked_at"`
+		LastError     *string     `json:"last_error"`
+		SentAt        *time.Time  `json:"sent_at"`
+
+		pubEntity.DaoEntity
+	}
+
+	EmailOutboxes []EmailOutbox
+)
@@ -0,0 +1,68 @@
+package app_payment
+
+import (
+	"time"
+
+	pubEntity "rakit-tiket-be/pkg/entity"
+)
+
+type RefundType string
+
+const (
+	RefundTypeRefund RefundType = "REFUND"
+	RefundTypeCancel RefundType = "CANCEL"
+)
+
+type RefundMethod string
+
+const (
+	RefundMethodGateway      RefundMethod = "GATEWAY"
+	RefundMethodBankTransfer RefundMethod = "BANK_TRANSFER"
+)
+
+type RefundStatus string
+
+const (
+	RefundStatusPending   RefundStatus = "PENDING"
+	RefundStatusCompleted RefundStatus = "COMPLETED"
+	RefundStatusFailed    RefundStatus = "FAILED"
+)
+
+type Refund struct {
+	ID      pubEntity.UUID `json:"id"`
+	OrderID pubEntity.UUID `json:"order_id"`
+
+	Type   RefundType   `json:"type"`
+	Method RefundMethod `json:"method"`
+	Status RefundStatus `json:"status"`
+	Amount float64      `json:"amount"`
+	IsFull bool         `json:"is_full"`
+	Reason *string      `json:"reason"`
+
+	// Gateway (GATEWAY)
+	Gateway         *string `json:"gateway"`
+	RefundKey       *string `json:"refund_key"`
+	GatewayRefundID *string `json:"gateway_refund_id"`
+
+	// SubmittedAt kosong = refund belum diterima gateway, dikirim ulang dengan RefundKey yang sama
+	SubmittedAt *time.Time `json:"submitted_at"`
+
+	// Rekening tujuan (BANK_TRANSFER)
+	BankName      *string `json:"bank_name"`
+	AccountNumber *string `json:"account_number"`
+	AccountHolder *string `json:"account_holder"`
+
+	// Audit Trail
+	ProcessedBy *pubEntity.UUID `json:"processed_by"`
+	CompletedAt *time.Time      `json:"completed_at"`
+
+	pubEntity.DaoEntity
+}
+
+type Refunds []Refund
+
+type RefundQuery struct {
+	IDs      []string `query:"id"`
+	OrderIDs []string `query:"order_id"`
+	Statuses []string `query:"status"`
+}
@@ -0,0 +1,48 @@
+package app_physical_ticket
+
+import (
+	"time"
+
+	pubEntity "rakit-tiket-be/pkg/entity"
+)
+
+type PhysicalTicketAuditAction string
+
+const (
+	PhysicalTicketAuditVoid      PhysicalTicketAuditAction = "VOID"
+	PhysicalTicketAuditReplace   PhysicalTicketAuditAction = "REPLACE"  // dicatat di tiket lama
+	PhysicalTicketAuditReissue   PhysicalTicketAuditAction = "REISSUE"  // dicatat di tiket pengganti
+	PhysicalTicketAuditReassign  PhysicalTicketAuditAction = "REASSIGN" // pindah ticket type
+	PhysicalTicketAuditResetScan PhysicalTicketAuditAction = "RESET_SCAN"
+)
+
+type PhysicalTicketAuditQuery struct {
+	EventIDs          []string `query:"event_id"`
+	PhysicalTicketIDs []string `query:"physical_ticket_id"`
+	Actions           []string `query:"action"`
+}
+
+// PhysicalTicketAudit mencatat setiap perubahan tiket fisik oleh admin, berdampingan dengan gate_logs
+// yang mencatat scan. Nilai sebelum dan sesudah disimpan agar perubahan bisa ditelusuri.
+type PhysicalTicketAudit struct {
+	ID               pubEntity.UUID            `json:"id"`
+	EventID          pubEntity.UUID            `json:"event_id"`
+	PhysicalTicketID pubEntity.UUID            `json:"physical_ticket_id"`
+	Action           PhysicalTicketAuditAction `json:"action"`
+
+	FromStatus     PhysicalTicketStatus `json:"from_status"`
+	ToStatus       PhysicalTicketStatus `json:"to_status"`
+	FromTicketType string               `json:"from_ticket_type"`
+	ToTicketType   string               `json:"to_ticket_type"`
+	FromScanCount  int                  `json:"from_scan_count"`
+	ToScanCount    int                  `json:"to_scan_count"`
+
+	// RelatedTicketID: tiket pengganti (REPLACE) atau tiket yang digantikan (REISSUE)
+	RelatedTicketID *pubEntity.UUID `json:"related_ticket_id"`
+
+	Reason      string    `json:"reason"`
+	PerformedBy string    `json:"performed_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type PhysicalTicketAudits []PhysicalTicketAudit
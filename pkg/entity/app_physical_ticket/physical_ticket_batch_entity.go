@@ -0,0 +1,30 @@
+package app_physical_ticket
+
+import (
+	"time"
+
+	pubEntity "rakit-tiket-be/pkg/entity"
+)
+
+type PhysicalTicketBatchQuery struct {
+	IDs      []string `query:"id"`
+	EventIDs []string `query:"event_id"`
+}
+
+// PhysicalTicketBatch adalah satu kali generate tiket fisik yang dikirim ke vendor cetak
+type PhysicalTicketBatch struct {
+	ID      pubEntity.UUID `json:"id"`
+	EventID pubEntity.UUID `json:"event_id"`
+
+	Vendor    string     `json:"vendor"`
+	PrintDate *time.Time `json:"print_date"`
+
+	// TicketCounts: ticket type -> jumlah tiket saat batch dibuat
+	TicketCounts map[string]int `json:"ticket_counts"`
+
+	CreatedBy string `json:"created_by"`
+
+	pubEntity.DaoEntity
+}
+
+type PhysicalTicketBatches []PhysicalTicketBatch
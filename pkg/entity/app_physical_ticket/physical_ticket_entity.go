@@ -23,6 +23,11 @@ type PhysicalTicketQuery struct {
 	TicketTypes []string               `query:"ticket_type"`
 	QRCodes     []string               `query:"qr_code"`
 	Statuses    []PhysicalTicketStatus `query:"status"`
+	ReplacesIDs []string               `query:"replaces_id"`
+	BatchIDs    []string               `query:"batch_id"`
+	Serials     []string               `query:"serial"`
+
+	pubEntity.PagingQuery
 }
 
 type PhysicalTicket struct {
@@ -41,6 +46,17 @@ type PhysicalTicket struct {
 	CheckedInAt  *time.Time `json:"checked_in_at"`
 	CheckedOutAt *time.Time `json:"checked_out_at"`
 
+	// ScansResetAt adalah waktu reset scan terakhir; gate_logs sebelumnya tidak dihitung lagi
+	ScansResetAt *time.Time `json:"scans_reset_at"`
+
+	// ReplacesID adalah tiket lama (hilang / rusak) yang digantikan tiket ini
+	ReplacesID *pubEntity.UUID `json:"replaces_id"`
+
+	// Batch cetak; tiket lama dan tiket pengganti tidak punya batch
+	BatchID  *pubEntity.UUID `json:"batch_id"`
+	BatchSeq *int            `json:"batch_seq"` // urutan cetak per ticket type dalam batch, mulai dari 1
+	Serial   *string         `json:"serial"`    // kode yang dicetak di bawah QR
+
 	pubEntity.DaoEntity
 }
 
@@ -0,0 +1,118 @@
+package app_presale
+
+import (
+	pubEntity "rakit-tiket-be/pkg/entity"
+	ticketEntity "rakit-tiket-be/pkg/entity/app_ticket"
+)
+
+type (
+	AccessCodeQuery struct {
+		IDs      []string `query:"id"`
+		EventIDs []string `query:"event_id"`
+		Codes    []string `query:"code"` // dicocokkan tanpa membedakan huruf besar / kecil
+		IsActive *bool    `query:"is_active"`
+	}
+
+	AccessCode struct {
+		ID          pubEntity.UUID `json:"id"`
+		EventID     pubEntity.UUID `json:"event_id"`
+		Code        string         `json:"code"`
+		Description *string        `json:"description"`
+
+		// Scope, kosong berarti semua tiket presale event
+		TicketIDs []string `json:"ticket_ids"`
+
+		IsActive bool `json:"is_active"`
+
+		pubEntity.DaoEntity
+	}
+
+	AccessCodes []AccessCode
+)
+
+type (
+	AllowlistQuery struct {
+		IDs      []string `query:"id"`
+		EventIDs []string `query:"event_id"`
+		Emails   []string `query:"email"` // hanya allowlist yang memuat salah satu email
+	}
+
+	Allowlist struct {
+		ID      pubEntity.UUID `json:"id"`
+		EventID pubEntity.UUID `json:"event_id"`
+		Name    string         `json:"name"`
+
+		// Scope, kosong berarti semua tiket presale event
+		TicketIDs []string `json:"ticket_ids"`
+
+		EmailCount int `json:"email_count"`
+
+		pubEntity.DaoEntity
+	}
+
+	Allowlists []Allowlist
+)
+
+// Credential adalah bukti akses presale yang dikirim pembeli: kode akses dan / atau email
+type Credential struct {
+	AccessCode string `json:"access_code" query:"access_code"`
+	Email      string `json:"email" query:"email"`
+}
+
+func (c Credential) IsEmpty() bool {
+	return c.AccessCode == "" && c.Email == ""
+}
+
+// Access adalah hasil resolve Credential: event yang seluruh tiket presalenya terbuka
+// dan tiket presale yang terbuka satu per satu. Zero value tidak membuka tiket presale apa pun.
+type Access struct {
+	events  map[pubEntity.UUID]bool
+	tickets map[string]bool
+}
+
+// Grant membuka tiket presale event; ticketIDs kosong berarti semua tiket presale event
+func (a *Access) Grant(eventID pubEntity.UUID, ticketIDs []string) {
+	if len(ticketIDs) == 0 {
+		if a.events == nil {
+			a.events = make(map[pubEntity.UUID]bool)
+		}
+		a.events[eventID] = true
+		return
+	}
+
+	if a.tickets == nil {
+		a.tickets = make(map[string]bool)
+	}
+	for _, id := range ticketIDs {
+		a.tickets[id] = true
+	}
+}
+
+// Allows bernilai true untuk tiket non-presale atau tiket presale yang sudah dibuka
+func (a Access) Allows(t ticketEntity.Ticket) bool {
+	return !t.IsPresale || a.events[t.EventID] || a.tickets[string(t.ID)]
+}
+
+// Filter membuang tiket presale yang belum dibuka
+func (a Access) Filter(tickets ticketEntity.Tickets) ticketEntity.Tickets {
+	visible := make(ticketEntity.Tickets, 0, len(tickets))
+	for _, t := range tickets {
+		if a.Allows(t) {
+			visible = append(visible, t)
+		}
+	}
+	return visible
+}
+
+// PresaleEventIDs mengembalikan event yang punya tiket presale, untuk membatasi resolve Credential
+func PresaleEventIDs(tickets ticketEntity.Tickets) []string {
+	seen := make(map[pubEntity.UUID]bool)
+	var eventIDs []string
+	for _, t := range tickets {
+		if t.IsPresale && !seen[t.EventID] {
+			seen[t.EventID] = true
+			eventIDs = append(eventIDs, string(t.EventID))
+		}
+	}
+	return eventIDs
+}
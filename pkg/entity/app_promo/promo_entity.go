@@ -0,0 +1,103 @@
+package app_promo
+
+import (
+	"time"
+
+	pubEntity "rakit-tiket-be/pkg/entity"
+)
+
+type DiscountType string
+
+const (
+	DiscountTypePercent DiscountType = "PERCENT"
+	DiscountTypeFixed   DiscountType = "FIXED"
+)
+
+type RedemptionStatus string
+
+const (
+	RedemptionStatusActive   RedemptionStatus = "ACTIVE"
+	RedemptionStatusReleased RedemptionStatus = "RELEASED" // order expired / gagal, kuota dikembalikan
+)
+
+type (
+	PromoCodeQuery struct {
+		IDs      []string `query:"id"`
+		Codes    []string `query:"code"` // dicocokkan tanpa membedakan huruf besar / kecil
+		IsActive *bool    `query:"is_active"`
+	}
+
+	PromoCode struct {
+		ID          pubEntity.UUID `json:"id"`
+		Code        string         `json:"code"`
+		Description *string        `json:"description"`
+
+		// Discount: PERCENT (0-100, opsional dibatasi MaxDiscount) atau FIXED (rupiah)
+		DiscountType  DiscountType `json:"discount_type"`
+		DiscountValue float64      `json:"discount_value"`
+		MaxDiscount   *float64     `json:"max_discount"`
+
+		// Scope, kosong berarti berlaku untuk semua event / ticket type
+		EventIDs    []string `json:"event_ids"`
+		TicketTypes []string `json:"ticket_types"`
+
+		// Limit; nil berarti tanpa batas
+		MaxUses         *int `json:"max_uses"`
+		MaxUsesPerEmail *int `json:"max_uses_per_email"`
+		UsedCount       int  `json:"used_count"`
+		MinQty          int  `json:"min_qty"` // minimal jumlah tiket yang memenuhi scope
+
+		StartsAt *time.Time `json:"starts_at"`
+		EndsAt   *time.Time `json:"ends_at"`
+		IsActive bool       `json:"is_active"`
+
+		pubEntity.DaoEntity
+	}
+
+	PromoCodes []PromoCode
+)
+
+// AppliesToEvent bernilai true jika EventIDs kosong atau memuat eventID
+func (p PromoCode) AppliesToEvent(eventID string) bool {
+	return len(p.EventIDs) == 0 || contains(p.EventIDs, eventID)
+}
+
+// AppliesToTicketType bernilai true jika TicketTypes kosong atau memuat ticketType
+func (p PromoCode) AppliesToTicketType(ticketType string) bool {
+	return len(p.TicketTypes) == 0 || contains(p.TicketTypes, ticketType)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+type (
+	PromoRedemptionQuery struct {
+		PromoCodeIDs []string           `query:"promo_code_id"`
+		OrderIDs     []string           `query:"order_id"`
+		Emails       []string           `query:"email"`
+		Statuses     []RedemptionStatus `query:"status"`
+	}
+
+	// PromoRedemption mencatat pemakaian kode promo oleh satu order
+	PromoRedemption struct {
+		ID             pubEntity.UUID   `json:"id"`
+		PromoCodeID    pubEntity.UUID   `json:"promo_code_id"`
+		OrderID        pubEntity.UUID   `json:"order_id"`
+		Email          string           `json:"email"`
+		DiscountAmount float64          `json:"discount_amount"`
+		Status         RedemptionStatus `json:"status"`
+		ReleasedAt     *time.Time       `json:"released_at"`
+		CreatedAt      time.Time        `json:"created_at"`
+
+		// Hanya dibaca (join orders)
+		OrderNumber string `json:"order_number"`
+	}
+
+	PromoRedemptions []PromoRedemption
+)
@@ -0,0 +1,33 @@
+package app_scanner_device
+
+import (
+	"time"
+
+	pubEntity "rakit-tiket-be/pkg/entity"
+)
+
+type ScannerDeviceQuery struct {
+	IDs            []string `query:"id"`
+	DeviceIDs      []string `query:"device_id"`
+	EventIDs       []string `query:"event_id"`
+	TokenHashes    []string `query:"-"`
+	IncludeRevoked bool     `query:"include_revoked"`
+}
+
+type ScannerDevice struct {
+	ID       pubEntity.UUID `json:"id"`
+	DeviceID string         `json:"device_id"`
+	Name     string         `json:"name"`
+
+	EventID  pubEntity.UUID `json:"event_id"`
+	GateName string         `json:"gate_name"`
+
+	TokenHash  string          `json:"-"`
+	RevokedAt  *time.Time      `json:"revoked_at"`
+	LastSeenAt *time.Time      `json:"last_seen_at"`
+	CreatedBy  *pubEntity.UUID `json:"created_by"`
+
+	pubEntity.DaoEntity
+}
+
+type ScannerDevices []ScannerDevice
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"time"
+
+	pubEntity "rakit-tiket-be/pkg/entity"
+)
+
+type (
+	PriceTierQuery struct {
+		IDs       []string `query:"id"`
+		TicketIDs []string `query:"ticket_id"`
+	}
+
+	// PriceTier adalah satu tingkat harga dalam tier group sebuah tiket.
+	// Tier berakhir saat booked + sold tiket mencapai EndSoldQty (kumulatif) atau saat EndAt terlewati.
+	PriceTier struct {
+		ID       pubEntity.UUID `json:"id"`
+		TicketID pubEntity.UUID `json:"ticket_id"`
+
+		TierOrder  int        `json:"tier_order"`
+		Name       string     `json:"name"`
+		Price      float64    `json:"price"`
+		EndSoldQty *int       `json:"end_sold_qty"`
+		EndAt      *time.Time `json:"end_at"`
+
+		CreatedAt time.Time  `json:"created_at"`
+		UpdatedAt *time.Time `json:"updated_at"`
+	}
+
+	PriceTiers []PriceTier
+
+	// TierStats adalah rekap penjualan per tier untuk ops
+	TierStats struct {
+		PriceTier
+
+		TicketTitle string `json:"ticket_title"`
+		IsActive    bool   `json:"is_active"`
+		IsEnded     bool   `json:"is_ended"`
+
+		// SoldQty dari order paid, BookedQty dari order pending
+		SoldQty   int     `json:"sold_qty"`
+		BookedQty int     `json:"booked_qty"`
+		Revenue   float64 `json:"revenue"`
+	}
+
+	TierStatsList []TierStats
+)
@@ -12,15 +12,19 @@ const (
 	TicketStatusAvailable TicketStatus = "AVAILABLE" // available_qty
 	TicketStatusBooked    TicketStatus = "BOOKOUT"   // booked_qty
 	TicketStatusSold      TicketStatus = "SOLD"      // sold_qty
+
+	// Hanya di response, tidak disimpan: stok masih ada tetapi penjualan / semua tier sudah berakhir
+	TicketStatusSaleEnded TicketStatus = "SALE_ENDED"
 )
 
 type (
 	TicketQuery struct {
-		IDs       []string       `query:"id"`
-		EventIDs  []string       `query:"event_id"`
-		Types     []string       `query:"type"`
-		IsPresale *bool          `query:"is_presale"`
-		Statuses  []TicketStatus `query:"status"`
+		IDs         []string       `query:"id"`
+		EventIDs    []string       `query:"event_id"`
+		Types       []string       `query:"type"`
+		IsPresale   *bool          `query:"is_presale"`
+		IsFlashSale *bool          `query:"is_flash_sale"`
+		Statuses    []TicketStatus `query:"status"`
 	}
 
 	Ticket struct {
@@ -72,6 +76,11 @@ type (
 		ShowCountdown bool       `json:"show_countdown"`
 		CountdownEnd  *time.Time `json:"countdown_end"`
 
+		// Tier Pricing, diisi service dari ticket_price_tiers (bukan kolom tickets)
+		PriceTiers PriceTiers `json:"price_tiers,omitempty"`
+		ActiveTier *PriceTier `json:"active_tier,omitempty"`
+		NextTier   *PriceTier `json:"next_tier,omitempty"`
+
 		pubEntity.DaoEntity
 	}
 
@@ -103,6 +112,13 @@ type (
 		SaleStartTime     *string  `json:"sale_start_time"`
 		SaleEndTime       *string  `json:"sale_end_time"`
 		UrgentMessage     *string  `json:"urgent_message"`
+
+		// Tier Pricing: tier yang sedang berlaku dan harga tier berikutnya
+		ActiveTierName *string  `json:"active_tier_name"`
+		TierEndsAt     *string  `json:"tier_ends_at"`
+		TierRemaining  *int     `json:"tier_remaining"`
+		NextTierName   *string  `json:"next_tier_name"`
+		NextPrice      *float64 `json:"next_price"`
 	}
 
 	Tickets []Ticket
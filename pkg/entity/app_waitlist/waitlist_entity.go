@@ -0,0 +1,92 @@
+package app_waitlist
+
+import (
+	"time"
+
+	pubEntity "rakit-tiket-be/pkg/entity"
+)
+
+type EntryStatus string
+
+const (
+	EntryStatusWaiting   EntryStatus = "WAITING"   // Mengantre, menunggu stok dilepas
+	EntryStatusOffered   EntryStatus = "OFFERED"   // Stok ditahan, link klaim dikirim ke email
+	EntryStatusClaimed   EntryStatus = "CLAIMED"   // Penawaran dipakai untuk registrasi (order_id terisi)
+	EntryStatusExpired   EntryStatus = "EXPIRED"   // Penawaran tidak diklaim sampai batas waktu
+	EntryStatusCancelled EntryStatus = "CANCELLED" // Keluar antrean (pembeli / admin)
+)
+
+type (
+	EntryQuery struct {
+		IDs       []string `query:"id"`
+		EventIDs  []string `query:"event_id"`
+		TicketIDs []string `query:"ticket_id"`
+		Emails    []string `query:"email"`
+		Statuses  []string `query:"status"`
+
+		TokenHashes        []string   `query:"-"`
+		ClaimTokenHashes   []string   `query:"-"`
+		OfferExpiredBefore *time.Time `query:"-"`
+	}
+
+	Entry struct {
+		ID       pubEntity.UUID `json:"id"`
+		EventID  pubEntity.UUID `json:"event_id"`
+		TicketID pubEntity.UUID `json:"ticket_id"`
+
+		Email    string      `json:"email"`
+		Name     string      `json:"name"`
+		Quantity int         `json:"quantity"`
+		Status   EntryStatus `json:"status"`
+
+		// Urutan antrean untuk entry WAITING (mulai dari 1), 0 untuk status lain
+		Position int `json:"position"`
+
+		TokenHash      string  `json:"-"`
+		ClaimTokenHash *string `json:"-"`
+
+		OfferedAt      *time.Time      `json:"offered_at"`
+		OfferExpiresAt *time.Time      `json:"offer_expires_at"`
+		ClaimedAt      *time.Time      `json:"claimed_at"`
+		OrderID        *pubEntity.UUID `json:"order_id"`
+
+		CreatedAt time.Time  `json:"created_at"`
+		UpdatedAt *time.Time `json:"updated_at"`
+	}
+
+	Entries []Entry
+)
+
+// Offer adalah detail penawaran yang ditampilkan di halaman klaim
+type Offer struct {
+	EntryID        pubEntity.UUID `json:"entry_id"`
+	EventID        pubEntity.UUID `json:"event_id"`
+	EventName      string         `json:"event_name"`
+	TicketID       pubEntity.UUID `json:"ticket_id"`
+	TicketTitle    string         `json:"ticket_title"`
+	Email          string         `json:"email"`
+	Name           string         `json:"name"`
+	Quantity       int            `json:"quantity"`
+	OfferExpiresAt time.Time      `json:"offer_expires_at"`
+}
+
+// TicketStats adalah ringkasan antrean dan konversi waitlist per tiket
+type TicketStats struct {
+	TicketID    pubEntity.UUID `json:"ticket_id"`
+	TicketTitle string         `json:"ticket_title"`
+
+	Waiting    int `json:"waiting"`
+	WaitingQty int `json:"waiting_qty"` // total tiket yang diminta antrean WAITING
+	Offered    int `json:"offered"`
+	Claimed    int `json:"claimed"`
+	Expired    int `json:"expired"`
+	Cancelled  int `json:"cancelled"`
+
+	// Konversi: dari semua entry yang pernah ditawari, berapa yang klaim dan berapa yang order-nya lunas
+	EverOffered    int     `json:"ever_offered"`
+	Converted      int     `json:"converted"`
+	ClaimRate      float64 `json:"claim_rate"`      // persen, claimed / ever_offered
+	ConversionRate float64 `json:"conversion_rate"` // persen, converted / ever_offered
+}
+
+type TicketStatsList []TicketStats
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+type ReconciliationReport struct {
+	StartedAt     time.Time                   `json:"started_at"`
+	FinishedAt    time.Time                   `json:"finished_at"`
+	Checked       int                         `json:"checked"`
+	Applied       int                         `json:"applied"`
+	Failed        int                         `json:"failed"`
+	Discrepancies []ReconciliationDiscrepancy `json:"discrepancies"`
+}
+
+// ReconciliationDiscrepancy adalah order yang status lokalnya berbeda dengan status di gateway
+type ReconciliationDiscrepancy struct {
+	OrderNumber   string  `json:"order_number"`
+	Gateway       string  `json:"gateway"`
+	LocalStatus   string  `json:"local_status"`
+	GatewayStatus string  `json:"gateway_status"`
+	LocalAmount   float64 `json:"local_amount"`
+	GatewayAmount float64 `json:"gateway_amount"`
+	Applied       bool    `json:"applied"`
+	Error         string  `json:"error,omitempty"`
+}
@@ -8,6 +8,7 @@ import (
 
 	"rakit-tiket-be/internal/app/app_payment/service"
 	"rakit-tiket-be/pkg/entity"
+	addonEntity "rakit-tiket-be/pkg/entity/app_addon"
 	orderEntity "rakit-tiket-be/pkg/entity/app_order"
 	appRegistrantEntity "rakit-tiket-be/pkg/entity/app_registrant"
 	ticketEntity "rakit-tiket-be/pkg/entity/app_ticket"
@@ -35,6 +36,14 @@ type AttendeeData struct {
 type RegisterRequest struct {
 	Registrant RegistrantData `json:"registrant" validate:"required"`
 	Attendees  []AttendeeData `json:"attendees"`
+	PromoCode  string         `json:"promo_code"`
+	AccessCode string         `json:"access_code"` // kode akses tiket presale
+
+	// WaitlistToken adalah token klaim dari email penawaran waitlist; stok tiketnya sudah ditahan
+	WaitlistToken string `json:"waitlist_token"`
+
+	// Addons adalah add-on (merchandise, parkir, shuttle) yang dibeli bersama tiket
+	Addons []addonEntity.AddonSelection `json:"addons"`
 }
 
 type RegisterResponse struct {
@@ -45,12 +54,17 @@ type RegisterResponse struct {
 }
 
 type OrderInfo struct {
-	OrderID       string     `json:"order_id"`
-	OrderNumber   string     `json:"order_number"`
-	Amount        float64    `json:"amount"`
-	Currency      string     `json:"currency"`
-	PaymentStatus string     `json:"payment_status"`
-	ExpiresAt     *time.Time `json:"expires_at"`
+	OrderID        string     `json:"order_id"`
+	OrderNumber    string     `json:"order_number"`
+	Amount         float64    `json:"amount"`
+	DiscountAmount float64    `json:"discount_amount"`
+	PromoCode      *string    `json:"promo_code,omitempty"`
+	AddonAmount    float64    `json:"addon_amount"`
+	Currency       string     `json:"currency"`
+	PaymentStatus  string     `json:"payment_status"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+
+	Addons addonEntity.OrderAddons `json:"addons,omitempty"`
 }
 
 type RegistrantInfo struct {
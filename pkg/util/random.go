@@ -0,0 +1,17 @@
+package util
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+)
+
+var randomCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// MakeRandomCode membuat kode acak (crypto/rand) sepanjang nBytes byte dalam base32 huruf besar
+func MakeRandomCode(nBytes int) (string, error) {
+	buf := make([]byte, nBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return randomCodeEncoding.EncodeToString(buf), nil
+}